@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/qr"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// RenderPaperBackup renders a printable text backup of a contract with QR
+// codes for the funding address and redeem script. Private keys are only
+// included when includeKeys is set.
+func RenderPaperBackup(contractInfo *contract.ContractInfo, includeKeys bool) (string, error) {
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	parsed, err := script.ParseRedeemScript(redeemScript, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to read timelock from redeem script: %w", err)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "BITCOIN INHERITANCE CONTRACT - PAPER BACKUP\n")
+	fmt.Fprintf(&b, "%s\n\n", strings.Repeat("=", 60))
+	fmt.Fprintf(&b, "Contract ID: %s\n", contractInfo.ContractID)
+	if contractInfo.Label != "" {
+		fmt.Fprintf(&b, "Label:       %s\n", contractInfo.Label)
+	}
+	fmt.Fprintf(&b, "Network:     %s\n", contractInfo.Network)
+	fmt.Fprintf(&b, "Created:     %s\n", contractInfo.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&b, "Timelock:    %s\n\n", parsed.DescribeTimelock())
+
+	if err := writeQRSection(&b, "Funding Address (P2WSH)", contractInfo.P2WSHAddress); err != nil {
+		return "", err
+	}
+	if err := writeQRSection(&b, "Redeem Script", contractInfo.RedeemScript); err != nil {
+		return "", err
+	}
+
+	if includeKeys {
+		fmt.Fprintf(&b, "!!! PRIVATE KEYS - ANYONE WITH THIS SHEET CAN SPEND THE FUNDS !!!\n\n")
+		if err := writeQRSection(&b, "Owner Private Key (WIF)", contractInfo.OwnerWIF); err != nil {
+			return "", err
+		}
+		if err := writeQRSection(&b, "Inheritor Private Key (WIF)", contractInfo.InheritorWIF); err != nil {
+			return "", err
+		}
+	} else {
+		fmt.Fprintf(&b, "Private keys are not included on this sheet.\n")
+	}
+
+	return b.String(), nil
+}
+
+// writeQRSection writes a titled value followed by its QR code
+func writeQRSection(b *strings.Builder, title, value string) error {
+	if value == "" {
+		fmt.Fprintf(b, "%s: (not available)\n\n", title)
+		return nil
+	}
+
+	code, err := qr.Text(value)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", strings.ToLower(title), err)
+	}
+
+	fmt.Fprintf(b, "%s:\n%s\n%s\n", title, value, code)
+	return nil
+}
@@ -0,0 +1,92 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to build contract info for a fresh 180-day contract
+func createTestContractInfo(t *testing.T) *contract.ContractInfo {
+	t.Helper()
+
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		180,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	p2wshAddr, err := inheritanceScript.GetP2WSHAddress()
+	if err != nil {
+		t.Fatalf("GetP2WSHAddress failed: %v", err)
+	}
+
+	return &contract.ContractInfo{
+		ContractID:   contract.GenerateContractID(p2wshAddr, &chaincfg.TestNet3Params),
+		CreatedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Network:      chaincfg.TestNet3Params.Name,
+		TimelockDays: 180,
+		OwnerWIF:     "owner-wif",
+		InheritorWIF: "inheritor-wif",
+		RedeemScript: fmt.Sprintf("%x", inheritanceScript.RedeemScript),
+		P2WSHAddress: p2wshAddr.EncodeAddress(),
+		ScriptHash:   fmt.Sprintf("%x", inheritanceScript.GetScriptHash()),
+	}
+}
+
+func TestRenderPaperBackup(t *testing.T) {
+	contractInfo := createTestContractInfo(t)
+
+	sheet, err := RenderPaperBackup(contractInfo, false)
+	if err != nil {
+		t.Fatalf("RenderPaperBackup failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		contractInfo.ContractID,
+		contractInfo.P2WSHAddress,
+		contractInfo.RedeemScript,
+		"180 days = 4320 hours",
+		"█",
+	} {
+		if !strings.Contains(sheet, expected) {
+			t.Errorf("Paper backup is missing %q", expected)
+		}
+	}
+
+	if strings.Contains(sheet, "owner-wif") || strings.Contains(sheet, "inheritor-wif") {
+		t.Error("Paper backup contains private keys without includeKeys")
+	}
+}
+
+func TestRenderPaperBackup_IncludeKeys(t *testing.T) {
+	contractInfo := createTestContractInfo(t)
+
+	sheet, err := RenderPaperBackup(contractInfo, true)
+	if err != nil {
+		t.Fatalf("RenderPaperBackup failed: %v", err)
+	}
+
+	if !strings.Contains(sheet, "owner-wif") || !strings.Contains(sheet, "inheritor-wif") {
+		t.Error("Paper backup is missing private keys with includeKeys")
+	}
+}
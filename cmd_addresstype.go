@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+var generateAddressType string
+
+func init() {
+	generateCmd.Flags().StringVar(&generateAddressType, "address-type", "p2wsh", "Contract address type: p2wsh (native bech32) or p2sh (P2SH-P2WSH, for wallets that cannot pay to bech32)")
+}
+
+// parseGenerateAddressType returns the address type selected with
+// --address-type, where p2sh is short for p2sh-p2wsh
+func parseGenerateAddressType() (script.AddressType, error) {
+	if generateAddressType == "p2sh" {
+		return script.AddressTypeP2SHP2WSH, nil
+	}
+	addressType, err := script.ParseAddressType(generateAddressType)
+	if err != nil {
+		return "", fmt.Errorf("invalid --address-type: %w", err)
+	}
+	if addressType == script.AddressTypeP2TR {
+		return "", fmt.Errorf("--address-type p2tr is not supported; use p2wsh or p2sh")
+	}
+	return addressType, nil
+}
+
+// contractScriptPubKey returns the output script paying to a contract's
+// address of the given type
+func contractScriptPubKey(redeemScript []byte, addressType script.AddressType) ([]byte, error) {
+	inheritanceScript := &script.InheritanceScript{RedeemScript: redeemScript, ChainParams: cfg.ChainParams}
+	addr, err := inheritanceScript.GetAddress(addressType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build contract script: %w", err)
+	}
+	return txscript.PayToAddrScript(addr)
+}
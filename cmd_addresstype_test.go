@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Every address type generate accepts must be spendable by both withdrawal
+// commands; the others are refused before a contract is created
+func TestGenerate_AddressTypesAreSpendable(t *testing.T) {
+	tests := []struct {
+		flag     string
+		expected script.AddressType
+		wantErr  string
+	}{
+		{"p2wsh", script.AddressTypeP2WSH, ""},
+		{"p2sh", script.AddressTypeP2SHP2WSH, ""},
+		{"p2sh-p2wsh", script.AddressTypeP2SHP2WSH, ""},
+		{"p2tr", "", "--address-type p2tr is not supported"},
+		{"p2pkh", "", "invalid --address-type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			existingID, _ := setupNonInteractive(t)
+			t.Cleanup(func() {
+				generateAddressType = "p2wsh"
+				generateCmd.Flags().Lookup("address-type").Changed = false
+			})
+
+			err := runCommand(t, "generate", "--address-type", tt.flag)
+			contractIDs, listErr := contract.ListContracts()
+			if listErr != nil {
+				t.Fatalf("ListContracts failed: %v", listErr)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if len(contractIDs) != 1 {
+					t.Errorf("A refused address type created a contract: %v", contractIDs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("generate failed: %v", err)
+			}
+			if len(contractIDs) != 2 {
+				t.Fatalf("Expected the generated contract next to the existing one, got %v", contractIDs)
+			}
+			generatedID := contractIDs[0]
+			if generatedID == existingID {
+				generatedID = contractIDs[1]
+			}
+			contractInfo, err := contract.LoadContractInfo(generatedID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			if contractInfo.AddressType != tt.expected {
+				t.Errorf("Expected address type %q, got %q", tt.expected, contractInfo.AddressType)
+			}
+			txid := "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807"
+			if err := contract.UpdateFundingStatus(generatedID, txid, 0, 100000); err != nil {
+				t.Fatalf("UpdateFundingStatus failed: %v", err)
+			}
+
+			// Both spending paths sign, so the runs get as far as the
+			// broadcast, which fails at the unreachable node
+			for _, cmd := range []string{"owner-withdraw", "inheritor-withdraw"} {
+				err := runCommand(t, cmd, "--contract-id", generatedID, "--to", testDestination(t), "--yes")
+				if err == nil || !strings.Contains(err.Error(), "failed to broadcast") {
+					t.Errorf("Expected %s to fail only at the broadcast, got %v", cmd, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/spf13/cobra"
+)
+
+var auditFeeCmd = &cobra.Command{
+	Use:   "audit-fee [txid]",
+	Short: "Audit the fee actually paid by a withdrawal",
+	Long: `Fetch a transaction and its inputs from the node, compute the realized fee
+(sum of inputs minus sum of outputs) and fee rate, and compare it against the
+fee recorded when the withdrawal was broadcast. This command is read-only.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return auditFee(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditFeeCmd)
+}
+
+func auditFee(txid string) error {
+	log.Printf("=== Fee Audit: %s ===", txid)
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+
+	tx, err := rpcClient.GetRawTransactionCtx(ctx, txid)
+	if err != nil {
+		return fmt.Errorf("failed to fetch transaction: %w", err)
+	}
+
+	// Sum the values of the outputs spent by this transaction
+	var totalIn btcutil.Amount
+	for _, vin := range tx.Vin {
+		if vin.Coinbase != "" {
+			return fmt.Errorf("coinbase transactions do not pay a fee")
+		}
+
+		prevTx, err := rpcClient.GetRawTransactionCtx(ctx, vin.TxID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch input transaction %s: %w", vin.TxID, err)
+		}
+		if int(vin.Vout) >= len(prevTx.Vout) {
+			return fmt.Errorf("input %s:%d references a missing output", vin.TxID, vin.Vout)
+		}
+
+		value, err := btcutil.NewAmount(prevTx.Vout[vin.Vout].Value)
+		if err != nil {
+			return fmt.Errorf("invalid input amount: %w", err)
+		}
+		totalIn += value
+	}
+
+	var totalOut btcutil.Amount
+	for _, vout := range tx.Vout {
+		value, err := btcutil.NewAmount(vout.Value)
+		if err != nil {
+			return fmt.Errorf("invalid output amount: %w", err)
+		}
+		totalOut += value
+	}
+
+	realizedFee := totalIn - totalOut
+	log.Printf("Inputs: %d satoshis (%d inputs)", int64(totalIn), len(tx.Vin))
+	log.Printf("Outputs: %d satoshis (%d outputs)", int64(totalOut), len(tx.Vout))
+	log.Printf("Realized fee: %d satoshis", int64(realizedFee))
+	if tx.VSize > 0 {
+		log.Printf("Fee rate: %.2f sat/vB (%d vbytes)", float64(realizedFee)/float64(tx.VSize), tx.VSize)
+	}
+
+	// Compare against the fee recorded at broadcast time
+	contractInfo, record, err := contract.FindWithdrawal(txid)
+	if err != nil {
+		return fmt.Errorf("failed to search contracts: %w", err)
+	}
+	if record == nil {
+		log.Printf("No recorded withdrawal found for this transaction; nothing to compare against")
+		return nil
+	}
+
+	log.Printf("Contract: %s (%s path)", contractInfo.ContractID, record.Path)
+	log.Printf("Intended fee: %d satoshis", record.Fee)
+	if int64(realizedFee) != record.Fee {
+		log.Printf("⚠️  DISCREPANCY: realized fee differs from intended fee by %d satoshis",
+			int64(realizedFee)-record.Fee)
+	} else {
+		log.Printf("✅ Realized fee matches the intended fee")
+	}
+
+	return nil
+}
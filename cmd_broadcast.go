@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+var (
+	broadcastHex      string
+	broadcastFile     string
+	skipMempoolAccept bool
+)
+
+// maxRawTxHexSize bounds how much of stdin is read, well above the hex of
+// the largest standard transaction
+const maxRawTxHexSize = 4 << 20
+
+var broadcastCmd = &cobra.Command{
+	Use:   "broadcast",
+	Short: "Broadcast a raw transaction signed elsewhere",
+	Long: `Broadcast a signed raw transaction given as hex with --hex, in a file with
+--file, or on stdin. The transaction is decoded and checked for inputs and
+outputs first, and the node is asked whether it would accept it
+(testmempoolaccept) unless --skip-mempool-check is given. The txid is printed
+on success.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return broadcastRaw(os.Stdin)
+	},
+}
+
+func init() {
+	broadcastCmd.Flags().StringVar(&broadcastHex, "hex", "", "Raw transaction hex")
+	broadcastCmd.Flags().StringVar(&broadcastFile, "file", "", "File holding the raw transaction hex")
+	broadcastCmd.Flags().BoolVar(&skipMempoolAccept, "skip-mempool-check", false, "Broadcast without asking the node first whether it would accept the transaction")
+	rootCmd.AddCommand(broadcastCmd)
+}
+
+func broadcastRaw(stdin io.Reader) error {
+	log.Printf("=== Broadcast Raw Transaction ===")
+
+	txHex, err := readRawTransactionHex(stdin)
+	if err != nil {
+		return err
+	}
+	tx, err := parseRawTransaction(txHex)
+	if err != nil {
+		return err
+	}
+	log.Printf("Transaction: %s (%d inputs, %d outputs, %d vbytes)",
+		tx.TxHash(), len(tx.TxIn), len(tx.TxOut), transaction.VirtualSize(tx))
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+
+	if skipMempoolAccept {
+		log.Printf("Skipping the mempool acceptance check (--skip-mempool-check)")
+	} else if err := checkMempoolAccept(ctx, tx); err != nil {
+		return err
+	}
+
+	txid, err := broadcastTransaction(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+	log.Printf("✅ Transaction broadcast successfully!")
+	log.Printf("Transaction ID: %s", txid)
+	session.record("transactions broadcast")
+	printResult("%s", txid)
+	return nil
+}
+
+// readRawTransactionHex returns the transaction hex given with --hex, read
+// from --file, or read from stdin, in that order
+func readRawTransactionHex(stdin io.Reader) (string, error) {
+	if broadcastHex != "" && broadcastFile != "" {
+		return "", fmt.Errorf("--hex and --file cannot be combined")
+	}
+
+	switch {
+	case broadcastHex != "":
+		return broadcastHex, nil
+	case broadcastFile != "":
+		data, err := os.ReadFile(broadcastFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read transaction file: %w", err)
+		}
+		return string(data), nil
+	case stdinIsTerminal():
+		promptf("Enter the raw transaction hex: ")
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read transaction hex: %w", err)
+		}
+		return line, nil
+	default:
+		data, err := io.ReadAll(io.LimitReader(stdin, maxRawTxHexSize))
+		if err != nil {
+			return "", fmt.Errorf("failed to read transaction hex from stdin: %w", err)
+		}
+		return string(data), nil
+	}
+}
+
+// parseRawTransaction decodes a raw transaction from hex, refusing trailing
+// data and transactions without inputs or outputs
+func parseRawTransaction(txHex string) (*wire.MsgTx, error) {
+	txHex = strings.TrimSpace(txHex)
+	if txHex == "" {
+		return nil, fmt.Errorf("no transaction hex given")
+	}
+	rawTx, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hex: %w", err)
+	}
+
+	var tx wire.MsgTx
+	reader := bytes.NewReader(rawTx)
+	if err := tx.Deserialize(reader); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	if reader.Len() != 0 {
+		return nil, fmt.Errorf("transaction hex has %d bytes of trailing data", reader.Len())
+	}
+	if len(tx.TxIn) == 0 {
+		return nil, fmt.Errorf("transaction has no inputs")
+	}
+	if len(tx.TxOut) == 0 {
+		return nil, fmt.Errorf("transaction has no outputs")
+	}
+	return &tx, nil
+}
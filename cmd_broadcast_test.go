@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// testRawTransaction returns a signed-looking one-input, one-output
+// transaction and its hex
+func testRawTransaction(t *testing.T) (*wire.MsgTx, string) {
+	t.Helper()
+
+	prevHash, err := chainhash.NewHashFromStr("6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807")
+	if err != nil {
+		t.Fatalf("Failed to parse funding hash: %v", err)
+	}
+	addr, err := btcutil.DecodeAddress(testDestination(t), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("DecodeAddress failed: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(prevHash, 0), nil, wire.TxWitness{make([]byte, 72), make([]byte, 33)}))
+	tx.AddTxOut(wire.NewTxOut(98000, pkScript))
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	return tx, hex.EncodeToString(buf.Bytes())
+}
+
+func TestBroadcast(t *testing.T) {
+	tx, txHex := testRawTransaction(t)
+	txid := tx.TxHash().String()
+
+	noOutputs := wire.NewMsgTx(2)
+	noOutputs.AddTxIn(tx.TxIn[0])
+	var noOutputsBuf bytes.Buffer
+	if err := noOutputs.Serialize(&noOutputsBuf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	accepted := `[{"txid":"` + txid + `","allowed":true}]`
+	rejected := `[{"txid":"` + txid + `","allowed":false,"reject-reason":"missing-inputs"}]`
+
+	tests := []struct {
+		name       string
+		args       func(dir string) []string
+		stdin      string
+		acceptance string
+		wantErr    string
+		wantTested bool
+	}{
+		{"hex flag", func(string) []string { return []string{"broadcast", "--hex", txHex} }, "", accepted, "", true},
+		{"file", func(dir string) []string {
+			path := filepath.Join(dir, "tx.hex")
+			if err := os.WriteFile(path, []byte(txHex+"\n"), 0600); err != nil {
+				t.Fatalf("Failed to write transaction file: %v", err)
+			}
+			return []string{"broadcast", "--file", path}
+		}, "", accepted, "", true},
+		{"stdin", func(string) []string { return []string{"broadcast"} }, txHex + "\n", accepted, "", true},
+		{"mempool check skipped", func(string) []string {
+			return []string{"broadcast", "--hex", txHex, "--skip-mempool-check"}
+		}, "", rejected, "", false},
+		{"rejected by the node", func(string) []string { return []string{"broadcast", "--hex", txHex} }, "", rejected,
+			"the node would reject this transaction: missing-inputs", true},
+		{"invalid hex", func(string) []string { return []string{"broadcast", "--hex", "zz" + txHex} }, "", accepted,
+			"invalid transaction hex", false},
+		{"truncated", func(string) []string { return []string{"broadcast", "--hex", txHex[:len(txHex)-8]} }, "", accepted,
+			"failed to decode transaction", false},
+		{"trailing data", func(string) []string { return []string{"broadcast", "--hex", txHex + "00"} }, "", accepted,
+			"1 bytes of trailing data", false},
+		{"no outputs", func(string) []string {
+			return []string{"broadcast", "--hex", hex.EncodeToString(noOutputsBuf.Bytes())}
+		}, "", accepted, "transaction has no outputs", false},
+		{"hex and file", func(string) []string { return []string{"broadcast", "--hex", txHex, "--file", "tx.hex"} }, "", accepted,
+			"cannot be combined", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupNonInteractive(t)
+			node := newStubNode(t, map[string]string{
+				"getblockchaininfo":  `{"chain":"test","blocks":100}`,
+				"testmempoolaccept":  tt.acceptance,
+				"sendrawtransaction": `"` + txid + `"`,
+			})
+			t.Setenv("TESTNET_RPC_HOST", node.host)
+			t.Setenv("TESTNET_RPC_DISABLE_TLS", "true")
+			if tt.stdin != "" {
+				r, w, err := os.Pipe()
+				if err != nil {
+					t.Fatalf("Failed to create pipe: %v", err)
+				}
+				w.WriteString(tt.stdin)
+				w.Close()
+				os.Stdin = r
+				t.Cleanup(func() { r.Close() })
+			}
+
+			var out bytes.Buffer
+			stdout = &out
+			t.Cleanup(func() {
+				stdout = os.Stdout
+				broadcastHex, broadcastFile, skipMempoolAccept = "", "", false
+			})
+
+			err := runCommand(t, tt.args(t.TempDir())...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if sent := node.params("sendrawtransaction"); len(sent) != 0 {
+					t.Errorf("Expected nothing to be broadcast, got %s", sent)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("broadcast failed: %v", err)
+				}
+				sent := node.params("sendrawtransaction")
+				if len(sent) != 1 {
+					t.Fatalf("Expected one sendrawtransaction call, got %d", len(sent))
+				}
+				var params []string
+				if err := json.Unmarshal(sent[0], &params); err != nil || len(params) != 1 || params[0] != txHex {
+					t.Errorf("Expected sendrawtransaction with the transaction hex, got %s", sent[0])
+				}
+				if got := strings.TrimSpace(out.String()); got != txid {
+					t.Errorf("Expected the txid %s on stdout, got %q", txid, got)
+				}
+			}
+			if tested := len(node.params("testmempoolaccept")) > 0; tested != tt.wantTested {
+				t.Errorf("Expected testmempoolaccept called: %v, got %v", tt.wantTested, tested)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+var bumpFeeCmd = &cobra.Command{
+	Use:   "bump-fee <contract-id> <txid>",
+	Short: "Speed up an unconfirmed withdrawal with a child-pays-for-parent transaction",
+	Long: `Spend the P2WPKH output of an unconfirmed withdrawal into a child transaction
+paying enough fee that parent and child together reach --fee-rate. The private
+key of the withdrawal's destination address is read from stdin.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bumpFee(args[0], args[1])
+	},
+}
+
+func init() {
+	bumpFeeCmd.Flags().StringVar(&destinationAddr, "to", "", "Address receiving the child's output (default: prompt for it)")
+	rootCmd.AddCommand(bumpFeeCmd)
+}
+
+func bumpFee(contractID, txid string) error {
+	log.Printf("=== Fee Bump (CPFP) ===")
+
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+	var record *contract.WithdrawalRecord
+	for i := range contractInfo.Withdrawals {
+		if contractInfo.Withdrawals[i].TxID == txid {
+			record = &contractInfo.Withdrawals[i]
+		}
+	}
+	if record == nil {
+		return fmt.Errorf("transaction %s is not a recorded withdrawal of contract %s", txid, contractID)
+	}
+
+	packageFeeRate, err := withdrawFeeRateValue()
+	if err != nil {
+		return err
+	}
+	if packageFeeRate == 0 {
+		packageFeeRate = cfg.Fees.DefaultFeeRate
+	}
+	if packageFeeRate <= 0 {
+		return fmt.Errorf("bump-fee needs a target fee rate: pass --fee-rate")
+	}
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+	parent, err := rpcClient.GetRawTransactionCtx(ctx, txid)
+	if err != nil {
+		return err
+	}
+	if parent.Confirmations > 0 {
+		return fmt.Errorf("withdrawal %s is already confirmed (%d confirmations); there is nothing to bump",
+			txid, parent.Confirmations)
+	}
+
+	// Read the WIF from stdin so it does not end up in shell history
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter the private key (WIF) of the withdrawal's destination address: ")
+	wifStr, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %w", err)
+	}
+	wif, err := btcutil.DecodeWIF(strings.TrimSpace(wifStr))
+	if err != nil {
+		return fmt.Errorf("failed to decode private key: %w", err)
+	}
+	if !wif.IsForNet(cfg.ChainParams) {
+		return fmt.Errorf("private key is not for %s", cfg.ChainParams.Name)
+	}
+
+	keyAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(wif.PrivKey.PubKey().SerializeCompressed()), cfg.ChainParams)
+	if err != nil {
+		return fmt.Errorf("failed to derive P2WPKH address: %w", err)
+	}
+	keyScript, err := txscript.PayToAddrScript(keyAddr)
+	if err != nil {
+		return fmt.Errorf("failed to create P2WPKH script: %w", err)
+	}
+	outputs := parent.FindOutputsByScript(keyScript)
+	if len(outputs) == 0 {
+		return fmt.Errorf("withdrawal %s has no output paying %s", txid, keyAddr.EncodeAddress())
+	}
+	amount, err := btcutil.NewAmount(outputs[0].Value)
+	if err != nil {
+		return fmt.Errorf("invalid output value: %w", err)
+	}
+	parentHash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return fmt.Errorf("invalid transaction id: %w", err)
+	}
+	parentOut := &transaction.UTXO{
+		TxHash:   parentHash,
+		Vout:     outputs[0].N,
+		Amount:   amount,
+		PkScript: keyScript,
+	}
+
+	destAddr, err := readDestination(reader)
+	if err != nil {
+		return err
+	}
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		return fmt.Errorf("failed to create destination script: %w", err)
+	}
+
+	parentFee := btcutil.Amount(record.Fee)
+	childFeeRate, err := transaction.CPFPChildFeeRate(parentFee, parent.VSize,
+		transaction.EstimateCPFPChildVSize(destScript), packageFeeRate)
+	if err != nil {
+		return err
+	}
+	log.Printf("Parent: %d vbytes paying %d satoshis (%.2f sat/vB)",
+		parent.VSize, int64(parentFee), float64(parentFee)/float64(parent.VSize))
+	log.Printf("Target package fee rate: %.2f sat/vB; child pays %.2f sat/vB", packageFeeRate, childFeeRate)
+
+	txBuilder := transaction.NewTransactionBuilder(cfg.ChainParams, 0)
+	child, err := txBuilder.BuildCPFPChildTx(parentOut, destAddr, wif.PrivKey, childFeeRate)
+	if err != nil {
+		return fmt.Errorf("failed to build child transaction: %w", err)
+	}
+
+	// The child's own rate is high by design; MAX_FEE_RATE applies to the package
+	childFee := parentOut.Amount - totalOutput(child)
+	if err := transaction.CheckFeeRate(parentFee+childFee, parent.VSize+transaction.VirtualSize(child), cfg.Fees.MaxFeeRate); err != nil {
+		return err
+	}
+
+	txHex, err := txBuilder.SerializeTransaction(child)
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	log.Printf("Child transaction hex: %s", txHex)
+
+	if !confirm(reader, "Do you want to broadcast the fee-bumping transaction?") {
+		log.Printf("Transaction not broadcast (user cancelled)")
+		return nil
+	}
+
+	broadcastCtx, cancelBroadcast := rpcContext()
+	defer cancelBroadcast()
+	childTxID, err := broadcastTransaction(broadcastCtx, child)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	log.Printf("✅ Child transaction broadcast successfully!")
+	log.Printf("Transaction ID: %s", childTxID)
+	session.record("fee bumps")
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleOut        string
+	bundleIn         string
+	bundleRedactKeys bool
+	bundleOverwrite  bool
+)
+
+var exportAllCmd = &cobra.Command{
+	Use:   "export-all",
+	Short: "Export every local contract into a single bundle file",
+	Long: `Serialize every contract in the contracts directory into one bundle file for
+backup or transfer. Use a .gz file name to gzip-compress the bundle.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		count, err := contract.ExportBundle(bundleOut, bundleRedactKeys)
+		if err != nil {
+			return fmt.Errorf("failed to export bundle: %w", err)
+		}
+		log.Printf("Exported %d contracts to %s", count, bundleOut)
+		session.record("bundles exported")
+		if bundleRedactKeys {
+			log.Printf("Private keys were redacted; the bundle is watch-only")
+		} else {
+			log.Printf("⚠️  The bundle contains private keys - store it securely")
+		}
+		return nil
+	},
+}
+
+var importAllCmd = &cobra.Command{
+	Use:   "import-all",
+	Short: "Import every contract from a bundle file",
+	Long: `Import the contracts of a bundle created by export-all. Contracts that already
+exist locally are skipped unless --overwrite is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		imported, skipped, err := contract.ImportBundle(bundleIn, bundleOverwrite)
+		if err != nil {
+			return fmt.Errorf("failed to import bundle: %w", err)
+		}
+		log.Printf("Imported %d contracts, skipped %d existing contracts", imported, skipped)
+		session.record("bundles imported")
+		return nil
+	},
+}
+
+func init() {
+	exportAllCmd.Flags().StringVar(&bundleOut, "out", "bundle.json", "Bundle file to write (.gz for compression)")
+	exportAllCmd.Flags().BoolVar(&bundleRedactKeys, "redact-keys", false, "Leave private keys out of the bundle (watch-only)")
+	importAllCmd.Flags().StringVar(&bundleIn, "in", "bundle.json", "Bundle file to read")
+	importAllCmd.Flags().BoolVar(&bundleOverwrite, "overwrite", false, "Overwrite contracts that already exist locally")
+
+	rootCmd.AddCommand(exportAllCmd)
+	rootCmd.AddCommand(importAllCmd)
+}
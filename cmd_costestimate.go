@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+var (
+	costCycles     int
+	costConfTarget int
+	costFeeRate    float64
+)
+
+var costEstimateCmd = &cobra.Command{
+	Use:   "cost-estimate [contract-id]",
+	Short: "Project the fees paid over a contract's lifetime",
+	Long: `Estimate the fee of the eventual withdrawal and, for contracts the owner
+refreshes before the timelock expires (spending back to the contract address
+with the owner key), the cumulative refresh fees over --cycles refreshes.
+Sizes come from the contract's redeem script and the fee rate from the
+configured fee estimator, or --fee-rate. Fees are projected at today's rate.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contractID, err := resolveContractID(args)
+		if err != nil {
+			return err
+		}
+		return estimateContractCost(contractID)
+	},
+}
+
+func init() {
+	costEstimateCmd.Flags().IntVar(&costCycles, "cycles", 0, "Number of refresh cycles to include")
+	costEstimateCmd.Flags().IntVar(&costConfTarget, "conf-target", 6, "Confirmation target in blocks for the fee estimate")
+	costEstimateCmd.Flags().Float64Var(&costFeeRate, "fee-rate", 0, "Fee rate in sat/vB (default: from the fee estimator)")
+	rootCmd.AddCommand(costEstimateCmd)
+}
+
+func estimateContractCost(contractID string) error {
+	if costCycles < 0 {
+		return fmt.Errorf("--cycles must not be negative")
+	}
+
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+
+	feeRate, source, err := costEstimateFeeRate()
+	if err != nil {
+		return err
+	}
+
+	// The withdrawal pays a single P2WPKH output; a refresh pays the
+	// contract address again
+	destAddr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), cfg.ChainParams)
+	if err != nil {
+		return fmt.Errorf("failed to build destination script: %w", err)
+	}
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		return fmt.Errorf("failed to build destination script: %w", err)
+	}
+	inheritanceScript := &script.InheritanceScript{RedeemScript: redeemScript, ChainParams: cfg.ChainParams}
+	contractScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		return fmt.Errorf("failed to build contract script: %w", err)
+	}
+
+	withdrawVSize := transaction.EstimateSpendVSize(redeemScript, destScript)
+	refreshVSize := transaction.EstimateSpendVSize(redeemScript, contractScript)
+	withdrawFee := feeForVSize(withdrawVSize, feeRate)
+	refreshFee := feeForVSize(refreshVSize, feeRate)
+	refreshTotal := refreshFee * btcutil.Amount(costCycles)
+	total := refreshTotal + withdrawFee
+
+	log.Printf("=== Cost Estimate: %s ===", contractInfo.ContractID)
+	log.Printf("Fee rate: %.2f sat/vB (%s)", feeRate, source)
+	log.Printf("")
+	log.Printf("Withdrawal (either path, to one P2WPKH output): %d vbytes = %d satoshis", withdrawVSize, int64(withdrawFee))
+	log.Printf("Refresh (owner path, back to the contract):     %d vbytes = %d satoshis per cycle", refreshVSize, int64(refreshFee))
+
+	if relativeTimelock, err := script.ParseRelativeTimelock(redeemScript); err == nil {
+		lockDays := script.RelativeTimelockDuration(relativeTimelock).Hours() / 24
+		log.Printf("Refresh cycles: %d (one at least every %.0f days, covering %.1f years)",
+			costCycles, math.Floor(lockDays), lockDays*float64(costCycles)/365)
+	} else {
+		log.Printf("Refresh cycles: %d", costCycles)
+	}
+	log.Printf("Cumulative refresh fees: %d satoshis", int64(refreshTotal))
+	log.Printf("")
+	log.Printf("Total (refreshes + final withdrawal): %d satoshis", int64(total))
+
+	if contractInfo.IsFunded && contractInfo.TotalFunding() > 0 {
+		share := float64(total) / float64(contractInfo.TotalFunding()) * 100
+		log.Printf("Share of funding (%d satoshis): %.2f%%", contractInfo.TotalFunding(), share)
+		if int64(total) >= contractInfo.TotalFunding() {
+			log.Printf("Warning: the projected fees use up the entire funding amount")
+		}
+	}
+	log.Printf("Note: fees are projected at today's rate; actual rates at each refresh will differ")
+
+	return nil
+}
+
+// costEstimateFeeRate returns the fee rate to project with and where it came from
+func costEstimateFeeRate() (float64, string, error) {
+	if costFeeRate < 0 {
+		return 0, "", fmt.Errorf("fee rate must not be negative")
+	}
+	if costFeeRate > 0 {
+		return costFeeRate, "from --fee-rate", nil
+	}
+
+	estimator, err := newFeeEstimator()
+	if err != nil {
+		return 0, "", err
+	}
+	feeRate, err := estimator.EstimateFeeRate(costConfTarget)
+	if err != nil {
+		return 0, "", fmt.Errorf("fee estimation failed: %w; pass --fee-rate instead", err)
+	}
+	name := cfg.Fees.Estimator
+	if name == "" {
+		name = "node"
+	}
+	return feeRate, fmt.Sprintf("%s estimate, %d-block target", name, costConfTarget), nil
+}
+
+// feeForVSize returns the fee paying feeRate sat/vB over vsize vbytes
+func feeForVSize(vsize int64, feeRate float64) btcutil.Amount {
+	return btcutil.Amount(math.Ceil(float64(vsize) * feeRate))
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+var (
+	decodeScriptHex string
+	decodeTxHex     string
+)
+
+var decodeCmd = &cobra.Command{
+	Use:   "decode",
+	Short: "Break down a redeem script or a raw transaction",
+	Long: `Decode a hex redeem script given with --script or a raw transaction given
+with --tx, for debugging. A script is disassembled and, when it is an
+inheritance script, its owner and inheritor keys and its timelock (with the
+BIP 68 value converted back to days) are listed. A transaction is listed with
+its inputs, sequences, witness stack sizes, outputs and lock time. Nothing is
+read from or sent to the node.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch {
+		case decodeScriptHex != "" && decodeTxHex != "":
+			return fmt.Errorf("--script and --tx cannot be combined")
+		case decodeScriptHex != "":
+			redeemScript, err := hex.DecodeString(strings.TrimSpace(decodeScriptHex))
+			if err != nil {
+				return fmt.Errorf("invalid script hex: %w", err)
+			}
+			_, err = decodeRedeemScript(redeemScript, cfg.ChainParams)
+			return err
+		case decodeTxHex != "":
+			tx, err := parseRawTransaction(decodeTxHex)
+			if err != nil {
+				return err
+			}
+			decodeTransaction(tx, cfg.ChainParams)
+			return nil
+		default:
+			return fmt.Errorf("give a redeem script with --script or a transaction with --tx")
+		}
+	},
+}
+
+func init() {
+	decodeCmd.Flags().StringVar(&decodeScriptHex, "script", "", "Redeem script hex")
+	decodeCmd.Flags().StringVar(&decodeTxHex, "tx", "", "Raw transaction hex")
+	rootCmd.AddCommand(decodeCmd)
+}
+
+// decodeRedeemScript logs the disassembly of a redeem script and, when it
+// parses as an inheritance script, its keys, timelock and address, which it
+// returns
+func decodeRedeemScript(redeemScript []byte, chainParams *chaincfg.Params) (*script.InheritanceScript, error) {
+	log.Printf("=== Decode Redeem Script ===")
+	disasm, err := txscript.DisasmString(redeemScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to disassemble script: %w", err)
+	}
+	log.Printf("Size: %d bytes", len(redeemScript))
+	log.Printf("Disassembly: %s", disasm)
+
+	parsed, err := script.ParseRedeemScript(redeemScript, chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("not an inheritance script: %w", err)
+	}
+
+	if len(parsed.OwnerPubKeys) > 0 {
+		log.Printf("Owner: %d of %d keys", parsed.OwnerRequired, len(parsed.OwnerPubKeys))
+		for i, pubKey := range parsed.OwnerPubKeys {
+			log.Printf("  Key %d: %x", i+1, pubKey)
+		}
+	} else {
+		log.Printf("Owner public key: %x", parsed.OwnerPubKey)
+	}
+
+	if parsed.TimelockType == script.Absolute {
+		log.Printf("Inheritor public key: %x", parsed.InheritorPubKey)
+		log.Printf("Timelock: absolute, %s", parsed.DescribeTimelock())
+	} else {
+		for i, tier := range parsed.InheritorTiers() {
+			log.Printf("Inheritor %d public key: %x", i+1, tier.PubKey)
+			log.Printf("  Timelock: %d days (BIP 68 %s)", tier.TimelockDays, script.FormatRelativeTimelock(tier.RelativeTimelock))
+		}
+	}
+
+	if addr, err := parsed.GetP2WSHAddress(); err == nil {
+		log.Printf("P2WSH address (%s): %s", chainParams.Name, addr.EncodeAddress())
+	}
+	return parsed, nil
+}
+
+// decodeTransaction logs the inputs, outputs and lock time of a transaction
+func decodeTransaction(tx *wire.MsgTx, chainParams *chaincfg.Params) {
+	log.Printf("=== Decode Transaction ===")
+	log.Printf("TxID: %s", tx.TxHash())
+	if tx.HasWitness() {
+		log.Printf("WTxID: %s", tx.WitnessHash())
+	}
+	log.Printf("Version: %d", tx.Version)
+	log.Printf("Size: %d bytes, %d vbytes, %d weight units", tx.SerializeSize(),
+		transaction.VirtualSize(tx), blockchain.GetTransactionWeight(btcutil.NewTx(tx)))
+
+	log.Printf("Inputs: %d", len(tx.TxIn))
+	for i, txIn := range tx.TxIn {
+		log.Printf("  #%d %s", i, txIn.PreviousOutPoint)
+		log.Printf("     Sequence: 0x%08x (%s)", txIn.Sequence, describeSequence(tx.Version, txIn.Sequence))
+		if len(txIn.SignatureScript) > 0 {
+			log.Printf("     Signature script: %d bytes", len(txIn.SignatureScript))
+		}
+		if len(txIn.Witness) > 0 {
+			sizes := make([]string, len(txIn.Witness))
+			for j, item := range txIn.Witness {
+				sizes[j] = fmt.Sprintf("%d", len(item))
+			}
+			log.Printf("     Witness: %d items (%s bytes)", len(txIn.Witness), strings.Join(sizes, ", "))
+		}
+	}
+
+	log.Printf("Outputs: %d", len(tx.TxOut))
+	for i, txOut := range tx.TxOut {
+		class, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, chainParams)
+		destination := class.String()
+		if err == nil && len(addrs) == 1 {
+			destination = fmt.Sprintf("%s %s", class, addrs[0].EncodeAddress())
+		}
+		log.Printf("  #%d %v to %s", i, btcutil.Amount(txOut.Value), destination)
+	}
+
+	if tx.LockTime == 0 {
+		log.Printf("Lock time: none")
+	} else {
+		log.Printf("Lock time: %s", script.FormatLockTime(int64(tx.LockTime)))
+	}
+}
+
+// describeSequence explains what an input sequence enables: RBF signaling,
+// a BIP 68 relative timelock, or neither
+func describeSequence(version int32, sequence uint32) string {
+	switch {
+	case sequence == wire.MaxTxInSequenceNum:
+		return "final"
+	case sequence == wire.MaxTxInSequenceNum-1:
+		return "lock time enabled, no RBF"
+	case sequence&wire.SequenceLockTimeDisabled != 0 || version < 2:
+		return "RBF"
+	default:
+		return "RBF, relative timelock " + script.FormatRelativeTimelock(int64(sequence&(wire.SequenceLockTimeIsSeconds|wire.SequenceLockTimeMask)))
+	}
+}
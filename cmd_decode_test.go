@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+func TestDecodeRedeemScript_TimelockDays(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	ownerPubKey := ownerKey.PubKey().SerializeCompressed()
+	inheritorPubKey := inheritorKey.PubKey().SerializeCompressed()
+
+	for _, days := range []int64{1, 7, 30, 90, 180, 365, 388} {
+		inheritanceScript, err := script.NewInheritanceScript(ownerPubKey, inheritorPubKey, days,
+			&chaincfg.TestNet3Params, script.AllowShortTimelock())
+		if err != nil {
+			t.Fatalf("NewInheritanceScript(%d days) failed: %v", days, err)
+		}
+
+		decoded, err := decodeRedeemScript(inheritanceScript.RedeemScript, &chaincfg.TestNet3Params)
+		if err != nil {
+			t.Fatalf("%d days: decodeRedeemScript failed: %v", days, err)
+		}
+		if got := decoded.InheritorTiers()[0].TimelockDays; got != days {
+			t.Errorf("Expected %d timelock days, got %d", days, got)
+		}
+		if !bytes.Equal(decoded.OwnerPubKey, ownerPubKey) || !bytes.Equal(decoded.InheritorPubKey, inheritorPubKey) {
+			t.Errorf("%d days: recovered keys %x and %x differ from the original ones", days, decoded.OwnerPubKey, decoded.InheritorPubKey)
+		}
+	}
+
+	// A script that is not an inheritance script is still disassembled
+	if _, err := decodeRedeemScript([]byte{0x51}, &chaincfg.TestNet3Params); err == nil || !strings.Contains(err.Error(), "not an inheritance script") {
+		t.Errorf("Expected OP_TRUE to be refused as an inheritance script, got %v", err)
+	}
+}
+
+func TestDescribeSequence(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int32
+		sequence uint32
+		expected string
+	}{
+		{"final", 2, wire.MaxTxInSequenceNum, "final"},
+		{"lock time only", 2, wire.MaxTxInSequenceNum - 1, "lock time enabled, no RBF"},
+		{"RBF", 2, wire.MaxTxInSequenceNum - 2, "RBF"},
+		{"180 days", 2, 0x400000 | 30375, "RBF, relative timelock 180 days"},
+		{"blocks", 2, 144, "RBF, relative timelock 144 blocks"},
+		{"version 1 ignores BIP 68", 1, 144, "RBF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeSequence(tt.version, tt.sequence); !strings.HasPrefix(got, tt.expected) {
+				t.Errorf("Expected a description starting with %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
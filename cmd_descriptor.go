@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/spf13/cobra"
+)
+
+var descriptorCmd = &cobra.Command{
+	Use:   "descriptor [contract-id]",
+	Short: "Export the output descriptor of a contract",
+	Long: `Print the wsh(raw(...)) output descriptor of a contract, including its checksum.
+Keeping the descriptor is enough to recover the funding address later.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportDescriptor(args[0])
+	},
+}
+
+var addressFromDescriptorCmd = &cobra.Command{
+	Use:   "address-from-descriptor <descriptor>",
+	Short: "Recover the funding address from a descriptor",
+	Long: `Parse a wsh(raw(...)) descriptor, validate its checksum and print the
+P2WSH funding address and script hash it commits to.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addressFromDescriptor(args[0])
+	},
+}
+
+// checkDescriptorWithNode cross-checks the exported descriptor with getdescriptorinfo
+var checkDescriptorWithNode bool
+
+func init() {
+	descriptorCmd.Flags().BoolVar(&checkDescriptorWithNode, "check-node", false, "Cross-check the descriptor checksum with the node's getdescriptorinfo")
+	rootCmd.AddCommand(descriptorCmd)
+	rootCmd.AddCommand(addressFromDescriptorCmd)
+}
+
+func exportDescriptor(contractID string) error {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+
+	inheritanceScript := &script.InheritanceScript{
+		RedeemScript: redeemScript,
+		ChainParams:  cfg.ChainParams,
+	}
+
+	descriptor, err := inheritanceScript.Descriptor()
+	if err != nil {
+		return fmt.Errorf("failed to build descriptor: %w", err)
+	}
+
+	log.Printf("Descriptor: %s", descriptor)
+	if contractInfo.AddressType == script.AddressTypeP2SHP2WSH {
+		log.Printf("Note: The contract pays to a P2SH-P2WSH address; wrap the descriptor in sh() to match it")
+	}
+
+	if checkDescriptorWithNode {
+		return verifyDescriptorWithNode(descriptor)
+	}
+	return nil
+}
+
+// verifyDescriptorWithNode checks an exported descriptor against the node.
+// The exported string itself is sent to getdescriptorinfo, and the node's
+// checksum must match the one computed locally for that string. Bitcoin Core
+// only accepts raw() at the top level and rejects wsh(raw(...)); the check
+// then falls back, with a warning, to the P2WSH output script the descriptor
+// commits to, sent as raw(<scriptPubKey>). A node that answers neither, or
+// disagrees, is an error.
+func verifyDescriptorWithNode(descriptor string) error {
+	witnessScript, err := script.ParseDescriptor(descriptor)
+	if err != nil {
+		return fmt.Errorf("exported descriptor does not parse back: %w", err)
+	}
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+
+	body, _, _ := strings.Cut(descriptor, "#")
+	nodeErr, err := checkDescriptorInfo(rpcClient, body)
+	if err != nil {
+		return err
+	}
+	if nodeErr == nil {
+		log.Printf("✅ The node accepts the exported descriptor %s", descriptor)
+		return nil
+	}
+	log.Printf("Warning: The node cannot check the exported descriptor: %v", nodeErr)
+
+	scriptPubKey, err := contractScriptPubKey(witnessScript, script.AddressTypeP2WSH)
+	if err != nil {
+		return err
+	}
+	rawBody := fmt.Sprintf("raw(%x)", scriptPubKey)
+	log.Printf("Warning: Checking the output script %s with the node instead; the exported checksum is only verified locally", rawBody)
+	nodeErr, err = checkDescriptorInfo(rpcClient, rawBody)
+	if err != nil {
+		return err
+	}
+	if nodeErr != nil {
+		return fmt.Errorf("the node could not check the descriptor: %w", nodeErr)
+	}
+
+	log.Printf("✅ The node accepts the output script %s the exported descriptor commits to", rawBody)
+	return nil
+}
+
+// checkDescriptorInfo compares the checksum and canonical form the node
+// gives for a descriptor body with the ones computed locally. The node's own
+// error is returned separately, so a node that cannot check body is told
+// apart from one that disagrees.
+func checkDescriptorInfo(rpcClient *rpc.RPCClient, body string) (nodeErr, err error) {
+	checksum, err := script.DescriptorChecksum(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute checksum: %w", err)
+	}
+
+	info, err := rpcClient.GetDescriptorInfo(body)
+	if err != nil {
+		return err, nil
+	}
+	if info.Checksum != checksum {
+		return nil, fmt.Errorf("checksum mismatch for %s: local %s, node %s", body, checksum, info.Checksum)
+	}
+	if info.Descriptor != body+"#"+checksum {
+		return nil, fmt.Errorf("node canonical form %s differs from %s#%s", info.Descriptor, body, checksum)
+	}
+	return nil, nil
+}
+
+func addressFromDescriptor(descriptor string) error {
+	witnessScript, err := script.ParseDescriptor(descriptor)
+	if err != nil {
+		return fmt.Errorf("invalid descriptor: %w", err)
+	}
+
+	inheritanceScript := &script.InheritanceScript{
+		RedeemScript: witnessScript,
+		ChainParams:  cfg.ChainParams,
+	}
+
+	p2wshAddr, err := inheritanceScript.GetP2WSHAddress()
+	if err != nil {
+		return fmt.Errorf("failed to derive P2WSH address: %w", err)
+	}
+
+	log.Printf("Funding Address (P2WSH): %s", p2wshAddr.EncodeAddress())
+	log.Printf("Script Hash: %x", inheritanceScript.GetScriptHash())
+	log.Printf("Witness Script: %x", witnessScript)
+	printResult("%s", p2wshAddr.EncodeAddress())
+	return emitJSON(descriptorAddressOutput{
+		Address:       p2wshAddr.EncodeAddress(),
+		ScriptHash:    hex.EncodeToString(inheritanceScript.GetScriptHash()),
+		WitnessScript: hex.EncodeToString(witnessScript),
+	})
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// descriptorNode is a stub node answering getdescriptorinfo for the
+// descriptor bodies in results and rejecting any other, recording the body
+// of every call
+type descriptorNode struct {
+	host string
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+func newDescriptorNode(t *testing.T, results map[string]string) *descriptorNode {
+	t.Helper()
+
+	node := &descriptorNode{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Params []string `json:"params"`
+			ID     int      `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Params) != 1 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		node.mu.Lock()
+		node.bodies = append(node.bodies, request.Params[0])
+		node.mu.Unlock()
+
+		result, ok := results[request.Params[0]]
+		if !ok {
+			fmt.Fprintf(w, `{"result":null,"error":{"code":-5,"message":"Can only have raw() at top level"},"id":%d}`, request.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"result":%s,"error":null,"id":%d}`, result, request.ID)
+	}))
+	t.Cleanup(server.Close)
+
+	node.host = strings.TrimPrefix(server.URL, "http://")
+	return node
+}
+
+func TestExportDescriptor_CheckNode(t *testing.T) {
+	agree := func(body string) string {
+		checksum, _ := script.DescriptorChecksum(body)
+		return fmt.Sprintf(`{"descriptor":"%s#%s","checksum":"%s"}`, body, checksum, checksum)
+	}
+	disagree := func(body string) string {
+		return fmt.Sprintf(`{"descriptor":"%s#qqqqqqqq","checksum":"qqqqqqqq"}`, body)
+	}
+
+	tests := []struct {
+		name      string
+		results   func(exported, raw string) map[string]string
+		wantCalls func(exported, raw string) []string
+		wantErr   string
+	}{
+		{"node checks the exported descriptor", func(exported, raw string) map[string]string {
+			return map[string]string{exported: agree(exported)}
+		}, func(exported, raw string) []string { return []string{exported} }, ""},
+		{"node disagrees on the exported descriptor", func(exported, raw string) map[string]string {
+			return map[string]string{exported: disagree(exported), raw: agree(raw)}
+		}, func(exported, raw string) []string { return []string{exported} }, "checksum mismatch"},
+		{"node rejects wsh(raw()) and checks the output script", func(exported, raw string) map[string]string {
+			return map[string]string{raw: agree(raw)}
+		}, func(exported, raw string) []string { return []string{exported, raw} }, ""},
+		{"node disagrees on the output script", func(exported, raw string) map[string]string {
+			return map[string]string{raw: disagree(raw)}
+		}, func(exported, raw string) []string { return []string{exported, raw} }, "checksum mismatch"},
+		{"node rejects both", func(exported, raw string) map[string]string {
+			return map[string]string{}
+		}, func(exported, raw string) []string { return []string{exported, raw} }, "the node could not check the descriptor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractID, _ := setupNonInteractive(t)
+			contractInfo, err := contract.LoadContractInfo(contractID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			redeemScript, _ := hex.DecodeString(contractInfo.RedeemScript)
+			// Any command loads the configuration contractScriptPubKey reads
+			if err := runCommand(t, "list"); err != nil {
+				t.Fatalf("Failed to load the configuration: %v", err)
+			}
+			scriptPubKey, err := contractScriptPubKey(redeemScript, script.AddressTypeP2WSH)
+			if err != nil {
+				t.Fatalf("contractScriptPubKey failed: %v", err)
+			}
+			exported := fmt.Sprintf("wsh(raw(%x))", redeemScript)
+			raw := fmt.Sprintf("raw(%x)", scriptPubKey)
+
+			node := newDescriptorNode(t, tt.results(exported, raw))
+			t.Setenv("TESTNET_RPC_HOST", node.host)
+			t.Setenv("TESTNET_RPC_DISABLE_TLS", "true")
+			t.Cleanup(func() { checkDescriptorWithNode = false })
+
+			err = runCommand(t, "descriptor", contractID, "--check-node")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("descriptor --check-node failed: %v", err)
+			}
+
+			if want := tt.wantCalls(exported, raw); strings.Join(node.bodies, " ") != strings.Join(want, " ") {
+				t.Errorf("Expected getdescriptorinfo with %v, got %v", want, node.bodies)
+			}
+		})
+	}
+}
+
+func TestAddressFromDescriptor_AddressOnStdout(t *testing.T) {
+	contractID, _ := setupNonInteractive(t)
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	redeemScript, _ := hex.DecodeString(contractInfo.RedeemScript)
+	descriptor, err := (&script.InheritanceScript{RedeemScript: redeemScript}).Descriptor()
+	if err != nil {
+		t.Fatalf("Descriptor failed: %v", err)
+	}
+
+	var outBuf, errBuf bytes.Buffer
+	stdout, stderr = &outBuf, &errBuf
+	t.Cleanup(func() {
+		stdout, stderr = os.Stdout, os.Stderr
+		log.SetOutput(os.Stderr)
+	})
+
+	if err := runCommand(t, "address-from-descriptor", descriptor); err != nil {
+		t.Fatalf("address-from-descriptor failed: %v", err)
+	}
+	if outBuf.String() != contractInfo.P2WSHAddress+"\n" {
+		t.Errorf("Expected only the address %s on stdout, got %q", contractInfo.P2WSHAddress, outBuf.String())
+	}
+	if !strings.Contains(errBuf.String(), "Witness Script") {
+		t.Errorf("Expected the script details on stderr, got:\n%s", errBuf.String())
+	}
+}
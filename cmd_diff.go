@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [contract-id-1] [contract-id-2]",
+	Short: "Show the differences between two contracts",
+	Long: `Compare two saved contracts field by field (keys, timelock, address, funding,
+...) and print every field that differs.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return diffContracts(args[0], args[1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
+
+func diffContracts(leftID, rightID string) error {
+	left, err := contract.LoadContractInfo(leftID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract %s: %w", leftID, err)
+	}
+	right, err := contract.LoadContractInfo(rightID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract %s: %w", rightID, err)
+	}
+
+	log.Printf("=== Contract Diff: %s -> %s ===", leftID, rightID)
+
+	diffs := contract.DiffContracts(left, right)
+	if len(diffs) == 0 {
+		log.Printf("The contracts are identical")
+		return nil
+	}
+
+	for _, diff := range diffs {
+		log.Printf("%s:", diff.Field)
+		log.Printf("  - %s", diff.Left)
+		log.Printf("  + %s", diff.Right)
+	}
+	log.Printf("%d fields differ", len(diffs))
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// cachedPassphrase is the contract passphrase entered during this run, so it
+// is asked for once however many contracts are loaded
+var cachedPassphrase []byte
+
+var migrateEncryptCmd = &cobra.Command{
+	Use:   "migrate-encrypt [contract-id...]",
+	Short: "Encrypt the private keys of contracts stored in plaintext",
+	Long: `Encrypt the owner and inheritor WIFs of the given contracts, or of every
+saved contract, with a passphrase (CONTRACT_PASSPHRASE, or asked for twice).
+The keys are sealed with AES-GCM under a key derived by scrypt and the
+plaintext WIFs are removed from the contract files. Contracts whose keys are
+already encrypted are left alone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return migrateEncrypt(args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateEncryptCmd)
+}
+
+func migrateEncrypt(contractIDs []string) error {
+	log.Printf("=== Encrypt Contract Keys ===")
+
+	if len(contractIDs) == 0 {
+		var err error
+		if contractIDs, err = contract.ListContracts(); err != nil {
+			return fmt.Errorf("failed to list contracts: %w", err)
+		}
+	}
+	if len(contractIDs) == 0 {
+		log.Printf("No contracts found")
+		return nil
+	}
+
+	passphrase, err := newPassphrase()
+	if err != nil {
+		return err
+	}
+
+	encrypted := 0
+	for _, contractID := range contractIDs {
+		migrated, err := contract.EncryptContract(contractID, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt contract %s: %w", contractID, err)
+		}
+		if migrated {
+			log.Printf("  %s: keys encrypted", contractID)
+			encrypted++
+		} else {
+			log.Printf("  %s: already encrypted", contractID)
+		}
+	}
+
+	log.Printf("Encrypted the keys of %d of %d contracts", encrypted, len(contractIDs))
+	log.Printf("Note: Without the passphrase the keys cannot be recovered; keep it with your backups")
+	return nil
+}
+
+// contractPassphrase supplies the passphrase of encrypted contracts: the
+// configured CONTRACT_PASSPHRASE, or one asked for on the terminal
+func contractPassphrase(contractID string) ([]byte, error) {
+	if cfg.Contract.Passphrase != "" {
+		return []byte(cfg.Contract.Passphrase), nil
+	}
+	if cachedPassphrase != nil {
+		return cachedPassphrase, nil
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Passphrase for contract %s: ", contractID))
+	if err != nil {
+		return nil, err
+	}
+	cachedPassphrase = passphrase
+	return passphrase, nil
+}
+
+// newPassphrase returns the passphrase to encrypt keys with: the configured
+// CONTRACT_PASSPHRASE, or one entered twice on the terminal
+func newPassphrase() ([]byte, error) {
+	if cfg.Contract.Passphrase != "" {
+		return []byte(cfg.Contract.Passphrase), nil
+	}
+
+	passphrase, err := readPassphrase("New passphrase for the contract keys: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("the passphrase must not be empty")
+	}
+	again, err := readPassphrase("Repeat the passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, again) {
+		return nil, fmt.Errorf("the passphrases do not match")
+	}
+
+	cachedPassphrase = passphrase
+	return passphrase, nil
+}
+
+// readPassphrase asks for a passphrase on stdin, without echo on a terminal
+func readPassphrase(prompt string) ([]byte, error) {
+	fmt.Print(prompt)
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		passphrase, err := terminal.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return passphrase, nil
+	}
+
+	// Read byte by byte so nothing after the line is taken from the prompts
+	// that follow
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n == 1 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			if len(line) == 0 {
+				return nil, fmt.Errorf("failed to read passphrase: %w", err)
+			}
+			break
+		}
+	}
+	return bytes.TrimRight(line, "\r"), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOut     string
+	exportEncrypt bool
+	importForce   bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <contract-id>",
+	Short: "Export a contract into a single portable file",
+	Long: `Write one contract into a versioned file, in the bundle format of export-all,
+to move it to another machine with import. Use a .gz file name to
+gzip-compress it. Keys that are encrypted stay encrypted; with --encrypt keys
+stored in plaintext are encrypted in the exported file with a passphrase
+(CONTRACT_PASSPHRASE, or asked for twice), leaving the local contract as it is.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportContract(args[0])
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a contract exported with export",
+	Long: `Install the contract of a file written by export into the contracts directory.
+The contract must be for the configured network and its address must match
+its redeem script. A contract with the same ID is not overwritten unless
+--force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importContract(args[0])
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "File to write (default: <contract-id>.json; .gz for compression)")
+	exportCmd.Flags().BoolVar(&exportEncrypt, "encrypt", false, "Encrypt plaintext private keys in the exported file with a passphrase")
+	importCmd.Flags().BoolVar(&importForce, "force", false, "Overwrite a contract that already exists locally")
+
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+func exportContract(contractID string) error {
+	path := exportOut
+	if path == "" {
+		path = contractID + ".json"
+	}
+
+	var passphrase []byte
+	if exportEncrypt {
+		var err error
+		if passphrase, err = newPassphrase(); err != nil {
+			return err
+		}
+	}
+
+	if err := contract.ExportContract(contractID, path, passphrase); err != nil {
+		return fmt.Errorf("failed to export contract: %w", err)
+	}
+	log.Printf("Exported contract %s to %s", contractID, path)
+	if !exportEncrypt {
+		log.Printf("⚠️  Unless its keys are encrypted, the file contains private keys - store it securely")
+	}
+	session.record("contracts exported")
+	printResult("%s", path)
+	return nil
+}
+
+func importContract(path string) error {
+	contractInfo, err := contract.ImportContract(path, cfg.ChainParams, importForce)
+	if err != nil {
+		return fmt.Errorf("failed to import contract: %w", err)
+	}
+	log.Printf("Imported contract %s (%s)", contractInfo.ContractID, contractInfo.P2WSHAddress)
+	if contractInfo.IsEncrypted() {
+		log.Printf("Its keys are encrypted; commands that sign will ask for the passphrase")
+	}
+	session.record("contracts imported")
+	printResult("%s", contractInfo.ContractID)
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/spf13/cobra"
+)
+
+var fundCmd = &cobra.Command{
+	Use:   "fund [contract-id] [amount-satoshis]",
+	Short: "Fund a contract from the node's wallet",
+	Long: `Pay the given number of satoshis to the contract address from the Bitcoin
+Core wallet behind the RPC connection (sendtoaddress), then record the
+funding output on the contract, as set-funding does. The wallet selects the
+coins, adds change and picks the fee. The node must have exactly one wallet
+loaded, since the RPC host cannot name a wallet.
+
+A contract that is already funded is refused unless --add is given, which
+records the new output in addition to the existing funding UTXOs.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fundContract(args[0], args[1])
+	},
+}
+
+func init() {
+	fundCmd.Flags().BoolVar(&addFunding, "add", false, "Add to a contract that is already funded instead of refusing")
+	rootCmd.AddCommand(fundCmd)
+}
+
+func fundContract(contractID, amountArg string) error {
+	amount, err := strconv.ParseInt(amountArg, 10, 64)
+	if err != nil || amount <= 0 {
+		return fmt.Errorf("invalid amount %q: expected a positive number of satoshis", amountArg)
+	}
+
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+	if contractInfo.IsFunded && !addFunding {
+		return fmt.Errorf("contract %s is already funded; pass --add to fund it again", contractID)
+	}
+
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	pkScript, err := contractScriptPubKey(redeemScript, contractInfo.AddressType)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Funding %s with %v from the node's wallet", contractInfo.P2WSHAddress, btcutil.Amount(amount))
+	if !confirm(bufio.NewReader(os.Stdin), "Do you want to send the funds?") {
+		log.Printf("Funding cancelled")
+		return nil
+	}
+
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+	tx, output, err := rpcClient.FundAddress(contractInfo.P2WSHAddress, amount, pkScript)
+	if err != nil {
+		return fmt.Errorf("failed to fund contract: %w", err)
+	}
+	log.Printf("✅ Funding transaction broadcast: %s", tx.TxID)
+
+	if err := recordFundingUTXO(contractID, tx.TxID, output.N, amount); err != nil {
+		return fmt.Errorf("funding sent as %s:%d, but the contract file was not updated: %w", tx.TxID, output.N, err)
+	}
+	log.Printf("Funding recorded: %s:%d (%d satoshis)", tx.TxID, output.N, amount)
+	log.Printf("Note: Funding is unconfirmed; run reminders or set-funding again after it confirms to record its time")
+	session.record("contracts funded")
+	printResult("%s", tx.TxID)
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/nikolay.stoev/bitcoin-inheritance/config"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fundingVout int64
+	addFunding  bool
+)
+
+var setFundingCmd = &cobra.Command{
+	Use:   "set-funding [contract-id] [txid]",
+	Short: "Record the funding transaction of a contract",
+	Long: `Fetch the funding transaction from the node and record it on the contract.
+Without --vout every output is checked and the one paying to the contract's
+P2WSH script is used, so only the txid needs to be known.
+
+Without a txid, the unspent outputs of the contract address are looked up
+through the chain backend (the Electrum server or Esplora API selected by
+BACKEND, otherwise the node's wallet) and the single one found is recorded.
+
+With --add the output is recorded in addition to the funding UTXOs already
+known, for a contract address that was funded more than once. Withdrawals
+then sweep all of them in one transaction.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			return setFundingFromBackend(args[0])
+		}
+		return setFunding(args[0], args[1])
+	},
+}
+
+func init() {
+	setFundingCmd.Flags().Int64Var(&fundingVout, "vout", -1, "Funding output index (detected automatically when omitted)")
+	setFundingCmd.Flags().BoolVar(&addFunding, "add", false, "Record an additional funding UTXO instead of replacing the recorded ones")
+	rootCmd.AddCommand(setFundingCmd)
+}
+
+func setFunding(contractID, txid string) error {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+
+	pkScript, err := contractScriptPubKey(redeemScript, contractInfo.AddressType)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+	tx, err := rpcClient.GetRawTransactionCtx(ctx, txid)
+	if err != nil {
+		return fmt.Errorf("failed to fetch funding transaction: %w", err)
+	}
+
+	matches := tx.FindOutputsByScript(pkScript)
+	if len(matches) == 0 {
+		return fmt.Errorf("no output of transaction %s pays to contract address %s", txid, contractInfo.P2WSHAddress)
+	}
+
+	output := matches[0]
+	if fundingVout >= 0 {
+		found := false
+		for _, match := range matches {
+			if int64(match.N) == fundingVout {
+				output, found = match, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("output %d of transaction %s does not pay to contract address %s",
+				fundingVout, txid, contractInfo.P2WSHAddress)
+		}
+	} else if len(matches) > 1 {
+		return fmt.Errorf("transaction %s pays to the contract in %d outputs; select one with --vout", txid, len(matches))
+	}
+
+	amount, err := btcutil.NewAmount(output.Value)
+	if err != nil {
+		return fmt.Errorf("invalid output amount: %w", err)
+	}
+
+	if err := recordFundingUTXO(contractID, txid, output.N, int64(amount)); err != nil {
+		return fmt.Errorf("failed to update funding status: %w", err)
+	}
+
+	log.Printf("Funding recorded: %s:%d (%d satoshis)", txid, output.N, int64(amount))
+
+	// The confirmation time is what the inheritor's timelock counts from
+	if tx.BlockHash != "" {
+		if err := recordFundingBlockTime(ctx, rpcClient, contractID, tx.BlockHash); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	} else {
+		log.Printf("Note: Funding is unconfirmed; run reminders or set-funding again after it confirms to record its time")
+	}
+	session.record("fundings recorded")
+	return nil
+}
+
+// setFundingFromBackend finds the contract's funding UTXO by its address and
+// records it
+func setFundingFromBackend(contractID string) error {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+
+	backend := newChainBackend()
+	utxos, err := backend.ListUnspent(contractInfo.P2WSHAddress)
+	if err != nil {
+		return fmt.Errorf("failed to look up contract address: %w", err)
+	}
+
+	var candidates []*rpc.UTXO
+	for _, utxo := range utxos {
+		if fundingVout < 0 || int64(utxo.Vout) == fundingVout {
+			candidates = append(candidates, utxo)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no unspent output pays contract address %s", contractInfo.P2WSHAddress)
+	}
+	if len(candidates) > 1 {
+		for _, utxo := range candidates {
+			log.Printf("  %s:%d (%.8f BTC, %d confirmations)", utxo.TxID, utxo.Vout, utxo.Amount, utxo.Confirmations)
+		}
+		return fmt.Errorf("%d unspent outputs pay contract address %s; pass the txid (and --vout) to pick one",
+			len(candidates), contractInfo.P2WSHAddress)
+	}
+	utxo := candidates[0]
+
+	// The node can supply the funding transaction and its block time
+	if cfg.Backend == config.BackendNode {
+		fundingVout = int64(utxo.Vout)
+		return setFunding(contractID, utxo.TxID)
+	}
+
+	amount, err := utxo.AmountSats()
+	if err != nil {
+		return fmt.Errorf("invalid output amount: %w", err)
+	}
+	if err := recordFundingUTXO(contractID, utxo.TxID, utxo.Vout, amount); err != nil {
+		return fmt.Errorf("failed to update funding status: %w", err)
+	}
+
+	log.Printf("Funding recorded: %s:%d (%d satoshis, %d confirmations)", utxo.TxID, utxo.Vout, amount, utxo.Confirmations)
+
+	// The confirmation time is what the inheritor's timelock counts from;
+	// an added UTXO leaves the first one's time in place
+	if !addFunding && utxo.Confirmations > 0 {
+		tx, err := backend.GetRawTransaction(utxo.TxID)
+		if err == nil && tx.BlockTime > 0 {
+			err = contract.UpdateFundingBlockTime(contractID, tx.BlockTime)
+		}
+		if err != nil || tx.BlockTime == 0 {
+			log.Printf("Note: The funding block time is not available from the %s backend; reminders looks it up on the node", cfg.Backend)
+		}
+	}
+	session.record("fundings recorded")
+	return nil
+}
+
+// recordFundingUTXO records a funding UTXO, replacing the recorded ones
+// unless --add was given
+func recordFundingUTXO(contractID, txid string, vout uint32, amount int64) error {
+	if addFunding {
+		return contract.AddFundingUTXO(contractID, txid, vout, amount)
+	}
+	return contract.UpdateFundingStatus(contractID, txid, vout, amount)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var withdrawContract string
+
+// stdinIsTerminal reports whether stdin is a terminal, so prompts are only
+// shown to someone who can answer them; tests replace it
+var stdinIsTerminal = func() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{ownerWithdrawCmd, inheritorWithdrawCmd} {
+		cmd.Flags().StringVar(&withdrawContract, "contract-id", "", "Contract to withdraw from (instead of the argument; default: prompt for it, or the active contract without a terminal)")
+		cmd.Flags().StringVar(&destinationAddr, "destination", "", "Destination address (same as --to)")
+	}
+}
+
+// errNotInteractive is returned for a value that would be prompted for, when
+// stdin is not a terminal
+func errNotInteractive(what, flag string) error {
+	return fmt.Errorf("no %s given and stdin is not a terminal; pass %s", what, flag)
+}
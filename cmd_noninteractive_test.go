@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// stdinSentinel is fed to stdin; a command that prompts would consume it
+const stdinSentinel = "must-not-be-read\n"
+
+// setupNonInteractive runs the test in a temporary directory with the example
+// configuration and a funded contract, with an unreachable node and stdin
+// replaced by a pipe holding stdinSentinel. It returns the contract ID and a
+// function reporting whether anything was read from stdin.
+func setupNonInteractive(t *testing.T) (string, func() bool) {
+	t.Helper()
+
+	env, err := os.ReadFile(".env.example")
+	if err != nil {
+		t.Fatalf("Failed to read .env.example: %v", err)
+	}
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".env", env, 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+	t.Setenv("TESTNET_RPC_HOST", "127.0.0.1:1")
+
+	ownerKeys, err := keys.NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKeys, err := keys.NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(ownerKeys.GetCompressedPubKeyBytes(),
+		inheritorKeys.GetCompressedPubKeyBytes(), 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	addr, err := inheritanceScript.GetP2WSHAddress()
+	if err != nil {
+		t.Fatalf("GetP2WSHAddress failed: %v", err)
+	}
+	contractID := contract.GenerateContractID(addr, &chaincfg.TestNet3Params)
+	contractInfo := &contract.ContractInfo{
+		ContractID:   contractID,
+		Network:      chaincfg.TestNet3Params.Name,
+		TimelockDays: 180,
+		OwnerWIF:     ownerKeys.WIF.String(),
+		InheritorWIF: inheritorKeys.WIF.String(),
+		AddressType:  script.AddressTypeP2WSH,
+		RedeemScript: hex.EncodeToString(inheritanceScript.RedeemScript),
+		P2WSHAddress: addr.EncodeAddress(),
+	}
+	if err := contract.SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+	txid := "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807"
+	if err := contract.UpdateFundingStatus(contractID, txid, 0, 100000); err != nil {
+		t.Fatalf("UpdateFundingStatus failed: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	if _, err := w.WriteString(stdinSentinel); err != nil {
+		t.Fatalf("Failed to write to pipe: %v", err)
+	}
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+	})
+
+	stdinRead := func() bool {
+		rest, _ := io.ReadAll(r)
+		return string(rest) != stdinSentinel
+	}
+	return contractID, stdinRead
+}
+
+// testDestination returns a testnet P2WPKH address to withdraw to
+func testDestination(t *testing.T) string {
+	t.Helper()
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to create destination address: %v", err)
+	}
+	return addr.EncodeAddress()
+}
+
+// runCommand executes the root command with args, resetting the flags the
+// withdrawal commands share, --output and --quiet afterwards
+func runCommand(t *testing.T, args ...string) error {
+	t.Helper()
+	t.Cleanup(func() {
+		withdrawContract, destinationAddr, assumeYes, outputFormat, quiet = "", "", false, "text", false
+		for _, name := range []string{"output", "quiet"} {
+			rootCmd.PersistentFlags().Lookup(name).Changed = false
+		}
+		for _, cmd := range []string{"owner-withdraw", "inheritor-withdraw"} {
+			sub, _, _ := rootCmd.Find([]string{cmd})
+			for _, name := range []string{"contract-id", "to", "destination"} {
+				sub.Flags().Lookup(name).Changed = false
+			}
+		}
+	})
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	return rootCmd.Execute()
+}
+
+func TestWithdrawCommands_FlagsReadNoStdin(t *testing.T) {
+	destination := testDestination(t)
+
+	tests := []struct {
+		name string
+		args func(contractID string) []string
+	}{
+		{"owner with --contract-id and --destination", func(id string) []string {
+			return []string{"owner-withdraw", "--contract-id", id, "--destination", destination, "--yes"}
+		}},
+		{"owner with argument and --to", func(id string) []string {
+			return []string{"owner-withdraw", id, "--to", destination, "--yes"}
+		}},
+		{"inheritor with --contract-id and --destination", func(id string) []string {
+			return []string{"inheritor-withdraw", "--contract-id", id, "--destination", destination, "--yes"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractID, stdinRead := setupNonInteractive(t)
+
+			// Every input comes from the flags, so the run gets as far as
+			// the broadcast, which fails at the unreachable node
+			err := runCommand(t, tt.args(contractID)...)
+			if err == nil || !strings.Contains(err.Error(), "failed to broadcast") {
+				t.Errorf("Expected the broadcast to fail, got %v", err)
+			}
+			if stdinRead() {
+				t.Error("Command read from stdin")
+			}
+		})
+	}
+}
+
+func TestWithdrawCommands_NoTerminalNoPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    func(contractID string) []string
+		wantErr string
+	}{
+		{"missing destination", func(id string) []string {
+			return []string{"owner-withdraw", "--contract-id", id}
+		}, "no destination address given and stdin is not a terminal"},
+		{"missing contract and no active contract", func(string) []string {
+			return []string{"owner-withdraw", "--to", testDestination(t)}
+		}, "no active contract"},
+		{"conflicting contract IDs", func(id string) []string {
+			return []string{"inheritor-withdraw", "other", "--contract-id", id}
+		}, "given as argument and"},
+		{"mainnet destination", func(id string) []string {
+			mainnet, _ := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.MainNetParams)
+			return []string{"owner-withdraw", "--contract-id", id, "--to", mainnet.EncodeAddress()}
+		}, "is a mainnet address, but the configured network is testnet3"},
+		{"broadcast declined without --yes", func(id string) []string {
+			return []string{"owner-withdraw", "--contract-id", id, "--to", testDestination(t)}
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractID, stdinRead := setupNonInteractive(t)
+
+			err := runCommand(t, tt.args(contractID)...)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Expected the withdrawal to be declined without error, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if stdinRead() {
+				t.Error("Command read from stdin")
+			}
+		})
+	}
+}
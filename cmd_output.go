@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+)
+
+var (
+	outputFormat string
+	quiet        bool
+)
+
+// stdout receives command results and stderr the logs; tests replace them
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "Output format: text, or json to print the results of generate, show, list, status, address-from-descriptor and the withdraw commands as JSON on stdout (logs stay on stderr)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress logs and warnings on stderr; results on stdout and errors are still printed")
+}
+
+// configureLogging sends the logs to stderr, or discards them with --quiet
+func configureLogging() {
+	if quiet {
+		log.SetOutput(io.Discard)
+		return
+	}
+	log.SetOutput(stderr)
+}
+
+// contractListEntry is a contract as listed by list with --output json. It
+// leaves out the keys, which show prints for a single contract.
+type contractListEntry struct {
+	ContractID    string    `json:"contract_id"`
+	Label         string    `json:"label,omitempty"`
+	Network       string    `json:"network,omitempty"`
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+	Timelock      string    `json:"timelock,omitempty"`
+	Address       string    `json:"address,omitempty"`
+	IsFunded      bool      `json:"is_funded"`
+	FundingAmount int64     `json:"funding_amount,omitempty"`
+	FundingUTXOs  int       `json:"funding_utxos,omitempty"`
+	Error         string    `json:"error,omitempty"` // set for an unreadable contract file
+}
+
+// withdrawalOutput is the result of a withdraw command with --output json
+type withdrawalOutput struct {
+	ContractID string `json:"contract_id"`
+	Path       string `json:"path"` // "owner" or "inheritor"
+	TxHex      string `json:"tx_hex"`
+	Fee        int64  `json:"fee"`
+	Broadcast  bool   `json:"broadcast"`
+	TxID       string `json:"txid,omitempty"`
+}
+
+// descriptorAddressOutput is the result of address-from-descriptor with
+// --output json
+type descriptorAddressOutput struct {
+	Address       string `json:"address"`
+	ScriptHash    string `json:"script_hash"`
+	WitnessScript string `json:"witness_script"`
+}
+
+// checkOutputFormat validates --output
+func checkOutputFormat() error {
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format %q (expected text or json)", outputFormat)
+	}
+	return nil
+}
+
+// jsonOutput reports whether results are printed as JSON
+func jsonOutput() bool {
+	return outputFormat == "json"
+}
+
+// promptf prints a prompt for input. With --output json or --quiet it goes
+// to stderr, so stdout holds nothing but the result.
+func promptf(format string, args ...interface{}) {
+	if jsonOutput() || quiet {
+		fmt.Fprintf(stderr, format, args...)
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// printResult prints a line of a command's result on stdout, apart from the
+// logs, so scripts can capture it. With --output json the JSON document is
+// the result instead.
+func printResult(format string, args ...interface{}) {
+	if jsonOutput() {
+		return
+	}
+	fmt.Fprintf(stdout, format+"\n", args...)
+}
+
+// emitJSON prints v as indented JSON on stdout with --output json, and does
+// nothing otherwise
+func emitJSON(v interface{}) error {
+	if !jsonOutput() {
+		return nil
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON output: %w", err)
+	}
+	return nil
+}
+
+// emitWithdrawal prints the result of a withdrawal: the txid, or the signed
+// transaction's hex when txid is empty because it was not broadcast, or
+// both as JSON with --output json
+func emitWithdrawal(contractID, path string, tx *wire.MsgTx, fee btcutil.Amount, txid string) error {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	if !jsonOutput() {
+		if txid != "" {
+			printResult("%s", txid)
+		} else {
+			printResult("%x", buf.Bytes())
+		}
+		return nil
+	}
+	return emitJSON(withdrawalOutput{
+		ContractID: contractID,
+		Path:       path,
+		TxHex:      hex.EncodeToString(buf.Bytes()),
+		Fee:        int64(fee),
+		Broadcast:  txid != "",
+		TxID:       txid,
+	})
+}
+
+// newContractListEntry summarizes a contract for list with --output json
+func newContractListEntry(contractInfo *contract.ContractInfo) contractListEntry {
+	entry := contractListEntry{
+		ContractID: contractInfo.ContractID,
+		Label:      contractInfo.Label,
+		Network:    contractInfo.Network,
+		CreatedAt:  contractInfo.CreatedAt,
+		Timelock:   timelockSummary(contractInfo),
+		Address:    contractInfo.P2WSHAddress,
+		IsFunded:   contractInfo.IsFunded,
+	}
+	if contractInfo.IsFunded {
+		entry.FundingAmount = contractInfo.TotalFunding()
+		entry.FundingUTXOs = len(contractInfo.FundingUTXOs)
+	}
+	return entry
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// runJSONCommand runs a command with --output json and returns what it
+// printed on stdout
+func runJSONCommand(t *testing.T, args ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	stdout = &buf
+	t.Cleanup(func() { stdout = os.Stdout })
+
+	if err := runCommand(t, append(args, "--output", "json")...); err != nil {
+		t.Fatalf("%v failed: %v", args, err)
+	}
+	return buf.Bytes()
+}
+
+// decodeJSON unmarshals the output of a command, rejecting unknown fields
+// and anything after the JSON value
+func decodeJSON(t *testing.T, data []byte, v interface{}) {
+	t.Helper()
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		t.Fatalf("Output is not the expected JSON: %v\n%s", err, data)
+	}
+	if decoder.More() {
+		t.Fatalf("Output holds more than one JSON value:\n%s", data)
+	}
+}
+
+func TestOutputJSON_Generate(t *testing.T) {
+	setupNonInteractive(t)
+
+	var contractInfo contract.ContractInfo
+	decodeJSON(t, runJSONCommand(t, "generate"), &contractInfo)
+
+	if contractInfo.ContractID == "" || contractInfo.P2WSHAddress == "" || contractInfo.RedeemScript == "" {
+		t.Errorf("Generated contract is incomplete: %+v", contractInfo)
+	}
+	if _, err := contract.LoadContractInfo(contractInfo.ContractID); err != nil {
+		t.Errorf("Emitted contract %s was not saved: %v", contractInfo.ContractID, err)
+	}
+}
+
+func TestOutputJSON_Show(t *testing.T) {
+	contractID, _ := setupNonInteractive(t)
+
+	var contractInfo contract.ContractInfo
+	decodeJSON(t, runJSONCommand(t, "show", contractID), &contractInfo)
+
+	if contractInfo.ContractID != contractID {
+		t.Errorf("Expected contract %s, got %s", contractID, contractInfo.ContractID)
+	}
+	if !contractInfo.IsFunded || contractInfo.TotalFunding() != 100000 {
+		t.Errorf("Expected a contract funded with 100000 satoshis, got %+v", contractInfo)
+	}
+}
+
+func TestOutputJSON_List(t *testing.T) {
+	contractID, _ := setupNonInteractive(t)
+
+	var entries []contractListEntry
+	decodeJSON(t, runJSONCommand(t, "list"), &entries)
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 contract, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.ContractID != contractID || !entry.IsFunded || entry.FundingAmount != 100000 || entry.Address == "" {
+		t.Errorf("Unexpected list entry: %+v", entry)
+	}
+}
+
+func TestOutputJSON_Withdraw(t *testing.T) {
+	for _, path := range []string{"owner", "inheritor"} {
+		t.Run(path, func(t *testing.T) {
+			contractID, _ := setupNonInteractive(t)
+
+			// Without --yes and a terminal the broadcast is declined, so
+			// the built transaction is reported without a txid
+			var result withdrawalOutput
+			decodeJSON(t, runJSONCommand(t, path+"-withdraw", contractID, "--to", testDestination(t), "--fee", "500"), &result)
+
+			if result.ContractID != contractID || result.Path != path {
+				t.Errorf("Unexpected withdrawal: %+v", result)
+			}
+			if result.Broadcast || result.TxID != "" {
+				t.Errorf("Expected no broadcast, got %+v", result)
+			}
+			if result.Fee != 500 {
+				t.Errorf("Expected fee 500, got %d", result.Fee)
+			}
+
+			raw, err := hex.DecodeString(result.TxHex)
+			if err != nil {
+				t.Fatalf("Invalid tx_hex: %v", err)
+			}
+			var tx wire.MsgTx
+			if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+				t.Fatalf("tx_hex is not a transaction: %v", err)
+			}
+			if len(tx.TxOut) != 1 || tx.TxOut[0].Value != 100000-500 {
+				t.Errorf("Unexpected withdrawal outputs: %+v", tx.TxOut)
+			}
+		})
+	}
+}
+
+func TestQuiet_GenerateAddressOnStdout(t *testing.T) {
+	for _, quietRun := range []bool{true, false} {
+		t.Run(fmt.Sprintf("quiet=%t", quietRun), func(t *testing.T) {
+			existingID, _ := setupNonInteractive(t)
+
+			var outBuf, errBuf bytes.Buffer
+			stdout, stderr = &outBuf, &errBuf
+			t.Cleanup(func() {
+				stdout, stderr = os.Stdout, os.Stderr
+				log.SetOutput(os.Stderr)
+			})
+
+			args := []string{"generate"}
+			if quietRun {
+				args = append(args, "--quiet")
+			}
+			if err := runCommand(t, args...); err != nil {
+				t.Fatalf("generate failed: %v", err)
+			}
+
+			contractIDs, err := contract.ListContracts()
+			if err != nil || len(contractIDs) != 2 {
+				t.Fatalf("Expected the generated contract next to the existing one, got %v (%v)", contractIDs, err)
+			}
+			generatedID := contractIDs[0]
+			if generatedID == existingID {
+				generatedID = contractIDs[1]
+			}
+			contractInfo, err := contract.LoadContractInfo(generatedID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+
+			if outBuf.String() != contractInfo.P2WSHAddress+"\n" {
+				t.Errorf("Expected only the address %s on stdout, got %q", contractInfo.P2WSHAddress, outBuf.String())
+			}
+			if quietRun {
+				if strings.Contains(errBuf.String(), contractInfo.P2WSHAddress) {
+					t.Errorf("Address appears on stderr with --quiet:\n%s", errBuf.String())
+				}
+				if errBuf.Len() != 0 {
+					t.Errorf("Expected no logs with --quiet, got:\n%s", errBuf.String())
+				}
+			} else if !strings.Contains(errBuf.String(), "Generating Bitcoin Inheritance Contract") {
+				t.Errorf("Expected progress logs on stderr, got:\n%s", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestOutputJSON_AddressFromDescriptor(t *testing.T) {
+	contractID, _ := setupNonInteractive(t)
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	redeemScript, _ := hex.DecodeString(contractInfo.RedeemScript)
+	descriptor, err := (&script.InheritanceScript{RedeemScript: redeemScript}).Descriptor()
+	if err != nil {
+		t.Fatalf("Descriptor failed: %v", err)
+	}
+
+	var output descriptorAddressOutput
+	decodeJSON(t, runJSONCommand(t, "address-from-descriptor", descriptor), &output)
+
+	if output.Address != contractInfo.P2WSHAddress {
+		t.Errorf("Expected address %s, got %s", contractInfo.P2WSHAddress, output.Address)
+	}
+	if output.WitnessScript != contractInfo.RedeemScript {
+		t.Errorf("Expected witness script %s, got %s", contractInfo.RedeemScript, output.WitnessScript)
+	}
+	if scriptHash := sha256.Sum256(redeemScript); output.ScriptHash != hex.EncodeToString(scriptHash[:]) {
+		t.Errorf("Expected script hash %x, got %s", scriptHash, output.ScriptHash)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/backup"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+)
+
+var (
+	paperOut         string
+	paperIncludeKeys bool
+)
+
+var paperBackupCmd = &cobra.Command{
+	Use:   "paper-backup [contract-id]",
+	Short: "Write a printable paper backup of a contract",
+	Long: `Render a printable text sheet with the contract ID, funding address, redeem
+script and timelock, with QR codes for offline cold storage. Private keys are
+only included with --include-keys.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return paperBackup(args[0])
+	},
+}
+
+func init() {
+	paperBackupCmd.Flags().StringVar(&paperOut, "out", "", "Output file (default: <contract-id>-backup.txt)")
+	paperBackupCmd.Flags().BoolVar(&paperIncludeKeys, "include-keys", false, "Include the private keys (WIF) on the sheet")
+	rootCmd.AddCommand(paperBackupCmd)
+}
+
+func paperBackup(contractID string) error {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+
+	sheet, err := backup.RenderPaperBackup(contractInfo, paperIncludeKeys)
+	if err != nil {
+		return fmt.Errorf("failed to render paper backup: %w", err)
+	}
+
+	outPath := paperOut
+	if outPath == "" {
+		outPath = contractID + "-backup.txt"
+	}
+	if err := os.WriteFile(outPath, []byte(sheet), 0600); err != nil {
+		return fmt.Errorf("failed to write paper backup: %w", err)
+	}
+
+	log.Printf("Paper backup written to %s", outPath)
+	session.record("paper backups written")
+	if paperIncludeKeys {
+		log.Printf("⚠️  The backup contains private keys - print it offline and delete the file")
+	}
+	return nil
+}
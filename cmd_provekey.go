@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proveKeyMessage string
+	proveKeyOut     string
+)
+
+var proveKeyCmd = &cobra.Command{
+	Use:   "prove-key",
+	Short: "Sign a challenge to prove control of an inheritor key",
+	Long: `Run by the inheritor: sign the owner's challenge message with the inheritor
+private key and write a signed public key file. The owner passes the file to
+'generate --inheritor-pubkey' to verify the key before building the contract.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return proveKey()
+	},
+}
+
+func init() {
+	proveKeyCmd.Flags().StringVar(&proveKeyMessage, "message", "", "Challenge message provided by the owner (required)")
+	proveKeyCmd.Flags().StringVar(&proveKeyOut, "out", "inheritor-pubkey.json", "Signed public key file to write")
+	proveKeyCmd.MarkFlagRequired("message")
+	rootCmd.AddCommand(proveKeyCmd)
+}
+
+func proveKey() error {
+	// Read the WIF from stdin so it does not end up in shell history
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter inheritor private key (WIF): ")
+	wif, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	keyPair, err := keys.KeyPairFromWIF(strings.TrimSpace(wif), cfg.ChainParams)
+	if err != nil {
+		return fmt.Errorf("failed to load private key: %w", err)
+	}
+
+	signature, err := keyPair.SignMessage(proveKeyMessage)
+	if err != nil {
+		return err
+	}
+
+	signed := keys.SignedPubKey{
+		Message:   proveKeyMessage,
+		Signature: signature,
+		PubKey:    hex.EncodeToString(keyPair.GetCompressedPubKeyBytes()),
+	}
+	data, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal signed public key: %w", err)
+	}
+	if err := os.WriteFile(proveKeyOut, data, 0644); err != nil {
+		return fmt.Errorf("failed to write signed public key file: %w", err)
+	}
+
+	log.Printf("Signed public key written to %s", proveKeyOut)
+	log.Printf("Send this file to the owner; it contains no private key")
+	return nil
+}
+
+// loadInheritorPubKey accepts a hex public key or the path of a signed public
+// key file. A file's signature is verified before the key is used.
+func loadInheritorPubKey(value string) ([]byte, error) {
+	if _, err := os.Stat(value); err == nil {
+		pubKey, signed, err := keys.LoadSignedPubKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("inheritor key proof rejected: %w", err)
+		}
+		log.Printf("✅ Inheritor proved control of %s by signing: %q", signed.PubKey, signed.Message)
+		return pubKey, nil
+	}
+
+	pubKey, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("--inheritor-pubkey is neither a file nor a hex public key: %w", err)
+	}
+	log.Printf("⚠️  Inheritor public key was not proven; use a signed public key file from prove-key to verify the inheritor controls it")
+	return pubKey, nil
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+// defaultPSBTFile is where export-psbt writes when --out is not given
+const defaultPSBTFile = "withdrawal.psbt"
+
+var (
+	psbtPath string
+	psbtOut  string
+)
+
+var exportPSBTCmd = &cobra.Command{
+	Use:   "export-psbt <contract-id>",
+	Short: "Build an unsigned withdrawal as a PSBT for offline signing",
+	Long: `Build an unsigned owner or inheritor withdrawal and write it as a base64 PSBT.
+Sign it on another machine with sign-psbt, then finalize and broadcast it here
+with import-psbt. No private key is needed on this machine.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportPSBT(bufio.NewReader(os.Stdin), args[0])
+	},
+}
+
+var signPSBTCmd = &cobra.Command{
+	Use:   "sign-psbt <file>",
+	Short: "Sign a withdrawal PSBT with the owner's or inheritor's key",
+	Long: `Add a signature to a PSBT written by export-psbt. The private key (WIF) is read
+from stdin and selects the path: the owner's key signs an owner withdrawal, the
+inheritor's key an inheritor withdrawal. No node connection is needed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return signPSBT(args[0])
+	},
+}
+
+var importPSBTCmd = &cobra.Command{
+	Use:   "import-psbt <contract-id> <file>",
+	Short: "Finalize a signed withdrawal PSBT and broadcast it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importPSBT(args[0], args[1])
+	},
+}
+
+func init() {
+	exportPSBTCmd.Flags().StringVar(&psbtPath, "path", "owner", "Spend path: owner or inheritor")
+	exportPSBTCmd.Flags().StringVar(&psbtOut, "out", defaultPSBTFile, "File to write the PSBT to")
+	exportPSBTCmd.Flags().StringVar(&destinationAddr, "to", "", "Destination address (default: prompt for it)")
+	exportPSBTCmd.Flags().Int64Var(&withdrawFee, "fee", 0, "Flat fee in satoshis (default: DEFAULT_FEE_SATOSHIS)")
+	exportPSBTCmd.Flags().BoolVar(&ownerRBF, "rbf", false, "Signal BIP 125 replaceability (owner path)")
+	signPSBTCmd.Flags().StringVar(&psbtOut, "out", "", "File to write the signed PSBT to (default: overwrite the input)")
+	importPSBTCmd.Flags().BoolVar(&allowHighFee, "allow-high-fee-rate", false, "Skip the MAX_FEE_RATE check on the effective fee rate")
+	rootCmd.AddCommand(exportPSBTCmd)
+	rootCmd.AddCommand(signPSBTCmd)
+	rootCmd.AddCommand(importPSBTCmd)
+}
+
+func exportPSBT(reader *bufio.Reader, contractID string) error {
+	log.Printf("=== Export Withdrawal PSBT ===")
+
+	if psbtPath != "owner" && psbtPath != "inheritor" {
+		return fmt.Errorf("--path must be owner or inheritor, got %q", psbtPath)
+	}
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+	contractInfo, redeemScript, contractUTXO, err := loadFundedContract(ctx, contractID)
+	if err != nil {
+		return err
+	}
+
+	destAddr, err := readDestination(reader)
+	if err != nil {
+		return err
+	}
+
+	feeChoice, err := resolveWithdrawFee()
+	if err != nil {
+		return err
+	}
+
+	var timelock int64
+	if psbtPath == "inheritor" {
+		parsed, err := script.ParseRedeemScript(redeemScript, cfg.ChainParams)
+		if err != nil {
+			return fmt.Errorf("failed to parse redeem script: %w", err)
+		}
+		timelock = parsed.RelativeTimelock
+		if parsed.TimelockType == script.Absolute {
+			timelock = parsed.LockTime
+		}
+		log.Printf("Note: the inheritor withdrawal can only confirm once the timelock has expired (%s)",
+			inheritorAvailability(contractInfo))
+	}
+
+	var packet *psbt.Packet
+	_, _, _, err = buildWithFee(feeChoice, redeemScript, func(txBuilder *transaction.TransactionBuilder) (*wire.MsgTx, error) {
+		var buildErr error
+		if psbtPath == "inheritor" {
+			packet, buildErr = txBuilder.BuildInheritorWithdrawPSBT(contractUTXO, destAddr, redeemScript, timelock)
+		} else {
+			packet, buildErr = txBuilder.BuildOwnerWithdrawPSBT(contractUTXO, destAddr, redeemScript, ownerRBF)
+		}
+		if buildErr != nil {
+			return nil, buildErr
+		}
+		return packet.UnsignedTx, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build PSBT: %w", err)
+	}
+
+	// sign-psbt shares psbtOut with a different default, so the variable
+	// may be empty
+	out := psbtOut
+	if out == "" {
+		out = defaultPSBTFile
+	}
+	if err := writePSBT(out, packet); err != nil {
+		return err
+	}
+	log.Printf("✅ Unsigned %s withdrawal written to %s", psbtPath, out)
+	log.Printf("Sign it offline with: sign-psbt %s", out)
+	session.record("PSBTs exported")
+	return nil
+}
+
+func signPSBT(path string) error {
+	log.Printf("=== Sign Withdrawal PSBT ===")
+
+	packet, err := readPSBT(path)
+	if err != nil {
+		return err
+	}
+
+	// Read the WIF from stdin so it does not end up in shell history
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter the owner's or inheritor's private key (WIF): ")
+	wif, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %w", err)
+	}
+	keyPair, err := keys.KeyPairFromWIF(strings.TrimSpace(wif), cfg.ChainParams)
+	if err != nil {
+		return fmt.Errorf("failed to load private key: %w", err)
+	}
+
+	txBuilder := transaction.NewTransactionBuilder(cfg.ChainParams, 0)
+	if err := txBuilder.SignPSBT(packet, keyPair.Signer()); err != nil {
+		return err
+	}
+
+	out := psbtOut
+	if out == "" {
+		out = path
+	}
+	if err := writePSBT(out, packet); err != nil {
+		return err
+	}
+	log.Printf("✅ Signed PSBT written to %s", out)
+	log.Printf("Broadcast it from the online machine with: import-psbt <contract-id> %s", out)
+	return nil
+}
+
+func importPSBT(contractID, path string) error {
+	log.Printf("=== Import Signed PSBT ===")
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+	contractInfo, redeemScript, contractUTXO, err := loadFundedContract(ctx, contractID)
+	if err != nil {
+		return err
+	}
+	packet, err := readPSBT(path)
+	if err != nil {
+		return err
+	}
+
+	// Refuse a PSBT for another contract or an outdated funding UTXO
+	input := packet.Inputs[0]
+	if !bytes.Equal(input.WitnessScript, redeemScript) {
+		return fmt.Errorf("PSBT does not spend contract %s (witness script differs)", contractID)
+	}
+	if packet.UnsignedTx.TxIn[0].PreviousOutPoint != *wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout) {
+		return fmt.Errorf("PSBT spends %s, not the contract's funding UTXO %s:%d",
+			packet.UnsignedTx.TxIn[0].PreviousOutPoint, contractUTXO.TxHash, contractUTXO.Vout)
+	}
+	if len(input.PartialSigs) == 0 {
+		return fmt.Errorf("PSBT is not signed yet; run sign-psbt first")
+	}
+
+	parsed, err := script.ParseRedeemScript(redeemScript, cfg.ChainParams)
+	if err != nil {
+		return fmt.Errorf("failed to parse redeem script: %w", err)
+	}
+	spender := "owner"
+	if !bytes.Equal(input.PartialSigs[0].PubKey, parsed.OwnerPubKey) {
+		spender = "inheritor"
+	}
+
+	txBuilder := transaction.NewTransactionBuilder(cfg.ChainParams, 0)
+	tx, err := txBuilder.FinalizePSBT(packet)
+	if err != nil {
+		return err
+	}
+	if err := txBuilder.ValidateTransactionWithScript(tx, contractUTXO, redeemScript); err != nil {
+		return fmt.Errorf("transaction validation failed: %w", err)
+	}
+	if err := checkMaxFeeRate(tx, contractUTXO.Amount); err != nil {
+		return err
+	}
+
+	if err := checkFundingUnspent(ctx, newChainBackend(), contractInfo.FundingTxID, contractInfo.FundingVout); err != nil {
+		return err
+	}
+	if spender == "inheritor" {
+		if parsed.TimelockType == script.Absolute {
+			err = checkLockTimeExpired(ctx, parsed.LockTime)
+		} else {
+			err = checkTimelockExpired(ctx, contractInfo.FundingTxID, parsed.RelativeTimelock)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	txHex, err := txBuilder.SerializeTransaction(tx)
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	log.Printf("Transaction hex: %s", txHex)
+	if err := checkMempoolAccept(ctx, tx); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if !confirm(reader, fmt.Sprintf("Do you want to broadcast this %s withdrawal?", spender)) {
+		log.Printf("Transaction not broadcast (user cancelled)")
+		return nil
+	}
+
+	broadcastCtx, cancelBroadcast := rpcContext()
+	defer cancelBroadcast()
+	txid, err := broadcastTransaction(broadcastCtx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	log.Printf("✅ Transaction broadcast successfully!")
+	log.Printf("Transaction ID: %s", txid)
+	recordWithdrawal(contractID, txid, spender, btcutil.Amount(tx.TxOut[0].Value), contractUTXO.Amount-totalOutput(tx))
+	return nil
+}
+
+// loadFundedContract loads a funded contract with its redeem script and
+// funding UTXO
+func loadFundedContract(ctx context.Context, contractID string) (*contract.ContractInfo, []byte, *transaction.UTXO, error) {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load contract: %w", err)
+	}
+	if !contractInfo.IsFunded {
+		return nil, nil, nil, fmt.Errorf("contract is not funded yet")
+	}
+	if len(contractInfo.FundingUTXOs) > 1 {
+		return nil, nil, nil, fmt.Errorf("PSBTs spend a single funding UTXO; contract has %d", len(contractInfo.FundingUTXOs))
+	}
+
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	fundingHash, err := chainhash.NewHashFromStr(contractInfo.FundingTxID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid funding transaction hash: %w", err)
+	}
+
+	pkScript, err := contractUTXOPkScript(ctx, contractInfo, contractInfo.FundingTxID, contractInfo.FundingVout)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	contractUTXO := &transaction.UTXO{
+		TxHash:   fundingHash,
+		Vout:     contractInfo.FundingVout,
+		Amount:   btcutil.Amount(contractInfo.FundingAmount),
+		PkScript: pkScript,
+
+		AddressType: contractInfo.AddressType,
+	}
+	return contractInfo, redeemScript, contractUTXO, nil
+}
+
+// readPSBT reads a base64 PSBT file
+func readPSBT(path string) (*psbt.Packet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PSBT file: %w", err)
+	}
+	return transaction.ParsePSBT(string(data))
+}
+
+// writePSBT writes a PSBT as base64 to path
+func writePSBT(path string, packet *psbt.Packet) error {
+	encoded, err := transaction.SerializePSBT(packet)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write PSBT file: %w", err)
+	}
+	return nil
+}
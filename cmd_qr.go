@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/qr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	showQR bool
+	qrOut  string
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{generateCmd, showCmd} {
+		cmd.Flags().BoolVar(&showQR, "qr", false, "Draw the funding address as a QR code in the terminal (on stderr)")
+		cmd.Flags().StringVar(&qrOut, "qr-out", "", "Write the funding address as a PNG QR code to this file")
+	}
+}
+
+// renderAddressQR draws address as a QR code with --qr and writes it as a PNG
+// with --qr-out. The terminal code goes to stderr, so stdout keeps only the
+// result; it is drawn even with --quiet, since it was asked for.
+func renderAddressQR(address string) error {
+	if showQR {
+		code, err := qr.Text(address)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "\nFunding address %s:\n%s\n", address, code)
+	}
+	if qrOut != "" {
+		if err := qr.WritePNG(address, qrOut, qr.PNGSize); err != nil {
+			return err
+		}
+		log.Printf("QR code of the funding address written to %s", qrOut)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh [contract-id]",
+	Short: "Reset the inheritor's timelock by spending the contract back to itself",
+	Long: `Spend every funding UTXO of a contract with the owner's key back to the
+contract's own address, as a dead man's switch. The keys and the address stay
+the same, but the relative timelock restarts when the new UTXO confirms, so the
+inheritor's path opens a full timelock later. The contract file is updated with
+the new funding UTXO. Contracts with an absolute timelock cannot be refreshed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return refreshContract(args)
+	},
+}
+
+func init() {
+	refreshCmd.Flags().Int64Var(&withdrawFee, "fee", 0, "Flat fee in satoshis (default: DEFAULT_FEE_SATOSHIS)")
+	refreshCmd.Flags().BoolVar(&allowHighFee, "allow-high-fee-rate", false, "Skip the MAX_FEE_RATE check on the effective fee rate")
+	refreshCmd.Flags().BoolVar(&grindLowR, "low-r", true, "Grind signatures for a low R value (71-byte signatures, as modern wallets do); --low-r=false disables it")
+	refreshCmd.Flags().BoolVar(&ownerRBF, "rbf", false, "Signal BIP 125 replaceability so the refresh can be fee-bumped")
+	rootCmd.AddCommand(refreshCmd)
+}
+
+func refreshContract(args []string) error {
+	log.Printf("=== Contract Refresh ===")
+
+	reader := bufio.NewReader(os.Stdin)
+	contractID, err := withdrawContractID(reader, args)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Step 1: Loading contract details...")
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+	if !contractInfo.IsFunded {
+		return fmt.Errorf("contract is not funded yet")
+	}
+	if contractInfo.IsWatchOnly || contractInfo.OwnerWIF == "" {
+		return fmt.Errorf("contract %s stores no owner private key; refreshing needs the owner's signature", contractID)
+	}
+	log.Printf("Contract found: %s", contractInfo.P2WSHAddress)
+	logFundingUTXOs(contractInfo)
+
+	ownerKeys, err := keys.KeyPairFromWIF(contractInfo.OwnerWIF, cfg.ChainParams)
+	if err != nil {
+		return fmt.Errorf("failed to load owner keys: %w", err)
+	}
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	ctx, cancel := rpcContext()
+	defer cancel()
+	contractUTXOs, err := fundingUTXOs(ctx, contractInfo)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Step 2: Building refresh transaction...")
+	feeChoice, err := resolveWithdrawFee()
+	if err != nil {
+		return err
+	}
+	tx, txBuilder, _, err := buildWithFee(feeChoice, redeemScript, func(txBuilder *transaction.TransactionBuilder) (*wire.MsgTx, error) {
+		return txBuilder.BuildRefreshTx(contractUTXOs, redeemScript, ownerRBF)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build transaction: %w", err)
+	}
+
+	log.Printf("Step 3: Signing transaction...")
+	if err := txBuilder.SignOwnerTransactionMulti(tx, contractUTXOs, redeemScript, ownerKeys.Signer()); err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	inputAmount := transaction.TotalAmount(contractUTXOs)
+	if err := txBuilder.ValidateTransactionWithScriptMulti(tx, contractUTXOs, redeemScript); err != nil {
+		return fmt.Errorf("transaction validation failed: %w", err)
+	}
+	if err := checkMaxFeeRate(tx, inputAmount); err != nil {
+		return err
+	}
+
+	txHex, err := txBuilder.SerializeTransaction(tx)
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	log.Printf("Transaction built successfully!")
+	log.Printf("Transaction hex: %s", txHex)
+
+	if err := checkMempoolAccept(ctx, tx); err != nil {
+		return err
+	}
+	if !confirm(reader, "Do you want to broadcast this refresh?") {
+		log.Printf("Transaction not broadcast (user cancelled)")
+		return nil
+	}
+
+	log.Printf("Step 4: Broadcasting transaction...")
+	broadcastCtx, cancelBroadcast := rpcContext()
+	defer cancelBroadcast()
+	txid, err := broadcastTransaction(broadcastCtx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+	log.Printf("✅ Transaction broadcast successfully!")
+	log.Printf("Transaction ID: %s", txid)
+
+	// Record the fee for audit, then make the new output the funding UTXO
+	refreshed := btcutil.Amount(tx.TxOut[0].Value)
+	fee := inputAmount - refreshed
+	session.recordTransfer("refreshes", refreshed, fee)
+	record := contract.WithdrawalRecord{TxID: txid, Path: "refresh", Fee: int64(fee), BroadcastAt: time.Now()}
+	if err := contract.RecordWithdrawal(contractID, record); err != nil {
+		log.Printf("Warning: Failed to record refresh: %v", err)
+	}
+	if err := contract.UpdateFundingStatus(contractID, txid, 0, int64(refreshed)); err != nil {
+		return fmt.Errorf("refresh broadcast as %s, but the contract file was not updated: %w", txid, err)
+	}
+
+	log.Printf("Contract refreshed: new funding UTXO %s:0 (%d satoshis)", txid, int64(refreshed))
+	log.Printf("The inheritor's timelock (%s) restarts once it confirms", timelockSummary(contractInfo))
+	printResult("%s", txid)
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reminderDays   int64
+	reminderFormat string
+)
+
+var remindersCmd = &cobra.Command{
+	Use:   "reminders",
+	Short: "List contracts whose inheritor path unlocks soon",
+	Long: `List funded contracts whose inheritor path unlocks within --days, so the
+owner can refresh them in time. The unlock time is the funding block time plus
+the contract's timelock. Funding times not recorded yet are looked up on the node.
+
+With --format mail the output is a plain-text email, printed only when a
+reminder is due, so a cron job can mail it as-is.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listReminders()
+	},
+}
+
+func init() {
+	remindersCmd.Flags().Int64Var(&reminderDays, "days", 30, "Remind about contracts unlocking within this many days")
+	remindersCmd.Flags().StringVar(&reminderFormat, "format", "text", "Output format: text or mail")
+	rootCmd.AddCommand(remindersCmd)
+}
+
+func listReminders() error {
+	if reminderFormat != "text" && reminderFormat != "mail" {
+		return fmt.Errorf("unknown format %q (expected text or mail)", reminderFormat)
+	}
+	if reminderDays < 0 {
+		return fmt.Errorf("--days must not be negative")
+	}
+
+	backfillFundingBlockTimes()
+
+	now := time.Now()
+	window := time.Duration(reminderDays) * 24 * time.Hour
+	reminders, unknown, err := contract.DueReminders(now, window)
+	if err != nil {
+		return err
+	}
+
+	if reminderFormat == "mail" {
+		// Stay silent when nothing is due so cron sends no mail
+		if len(reminders) > 0 || len(unknown) > 0 {
+			fmt.Print(formatReminderMail(reminders, unknown, reminderDays))
+		}
+		return nil
+	}
+
+	log.Printf("=== Contracts Unlocking Within %d Days ===", reminderDays)
+	if len(reminders) == 0 {
+		log.Printf("No contracts are due.")
+	}
+	for _, reminder := range reminders {
+		log.Printf("%s", formatReminder(reminder))
+	}
+	for _, contractID := range unknown {
+		log.Printf("Warning: Unlock time of %s is unknown; its funding is unconfirmed, its time could not be fetched or its file is unreadable (see repair)", contractID)
+	}
+	return nil
+}
+
+// formatReminder describes one reminder on a single line
+func formatReminder(reminder contract.Reminder) string {
+	name := reminder.ContractID
+	if reminder.Label != "" {
+		name = fmt.Sprintf("%s (%s)", reminder.ContractID, reminder.Label)
+	}
+
+	expiry := reminder.Expiry.Format("2006-01-02 15:04 MST")
+	if reminder.Remaining <= 0 {
+		return fmt.Sprintf("%s: UNLOCKED since around %s - the inheritor can spend now", name, expiry)
+	}
+	return fmt.Sprintf("%s: unlocks around %s (in %s)", name, expiry, formatDays(reminder.Remaining))
+}
+
+// formatReminderMail renders the reminders as an email with a Subject header
+func formatReminderMail(reminders []contract.Reminder, unknown []string, days int64) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Subject: [bitcoin-inheritance] %d contract(s) unlock for the inheritor within %d days\n\n",
+		len(reminders), days)
+	b.WriteString("The inheritor path of these contracts unlocks soon. To keep control,\n")
+	b.WriteString("refresh each contract by spending it back to itself with the owner key:\n")
+	b.WriteString("refresh <contract-id>.\n\n")
+
+	for _, reminder := range reminders {
+		fmt.Fprintf(&b, "- %s\n", formatReminder(reminder))
+	}
+	if len(unknown) > 0 {
+		b.WriteString("\nUnlock time unknown (funding unconfirmed, not fetched or file unreadable):\n")
+		for _, contractID := range unknown {
+			fmt.Fprintf(&b, "- %s\n", contractID)
+		}
+	}
+	return b.String()
+}
+
+// formatDays renders a duration as whole days, or hours below one day
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%d hours", int64(d/time.Hour))
+	}
+	return fmt.Sprintf("%d days", int64(d/(24*time.Hour)))
+}
+
+// backfillFundingBlockTimes looks up the confirmation time of funded
+// contracts that have none recorded, skipping any the node cannot answer for
+func backfillFundingBlockTimes() {
+	contractIDs, err := contract.ListContracts()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+
+	var rpcClient *rpc.RPCClient
+	for _, contractID := range contractIDs {
+		contractInfo, err := contract.LoadContractInfo(contractID)
+		if err != nil || !contractInfo.IsFunded || contractInfo.FundingBlockTime != 0 {
+			continue
+		}
+
+		if rpcClient == nil {
+			rpcClient = rpc.NewRPCClient(&cfg.RPCConfig)
+		}
+		fundingTx, err := rpcClient.GetRawTransactionCtx(ctx, contractInfo.FundingTxID)
+		if err != nil || fundingTx.BlockHash == "" {
+			continue
+		}
+		if err := recordFundingBlockTime(ctx, rpcClient, contractID, fundingTx.BlockHash); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// recordFundingBlockTime stores the time of the block confirming a contract's funding
+func recordFundingBlockTime(ctx context.Context, rpcClient *rpc.RPCClient, contractID, blockHash string) error {
+	header, err := rpcClient.GetBlockHeaderCtx(ctx, blockHash)
+	if err != nil {
+		return fmt.Errorf("failed to fetch funding block: %w", err)
+	}
+	if err := contract.UpdateFundingBlockTime(contractID, header.Time); err != nil {
+		return fmt.Errorf("failed to record funding time: %w", err)
+	}
+	return nil
+}
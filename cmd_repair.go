@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+)
+
+var repairFromBundle string
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Find unreadable contract files and restore or quarantine them",
+	Long: `Load every file in the contracts directory and report those that fail to load,
+e.g. files truncated by a crash or a full disk. For each one, offer to restore
+it from a bundle created by export-all (--from-bundle) or, when the bundle does
+not hold it, to move it into the quarantine subdirectory. Broken files are
+never deleted: a restored contract's broken file is quarantined first.
+With --yes every repair is applied without asking.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return repairContracts()
+	},
+}
+
+func init() {
+	repairCmd.Flags().StringVar(&repairFromBundle, "from-bundle", "", "Bundle file (from export-all) to restore unreadable contracts from")
+	rootCmd.AddCommand(repairCmd)
+}
+
+func repairContracts() error {
+	log.Printf("=== Checking Contract Files ===")
+
+	invalid, err := contract.CheckContracts()
+	if err != nil {
+		return fmt.Errorf("failed to check contracts: %w", err)
+	}
+	if len(invalid) == 0 {
+		log.Printf("All contract files load correctly.")
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var repaired int
+	for _, broken := range invalid {
+		log.Printf("%s: %v", broken.ContractID, broken.Err)
+
+		var backup *contract.ContractInfo
+		if repairFromBundle != "" {
+			backup, err = contract.BundleContract(repairFromBundle, broken.ContractID)
+			if err != nil {
+				return fmt.Errorf("failed to read bundle: %w", err)
+			}
+			if backup == nil {
+				log.Printf("   Not in bundle %s", repairFromBundle)
+			}
+		}
+
+		action := "Quarantine"
+		if backup != nil {
+			action = "Restore from bundle"
+		}
+		if !confirm(reader, fmt.Sprintf("   %s %s?", action, broken.ContractID)) {
+			log.Printf("   Skipped")
+			continue
+		}
+
+		quarantined, err := contract.QuarantineContract(broken.ContractID)
+		if err != nil {
+			return err
+		}
+		log.Printf("   Moved broken file to %s", quarantined)
+
+		if backup != nil {
+			if err := contract.SaveContractInfo(backup); err != nil {
+				return fmt.Errorf("failed to restore contract %s: %w", broken.ContractID, err)
+			}
+			log.Printf("   Restored from %s", repairFromBundle)
+			if backup.OwnerWIF == "" && backup.InheritorWIF == "" && !backup.IsEncrypted() && !backup.IsWatchOnly {
+				log.Printf("   Note: the bundle was redacted; the restored contract has no private keys")
+			}
+			if backup.IsFunded {
+				log.Printf("   Note: funding and withdrawals are as of the bundle; check them with show")
+			}
+		}
+		repaired++
+	}
+
+	log.Printf("")
+	log.Printf("Repaired %d of %d unreadable contract files", repaired, len(invalid))
+	return nil
+}
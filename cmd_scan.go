@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan <contract-id>",
+	Short: "Find the unspent outputs of a contract address and record them as its funding",
+	Long: `Look up every unspent output paying the contract address through the chain
+backend (the Electrum server or Esplora API selected by BACKEND, otherwise the
+node's wallet, which must watch the address) and record all of them as the contract's
+funding UTXOs, replacing the recorded ones. Withdrawals then sweep them all.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return scanContract(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+}
+
+func scanContract(contractID string) error {
+	log.Printf("=== Scan Contract Address ===")
+
+	utxos, err := contract.FindContractUTXOs(contractID, newChainBackend(), cfg.ChainParams)
+	if err != nil {
+		return err
+	}
+	if len(utxos) == 0 {
+		log.Printf("No unspent outputs pay the contract address; recorded funding left unchanged")
+		return nil
+	}
+
+	outpoints := make([]contract.FundingOutpoint, len(utxos))
+	for i, utxo := range utxos {
+		outpoints[i] = contract.FundingOutpoint{TxID: utxo.TxHash.String(), Vout: utxo.Vout, Amount: int64(utxo.Amount)}
+		log.Printf("  %s:%d (%d satoshis)", utxo.TxHash, utxo.Vout, int64(utxo.Amount))
+	}
+
+	if err := contract.SetFundingUTXOs(contractID, outpoints); err != nil {
+		return fmt.Errorf("failed to update funding status: %w", err)
+	}
+
+	log.Printf("Funding recorded: %d UTXOs, %d satoshis in total", len(utxos), int64(transaction.TotalAmount(utxos)))
+	session.record("fundings recorded")
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/spf13/cobra"
+)
+
+var scriptHashCmd = &cobra.Command{
+	Use:   "script-hash [contract-id]",
+	Short: "Print the contract's redeem script hash in every common form",
+	Long: `Print the redeem script hash as SHA256 (the P2WSH witness program), as
+HASH160 (for P2SH wrapping, of the script itself and of its P2SH-P2WSH witness
+program) and as the byte-reversed Electrum scripthash of the P2WSH output.
+Use these to compare the contract against Electrum/ElectrumX servers and block
+explorers that key scripts by different hash forms.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contractID, err := resolveContractID(args)
+		if err != nil {
+			return err
+		}
+		return showScriptHashes(contractID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scriptHashCmd)
+}
+
+func showScriptHashes(contractID string) error {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+
+	hashes, err := script.ComputeScriptHashes(redeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to hash redeem script: %w", err)
+	}
+
+	log.Printf("=== Script Hashes: %s ===", contractInfo.ContractID)
+	log.Printf("Redeem script: %s", contractInfo.RedeemScript)
+	log.Printf("")
+	log.Printf("SHA256 (P2WSH witness program):      %x", hashes.WitnessScriptHash)
+	log.Printf("HASH160 (P2SH of the redeem script): %x", hashes.RedeemScriptHash160)
+	log.Printf("HASH160 (P2SH-P2WSH):                %x", hashes.NestedScriptHash160)
+	log.Printf("Electrum scripthash (P2WSH output):  %x", hashes.ElectrumScriptHash)
+
+	if contractInfo.ScriptHash != "" && contractInfo.ScriptHash != hex.EncodeToString(hashes.WitnessScriptHash) {
+		log.Printf("")
+		log.Printf("Warning: the stored script hash %s does not match the redeem script; run verify", contractInfo.ScriptHash)
+	}
+	return nil
+}
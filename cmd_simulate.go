@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/spf13/cobra"
+)
+
+// timelineWidth is the number of characters used for the locked period in the timeline
+const timelineWidth = 40
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate [contract-id]",
+	Short: "Simulate the inheritance timeline of a contract",
+	Long: `Print a narrative timeline of a contract: when it was funded, the period in
+which only the owner can spend, and when the inheritor gains access.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contractID, err := resolveContractID(args)
+		if err != nil {
+			return err
+		}
+		return simulateContract(contractID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+}
+
+func simulateContract(contractID string) error {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+
+	// Derive the lock duration enforced by consensus (the low 16 bits of the
+	// BIP 68 value, in 512-second units or blocks) rather than trusting the
+	// stored days
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	relativeTimelock, err := script.ParseRelativeTimelock(redeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to read timelock from redeem script (simulate supports relative timelocks only): %w", err)
+	}
+	lockDuration := script.RelativeTimelockDuration(relativeTimelock)
+	if script.IsBlockBasedTimelock(relativeTimelock) {
+		log.Printf("Note: the timelock counts %d blocks; days are estimated at %s per block", relativeTimelock&0xffff, script.BlockInterval)
+	}
+	lockDays := int64(lockDuration.Hours() / 24)
+
+	// Day 0 is the funding confirmation time when it is known
+	var start time.Time
+	if contractInfo.IsFunded {
+		ctx, cancel := rpcContext()
+		defer cancel()
+		fundingTx, err := rpc.NewRPCClient(&cfg.RPCConfig).GetRawTransactionCtx(ctx, contractInfo.FundingTxID)
+		if err != nil {
+			log.Printf("Warning: Could not fetch funding transaction: %v", err)
+		} else if fundingTx.BlockTime > 0 {
+			start = time.Unix(fundingTx.BlockTime, 0)
+		}
+	}
+
+	log.Printf("=== Inheritance Timeline: %s ===", contractInfo.ContractID)
+	log.Printf("")
+
+	dayLabel := func(day int64) string {
+		if start.IsZero() {
+			return fmt.Sprintf("Day %d", day)
+		}
+		return fmt.Sprintf("Day %d (%s)", day, start.Add(time.Duration(day)*24*time.Hour).Format("2006-01-02"))
+	}
+
+	if contractInfo.IsFunded {
+		log.Printf("%s: funded with %d satoshis", dayLabel(0), contractInfo.FundingAmount)
+	} else {
+		log.Printf("%s: funding confirms (not funded yet - the clock starts then)", dayLabel(0))
+	}
+	log.Printf("Day 0-%d: only the owner can spend", lockDays)
+	if start.IsZero() {
+		log.Printf("Day %d onward: the inheritor can spend as well", lockDays)
+	} else {
+		log.Printf("Day %d onward (%s): the inheritor can spend as well",
+			lockDays, start.Add(lockDuration).Format("2006-01-02 15:04 MST"))
+	}
+	log.Printf("")
+
+	// Render the timeline, marking today when the funding date is known
+	locked := []rune(strings.Repeat("=", timelineWidth))
+	if !start.IsZero() {
+		elapsed := time.Since(start)
+		if elapsed >= 0 && elapsed < lockDuration {
+			pos := int(float64(timelineWidth) * float64(elapsed) / float64(lockDuration))
+			locked[pos] = '*'
+		}
+	}
+
+	log.Printf("%-*s%s", timelineWidth+1, dayLabel(0), dayLabel(lockDays))
+	log.Printf("|%s|------------->", string(locked))
+	log.Printf(" %-*s%s", timelineWidth+1, "owner only", "owner or inheritor")
+	if !start.IsZero() {
+		if time.Since(start) >= lockDuration {
+			log.Printf("Today: the timelock has expired - the inheritor can spend now")
+		} else {
+			log.Printf("Today (*): %d days until the inheritor can spend",
+				int64((lockDuration-time.Since(start)).Hours()/24)+1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [contract-id]",
+	Short: "Check a contract's funding and timelock on chain",
+	Long: `Look up the unspent outputs paying the contract address through the chain
+backend (the Electrum server or Esplora API selected by BACKEND, otherwise the
+node's wallet, which must watch the address) and report the funded amount, its
+confirmations and how many blocks remain until the inheritor's timelock
+matures. A contract whose recorded funding has no unspent output left is
+reported as spent.
+
+The contract file is updated to match the chain: the outputs found become its
+funding UTXOs, and a spent contract is marked as no longer funded. The result
+is one of unfunded, funded, matured or spent.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contractID, err := resolveContractID(args)
+		if err != nil {
+			return err
+		}
+		return contractStatus(contractID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
+
+func contractStatus(contractID string) error {
+	log.Printf("=== Contract Status: %s ===", contractID)
+
+	status, err := contract.CheckChainStatus(contractID, newChainBackend(), cfg.ChainParams, time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Chain tip: block %d", status.TipHeight)
+	for _, output := range status.Outputs {
+		log.Printf("  %s:%d (%d satoshis, %d confirmations)", output.TxID, output.Vout, output.Amount, output.Confirmations)
+	}
+
+	switch status.State {
+	case contract.StateUnfunded:
+		log.Printf("Not funded: no unspent output pays the contract address")
+	case contract.StateSpent:
+		log.Printf("Spent: the recorded funding has no unspent output left")
+	case contract.StateMatured:
+		log.Printf("Funded with %v; the inheritor's timelock has matured and the inheritor can spend", btcutil.Amount(status.Amount))
+	default:
+		if status.Confirmations == 0 {
+			log.Printf("Funded with %v, unconfirmed; the inheritor's timelock starts once it confirms (%d blocks)",
+				btcutil.Amount(status.Amount), status.BlocksRemaining)
+		} else {
+			log.Printf("Funded with %v, %d confirmations; %d blocks until the inheritor's timelock matures",
+				btcutil.Amount(status.Amount), status.Confirmations, status.BlocksRemaining)
+		}
+	}
+
+	printResult("%s", status.State)
+	return emitJSON(status)
+}
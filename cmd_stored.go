@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+// storedAlternative selects which fee alternative of a stored withdrawal to broadcast
+var storedAlternative int
+
+var broadcastStoredCmd = &cobra.Command{
+	Use:   "broadcast-stored <file>",
+	Short: "Broadcast a withdrawal saved with inheritor-withdraw --save",
+	Long: `Broadcast a pre-signed withdrawal once it can confirm. The funding UTXO must
+still be unspent and the timelock must have matured.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return broadcastStored(args[0])
+	},
+}
+
+func init() {
+	broadcastStoredCmd.Flags().IntVar(&storedAlternative, "alternative", 1, "Fee alternative to broadcast (1 = cheapest)")
+	rootCmd.AddCommand(broadcastStoredCmd)
+}
+
+// storeInheritorWithdrawal saves signed inheritor withdrawals to
+// storeWithdrawalPath. relativeTimelock is the one the withdrawals were built
+// with, which for a tiered contract is the withdrawing tier's.
+func storeInheritorWithdrawal(
+	contractInfo *contract.ContractInfo,
+	parsedScript *script.InheritanceScript,
+	relativeTimelock int64,
+	contractUTXO *transaction.UTXO,
+	txs []*wire.MsgTx,
+) error {
+	stored := &contract.StoredWithdrawal{
+		ContractID:       contractInfo.ContractID,
+		Path:             "inheritor",
+		FundingTxID:      contractInfo.FundingTxID,
+		FundingVout:      contractInfo.FundingVout,
+		FundingAmount:    contractInfo.FundingAmount,
+		RelativeTimelock: relativeTimelock,
+		LockTime:         parsedScript.LockTime,
+		CreatedAt:        time.Now(),
+	}
+
+	for _, tx := range txs {
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			return fmt.Errorf("failed to serialize transaction: %w", err)
+		}
+		stored.Transactions = append(stored.Transactions, contract.StoredTransaction{
+			TxID: tx.TxHash().String(),
+			Hex:  hex.EncodeToString(buf.Bytes()),
+			Fee:  int64(contractUTXO.Amount - totalOutput(tx)),
+		})
+	}
+
+	if err := contract.SaveStoredWithdrawal(storeWithdrawalPath, stored); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Signed withdrawal saved to %s (%d alternatives)", storeWithdrawalPath, len(txs))
+	log.Printf("Broadcast it once the timelock matures with: broadcast-stored %s", storeWithdrawalPath)
+	session.record("withdrawals stored")
+	return nil
+}
+
+func broadcastStored(path string) error {
+	stored, err := contract.LoadStoredWithdrawal(path)
+	if err != nil {
+		return err
+	}
+	if storedAlternative < 1 || storedAlternative > len(stored.Transactions) {
+		return fmt.Errorf("alternative must be between 1 and %d", len(stored.Transactions))
+	}
+	selected := stored.Transactions[storedAlternative-1]
+
+	log.Printf("=== Broadcasting Stored Withdrawal ===")
+	log.Printf("Contract: %s (%s path)", stored.ContractID, stored.Path)
+	log.Printf("Funding UTXO: %s:%d", stored.FundingTxID, stored.FundingVout)
+	log.Printf("Transaction: %s (fee %d satoshis)", selected.TxID, selected.Fee)
+
+	rawTx, err := hex.DecodeString(selected.Hex)
+	if err != nil {
+		return fmt.Errorf("invalid transaction hex: %w", err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		return fmt.Errorf("failed to decode transaction: %w", err)
+	}
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+
+	// The owner may have spent the contract since the withdrawal was signed
+	if err := checkFundingUnspent(ctx, newChainBackend(), stored.FundingTxID, stored.FundingVout); err != nil {
+		return fmt.Errorf("%w; the stored transaction can no longer confirm", err)
+	}
+
+	if stored.Path == "inheritor" {
+		var timelockErr error
+		if stored.LockTime != 0 {
+			timelockErr = checkLockTimeExpired(ctx, stored.LockTime)
+		} else {
+			timelockErr = checkTimelockExpired(ctx, stored.FundingTxID, stored.RelativeTimelock)
+		}
+		if timelockErr != nil {
+			return timelockErr
+		}
+	}
+
+	txid, err := broadcastTransaction(ctx, &tx)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	log.Printf("✅ Transaction broadcast successfully!")
+	log.Printf("Transaction ID: %s", txid)
+
+	recordWithdrawal(stored.ContractID, txid, stored.Path, btcutil.Amount(tx.TxOut[0].Value), btcutil.Amount(selected.Fee))
+	return nil
+}
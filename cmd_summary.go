@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// showSummary enables the end-of-command session summary
+var showSummary bool
+
+// session accumulates the operations performed during this invocation
+var session = newSessionSummary()
+
+// sessionSummary counts operations and the funds they moved, in memory only
+type sessionSummary struct {
+	operations map[string]int
+	satsMoved  btcutil.Amount
+	feesPaid   btcutil.Amount
+}
+
+func newSessionSummary() *sessionSummary {
+	return &sessionSummary{operations: make(map[string]int)}
+}
+
+// record counts one operation that did not move funds
+func (s *sessionSummary) record(operation string) {
+	s.operations[operation]++
+}
+
+// recordTransfer counts one broadcast transaction with the amount it sent and the fee it paid
+func (s *sessionSummary) recordTransfer(operation string, moved, fee btcutil.Amount) {
+	s.operations[operation]++
+	s.satsMoved += moved
+	s.feesPaid += fee
+}
+
+// print logs the summary of the session
+func (s *sessionSummary) print() {
+	log.Printf("")
+	log.Printf("=== Session Summary ===")
+	if len(s.operations) == 0 {
+		log.Printf("No operations performed")
+		return
+	}
+
+	names := make([]string, 0, len(s.operations))
+	for name := range s.operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		log.Printf("%s: %d", name, s.operations[name])
+	}
+	log.Printf("Total moved: %d satoshis", int64(s.satsMoved))
+	log.Printf("Total fees paid: %d satoshis", int64(s.feesPaid))
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+var sweepCmd = &cobra.Command{
+	Use:   "sweep <contract-id> <contract-id>...",
+	Short: "Withdraw several matured contracts to one address in a single transaction",
+	Long: `Spend the matured funding UTXOs of several contracts through the inheritor
+path in one transaction paying everything, minus a single fee, to one
+destination address. Each input is signed with the inheritor key of its own
+contract and carries its own contract's timelock as its sequence, so contracts
+with different timelocks can be swept together.
+
+UTXOs whose timelock has not matured yet are left in their contract and
+reported; the command fails if nothing is left to sweep.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sweepContracts(args)
+	},
+}
+
+func init() {
+	sweepCmd.Flags().Int64Var(&withdrawFee, "fee", 0, "Flat fee in satoshis (default: DEFAULT_FEE_SATOSHIS)")
+	sweepCmd.Flags().BoolVar(&allowHighFee, "allow-high-fee-rate", false, "Skip the MAX_FEE_RATE check on the effective fee rate")
+	sweepCmd.Flags().BoolVar(&grindLowR, "low-r", true, "Grind signatures for a low R value (71-byte signatures, as modern wallets do); --low-r=false disables it")
+	sweepCmd.Flags().StringVar(&withdrawSigHash, "sighash", "all", "Signature hash type: all, single, all|anyonecanpay or single|anyonecanpay")
+	sweepCmd.Flags().StringVar(&destinationAddr, "to", "", "Destination address (default: prompt for it)")
+	rootCmd.AddCommand(sweepCmd)
+}
+
+// sweepContract is a contract taking part in a sweep with its matured inputs
+type sweepContract struct {
+	id     string
+	inputs []*transaction.SweepInput
+}
+
+func sweepContracts(contractIDs []string) error {
+	log.Printf("=== Sweep %d Contracts ===", len(contractIDs))
+
+	// The contracts are checked before the destination prompt, so the
+	// checks get their own deadline
+	checkCtx, cancelCheck := rpcContext()
+	defer cancelCheck()
+
+	seen := make(map[string]bool, len(contractIDs))
+	var swept []sweepContract
+	var inputs []*transaction.SweepInput
+	for _, contractID := range contractIDs {
+		if seen[contractID] {
+			return fmt.Errorf("contract %s is given more than once", contractID)
+		}
+		seen[contractID] = true
+
+		contractInputs, err := maturedSweepInputs(checkCtx, contractID)
+		if err != nil {
+			return fmt.Errorf("contract %s: %w", contractID, err)
+		}
+		if len(contractInputs) == 0 {
+			continue
+		}
+		swept = append(swept, sweepContract{id: contractID, inputs: contractInputs})
+		inputs = append(inputs, contractInputs...)
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("none of the contracts has a matured UTXO to sweep")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	destAddr, err := readDestination(reader)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := rpcContext()
+	defer cancel()
+
+	log.Printf("Building sweep transaction with %d inputs from %d contracts...", len(inputs), len(swept))
+	tx, txBuilder, err := buildSweep(inputs, destAddr)
+	if err != nil {
+		return fmt.Errorf("failed to build transaction: %w", err)
+	}
+	if err := txBuilder.SignSweepTx(tx, inputs); err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := txBuilder.ValidateSweepTx(tx, inputs); err != nil {
+		return fmt.Errorf("transaction validation failed: %w", err)
+	}
+	inputAmount := sweepAmount(inputs)
+	if err := checkMaxFeeRate(tx, inputAmount); err != nil {
+		return err
+	}
+
+	txHex, err := txBuilder.SerializeTransaction(tx)
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	log.Printf("Transaction built successfully!")
+	log.Printf("Transaction hex: %s", txHex)
+
+	if err := checkMempoolAccept(ctx, tx); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(swept))
+	for i, c := range swept {
+		ids[i] = c.id
+	}
+	fee := inputAmount - totalOutput(tx)
+	if !confirm(reader, "Do you want to broadcast this transaction?") {
+		log.Printf("Transaction not broadcast (user cancelled)")
+		return emitWithdrawal(strings.Join(ids, ","), "inheritor", tx, fee, "")
+	}
+
+	broadcastCtx, cancelBroadcast := rpcContext()
+	defer cancelBroadcast()
+	txid, err := broadcastTransaction(broadcastCtx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+	log.Printf("✅ Transaction broadcast successfully!")
+	log.Printf("Transaction ID: %s", txid)
+
+	// Each contract is charged the share of the fee its inputs make up
+	remainingFee := fee
+	for i, c := range swept {
+		amount := sweepAmount(c.inputs)
+		share := fee * amount / inputAmount
+		if i == len(swept)-1 {
+			share = remainingFee
+		}
+		remainingFee -= share
+		recordWithdrawal(c.id, txid, "inheritor", amount-share, share)
+	}
+
+	log.Printf("Sweep completed!")
+	return emitWithdrawal(strings.Join(ids, ","), "inheritor", tx, fee, txid)
+}
+
+// maturedSweepInputs returns the funding UTXOs of a contract whose timelock
+// has matured as sweep inputs signed by the contract's inheritor key. UTXOs
+// that have not matured are reported and left out.
+func maturedSweepInputs(ctx context.Context, contractID string) ([]*transaction.SweepInput, error) {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contract: %w", err)
+	}
+	if !contractInfo.IsFunded {
+		return nil, fmt.Errorf("contract is not funded yet")
+	}
+	if contractInfo.InheritorWIF == "" {
+		return nil, fmt.Errorf("contract has no inheritor private key; only the inheritor can sign for it")
+	}
+	log.Printf("Contract %s: %s", contractID, contractInfo.P2WSHAddress)
+	logFundingUTXOs(contractInfo)
+
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	parsedScript, err := script.ParseRedeemScript(redeemScript, cfg.ChainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timelock from redeem script: %w", err)
+	}
+	inheritorKeys, err := keys.KeyPairFromWIF(contractInfo.InheritorWIF, cfg.ChainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inheritor keys: %w", err)
+	}
+
+	timelock := parsedScript.InheritorTiers()[0].RelativeTimelock
+	if parsedScript.TimelockType == script.Absolute {
+		timelock = parsedScript.LockTime
+		if err := checkLockTimeExpired(ctx, timelock); err != nil {
+			log.Printf("Skipping contract %s: %v", contractID, err)
+			return nil, nil
+		}
+	}
+
+	utxos, err := fundingUTXOs(ctx, contractInfo)
+	if err != nil {
+		return nil, err
+	}
+	backend := newChainBackend()
+	var inputs []*transaction.SweepInput
+	for i, outpoint := range contractInfo.FundingUTXOs {
+		if err := checkFundingUnspent(ctx, backend, outpoint.TxID, outpoint.Vout); err != nil {
+			return nil, err
+		}
+		if parsedScript.TimelockType != script.Absolute {
+			if err := checkTimelockExpired(ctx, outpoint.TxID, timelock); err != nil {
+				log.Printf("Skipping %s:%d: %v", outpoint.TxID, outpoint.Vout, err)
+				continue
+			}
+		}
+		inputs = append(inputs, &transaction.SweepInput{
+			UTXO:         utxos[i],
+			RedeemScript: redeemScript,
+			Timelock:     timelock,
+			Signer:       inheritorKeys.Signer(),
+		})
+	}
+	return inputs, nil
+}
+
+// buildSweep builds a sweep paying the effective fee, measuring the size
+// first for a fee rate as buildWithFee does
+func buildSweep(inputs []*transaction.SweepInput, destAddr btcutil.Address) (*wire.MsgTx, *transaction.TransactionBuilder, error) {
+	feeChoice, err := resolveWithdrawFee()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fee := feeChoice.Fee
+	if feeChoice.Source.IsRate() {
+		template, err := transaction.NewTransactionBuilder(cfg.ChainParams, 0).BuildSweepTx(inputs, destAddr)
+		if err != nil {
+			return nil, nil, err
+		}
+		fee = transaction.SweepFeeForRate(template, inputs, feeChoice.FeeRate)
+		log.Printf("Fee: %d satoshis (%.2f sat/vB)", int64(fee), feeChoice.FeeRate)
+	}
+
+	txBuilder, err := newSigningBuilder(fee)
+	if err != nil {
+		return nil, nil, err
+	}
+	logSuggestedFeeRate(txBuilder, feeChoice.Source)
+
+	tx, err := txBuilder.BuildSweepTx(inputs, destAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, txBuilder, nil
+}
+
+// sweepAmount returns the total amount of sweep inputs
+func sweepAmount(inputs []*transaction.SweepInput) btcutil.Amount {
+	var total btcutil.Amount
+	for _, input := range inputs {
+		total += input.UTXO.Amount
+	}
+	return total
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+var (
+	generateTiers []string
+	withdrawTier  int
+)
+
+func init() {
+	generateCmd.Flags().StringArrayVar(&generateTiers, "add-tier", nil, "Add an inheritor tier after the first inheritor as <pubkey>:<days>, with a longer timelock than the tier before (repeatable)")
+	inheritorWithdrawCmd.Flags().IntVar(&withdrawTier, "tier", 1, "Inheritor tier to withdraw as (1 = first inheritor); tiers after the first are asked for their private key")
+}
+
+// contractTiers returns the inheritor tiers of a contract generated with
+// --add-tier: the first inheritor with --timelock-days, then each --add-tier
+func contractTiers(inheritorPubKey []byte) ([]script.InheritorTier, error) {
+	tiers := []script.InheritorTier{{PubKey: inheritorPubKey, TimelockDays: cfg.Contract.TimelockDays}}
+	for _, value := range generateTiers {
+		tier, err := parseTierArg(value)
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+	return tiers, nil
+}
+
+// parseTierArg parses an --add-tier value of the form <pubkey>:<days>, where
+// the public key is hex or a signed public key file from prove-key
+func parseTierArg(value string) (script.InheritorTier, error) {
+	sep := strings.LastIndex(value, ":")
+	if sep < 0 {
+		return script.InheritorTier{}, fmt.Errorf("--add-tier %q is not of the form <pubkey>:<days>", value)
+	}
+	days, err := strconv.ParseInt(value[sep+1:], 10, 64)
+	if err != nil || days <= 0 {
+		return script.InheritorTier{}, fmt.Errorf("--add-tier %q has an invalid number of days", value)
+	}
+	pubKey, err := loadInheritorPubKey(value[:sep])
+	if err != nil {
+		return script.InheritorTier{}, fmt.Errorf("--add-tier %q: %w", value, err)
+	}
+	return script.InheritorTier{PubKey: pubKey, TimelockDays: days}, nil
+}
+
+// tierInfos returns the tiers of a tiered script as stored in the contract
+// file, or nil for a single inheritor
+func tierInfos(inheritanceScript *script.InheritanceScript) []contract.TierInfo {
+	var infos []contract.TierInfo
+	for _, tier := range inheritanceScript.Tiers {
+		infos = append(infos, contract.TierInfo{PubKey: hex.EncodeToString(tier.PubKey), TimelockDays: tier.TimelockDays})
+	}
+	return infos
+}
+
+// readTierKey asks for the private key of an inheritor tier after the first,
+// whose key the contract file never holds
+func readTierKey(reader *bufio.Reader, tier script.InheritorTier, number int) (*keys.KeyPair, error) {
+	promptf("Enter the tier %d inheritor's private key (WIF): ", number)
+	wif, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	tierKeys, err := keys.KeyPairFromWIF(strings.TrimSpace(wif), cfg.ChainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tier %d inheritor keys: %w", number, err)
+	}
+	if !bytes.Equal(tierKeys.GetCompressedPubKeyBytes(), tier.PubKey) {
+		return nil, fmt.Errorf("private key does not match the tier %d inheritor key %x", number, tier.PubKey)
+	}
+	return tierKeys, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use [contract-id]",
+	Short: "Set the active contract",
+	Long: `Record a contract as the active default. show, simulate, watch and the
+withdraw commands use it when no contract ID is given. Without an ID the
+current active contract is printed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			active, err := contract.ActiveContract()
+			if err != nil {
+				return err
+			}
+			if active == "" {
+				log.Printf("No active contract set")
+			} else {
+				log.Printf("Active contract: %s", active)
+			}
+			return nil
+		}
+
+		if err := contract.SetActiveContract(args[0]); err != nil {
+			return err
+		}
+		log.Printf("Active contract set to %s", args[0])
+		return nil
+	},
+}
+
+var unsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Clear the active contract",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := contract.ClearActiveContract(); err != nil {
+			return err
+		}
+		log.Printf("Active contract cleared")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(useCmd)
+	rootCmd.AddCommand(unsetCmd)
+}
+
+// resolveContractID returns the contract ID given as the first argument, or
+// the active contract when none was given
+func resolveContractID(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	active, err := contract.ActiveContract()
+	if err != nil {
+		return "", err
+	}
+	if active == "" {
+		return "", fmt.Errorf("no contract ID given and no active contract set (see 'use')")
+	}
+
+	log.Printf("Using active contract %s", active)
+	return active, nil
+}
+
+// promptContractID asks for a contract ID, defaulting to the active contract
+// when the answer is empty. With --yes the active contract is used without
+// asking.
+func promptContractID(reader *bufio.Reader) (string, error) {
+	if assumeYes {
+		return resolveContractID(nil)
+	}
+
+	active, err := contract.ActiveContract()
+	if err != nil {
+		return "", err
+	}
+
+	if active != "" {
+		promptf("Enter contract ID [%s]: ", active)
+	} else {
+		promptf("Enter contract ID: ")
+	}
+
+	contractID, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read contract ID: %w", err)
+	}
+
+	contractID = strings.TrimSpace(contractID)
+	if contractID == "" {
+		if active == "" {
+			return "", fmt.Errorf("no contract ID given")
+		}
+		return active, nil
+	}
+	return contractID, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+func TestVerifyContract(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(t *testing.T, contractInfo *contract.ContractInfo)
+		wantErr string
+	}{
+		{"consistent", func(t *testing.T, contractInfo *contract.ContractInfo) {}, ""},
+		{"redeem script edited", func(t *testing.T, contractInfo *contract.ContractInfo) {
+			// Same keys with a shorter timelock: the script still parses,
+			// but no longer matches the stored hash, address and timelock
+			redeemScript, _ := hex.DecodeString(contractInfo.RedeemScript)
+			parsed, err := script.ParseRedeemScript(redeemScript, &chaincfg.TestNet3Params)
+			if err != nil {
+				t.Fatalf("ParseRedeemScript failed: %v", err)
+			}
+			edited, err := script.NewInheritanceScript(parsed.OwnerPubKey, parsed.InheritorPubKey, 30, &chaincfg.TestNet3Params)
+			if err != nil {
+				t.Fatalf("NewInheritanceScript failed: %v", err)
+			}
+			contractInfo.RedeemScript = hex.EncodeToString(edited.RedeemScript)
+		}, "failed 3 check(s)"},
+		{"redeem script corrupted", func(t *testing.T, contractInfo *contract.ContractInfo) {
+			contractInfo.RedeemScript = "51"
+		}, "failed 3 check(s)"},
+		{"script hash edited", func(t *testing.T, contractInfo *contract.ContractInfo) {
+			contractInfo.ScriptHash = strings.Repeat("00", 32)
+		}, "failed 1 check(s)"},
+		{"timelock days edited", func(t *testing.T, contractInfo *contract.ContractInfo) {
+			contractInfo.TimelockDays = 365
+		}, "failed 1 check(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractID, _ := setupNonInteractive(t)
+			// Any command loads the configuration verifyContract reads
+			if err := runCommand(t, "list"); err != nil {
+				t.Fatalf("Failed to load the configuration: %v", err)
+			}
+
+			contractInfo, err := contract.LoadContractInfo(contractID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			redeemScript, _ := hex.DecodeString(contractInfo.RedeemScript)
+			contractInfo.ScriptHash = hex.EncodeToString((&script.InheritanceScript{RedeemScript: redeemScript}).GetScriptHash())
+			tt.tamper(t, contractInfo)
+			if err := contract.SaveContractInfo(contractInfo); err != nil {
+				t.Fatalf("SaveContractInfo failed: %v", err)
+			}
+
+			err = verifyContract(contractID)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected the contract to pass, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "FAIL") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected a failure containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckStoredTimelock(t *testing.T) {
+	ownerPubKey, _ := hex.DecodeString("032e58d08ca45c7da87b2fc69c5b8a5e1a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e")
+	inheritorPubKey, _ := hex.DecodeString("02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5")
+	newScript := func(t *testing.T, days int64, opts ...script.Option) *script.InheritanceScript {
+		t.Helper()
+		built, err := script.NewInheritanceScript(ownerPubKey, inheritorPubKey, days, &chaincfg.TestNet3Params, opts...)
+		if err != nil {
+			t.Fatalf("NewInheritanceScript failed: %v", err)
+		}
+		return built
+	}
+	days := newScript(t, 180)
+	seconds := newScript(t, 0, script.WithTimelockSeconds(6000*512))
+	blocks := newScript(t, 0, script.WithTimelockBlocks(4320))
+	absolute := newScript(t, 0, script.WithAbsoluteTimelock(1893456000))
+
+	tests := []struct {
+		name    string
+		stored  contract.ContractInfo
+		parsed  *script.InheritanceScript
+		wantErr string
+	}{
+		{"days", contract.ContractInfo{TimelockDays: 180}, days, ""},
+		{"days differ", contract.ContractInfo{TimelockDays: 181}, days, "stored 181 days, script enforces 180 days"},
+		{"seconds", contract.ContractInfo{TimelockSeconds: 6000 * 512}, seconds, ""},
+		{"seconds differ", contract.ContractInfo{TimelockSeconds: 5999 * 512}, seconds, "script enforces 3072000 seconds"},
+		{"blocks", contract.ContractInfo{TimelockDays: 30, TimelockBlocks: 4320}, blocks, ""},
+		{"blocks stored as days", contract.ContractInfo{TimelockDays: 30}, blocks, "script enforces 4320 blocks (about 30 days)"},
+		{"days stored as blocks", contract.ContractInfo{TimelockBlocks: 4320}, days, "stored 4320 blocks, script enforces 180 days"},
+		{"absolute", contract.ContractInfo{LockTime: 1893456000}, absolute, ""},
+		{"absolute differs", contract.ContractInfo{LockTime: 1893456001}, absolute, "script enforces 1893456000"},
+		{"absolute stored for a relative script", contract.ContractInfo{LockTime: 1893456000}, days, "relative timelock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStoredTimelock(&tt.stored, tt.parsed)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected the timelocks to match, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
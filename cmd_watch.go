@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/notify"
+	"github.com/nikolay.stoev/bitcoin-inheritance/ratelimit"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+	"github.com/spf13/cobra"
+)
+
+// watchJitter extends each poll wait by up to this fraction of the interval
+const watchJitter = 0.2
+
+var watchInterval time.Duration
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [contract-id]",
+	Short: "Watch new blocks and the contract's timelock",
+	Long: `Poll the backend for new blocks and report the contract's funding and
+timelock status until interrupted. Polls are spaced at least
+WATCH_MIN_POLL_SECONDS apart with random jitter, and the poller backs off when
+the backend rate limits (HTTP 429 / Retry-After).
+
+When ZMQ_ENDPOINT is set, the node's rawtx and hashblock ZMQ notifications
+are used instead of polling, and a transaction paying the contract address is
+recorded as its funding as soon as the node sees it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contractID, err := resolveContractID(args)
+		if err != nil {
+			return err
+		}
+		return watchContract(contractID)
+	},
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", ratelimit.DefaultMinInterval, "Poll interval (raised to WATCH_MIN_POLL_SECONDS if lower)")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func watchContract(contractID string) error {
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+
+	if cfg.Watch.ZMQEndpoint != "" {
+		err := watchContractZMQ(ctx, rpcClient, contractInfo)
+		if err == nil || errors.Is(err, ratelimit.ErrStop) || ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("Warning: ZMQ notifications failed: %v", err)
+		log.Printf("Falling back to polling")
+	}
+
+	return watchContractPolling(ctx, rpcClient, contractID)
+}
+
+// watchContractPolling checks the contract each time a poll sees a new block
+func watchContractPolling(ctx context.Context, rpcClient *rpc.RPCClient, contractID string) error {
+	poller := ratelimit.NewPoller(watchInterval, cfg.Watch.MinPollInterval, watchJitter)
+	if poller.Interval() != watchInterval {
+		log.Printf("Poll interval raised to the minimum of %s", poller.Interval())
+	}
+	log.Printf("=== Watching %s every %s (Ctrl-C to stop) ===", contractID, poller.Interval())
+
+	lastHeight := int64(-1)
+
+	return poller.Run(ctx, func() error {
+		// Each poll gets its own deadline, so a hung node delays the
+		// next poll rather than ending the watch
+		pollCtx, cancel := withRPCDeadline(ctx)
+		defer cancel()
+
+		height, err := rpcClient.GetBlockCountCtx(pollCtx)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			return ratelimitOrNil(err)
+		}
+		if height == lastHeight {
+			return nil
+		}
+		lastHeight = height
+
+		return reportWatchStatus(pollCtx, rpcClient, contractID, height)
+	})
+}
+
+// watchContractZMQ records funding as soon as the node relays a transaction
+// paying the contract and checks the contract on every new block
+func watchContractZMQ(ctx context.Context, rpcClient *rpc.RPCClient, contractInfo *contract.ContractInfo) error {
+	contractID := contractInfo.ContractID
+
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	pkScript, err := contractScriptPubKey(redeemScript, contractInfo.AddressType)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("=== Watching %s via ZMQ at %s (Ctrl-C to stop) ===", contractID, cfg.Watch.ZMQEndpoint)
+
+	return notify.Subscribe(ctx, cfg.Watch.ZMQEndpoint, notify.Handlers{
+		Tx: func(tx *wire.MsgTx) error {
+			matches := notify.MatchOutputs(tx, pkScript)
+			if len(matches) == 0 {
+				return nil
+			}
+
+			// Reload so funding recorded elsewhere is not overwritten
+			current, err := contract.LoadContractInfo(contractID)
+			if err != nil {
+				return fmt.Errorf("failed to load contract: %w", err)
+			}
+			txid := tx.TxHash().String()
+			if current.IsFunded && current.FundingTxID == txid {
+				return nil
+			}
+			if current.IsFunded {
+				log.Printf("Note: Transaction %s also pays the contract; funding stays %s:%d (use set-funding to switch)",
+					txid, current.FundingTxID, current.FundingVout)
+				return nil
+			}
+			if len(matches) > 1 {
+				log.Printf("Note: Transaction %s pays the contract in %d outputs; recording output %d", txid, len(matches), matches[0])
+			}
+
+			vout := matches[0]
+			amount := tx.TxOut[vout].Value
+			if err := contract.UpdateFundingStatus(contractID, txid, vout, amount); err != nil {
+				return fmt.Errorf("failed to update funding status: %w", err)
+			}
+			log.Printf("Funding detected and recorded: %s:%d (%d satoshis)", txid, vout, amount)
+			session.record("fundings recorded")
+			return nil
+		},
+		Block: func(blockHash string) error {
+			blockCtx, cancel := withRPCDeadline(ctx)
+			defer cancel()
+
+			header, err := rpcClient.GetBlockHeaderCtx(blockCtx, blockHash)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+				return nil
+			}
+			return reportWatchStatus(blockCtx, rpcClient, contractID, header.Height)
+		},
+	})
+}
+
+// reportWatchStatus logs the contract's funding and timelock status at a
+// block height. It returns ratelimit.ErrStop once the contract is spent.
+func reportWatchStatus(ctx context.Context, rpcClient *rpc.RPCClient, contractID string, height int64) error {
+	// Reload each time so funding recorded elsewhere is picked up
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+	if !contractInfo.IsFunded {
+		log.Printf("Block %d: contract not funded yet", height)
+		return nil
+	}
+
+	if err := checkFundingUnspent(ctx, rpcClient, contractInfo.FundingTxID, contractInfo.FundingVout); err != nil {
+		log.Printf("Block %d: %v", height, err)
+		return ratelimit.ErrStop
+	}
+
+	remaining, err := watchTimelockRemaining(ctx, rpcClient, contractInfo)
+	if err != nil {
+		log.Printf("Block %d: could not check timelock: %v", height, err)
+		return ratelimitOrNil(err)
+	}
+	if remaining == 0 {
+		log.Printf("Block %d: timelock expired - the inheritor can spend", height)
+	} else {
+		log.Printf("Block %d: %s until the inheritor can spend", height, remaining.Round(time.Minute))
+	}
+	return nil
+}
+
+// ratelimitOrNil keeps rate limit errors so the poller backs off and drops
+// other transient errors so watching continues
+func ratelimitOrNil(err error) error {
+	var rateLimited *ratelimit.RateLimitError
+	if errors.As(err, &rateLimited) {
+		return err
+	}
+	return nil
+}
+
+// watchTimelockRemaining returns how long the contract's timelock still runs,
+// measured by median-time-past since the funding block, or for an absolute
+// timelock by lockTimeRemaining. The wait for a block-based timelock is
+// estimated at script.BlockInterval per block.
+func watchTimelockRemaining(ctx context.Context, rpcClient *rpc.RPCClient, contractInfo *contract.ContractInfo) (time.Duration, error) {
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	parsed, err := script.ParseRedeemScript(redeemScript, cfg.ChainParams)
+	if err != nil {
+		return 0, err
+	}
+	if parsed.TimelockType == script.Absolute {
+		return lockTimeRemaining(ctx, rpcClient, parsed.LockTime)
+	}
+
+	fundingTx, err := rpcClient.GetRawTransactionCtx(ctx, contractInfo.FundingTxID)
+	if err != nil {
+		return 0, err
+	}
+	if fundingTx.BlockHash == "" {
+		return 0, fmt.Errorf("funding transaction is not confirmed yet")
+	}
+
+	if script.IsBlockBasedTimelock(parsed.RelativeTimelock) {
+		fundingHeight, tipHeight, err := fetchHeights(ctx, rpcClient, fundingTx)
+		if err != nil {
+			return 0, err
+		}
+		blocks, err := transaction.BlocksRemaining(parsed.RelativeTimelock, fundingHeight, tipHeight)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(blocks) * script.BlockInterval, nil
+	}
+
+	fundingParentMTP, tipMTP, err := fetchMedianTimes(ctx, rpcClient, fundingTx)
+	if err != nil {
+		return 0, err
+	}
+
+	return transaction.TimelockRemaining(parsed.RelativeTimelock, fundingParentMTP, tipMTP)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/hdkeys"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateWatchOnly bool
+	ownerKeyArg       string
+)
+
+var generateWatchOnlyCmd = &cobra.Command{
+	Use:   "generate-watchonly",
+	Short: "Generate a contract from public keys alone, storing no private key",
+	Long: `Generate an inheritance contract from the owner's and inheritor's public keys.
+Each key is a hex public key or an account extended public key (xpub/tpub) of
+the path m/1017'/coin'/index', whose /0 child is used for the owner and /1
+child for the inheritor. No private key is generated or stored; owner-withdraw
+and inheritor-withdraw write an unsigned PSBT to be signed where the keys are
+kept (sign-psbt), which import-psbt then broadcasts.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		generateWatchOnly = true
+		return generateContract()
+	},
+}
+
+func init() {
+	generateWatchOnlyCmd.Flags().StringVar(&ownerKeyArg, "owner-key", "", "Owner's public key: hex, or an account xpub/tpub")
+	generateWatchOnlyCmd.Flags().StringVar(&inheritorPubKeyArg, "inheritor-key", "", "Inheritor's public key: hex, a signed public key file from prove-key, or an account xpub/tpub")
+	generateWatchOnlyCmd.Flags().BoolVar(&allowShortTimelock, "allow-short-timelock", false, "Allow a timelock below the configured minimum (MIN_TIMELOCK_DAYS)")
+	generateWatchOnlyCmd.Flags().BoolVar(&allowSameKey, "allow-same-key", false, "Allow the inheritor public key to equal the owner's")
+	generateWatchOnlyCmd.Flags().Int64Var(&generateTimelockSeconds, "timelock-seconds", 0, "Timelock duration in seconds, rounded up to 512-second intervals (instead of --timelock-days)")
+	generateWatchOnlyCmd.Flags().Int64Var(&generateTimelockBlocks, "timelock-blocks", 0, "Timelock in blocks confirmed after funding, at most 65535 (instead of --timelock-days)")
+	generateWatchOnlyCmd.Flags().StringVar(&generateUnlockDate, "unlock-date", "", "Let the inheritor spend from this date on (YYYY-MM-DD or RFC 3339, UTC), regardless of when the contract is funded")
+	generateWatchOnlyCmd.MarkFlagRequired("owner-key")
+	generateWatchOnlyCmd.MarkFlagRequired("inheritor-key")
+	rootCmd.AddCommand(generateWatchOnlyCmd)
+}
+
+// watchOnlyKeys returns the owner's and inheritor's public keys given to
+// generate-watchonly
+func watchOnlyKeys() ([]byte, []byte, error) {
+	ownerPubKey, err := watchOnlyPubKey(ownerKeyArg, hdkeys.RoleOwner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --owner-key: %w", err)
+	}
+
+	var inheritorPubKey []byte
+	if hdkeys.IsExtendedPubKey(inheritorPubKeyArg) {
+		inheritorPubKey, err = watchOnlyPubKey(inheritorPubKeyArg, hdkeys.RoleInheritor)
+	} else {
+		inheritorPubKey, err = loadInheritorPubKey(inheritorPubKeyArg)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --inheritor-key: %w", err)
+	}
+	return ownerPubKey, inheritorPubKey, nil
+}
+
+// watchOnlyPubKey decodes a hex public key, or derives the role child of an
+// account extended public key
+func watchOnlyPubKey(value string, role uint32) ([]byte, error) {
+	if hdkeys.IsExtendedPubKey(value) {
+		pubKey, err := hdkeys.ChildPubKey(value, role, cfg.ChainParams)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Derived public key %x from %s/%d", pubKey, value, role)
+		return pubKey, nil
+	}
+
+	pubKey, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("neither a hex public key nor an xpub: %w", err)
+	}
+	return pubKey, nil
+}
+
+// exportWatchOnlyWithdrawal writes the withdrawal of a watch-only contract as
+// an unsigned PSBT, as there is no key here to sign it with
+func exportWatchOnlyWithdrawal(reader *bufio.Reader, contractID, path string) error {
+	log.Printf("Contract %s is watch-only; writing an unsigned PSBT instead of signing", contractID)
+	if withdrawAmount > 0 {
+		return fmt.Errorf("--amount is not supported for watch-only contracts; the PSBT sweeps the funding UTXO")
+	}
+
+	psbtPath = path
+	return exportPSBT(reader, contractID)
+}
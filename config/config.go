@@ -1,9 +1,15 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/joho/godotenv"
@@ -19,15 +25,61 @@ type Config struct {
 
 	// Contract settings
 	Contract ContractConfig
+
+	// Fee estimation settings
+	Fees FeeConfig
+
+	// Watch poller settings
+	Watch WatchConfig
+
+	// Electrum server settings
+	Electrum ElectrumConfig
+
+	// Esplora REST API settings
+	Esplora EsploraConfig
+
+	// Backend is the chain backend for UTXO lookups and broadcasts:
+	// BackendNode, BackendElectrum or BackendEsplora
+	Backend string
 }
 
+// Chain backends selectable with BACKEND
+const (
+	BackendNode     = "node"
+	BackendElectrum = "electrum"
+	BackendEsplora  = "esplora"
+)
+
 // RPCConfig holds RPC connection settings
 type RPCConfig struct {
+	// Host is the primary endpoint; Hosts lists every endpoint in failover
+	// order, starting with Host. An empty Hosts means Host alone.
 	Host         string
+	Hosts        []string
 	User         string
 	Pass         string
 	HTTPPostMode bool
 	DisableTLS   bool
+
+	// CACertPath is a PEM file with the certificate authority, or the node's
+	// self-signed certificate (btcd's rpc.cert), trusted for TLS. Empty uses
+	// the system roots.
+	CACertPath string
+
+	// CookieFile is the node's .cookie file. When set, its
+	// __cookie__:password line is read on every request instead of using
+	// User and Pass, so a node restart with a new cookie is picked up.
+	CookieFile string
+
+	// Network is the chaincfg name of the network transactions are built for.
+	// Broadcasting refuses a node on a different chain; empty skips the check.
+	Network string
+
+	// Debug logs which endpoint served each request
+	Debug bool
+
+	// Timeout bounds each request; zero means rpc.DefaultTimeout
+	Timeout time.Duration
 }
 
 // ContractConfig holds inheritance contract specific settings
@@ -37,22 +89,84 @@ type ContractConfig struct {
 
 	// Default transaction fee in satoshis
 	DefaultFee int64
+
+	// Minimum timelock in days accepted without --allow-short-timelock
+	MinTimelockDays int64
+
+	// Passphrase encrypts and decrypts contract keys; when empty it is
+	// asked for on the terminal as needed
+	Passphrase string
 }
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() *Config {
-	// Load .env file - exit if not found
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf(".env file not found: %v", err)
-	}
+// FeeConfig holds fee estimation settings
+type FeeConfig struct {
+	// Estimator selects the fee oracle: "node", "mempool" or "static"
+	Estimator string
+
+	// MempoolAPIURL is the mempool.space API base URL
+	MempoolAPIURL string
+
+	// StaticFeeRate is the fee rate in sat/vB used by the static estimator
+	StaticFeeRate float64
+
+	// MaxFeeRate is the highest effective fee rate in sat/vB a withdrawal
+	// may pay; zero disables the check
+	MaxFeeRate float64
+
+	// DefaultFeeRate is the withdrawal fee rate in sat/vB used when neither
+	// --fee nor --fee-rate is given; zero falls back to the flat DefaultFee
+	DefaultFeeRate float64
+}
 
-	network := getEnvString("BITCOIN_NETWORK", "testnet")
+// WatchConfig holds settings for commands polling the backend
+type WatchConfig struct {
+	// MinPollInterval is the shortest allowed interval between polls
+	MinPollInterval time.Duration
+
+	// ZMQEndpoint is the node's ZMQ publisher (e.g. tcp://127.0.0.1:28332);
+	// when empty, watching falls back to polling
+	ZMQEndpoint string
+}
+
+// ElectrumConfig selects an Electrum server for UTXO lookups and broadcasts
+type ElectrumConfig struct {
+	// Server is host:port, or ssl://host:port for TLS; when empty the node's
+	// RPC interface is used
+	Server string
+}
+
+// EsploraConfig selects an Esplora REST API for UTXO lookups and broadcasts
+type EsploraConfig struct {
+	// URL is the API base URL, such as https://blockstream.info/testnet/api
+	URL string
+}
 
-	var cfg *Config
-	if network == "mainnet" {
-		cfg = createMainnetConfig()
-	} else {
-		cfg = createTestnetConfig()
+// LoadConfig loads configuration from environment variables and the .env file
+func LoadConfig() (*Config, error) {
+	return LoadConfigFile("")
+}
+
+// LoadConfigFile loads configuration from environment variables, the YAML or
+// TOML config file at path when it is not empty, and the .env file, which
+// each only fill in variables not set by the sources before them. The .env
+// file is optional as long as the required variables are set elsewhere.
+func LoadConfigFile(path string) (*Config, error) {
+	if path != "" {
+		if err := loadConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	settings, err := lookupNetwork(getEnvString("BITCOIN_NETWORK", "testnet"))
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := createNetworkConfig(settings)
+	if err != nil {
+		return nil, err
 	}
 
 	// Override with environment variables if present
@@ -63,43 +177,241 @@ func LoadConfig() *Config {
 		cfg.Contract.DefaultFee = defaultFee
 	}
 
+	hosts, err := NormalizeRPCHosts(cfg.RPCConfig.Host, defaultRPCPort(cfg.ChainParams))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RPC host: %w", err)
+	}
+	cfg.RPCConfig.Host = hosts[0]
+	cfg.RPCConfig.Hosts = hosts
+
+	backend, esploraURL, err := SelectBackend(getEnvString("BACKEND", ""), cfg.Electrum.Server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BACKEND: %w", err)
+	}
+	cfg.Backend = backend
+	if esploraURL != "" {
+		cfg.Esplora.URL = esploraURL
+	}
+
+	return cfg, nil
+}
+
+// MustLoadConfig is LoadConfig, panicking if the configuration is invalid
+func MustLoadConfig() *Config {
+	cfg, err := LoadConfig()
+	if err != nil {
+		panic(err)
+	}
 	return cfg
 }
 
-// createTestnetConfig creates a testnet configuration from environment variables
-func createTestnetConfig() *Config {
-	return &Config{
-		ChainParams: &chaincfg.TestNet3Params,
-		RPCConfig: RPCConfig{
-			Host:         getRequiredEnvString("TESTNET_RPC_HOST"),
-			User:         getRequiredEnvString("TESTNET_RPC_USER"),
-			Pass:         getRequiredEnvString("TESTNET_RPC_PASS"),
-			HTTPPostMode: getEnvBool("TESTNET_RPC_HTTP_POST_MODE", true),
-			DisableTLS:   getEnvBool("TESTNET_RPC_DISABLE_TLS", false),
-		},
-		Contract: ContractConfig{
-			TimelockDays: getEnvInt64("TIMELOCK_DAYS", 180),
-			DefaultFee:   getEnvInt64("DEFAULT_FEE_SATOSHIS", 2000),
-		},
+// SelectBackend resolves the BACKEND setting to a chain backend. It takes a
+// backend name, or an http:// or https:// URL, which selects the Esplora API
+// at that URL (returned as esploraURL). Empty selects the Electrum server
+// when one is configured, otherwise the node.
+func SelectBackend(value, electrumServer string) (backend, esploraURL string, err error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "":
+		if electrumServer != "" {
+			return BackendElectrum, "", nil
+		}
+		return BackendNode, "", nil
+	case BackendNode, BackendEsplora:
+		return value, "", nil
+	case BackendElectrum:
+		if electrumServer == "" {
+			return "", "", fmt.Errorf("the electrum backend needs ELECTRUM_SERVER")
+		}
+		return value, "", nil
 	}
+
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return BackendEsplora, strings.TrimSuffix(value, "/"), nil
+	}
+	return "", "", fmt.Errorf("unknown backend %q (expected node, electrum, esplora or an Esplora URL)", value)
 }
 
-// createMainnetConfig creates a mainnet configuration from environment variables
-func createMainnetConfig() *Config {
+// NormalizeRPCHosts splits a comma-separated list of RPC hosts and
+// normalizes each with NormalizeRPCHost, keeping their order
+func NormalizeRPCHosts(value, defaultPort string) ([]string, error) {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		normalized, err := NormalizeRPCHost(host, defaultPort)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, normalized)
+	}
+	return hosts, nil
+}
+
+// NormalizeRPCHost validates an RPC host and returns it in host:port form. A
+// leading http:// or https:// scheme is stripped and defaultPort is used when
+// no port is given. unix:// socket URLs are returned unchanged.
+func NormalizeRPCHost(host, defaultPort string) (string, error) {
+	host = strings.TrimSpace(host)
+	if strings.HasPrefix(host, "unix://") {
+		if strings.TrimPrefix(host, "unix://") == "" {
+			return "", fmt.Errorf("unix socket URL %q has no path", host)
+		}
+		return host, nil
+	}
+
+	original := host
+	if scheme, rest, ok := strings.Cut(host, "://"); ok {
+		if scheme != "http" && scheme != "https" {
+			return "", fmt.Errorf("unsupported scheme %q in %q", scheme, original)
+		}
+		host = rest
+	}
+	host = strings.TrimSuffix(host, "/")
+
+	if strings.Contains(host, "/") {
+		return "", fmt.Errorf("RPC host %q must not contain a path", original)
+	}
+	if host == "" {
+		return "", fmt.Errorf("RPC host is empty")
+	}
+
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		// No port given: add the default, bracketing IPv6 literals as needed
+		hostname = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		port = defaultPort
+	}
+	if hostname == "" {
+		return "", fmt.Errorf("RPC host %q has no hostname", original)
+	}
+	if strings.ContainsAny(hostname, "[] ") {
+		return "", fmt.Errorf("RPC host %q is malformed", original)
+	}
+
+	portNum, err := strconv.Atoi(port)
+	if err != nil || portNum < 1 || portNum > 65535 {
+		return "", fmt.Errorf("RPC host %q has an invalid port %q", original, port)
+	}
+
+	return net.JoinHostPort(hostname, port), nil
+}
+
+// network describes a value of BITCOIN_NETWORK: its chain parameters, the
+// prefix of its RPC settings (TESTNET_RPC_HOST, ...) and its defaults
+type network struct {
+	chainParams   *chaincfg.Params
+	envPrefix     string
+	rpcPort       string
+	mempoolAPIURL string
+	esploraURL    string
+}
+
+// networks maps the accepted values of BITCOIN_NETWORK to their settings.
+// Regtest has no public APIs, so its mempool and Esplora URLs must be set
+// explicitly when used.
+var networks = map[string]network{
+	"testnet": {
+		chainParams:   &chaincfg.TestNet3Params,
+		envPrefix:     "TESTNET",
+		rpcPort:       "18334",
+		mempoolAPIURL: "https://mempool.space/testnet/api",
+		esploraURL:    "https://blockstream.info/testnet/api",
+	},
+	"mainnet": {
+		chainParams:   &chaincfg.MainNetParams,
+		envPrefix:     "MAINNET",
+		rpcPort:       "8334",
+		mempoolAPIURL: "https://mempool.space/api",
+		esploraURL:    "https://blockstream.info/api",
+	},
+	"signet": {
+		chainParams:   &chaincfg.SigNetParams,
+		envPrefix:     "SIGNET",
+		rpcPort:       "38332",
+		mempoolAPIURL: "https://mempool.space/signet/api",
+		esploraURL:    "https://mempool.space/signet/api",
+	},
+	"regtest": {
+		chainParams: &chaincfg.RegressionNetParams,
+		envPrefix:   "REGTEST",
+		rpcPort:     "18334",
+	},
+}
+
+// lookupNetwork returns the settings of a BITCOIN_NETWORK value
+func lookupNetwork(name string) (network, error) {
+	settings, ok := networks[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return network{}, fmt.Errorf("unknown BITCOIN_NETWORK %q (expected testnet, mainnet, signet or regtest)", name)
+	}
+	return settings, nil
+}
+
+// defaultRPCPort returns the default btcd RPC port for a network
+func defaultRPCPort(chainParams *chaincfg.Params) string {
+	for _, settings := range networks {
+		if settings.chainParams.Name == chainParams.Name {
+			return settings.rpcPort
+		}
+	}
+	return "18334"
+}
+
+// createNetworkConfig creates the configuration of a network from environment
+// variables, reading its RPC settings from the network's prefixed variables
+func createNetworkConfig(settings network) (*Config, error) {
+	env := func(key string) string { return settings.envPrefix + "_RPC_" + key }
+
+	cookieFile := getEnvString(env("COOKIE_FILE"), "")
+	host, err := getRequiredEnvString(env("HOST"))
+	if err != nil {
+		return nil, err
+	}
+	user, err := getRPCCredential(env("USER"), cookieFile)
+	if err != nil {
+		return nil, err
+	}
+	pass, err := getRPCCredential(env("PASS"), cookieFile)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
-		ChainParams: &chaincfg.MainNetParams,
+		ChainParams: settings.chainParams,
 		RPCConfig: RPCConfig{
-			Host:         getRequiredEnvString("MAINNET_RPC_HOST"),
-			User:         getRequiredEnvString("MAINNET_RPC_USER"),
-			Pass:         getRequiredEnvString("MAINNET_RPC_PASS"),
-			HTTPPostMode: getEnvBool("MAINNET_RPC_HTTP_POST_MODE", true),
-			DisableTLS:   getEnvBool("MAINNET_RPC_DISABLE_TLS", false),
+			Host:         host,
+			User:         user,
+			Pass:         pass,
+			HTTPPostMode: getEnvBool(env("HTTP_POST_MODE"), true),
+			DisableTLS:   getEnvBool(env("DISABLE_TLS"), false),
+			CACertPath:   getEnvString(env("CA_CERT"), ""),
+			CookieFile:   cookieFile,
+			Debug:        getEnvBool("RPC_DEBUG", false),
+			Network:      settings.chainParams.Name,
 		},
 		Contract: ContractConfig{
-			TimelockDays: getEnvInt64("TIMELOCK_DAYS", 180),
-			DefaultFee:   getEnvInt64("DEFAULT_FEE_SATOSHIS", 2000),
+			TimelockDays:    getEnvInt64("TIMELOCK_DAYS", 180),
+			DefaultFee:      getEnvInt64("DEFAULT_FEE_SATOSHIS", 2000),
+			MinTimelockDays: getEnvInt64("MIN_TIMELOCK_DAYS", 30),
+			Passphrase:      getEnvString("CONTRACT_PASSPHRASE", ""),
 		},
-	}
+		Fees: FeeConfig{
+			Estimator:      getEnvString("FEE_ESTIMATOR", "node"),
+			MempoolAPIURL:  getEnvString("MEMPOOL_API_URL", settings.mempoolAPIURL),
+			StaticFeeRate:  getEnvFloat64("STATIC_FEE_RATE", 2),
+			MaxFeeRate:     getEnvFloat64("MAX_FEE_RATE", 1000),
+			DefaultFeeRate: getEnvFloat64("DEFAULT_FEE_RATE", 0),
+		},
+		Watch: WatchConfig{
+			MinPollInterval: time.Duration(getEnvInt64("WATCH_MIN_POLL_SECONDS", 60)) * time.Second,
+			ZMQEndpoint:     getEnvString("ZMQ_ENDPOINT", ""),
+		},
+		Electrum: ElectrumConfig{
+			Server: getEnvString("ELECTRUM_SERVER", ""),
+		},
+		Esplora: EsploraConfig{
+			URL: getEnvString("ESPLORA_URL", settings.esploraURL),
+		},
+	}, nil
 }
 
 // Helper functions for environment variable parsing
@@ -110,12 +422,21 @@ func getEnvString(key, defaultValue string) string {
 	return defaultValue
 }
 
-func getRequiredEnvString(key string) string {
+func getRequiredEnvString(key string) (string, error) {
 	value := os.Getenv(key)
 	if value == "" {
-		log.Fatalf("Required environment variable %s is not set", key)
+		return "", fmt.Errorf("required environment variable %s is not set", key)
+	}
+	return value, nil
+}
+
+// getRPCCredential reads an RPC user or password, which is only required
+// when no cookie file provides them
+func getRPCCredential(key, cookieFile string) (string, error) {
+	if cookieFile != "" {
+		return getEnvString(key, ""), nil
 	}
-	return value
+	return getRequiredEnvString(key)
 }
 
 func getEnvInt64(key string, defaultValue int64) int64 {
@@ -128,6 +449,16 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getEnvFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		log.Printf("Invalid float value for %s: %s, using default: %g", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestNormalizeRPCHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{"host and port", "localhost:18334", "localhost:18334"},
+		{"missing port", "localhost", "localhost:18334"},
+		{"http scheme", "http://localhost:18334", "localhost:18334"},
+		{"https scheme and trailing slash", "https://node.example.com:8334/", "node.example.com:8334"},
+		{"scheme without port", "http://127.0.0.1", "127.0.0.1:18334"},
+		{"surrounding whitespace", "  localhost:18334 ", "localhost:18334"},
+		{"IPv6 with port", "[::1]:8334", "[::1]:8334"},
+		{"IPv6 without port", "[::1]", "[::1]:18334"},
+		{"unix socket", "unix:///var/run/bitcoind.sock", "unix:///var/run/bitcoind.sock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRPCHost(tt.host, "18334")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeRPCHost_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+	}{
+		{"empty", ""},
+		{"scheme only", "http://"},
+		{"unsupported scheme", "ftp://localhost:18334"},
+		{"path", "http://localhost:18334/wallet/main"},
+		{"non-numeric port", "localhost:abc"},
+		{"port out of range", "localhost:70000"},
+		{"missing hostname", ":18334"},
+		{"empty unix socket", "unix://"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := NormalizeRPCHost(tt.host, "18334"); err == nil {
+				t.Errorf("Expected error for %q, got %q", tt.host, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeRPCHosts(t *testing.T) {
+	hosts, err := NormalizeRPCHosts("primary.example, http://backup.example:18000 ,unix:///run/node.sock", "18334")
+	if err != nil {
+		t.Fatalf("NormalizeRPCHosts failed: %v", err)
+	}
+	expected := []string{"primary.example:18334", "backup.example:18000", "unix:///run/node.sock"}
+	if len(hosts) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, hosts)
+	}
+	for i := range expected {
+		if hosts[i] != expected[i] {
+			t.Errorf("Host %d: expected %q, got %q", i, expected[i], hosts[i])
+		}
+	}
+
+	// One bad entry, including an empty one, rejects the list
+	for _, value := range []string{"primary.example,", "primary.example,localhost:abc"} {
+		if got, err := NormalizeRPCHosts(value, "18334"); err == nil {
+			t.Errorf("Expected error for %q, got %v", value, got)
+		}
+	}
+}
+
+func TestSelectBackend(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		electrumServer string
+		backend        string
+		esploraURL     string
+		wantErr        bool
+	}{
+		{"default node", "", "", BackendNode, "", false},
+		{"default electrum", "", "electrum.example:50001", BackendElectrum, "", false},
+		{"node over electrum", "node", "electrum.example:50001", BackendNode, "", false},
+		{"esplora by name", "esplora", "", BackendEsplora, "", false},
+		{"esplora by URL", "https://blockstream.info/testnet/api/", "", BackendEsplora, "https://blockstream.info/testnet/api", false},
+		{"plain http URL", "http://localhost:3000", "", BackendEsplora, "http://localhost:3000", false},
+		{"electrum without server", "electrum", "", "", "", true},
+		{"unknown", "bitcoind", "", "", "", true},
+		{"unsupported scheme", "ftp://example.com", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, esploraURL, err := SelectBackend(tt.value, tt.electrumServer)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %q", tt.value, backend)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if backend != tt.backend || esploraURL != tt.esploraURL {
+				t.Errorf("Expected %q %q, got %q %q", tt.backend, tt.esploraURL, backend, esploraURL)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"missing RPC host", map[string]string{
+			"TESTNET_RPC_USER": "alice", "TESTNET_RPC_PASS": "secret",
+		}, "TESTNET_RPC_HOST is not set"},
+		{"missing RPC user", map[string]string{
+			"TESTNET_RPC_HOST": "localhost", "TESTNET_RPC_PASS": "secret",
+		}, "TESTNET_RPC_USER is not set"},
+		{"missing mainnet RPC password", map[string]string{
+			"BITCOIN_NETWORK": "mainnet", "MAINNET_RPC_HOST": "localhost", "MAINNET_RPC_USER": "alice",
+		}, "MAINNET_RPC_PASS is not set"},
+		{"malformed RPC host", map[string]string{
+			"TESTNET_RPC_HOST": "ftp://localhost", "TESTNET_RPC_USER": "alice", "TESTNET_RPC_PASS": "secret",
+		}, "invalid RPC host"},
+		{"unknown backend", map[string]string{
+			"TESTNET_RPC_HOST": "localhost", "TESTNET_RPC_USER": "alice", "TESTNET_RPC_PASS": "secret", "BACKEND": "carrier-pigeon",
+		}, "invalid BACKEND"},
+		{"unknown network", map[string]string{
+			"BITCOIN_NETWORK": "testnet5", "TESTNET_RPC_HOST": "localhost", "TESTNET_RPC_USER": "alice", "TESTNET_RPC_PASS": "secret",
+		}, "unknown BITCOIN_NETWORK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfigDir(t)
+			for _, key := range []string{"MAINNET_RPC_HOST", "MAINNET_RPC_USER", "MAINNET_RPC_PASS", "MAINNET_RPC_COOKIE_FILE"} {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			// A missing value is an error, not a process exit
+			cfg, err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if cfg != nil {
+				t.Error("Expected no configuration with the error")
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Networks(t *testing.T) {
+	tests := []struct {
+		network     string
+		envPrefix   string
+		chainParams *chaincfg.Params
+		host        string
+		hrp         string
+	}{
+		{"testnet", "TESTNET", &chaincfg.TestNet3Params, "localhost:18334", "tb1"},
+		{"mainnet", "MAINNET", &chaincfg.MainNetParams, "localhost:8334", "bc1"},
+		{"signet", "SIGNET", &chaincfg.SigNetParams, "localhost:38332", "tb1"},
+		{"regtest", "REGTEST", &chaincfg.RegressionNetParams, "localhost:18334", "bcrt1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			useConfigDir(t)
+			t.Setenv("BITCOIN_NETWORK", tt.network)
+			t.Setenv(tt.envPrefix+"_RPC_HOST", "localhost")
+			t.Setenv(tt.envPrefix+"_RPC_USER", "alice")
+			t.Setenv(tt.envPrefix+"_RPC_PASS", "secret")
+
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+			if cfg.ChainParams != tt.chainParams {
+				t.Errorf("Expected chain params %s, got %s", tt.chainParams.Name, cfg.ChainParams.Name)
+			}
+			if cfg.RPCConfig.Network != tt.chainParams.Name {
+				t.Errorf("Expected RPC network %s, got %s", tt.chainParams.Name, cfg.RPCConfig.Network)
+			}
+			if cfg.RPCConfig.Host != tt.host || cfg.RPCConfig.User != "alice" {
+				t.Errorf("Unexpected RPC settings %+v", cfg.RPCConfig)
+			}
+
+			addr, err := btcutil.NewAddressWitnessScriptHash(make([]byte, 32), cfg.ChainParams)
+			if err != nil {
+				t.Fatalf("NewAddressWitnessScriptHash failed: %v", err)
+			}
+			if !strings.HasPrefix(addr.EncodeAddress(), tt.hrp) {
+				t.Errorf("Expected an address starting with %s, got %s", tt.hrp, addr.EncodeAddress())
+			}
+		})
+	}
+}
+
+func TestLoadConfig_CookieFileMakesCredentialsOptional(t *testing.T) {
+	useConfigDir(t)
+	t.Setenv("TESTNET_RPC_HOST", "localhost")
+	t.Setenv("TESTNET_RPC_COOKIE_FILE", "/var/lib/bitcoind/testnet3/.cookie")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.RPCConfig.Host != "localhost:18334" || cfg.RPCConfig.User != "" {
+		t.Errorf("Unexpected RPC settings %+v", cfg.RPCConfig)
+	}
+}
+
+func TestLoadConfig_MalformedEnvFile(t *testing.T) {
+	dir := useConfigDir(t)
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("TESTNET_RPC_HOST='unterminated\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), ".env") {
+		t.Errorf("Expected an error about .env, got %v", err)
+	}
+}
+
+func TestMustLoadConfig_Panics(t *testing.T) {
+	useConfigDir(t)
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected MustLoadConfig to panic without TESTNET_RPC_HOST")
+		}
+	}()
+	MustLoadConfig()
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile reads a YAML (.yaml, .yml) or TOML (.toml) config file and
+// sets the environment variables it holds that are not set yet, so the
+// environment takes precedence. The file uses the names of the environment
+// variables, in any case, and nested sections are joined with underscores:
+//
+//	bitcoin_network: testnet
+//	testnet:
+//	  rpc_host: 127.0.0.1:18332
+//
+// sets BITCOIN_NETWORK and TESTNET_RPC_HOST. A list is joined with commas,
+// as TESTNET_RPC_HOST takes several endpoints.
+func loadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("unsupported config file %s (expected .yaml, .yml or .toml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	settings := make(map[string]string)
+	if err := flattenConfig("", values, settings); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if os.Getenv(key) != "" {
+			continue
+		}
+		if err := os.Setenv(key, settings[key]); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// flattenConfig adds the settings of a parsed config file section to
+// settings, named by their upper-cased path joined with underscores
+func flattenConfig(prefix string, value interface{}, settings map[string]string) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			name := strings.ToUpper(key)
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+			if err := flattenConfig(name, nested, settings); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return fmt.Errorf("%s: list items must be plain values", prefix)
+			}
+			items[i] = fmt.Sprint(item)
+		}
+		settings[prefix] = strings.Join(items, ",")
+		return nil
+	case nil:
+		return nil
+	default:
+		if prefix == "" {
+			return fmt.Errorf("expected a mapping of settings")
+		}
+		settings[prefix] = fmt.Sprint(v)
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// configKeys are the variables the test config files set
+var configKeys = []string{
+	"BITCOIN_NETWORK", "TESTNET_RPC_HOST", "TESTNET_RPC_USER", "TESTNET_RPC_PASS",
+	"TESTNET_RPC_DISABLE_TLS", "TESTNET_RPC_COOKIE_FILE", "TIMELOCK_DAYS",
+	"FEE_ESTIMATOR", "STATIC_FEE_RATE", "BACKEND", "ELECTRUM_SERVER",
+}
+
+// useConfigDir runs the test in an empty directory, so no .env is present,
+// with the variables of the test config files unset. t.Setenv restores them
+// afterwards, including those the config file sets.
+func useConfigDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, key := range configKeys {
+		t.Setenv(key, "")
+	}
+	return dir
+}
+
+const testYAML = `bitcoin_network: testnet
+testnet:
+  rpc_host:
+    - primary.example
+    - backup.example:18000
+  rpc_user: alice
+  rpc_pass: secret
+  rpc_disable_tls: true
+timelock_days: 365
+fee_estimator: static
+static_fee_rate: 2.5
+`
+
+const testTOML = `bitcoin_network = "testnet"
+timelock_days = 365
+fee_estimator = "static"
+static_fee_rate = 2.5
+
+[testnet]
+rpc_host = ["primary.example", "backup.example:18000"]
+rpc_user = "alice"
+rpc_pass = "secret"
+rpc_disable_tls = true
+`
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "config.yaml", testYAML},
+		{"yml", "config.yml", testYAML},
+		{"toml", "config.toml", testTOML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useConfigDir(t)
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := LoadConfigFile(path)
+			if err != nil {
+				t.Fatalf("LoadConfigFile failed: %v", err)
+			}
+
+			if cfg.ChainParams.Name != "testnet3" {
+				t.Errorf("Expected testnet3, got %s", cfg.ChainParams.Name)
+			}
+			if len(cfg.RPCConfig.Hosts) != 2 || cfg.RPCConfig.Hosts[0] != "primary.example:18334" || cfg.RPCConfig.Hosts[1] != "backup.example:18000" {
+				t.Errorf("Unexpected RPC hosts %v", cfg.RPCConfig.Hosts)
+			}
+			if cfg.RPCConfig.User != "alice" || cfg.RPCConfig.Pass != "secret" {
+				t.Errorf("Unexpected RPC credentials %q/%q", cfg.RPCConfig.User, cfg.RPCConfig.Pass)
+			}
+			if !cfg.RPCConfig.DisableTLS {
+				t.Error("Expected TLS to be disabled")
+			}
+			if cfg.Contract.TimelockDays != 365 {
+				t.Errorf("Expected 365 timelock days, got %d", cfg.Contract.TimelockDays)
+			}
+			if cfg.Fees.Estimator != "static" || cfg.Fees.StaticFeeRate != 2.5 {
+				t.Errorf("Expected the static estimator at 2.5 sat/vB, got %s at %g", cfg.Fees.Estimator, cfg.Fees.StaticFeeRate)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile_EnvironmentTakesPrecedence(t *testing.T) {
+	dir := useConfigDir(t)
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(testYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("TIMELOCK_DAYS", "90")
+	t.Setenv("TESTNET_RPC_USER", "bob")
+
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+
+	if cfg.Contract.TimelockDays != 90 {
+		t.Errorf("Expected TIMELOCK_DAYS from the environment (90), got %d", cfg.Contract.TimelockDays)
+	}
+	if cfg.RPCConfig.User != "bob" {
+		t.Errorf("Expected TESTNET_RPC_USER from the environment (bob), got %q", cfg.RPCConfig.User)
+	}
+	if cfg.RPCConfig.Pass != "secret" {
+		t.Errorf("Expected TESTNET_RPC_PASS from the file, got %q", cfg.RPCConfig.Pass)
+	}
+}
+
+func TestLoadConfigFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"unsupported extension", "config.ini", "TIMELOCK_DAYS=365\n"},
+		{"malformed yaml", "config.yaml", "testnet: [unclosed\n"},
+		{"malformed toml", "config.toml", "timelock_days = \n"},
+		{"not a mapping", "config.yaml", "- TIMELOCK_DAYS\n"},
+		{"nested list", "config.yaml", "testnet:\n  rpc_host:\n    - [a, b]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useConfigDir(t)
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			if _, err := LoadConfigFile(path); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if _, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,44 @@
+package contract
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// activeFile is the state file in ContractsDir naming the default contract
+const activeFile = "active"
+
+// SetActiveContract records contractID as the default for commands run without an ID
+func SetActiveContract(contractID string) error {
+	if _, err := loadContractFile(contractID); err != nil {
+		return fmt.Errorf("unknown contract %s: %w", contractID, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(ContractsDir, activeFile), []byte(contractID+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write active contract: %w", err)
+	}
+	return nil
+}
+
+// ActiveContract returns the default contract ID, or "" if none is set
+func ActiveContract() (string, error) {
+	data, err := os.ReadFile(filepath.Join(ContractsDir, activeFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read active contract: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ClearActiveContract removes the default contract
+func ClearActiveContract() error {
+	err := os.Remove(filepath.Join(ContractsDir, activeFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear active contract: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package contract
+
+import "testing"
+
+func TestActiveContract(t *testing.T) {
+	useTempContractsDir(t)
+	saveTestContract(t, "testnet_aaaaaaaa")
+
+	active, err := ActiveContract()
+	if err != nil || active != "" {
+		t.Fatalf("Expected no active contract, got %q (%v)", active, err)
+	}
+
+	if err := SetActiveContract("testnet_aaaaaaaa"); err != nil {
+		t.Fatalf("SetActiveContract failed: %v", err)
+	}
+	if active, _ := ActiveContract(); active != "testnet_aaaaaaaa" {
+		t.Errorf("Expected active contract testnet_aaaaaaaa, got %q", active)
+	}
+
+	// The state file is not mistaken for a contract
+	contractIDs, err := ListContracts()
+	if err != nil || len(contractIDs) != 1 {
+		t.Errorf("Expected 1 contract, got %v (%v)", contractIDs, err)
+	}
+
+	if err := ClearActiveContract(); err != nil {
+		t.Fatalf("ClearActiveContract failed: %v", err)
+	}
+	if active, _ := ActiveContract(); active != "" {
+		t.Errorf("Expected no active contract after clearing, got %q", active)
+	}
+
+	// Clearing twice is not an error
+	if err := ClearActiveContract(); err != nil {
+		t.Errorf("Second ClearActiveContract failed: %v", err)
+	}
+}
+
+func TestSetActiveContract_Unknown(t *testing.T) {
+	useTempContractsDir(t)
+	if err := SetActiveContract("testnet_missing"); err == nil {
+		t.Error("Expected error for unknown contract")
+	}
+}
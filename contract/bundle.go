@@ -0,0 +1,184 @@
+package contract
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BundleVersion is the current version of the bundle format
+const BundleVersion = 1
+
+// Bundle is a portable collection of contracts for bulk backup and transfer
+type Bundle struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Contracts  []*ContractInfo `json:"contracts"`
+}
+
+// ExportBundle writes every local contract into a single bundle file. Paths
+// ending in .gz are gzip-compressed. Encrypted keys stay encrypted in the
+// bundle; with redactKeys the WIF private keys are left out altogether.
+func ExportBundle(path string, redactKeys bool) (int, error) {
+	contractIDs, err := ListContracts()
+	if err != nil {
+		return 0, err
+	}
+
+	bundle := Bundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now(),
+	}
+
+	for _, contractID := range contractIDs {
+		contractInfo, err := loadContractFile(contractID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load contract %s: %w", contractID, err)
+		}
+
+		if redactKeys {
+			contractInfo.OwnerWIF = ""
+			contractInfo.InheritorWIF = ""
+			contractInfo.OwnerWIFEncrypted = ""
+			contractInfo.InheritorWIFEncrypted = ""
+		}
+
+		bundle.Contracts = append(bundle.Contracts, contractInfo)
+	}
+
+	if err := writeBundle(path, &bundle); err != nil {
+		return 0, err
+	}
+
+	return len(bundle.Contracts), nil
+}
+
+// writeBundle writes a bundle file, gzip-compressed for paths ending in .gz.
+// The file is readable by its owner only, as it may hold private keys. A
+// bundle that could not be written completely is removed, so a truncated
+// file is never left behind as a backup.
+func writeBundle(path string, bundle *Bundle) error {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle file: %w", err)
+	}
+	if err := writeBundleData(file, data, strings.HasSuffix(path, ".gz")); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// writeBundleData writes data to file, gzip-compressed when compress is set,
+// and closes file. Closing the gzip writer writes its footer and closing the
+// file flushes it, so both errors are returned rather than ignored.
+func writeBundleData(file io.WriteCloser, data []byte, compress bool) error {
+	var writer io.Writer = file
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(file)
+		writer = gzipWriter
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write bundle file: %w", err)
+	}
+	if gzipWriter != nil {
+		if err := gzipWriter.Close(); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to finish compressed bundle: %w", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close bundle file: %w", err)
+	}
+	return nil
+}
+
+// ImportBundle installs the contracts of a bundle file into the contracts
+// directory. Existing contract IDs are skipped unless overwrite is set.
+func ImportBundle(path string, overwrite bool) (imported, skipped int, err error) {
+	bundle, err := readBundle(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	existing, err := ListContracts()
+	if err != nil {
+		return 0, 0, err
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, contractID := range existing {
+		exists[contractID] = true
+	}
+
+	for _, contractInfo := range bundle.Contracts {
+		if err := validateContractID(contractInfo.ContractID); err != nil {
+			return imported, skipped, err
+		}
+
+		if exists[contractInfo.ContractID] && !overwrite {
+			skipped++
+			continue
+		}
+
+		if err := SaveContractInfo(contractInfo); err != nil {
+			return imported, skipped, fmt.Errorf("failed to import contract %s: %w", contractInfo.ContractID, err)
+		}
+		imported++
+	}
+
+	return imported, skipped, nil
+}
+
+// readBundle reads and parses a bundle file, decompressing .gz paths
+func readBundle(path string) (*Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle file: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress bundle: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	var bundle Bundle
+	if err := json.NewDecoder(reader).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	if bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d (newest supported is %d)", bundle.Version, BundleVersion)
+	}
+
+	return &bundle, nil
+}
+
+// validateContractID rejects IDs that cannot safely be used as a file name
+func validateContractID(contractID string) error {
+	if contractID == "" {
+		return fmt.Errorf("contract ID is empty")
+	}
+	if contractID != filepath.Base(contractID) || strings.HasPrefix(contractID, ".") {
+		return fmt.Errorf("invalid contract ID %q", contractID)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportImportBundle_RoundTrip(t *testing.T) {
+	for _, name := range []string{"bundle.json", "bundle.json.gz"} {
+		t.Run(name, func(t *testing.T) {
+			useTempContractsDir(t)
+			first := saveTestContract(t, "testnet_aaaaaaaa")
+			first.OwnerWIF = "owner-wif"
+			first.InheritorWIF = "inheritor-wif"
+			if err := SaveContractInfo(first); err != nil {
+				t.Fatalf("SaveContractInfo failed: %v", err)
+			}
+			saveTestContract(t, "testnet_bbbbbbbb")
+
+			bundlePath := filepath.Join(t.TempDir(), name)
+			count, err := ExportBundle(bundlePath, false)
+			if err != nil {
+				t.Fatalf("ExportBundle failed: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("Expected 2 exported contracts, got %d", count)
+			}
+
+			// Import into an empty contracts directory
+			ContractsDir = t.TempDir()
+			imported, skipped, err := ImportBundle(bundlePath, false)
+			if err != nil {
+				t.Fatalf("ImportBundle failed: %v", err)
+			}
+			if imported != 2 || skipped != 0 {
+				t.Errorf("Expected 2 imported and 0 skipped, got %d and %d", imported, skipped)
+			}
+
+			loaded, err := LoadContractInfo("testnet_aaaaaaaa")
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			if loaded.OwnerWIF != "owner-wif" || loaded.P2WSHAddress != first.P2WSHAddress {
+				t.Errorf("Imported contract does not match the exported one: %+v", loaded)
+			}
+		})
+	}
+}
+
+func TestImportBundle_Collisions(t *testing.T) {
+	useTempContractsDir(t)
+	saveTestContract(t, "testnet_aaaaaaaa")
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.json")
+	if _, err := ExportBundle(bundlePath, false); err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+
+	if err := UpdateLabel("testnet_aaaaaaaa", "local edit"); err != nil {
+		t.Fatalf("UpdateLabel failed: %v", err)
+	}
+
+	// Without overwrite the local copy is kept
+	imported, skipped, err := ImportBundle(bundlePath, false)
+	if err != nil {
+		t.Fatalf("ImportBundle failed: %v", err)
+	}
+	if imported != 0 || skipped != 1 {
+		t.Errorf("Expected 0 imported and 1 skipped, got %d and %d", imported, skipped)
+	}
+	loaded, _ := LoadContractInfo("testnet_aaaaaaaa")
+	if loaded.Label != "local edit" {
+		t.Error("Existing contract was overwritten without overwrite flag")
+	}
+
+	// With overwrite the bundle copy replaces it
+	if _, _, err := ImportBundle(bundlePath, true); err != nil {
+		t.Fatalf("ImportBundle failed: %v", err)
+	}
+	loaded, _ = LoadContractInfo("testnet_aaaaaaaa")
+	if loaded.Label != "" {
+		t.Error("Existing contract was not overwritten with overwrite flag")
+	}
+}
+
+func TestExportBundle_RedactKeys(t *testing.T) {
+	useTempContractsDir(t)
+	contractInfo := saveTestContract(t, "testnet_aaaaaaaa")
+	contractInfo.OwnerWIF = "owner-wif"
+	contractInfo.InheritorWIF = "inheritor-wif"
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.json")
+	if _, err := ExportBundle(bundlePath, true); err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+
+	ContractsDir = t.TempDir()
+	if _, _, err := ImportBundle(bundlePath, false); err != nil {
+		t.Fatalf("ImportBundle failed: %v", err)
+	}
+
+	loaded, err := LoadContractInfo("testnet_aaaaaaaa")
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if loaded.OwnerWIF != "" || loaded.InheritorWIF != "" {
+		t.Error("Redacted bundle still contains private keys")
+	}
+}
+
+// failingFile accepts up to limit bytes and fails every write after that
+type failingFile struct {
+	bytes.Buffer
+	limit  int
+	closed bool
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if f.Len()+len(p) > f.limit {
+		return 0, errors.New("no space left on device")
+	}
+	return f.Buffer.Write(p)
+}
+
+func (f *failingFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestWriteBundleData_WriteFails(t *testing.T) {
+	data := []byte(strings.Repeat(`{"contract_id":"testnet_aaaaaaaa"}`, 100))
+
+	tests := []struct {
+		name     string
+		compress bool
+		limit    int
+	}{
+		{"plain", false, len(data) / 2},
+		// The gzip writer buffers the data, so the failure only shows up
+		// when its footer is written on Close
+		{"compressed", true, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &failingFile{limit: tt.limit}
+			err := writeBundleData(file, data, tt.compress)
+			if err == nil || !strings.Contains(err.Error(), "no space left on device") {
+				t.Errorf("Expected the write failure to be returned, got %v", err)
+			}
+			if !file.closed {
+				t.Errorf("Expected the file to be closed after a failed write")
+			}
+		})
+	}
+}
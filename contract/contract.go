@@ -1,51 +1,163 @@
 package contract
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
 )
 
+// ContractsDir is the directory where contract files are stored
+var ContractsDir = "contracts"
+
+// lockTimeout bounds how long an update waits for another writer to finish
+var lockTimeout = 5 * time.Second
+
 // ContractInfo represents the saved contract information
 type ContractInfo struct {
 	// Contract metadata
 	ContractID   string    `json:"contract_id"`
+	Label        string    `json:"label,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	Network      string    `json:"network"`
 	TimelockDays int64     `json:"timelock_days"`
 
-	// Keys (WIF format for easy import)
-	OwnerWIF     string `json:"owner_wif"`
-	InheritorWIF string `json:"inheritor_wif"`
+	// TimelockSeconds is the effective timelock of contracts generated with
+	// --timelock-seconds; TimelockDays then holds its whole days
+	TimelockSeconds int64 `json:"timelock_seconds,omitempty"`
+
+	// TimelockBlocks is the block count of contracts generated with
+	// --timelock-blocks; TimelockDays then holds its estimated whole days
+	TimelockBlocks int64 `json:"timelock_blocks,omitempty"`
+
+	// LockTime is the absolute timelock of contracts generated with
+	// --unlock-date, as a Unix time; TimelockDays is then zero
+	LockTime int64 `json:"lock_time,omitempty"`
+
+	// Keys (WIF format for easy import). An encrypted contract stores them
+	// only in the *Encrypted fields (see EncryptWIF), and LoadContractInfo
+	// fills OwnerWIF and InheritorWIF in by decrypting them.
+	OwnerWIF              string `json:"owner_wif"`
+	InheritorWIF          string `json:"inheritor_wif"`
+	OwnerWIFEncrypted     string `json:"owner_wif_encrypted,omitempty"`
+	InheritorWIFEncrypted string `json:"inheritor_wif_encrypted,omitempty"`
+
+	// KeySource records where the keys of a contract generated with
+	// --from-seed ("seed") or --mnemonic ("bip39") were derived: at
+	// OwnerPath and InheritorPath, as contract KeyIndex of the master key
+	// with fingerprint KeyFingerprint. The seed itself is never stored.
+	KeySource      string `json:"key_source,omitempty"`
+	KeyFingerprint string `json:"key_fingerprint,omitempty"`
+	KeyIndex       uint32 `json:"key_index,omitempty"`
+	OwnerPath      string `json:"owner_path,omitempty"`
+	InheritorPath  string `json:"inheritor_path,omitempty"`
+
+	// InheritorTiers lists the inheritors of a contract generated with
+	// --add-tier in the order they may spend; the first is the inheritor of
+	// InheritorWIF and TimelockDays. It is empty for a single inheritor.
+	InheritorTiers []TierInfo `json:"inheritor_tiers,omitempty"`
+
+	// IsWatchOnly marks a contract generated from public keys alone. It never
+	// stores a private key; its withdrawals are exported as unsigned PSBTs.
+	IsWatchOnly bool `json:"is_watch_only,omitempty"`
 
 	// Script and address info
-	RedeemScript string `json:"redeem_script"` // hex encoded
-	P2WSHAddress string `json:"p2wsh_address"`
-	ScriptHash   string `json:"script_hash"` // hex encoded
+	AddressType  script.AddressType `json:"address_type,omitempty"`
+	RedeemScript string             `json:"redeem_script"` // hex encoded
+	P2WSHAddress string             `json:"p2wsh_address"`
+	ScriptHash   string             `json:"script_hash"` // hex encoded
+
+	// Funding status. FundingTxID, FundingAmount and FundingVout describe the
+	// first funding UTXO; FundingUTXOs lists all of them.
+	IsFunded      bool              `json:"is_funded"`
+	FundingTxID   string            `json:"funding_tx_id,omitempty"`
+	FundingAmount int64             `json:"funding_amount,omitempty"` // satoshis
+	FundingVout   uint32            `json:"funding_vout,omitempty"`
+	FundingUTXOs  []FundingOutpoint `json:"funding_utxos,omitempty"`
+
+	// FundingBlockTime is the time of the block confirming the funding UTXO
+	// (unix seconds), or zero while it is unknown
+	FundingBlockTime int64 `json:"funding_block_time,omitempty"`
+
+	// Withdrawals broadcast from this contract
+	Withdrawals []WithdrawalRecord `json:"withdrawals,omitempty"`
+
+	// passphrase encrypts the keys on save; nil stores them as they are
+	passphrase []byte
+}
+
+// Key sources of seed-derived contracts
+const (
+	KeySourceSeed  = "seed"
+	KeySourceBIP39 = "bip39"
+)
+
+// TierInfo is one inheritor of a tiered contract
+type TierInfo struct {
+	PubKey       string `json:"pubkey"` // hex encoded, compressed
+	TimelockDays int64  `json:"timelock_days"`
+}
+
+// FundingOutpoint is an output paying to the contract address
+type FundingOutpoint struct {
+	TxID   string `json:"txid"`
+	Vout   uint32 `json:"vout"`
+	Amount int64  `json:"amount"` // satoshis
+}
+
+// TotalFunding returns the sum of all funding UTXOs in satoshis
+func (c *ContractInfo) TotalFunding() int64 {
+	var total int64
+	for _, outpoint := range c.FundingUTXOs {
+		total += outpoint.Amount
+	}
+	return total
+}
 
-	// Funding status
-	IsFunded      bool   `json:"is_funded"`
-	FundingTxID   string `json:"funding_tx_id,omitempty"`
-	FundingAmount int64  `json:"funding_amount,omitempty"` // satoshis
-	FundingVout   uint32 `json:"funding_vout,omitempty"`
+// WithdrawalRecord records a broadcast withdrawal and the fee it was built with
+type WithdrawalRecord struct {
+	TxID        string    `json:"txid"`
+	Path        string    `json:"path"` // "owner", "inheritor" or "refresh"
+	Fee         int64     `json:"fee"`  // satoshis
+	BroadcastAt time.Time `json:"broadcast_at"`
 }
 
-// SaveContractInfo saves contract information to a JSON file
+// SaveContractInfo saves contract information to a JSON file. The keys of an
+// encrypted contract are written encrypted only.
 func SaveContractInfo(contractInfo *ContractInfo) error {
+	if err := contractInfo.checkWatchOnly(); err != nil {
+		return err
+	}
+	contractInfo, err := contractInfo.sealed()
+	if err != nil {
+		return err
+	}
+
+	// Never replace another contract's file; one that does not load is left
+	// to repair or an explicit restore
+	if existing, err := loadContractFile(contractInfo.ContractID); err == nil && existing.P2WSHAddress != contractInfo.P2WSHAddress {
+		return fmt.Errorf("contract file %s belongs to address %s, not %s; refusing to overwrite it",
+			contractInfo.ContractID, existing.P2WSHAddress, contractInfo.P2WSHAddress)
+	}
+
 	// Create contracts directory if it doesn't exist
-	contractsDir := "contracts"
-	if err := os.MkdirAll(contractsDir, 0755); err != nil {
+	if err := os.MkdirAll(ContractsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create contracts directory: %w", err)
 	}
 
 	// Generate filename based on contract ID
 	filename := fmt.Sprintf("%s.json", contractInfo.ContractID)
-	filepath := filepath.Join(contractsDir, filename)
+	filepath := filepath.Join(ContractsDir, filename)
 
 	// Marshal to JSON with indentation
 	data, err := json.MarshalIndent(contractInfo, "", "  ")
@@ -53,18 +165,40 @@ func SaveContractInfo(contractInfo *ContractInfo) error {
 		return fmt.Errorf("failed to marshal contract info: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	// Write to a temporary file and rename it so readers never see a partial file
+	tmpPath := filepath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write contract file: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath); err != nil {
+		os.Remove(tmpPath)
 		return fmt.Errorf("failed to write contract file: %w", err)
 	}
 
 	return nil
 }
 
-// LoadContractInfo loads contract information from a JSON file
+// LoadContractInfo loads contract information from a JSON file. The keys of
+// an encrypted contract are decrypted with the passphrase from PassphraseFunc.
 func LoadContractInfo(contractID string) (*ContractInfo, error) {
+	contractInfo, err := loadContractFile(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	if contractInfo.OwnerWIFEncrypted != "" || contractInfo.InheritorWIFEncrypted != "" {
+		if err := contractInfo.unseal(); err != nil {
+			return nil, err
+		}
+	}
+	return contractInfo, nil
+}
+
+// loadContractFile loads a contract without decrypting its keys, for changes
+// and checks that do not need them. Saving it keeps the keys as they are.
+func loadContractFile(contractID string) (*ContractInfo, error) {
 	filename := fmt.Sprintf("%s.json", contractID)
-	filepath := filepath.Join("contracts", filename)
+	filepath := filepath.Join(ContractsDir, filename)
 
 	data, err := os.ReadFile(filepath)
 	if err != nil {
@@ -76,19 +210,47 @@ func LoadContractInfo(contractID string) (*ContractInfo, error) {
 		return nil, fmt.Errorf("failed to unmarshal contract info: %w", err)
 	}
 
+	// Contracts saved before the address type was recorded are all P2WSH
+	addressType, err := parseContractAddressType(&contractInfo)
+	if err != nil {
+		return nil, err
+	}
+	contractInfo.AddressType = addressType
+
+	// Contracts saved before multiple funding UTXOs were supported record
+	// only the single funding UTXO
+	if len(contractInfo.FundingUTXOs) == 0 && contractInfo.FundingTxID != "" {
+		contractInfo.FundingUTXOs = []FundingOutpoint{{
+			TxID:   contractInfo.FundingTxID,
+			Vout:   contractInfo.FundingVout,
+			Amount: contractInfo.FundingAmount,
+		}}
+	}
+
 	return &contractInfo, nil
 }
 
+// parseContractAddressType returns the address type of a contract file,
+// refusing types the withdraw commands cannot spend
+func parseContractAddressType(contractInfo *ContractInfo) (script.AddressType, error) {
+	addressType, err := script.ParseAddressType(string(contractInfo.AddressType))
+	if err != nil {
+		return "", fmt.Errorf("invalid contract %s: %w", contractInfo.ContractID, err)
+	}
+	if addressType == script.AddressTypeP2TR {
+		return "", fmt.Errorf("contract %s is a p2tr contract, which the withdraw commands cannot spend", contractInfo.ContractID)
+	}
+	return addressType, nil
+}
+
 // ListContracts returns a list of all saved contract IDs
 func ListContracts() ([]string, error) {
-	contractsDir := "contracts"
-
 	// Check if directory exists
-	if _, err := os.Stat(contractsDir); os.IsNotExist(err) {
+	if _, err := os.Stat(ContractsDir); os.IsNotExist(err) {
 		return []string{}, nil
 	}
 
-	files, err := os.ReadDir(contractsDir)
+	files, err := os.ReadDir(ContractsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read contracts directory: %w", err)
 	}
@@ -104,29 +266,251 @@ func ListContracts() ([]string, error) {
 	return contractIDs, nil
 }
 
-// GenerateContractID generates a unique contract ID based on the P2WSH address
+// contractIDPrefix returns the network prefix of contract IDs: mainnet,
+// signet, regtest, or testnet for testnet3 and any other test network
+func contractIDPrefix(chainParams *chaincfg.Params) string {
+	switch chainParams.Name {
+	case chaincfg.MainNetParams.Name, chaincfg.SigNetParams.Name, chaincfg.RegressionNetParams.Name:
+		return chainParams.Name
+	}
+	return "testnet"
+}
+
+// GenerateContractID returns the ID to save the contract paying p2wshAddr
+// under: a network prefix and the first 8 bytes of the SHA256 of the
+// address's output script, in hex. Should the ID be taken by a contract with
+// another address, a numeric suffix is appended until it is free. A contract
+// saved before IDs were hashed, under its address's last 8 characters, keeps
+// that ID.
 func GenerateContractID(p2wshAddr btcutil.Address, chainParams *chaincfg.Params) string {
-	// Use first 8 characters of the address and network prefix
 	addrStr := p2wshAddr.EncodeAddress()
-	networkPrefix := "testnet"
-	if chainParams.Net == chaincfg.MainNetParams.Net {
-		networkPrefix = "mainnet"
+	networkPrefix := contractIDPrefix(chainParams)
+
+	legacyID := fmt.Sprintf("%s_%s", networkPrefix, addrStr[len(addrStr)-8:])
+	if existing, err := loadContractFile(legacyID); err == nil && existing.P2WSHAddress == addrStr {
+		return legacyID
+	}
+
+	pkScript, err := txscript.PayToAddrScript(p2wshAddr)
+	if err != nil {
+		pkScript = []byte(addrStr)
 	}
+	hash := sha256.Sum256(pkScript)
+	baseID := fmt.Sprintf("%s_%x", networkPrefix, hash[:8])
 
-	return fmt.Sprintf("%s_%s", networkPrefix, addrStr[len(addrStr)-8:])
+	contractID := baseID
+	for n := 2; !contractIDAvailable(contractID, addrStr); n++ {
+		contractID = fmt.Sprintf("%s_%d", baseID, n)
+	}
+	return contractID
+}
+
+// contractIDAvailable reports whether a contract paying addrStr can be saved
+// under contractID: no file has the ID, or the file is that contract's
+func contractIDAvailable(contractID, addrStr string) bool {
+	if _, err := os.Stat(filepath.Join(ContractsDir, contractID+".json")); os.IsNotExist(err) {
+		return true
+	}
+	existing, err := loadContractFile(contractID)
+	return err == nil && existing.P2WSHAddress == addrStr
 }
 
-// UpdateFundingStatus updates the funding status of a contract
+// UpdateFundingStatus records txID:vout as the only funding UTXO of a
+// contract, leaving all other fields as they are on disk
 func UpdateFundingStatus(contractID, txID string, vout uint32, amount int64) error {
-	contractInfo, err := LoadContractInfo(contractID)
+	return updateContract(contractID, func(contractInfo *ContractInfo) {
+		contractInfo.IsFunded = true
+		contractInfo.FundingTxID = txID
+		contractInfo.FundingVout = vout
+		contractInfo.FundingAmount = amount
+		contractInfo.FundingUTXOs = []FundingOutpoint{{TxID: txID, Vout: vout, Amount: amount}}
+
+		// A new funding UTXO restarts the timelock once it confirms
+		contractInfo.FundingBlockTime = 0
+	})
+}
+
+// AddFundingUTXO records txID:vout as a further funding UTXO of a contract.
+// The first funding UTXO also fills the single-UTXO fields. Adding a UTXO
+// that is already recorded is a no-op.
+func AddFundingUTXO(contractID, txID string, vout uint32, amount int64) error {
+	return updateContract(contractID, func(contractInfo *ContractInfo) {
+		for _, outpoint := range contractInfo.FundingUTXOs {
+			if outpoint.TxID == txID && outpoint.Vout == vout {
+				return
+			}
+		}
+
+		if len(contractInfo.FundingUTXOs) == 0 {
+			contractInfo.IsFunded = true
+			contractInfo.FundingTxID = txID
+			contractInfo.FundingVout = vout
+			contractInfo.FundingAmount = amount
+			contractInfo.FundingBlockTime = 0
+		}
+		contractInfo.FundingUTXOs = append(contractInfo.FundingUTXOs,
+			FundingOutpoint{TxID: txID, Vout: vout, Amount: amount})
+	})
+}
+
+// SetFundingUTXOs records outpoints as the funding UTXOs of a contract,
+// replacing the recorded ones. The funding block time is kept only while the
+// first funding UTXO stays the same.
+func SetFundingUTXOs(contractID string, outpoints []FundingOutpoint) error {
+	if len(outpoints) == 0 {
+		return fmt.Errorf("at least one funding UTXO is required")
+	}
+
+	return updateContract(contractID, func(contractInfo *ContractInfo) {
+		first := outpoints[0]
+		if contractInfo.FundingTxID != first.TxID || contractInfo.FundingVout != first.Vout {
+			contractInfo.FundingBlockTime = 0
+		}
+
+		contractInfo.IsFunded = true
+		contractInfo.FundingTxID = first.TxID
+		contractInfo.FundingVout = first.Vout
+		contractInfo.FundingAmount = first.Amount
+		contractInfo.FundingUTXOs = append([]FundingOutpoint(nil), outpoints...)
+	})
+}
+
+// MarkSpent records that a contract no longer holds any funding UTXO, keeping
+// its withdrawals and all other fields
+func MarkSpent(contractID string) error {
+	return updateContract(contractID, func(contractInfo *ContractInfo) {
+		contractInfo.IsFunded = false
+		contractInfo.FundingTxID = ""
+		contractInfo.FundingVout = 0
+		contractInfo.FundingAmount = 0
+		contractInfo.FundingUTXOs = nil
+		contractInfo.FundingBlockTime = 0
+	})
+}
+
+// UpdateFundingBlockTime records when the funding UTXO was confirmed
+func UpdateFundingBlockTime(contractID string, blockTime int64) error {
+	return updateContract(contractID, func(contractInfo *ContractInfo) {
+		contractInfo.FundingBlockTime = blockTime
+	})
+}
+
+// UpdateLabel sets the human-readable label of a contract
+func UpdateLabel(contractID, label string) error {
+	return updateContract(contractID, func(contractInfo *ContractInfo) {
+		contractInfo.Label = label
+	})
+}
+
+// updateContract applies a change to a contract file under its lock. The
+// file is re-read immediately before the change so concurrent updates to
+// other fields are not lost.
+func updateContract(contractID string, mutate func(*ContractInfo)) error {
+	unlock, err := lockContract(contractID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	contractInfo, err := loadContractFile(contractID)
 	if err != nil {
 		return fmt.Errorf("failed to load contract: %w", err)
 	}
 
-	contractInfo.IsFunded = true
-	contractInfo.FundingTxID = txID
-	contractInfo.FundingVout = vout
-	contractInfo.FundingAmount = amount
+	mutate(contractInfo)
 
 	return SaveContractInfo(contractInfo)
 }
+
+// lockContract takes an exclusive lock on a contract file by creating a
+// companion .lock file holding the PID of the process and the time it was
+// taken, waiting up to lockTimeout for another writer. A lock whose process
+// no longer runs was left by a crash and is removed.
+func lockContract(contractID string) (func(), error) {
+	lockPath := filepath.Join(ContractsDir, fmt.Sprintf("%s.lock", contractID))
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, writeErr := fmt.Fprintf(lockFile, "%d %s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+			closeErr := lockFile.Close()
+			if writeErr != nil || closeErr != nil {
+				os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to write contract lock %s: %w", lockPath, errors.Join(writeErr, closeErr))
+			}
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock contract: %w", err)
+		}
+
+		holder, readErr := os.ReadFile(lockPath)
+		if readErr == nil && lockIsStale(holder) {
+			// Only remove the lock that was judged stale, not one another
+			// process has taken since
+			if current, err := os.ReadFile(lockPath); err == nil && bytes.Equal(current, holder) {
+				os.Remove(lockPath)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			var pid int
+			var created string
+			if _, err := fmt.Sscanf(string(holder), "%d %s", &pid, &created); err == nil {
+				return nil, fmt.Errorf("contract %s is locked by process %d since %s; if no command is running on it, delete %s",
+					contractID, pid, created, lockPath)
+			}
+			return nil, fmt.Errorf("timed out waiting for contract lock; if no command is running on %s, delete %s", contractID, lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// lockIsStale reports whether the process recorded in a lock file no longer
+// runs. A lock without a readable PID (being written, or left by an older
+// build) is not considered stale.
+func lockIsStale(holder []byte) bool {
+	var pid int
+	var created string
+	if _, err := fmt.Sscanf(string(holder), "%d %s", &pid, &created); err != nil || pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return errors.Is(err, os.ErrProcessDone) || errors.Is(err, syscall.ESRCH)
+}
+
+// RecordWithdrawal appends a broadcast withdrawal to the contract's history
+func RecordWithdrawal(contractID string, record WithdrawalRecord) error {
+	return updateContract(contractID, func(contractInfo *ContractInfo) {
+		contractInfo.Withdrawals = append(contractInfo.Withdrawals, record)
+	})
+}
+
+// FindWithdrawal searches all saved contracts for a withdrawal with the given txid
+func FindWithdrawal(txID string) (*ContractInfo, *WithdrawalRecord, error) {
+	contractIDs, err := ListContracts()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, contractID := range contractIDs {
+		contractInfo, err := loadContractFile(contractID)
+		if err != nil {
+			continue
+		}
+
+		for i := range contractInfo.Withdrawals {
+			if contractInfo.Withdrawals[i].TxID == txID {
+				return contractInfo, &contractInfo.Withdrawals[i], nil
+			}
+		}
+	}
+
+	return nil, nil, nil
+}
@@ -0,0 +1,411 @@
+package contract
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to store contracts in a temporary directory
+func useTempContractsDir(t *testing.T) {
+	t.Helper()
+
+	original := ContractsDir
+	ContractsDir = t.TempDir()
+	t.Cleanup(func() { ContractsDir = original })
+}
+
+// Test helper to create a minimal saved contract
+func saveTestContract(t *testing.T, contractID string) *ContractInfo {
+	t.Helper()
+
+	contractInfo := &ContractInfo{
+		ContractID:   contractID,
+		CreatedAt:    time.Now(),
+		Network:      "testnet3",
+		TimelockDays: 180,
+		RedeemScript: "63ac67b27568",
+		P2WSHAddress: "tb1qexample",
+	}
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+	return contractInfo
+}
+
+func TestSaveAndLoadContractInfo(t *testing.T) {
+	useTempContractsDir(t)
+	saved := saveTestContract(t, "testnet_abcdefgh")
+
+	loaded, err := LoadContractInfo(saved.ContractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+
+	if loaded.P2WSHAddress != saved.P2WSHAddress || loaded.TimelockDays != saved.TimelockDays {
+		t.Error("Loaded contract does not match the saved contract")
+	}
+
+	contractIDs, err := ListContracts()
+	if err != nil {
+		t.Fatalf("ListContracts failed: %v", err)
+	}
+	if len(contractIDs) != 1 || contractIDs[0] != saved.ContractID {
+		t.Errorf("Expected [%s], got %v", saved.ContractID, contractIDs)
+	}
+}
+
+func TestUpdateFundingStatus_PreservesOtherFields(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+	saveTestContract(t, contractID)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+
+	// Concurrent label and funding updates must both persist
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		errs <- UpdateLabel(contractID, "Family savings")
+	}()
+	go func() {
+		defer wg.Done()
+		errs <- UpdateFundingStatus(contractID, "abcd", 1, 50000)
+	}()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent update failed: %v", err)
+		}
+	}
+
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+
+	if loaded.Label != "Family savings" {
+		t.Errorf("Label update was lost, got %q", loaded.Label)
+	}
+	if !loaded.IsFunded || loaded.FundingTxID != "abcd" || loaded.FundingVout != 1 || loaded.FundingAmount != 50000 {
+		t.Errorf("Funding update was lost: %+v", loaded)
+	}
+	if loaded.P2WSHAddress != "tb1qexample" {
+		t.Errorf("Unrelated field was clobbered: %q", loaded.P2WSHAddress)
+	}
+}
+
+func TestMarkSpent_ClearsFunding(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+	saveTestContract(t, contractID)
+
+	if err := UpdateFundingStatus(contractID, "abcd", 1, 50000); err != nil {
+		t.Fatalf("UpdateFundingStatus failed: %v", err)
+	}
+	if err := UpdateFundingBlockTime(contractID, 1700000000); err != nil {
+		t.Fatalf("UpdateFundingBlockTime failed: %v", err)
+	}
+	if err := MarkSpent(contractID); err != nil {
+		t.Fatalf("MarkSpent failed: %v", err)
+	}
+
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if loaded.IsFunded || loaded.FundingTxID != "" || loaded.FundingAmount != 0 ||
+		len(loaded.FundingUTXOs) != 0 || loaded.FundingBlockTime != 0 {
+		t.Errorf("Funding state was not cleared: %+v", loaded)
+	}
+	if loaded.P2WSHAddress != "tb1qexample" {
+		t.Errorf("Unrelated field was clobbered: %q", loaded.P2WSHAddress)
+	}
+}
+
+func TestUpdateContract_ManyConcurrentWriters(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+	saveTestContract(t, contractID)
+
+	const writers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := RecordWithdrawal(contractID, WithdrawalRecord{TxID: "tx", Path: "owner"}); err != nil {
+				t.Errorf("RecordWithdrawal failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if len(loaded.Withdrawals) != writers {
+		t.Errorf("Expected %d withdrawals, got %d", writers, len(loaded.Withdrawals))
+	}
+}
+
+func TestLockContract(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+	saveTestContract(t, contractID)
+	lockPath := filepath.Join(ContractsDir, contractID+".lock")
+
+	oldTimeout := lockTimeout
+	lockTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = oldTimeout })
+
+	// A held lock names the process holding it
+	unlock, err := lockContract(contractID)
+	if err != nil {
+		t.Fatalf("lockContract failed: %v", err)
+	}
+	holder, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("Failed to read lock file: %v", err)
+	}
+	if !strings.HasPrefix(string(holder), fmt.Sprintf("%d ", os.Getpid())) {
+		t.Errorf("Expected the lock to hold PID %d, got %q", os.Getpid(), holder)
+	}
+
+	// A lock of a running process is waited for, and the error names the file
+	err = RecordWithdrawal(contractID, WithdrawalRecord{TxID: "tx", Path: "owner"})
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("locked by process %d", os.Getpid())) ||
+		!strings.Contains(err.Error(), "delete "+lockPath) {
+		t.Errorf("Expected a lock error naming the process and %s, got %v", lockPath, err)
+	}
+	unlock()
+
+	// A lock left by a process that exited is stale and taken over
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("Failed to run a short-lived process: %v", err)
+	}
+	stale := fmt.Sprintf("%d %s\n", exited.Process.Pid, time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(lockPath, []byte(stale), 0644); err != nil {
+		t.Fatalf("Failed to write stale lock: %v", err)
+	}
+	if err := RecordWithdrawal(contractID, WithdrawalRecord{TxID: "tx", Path: "owner"}); err != nil {
+		t.Fatalf("Expected the stale lock to be taken over, got %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock to be released, got %v", err)
+	}
+
+	// A lock without a PID is left alone
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to write empty lock: %v", err)
+	}
+	if err := RecordWithdrawal(contractID, WithdrawalRecord{TxID: "tx", Path: "owner"}); err == nil || !strings.Contains(err.Error(), "delete "+lockPath) {
+		t.Errorf("Expected a lock error naming %s, got %v", lockPath, err)
+	}
+}
+
+func TestLoadContractInfo_AddressType(t *testing.T) {
+	useTempContractsDir(t)
+
+	tests := []struct {
+		name     string
+		json     string
+		expected script.AddressType
+		wantErr  bool
+	}{
+		{"legacy file defaults to p2wsh", `{"contract_id":"testnet_legacy01"}`, script.AddressTypeP2WSH, false},
+		{"nested", `{"contract_id":"testnet_legacy01","address_type":"p2sh-p2wsh"}`, script.AddressTypeP2SHP2WSH, false},
+		{"unknown", `{"contract_id":"testnet_legacy01","address_type":"p2pkh"}`, "", true},
+		{"taproot", `{"contract_id":"testnet_legacy01","address_type":"p2tr"}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(ContractsDir, "testnet_legacy01.json"), []byte(tt.json), 0644); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+
+			contractInfo, err := LoadContractInfo("testnet_legacy01")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got address type %q", contractInfo.AddressType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			if contractInfo.AddressType != tt.expected {
+				t.Errorf("Expected address type %q, got %q", tt.expected, contractInfo.AddressType)
+			}
+		})
+	}
+}
+
+func TestLoadContractInfo_LegacyFunding(t *testing.T) {
+	useTempContractsDir(t)
+
+	legacy := `{"contract_id":"testnet_legacy01","is_funded":true,"funding_tx_id":"abcd","funding_vout":1,"funding_amount":50000}`
+	if err := os.WriteFile(filepath.Join(ContractsDir, "testnet_legacy01.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	contractInfo, err := LoadContractInfo("testnet_legacy01")
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	expected := []FundingOutpoint{{TxID: "abcd", Vout: 1, Amount: 50000}}
+	if !reflect.DeepEqual(contractInfo.FundingUTXOs, expected) {
+		t.Errorf("Expected funding UTXOs %+v, got %+v", expected, contractInfo.FundingUTXOs)
+	}
+}
+
+func TestAddFundingUTXO(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+	saveTestContract(t, contractID)
+
+	for _, outpoint := range []FundingOutpoint{
+		{TxID: "aaaa", Vout: 0, Amount: 50000},
+		{TxID: "bbbb", Vout: 2, Amount: 30000},
+		{TxID: "aaaa", Vout: 0, Amount: 50000}, // already recorded
+	} {
+		if err := AddFundingUTXO(contractID, outpoint.TxID, outpoint.Vout, outpoint.Amount); err != nil {
+			t.Fatalf("AddFundingUTXO failed: %v", err)
+		}
+	}
+
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if len(loaded.FundingUTXOs) != 2 {
+		t.Fatalf("Expected 2 funding UTXOs, got %+v", loaded.FundingUTXOs)
+	}
+	if !loaded.IsFunded || loaded.FundingTxID != "aaaa" || loaded.FundingAmount != 50000 {
+		t.Errorf("Expected the first UTXO in the single-UTXO fields, got %+v", loaded)
+	}
+	if total := loaded.TotalFunding(); total != 80000 {
+		t.Errorf("Expected total funding 80000, got %d", total)
+	}
+
+	// Setting the funding replaces all UTXOs
+	if err := UpdateFundingStatus(contractID, "cccc", 0, 10000); err != nil {
+		t.Fatalf("UpdateFundingStatus failed: %v", err)
+	}
+	loaded, err = LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if len(loaded.FundingUTXOs) != 1 || loaded.FundingUTXOs[0].TxID != "cccc" {
+		t.Errorf("Expected only cccc:0, got %+v", loaded.FundingUTXOs)
+	}
+}
+
+// Test helper to create a testnet P2WSH address with a witness program of b
+func testScriptAddress(t *testing.T, b byte) btcutil.Address {
+	t.Helper()
+
+	program := make([]byte, 32)
+	program[0] = b
+	addr, err := btcutil.NewAddressWitnessScriptHash(program, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to create address: %v", err)
+	}
+	return addr
+}
+
+func TestGenerateContractID(t *testing.T) {
+	useTempContractsDir(t)
+	first := testScriptAddress(t, 1)
+	second := testScriptAddress(t, 2)
+
+	id := GenerateContractID(first, &chaincfg.TestNet3Params)
+	if !strings.HasPrefix(id, "testnet_") || len(id) != len("testnet_")+16 {
+		t.Errorf("Expected testnet_ and 16 hex digits, got %s", id)
+	}
+	if again := GenerateContractID(first, &chaincfg.TestNet3Params); again != id {
+		t.Errorf("Expected the same ID for the same address, got %s and %s", id, again)
+	}
+	if other := GenerateContractID(second, &chaincfg.TestNet3Params); other == id {
+		t.Errorf("Expected different IDs for different addresses, both got %s", id)
+	}
+	for _, params := range []*chaincfg.Params{&chaincfg.MainNetParams, &chaincfg.SigNetParams, &chaincfg.RegressionNetParams} {
+		if other := GenerateContractID(first, params); !strings.HasPrefix(other, params.Name+"_") {
+			t.Errorf("Expected a %s_ prefix, got %s", params.Name, other)
+		}
+	}
+}
+
+func TestGenerateContractID_Collision(t *testing.T) {
+	useTempContractsDir(t)
+	first := testScriptAddress(t, 1)
+	second := testScriptAddress(t, 2)
+
+	// Store the first contract under the ID the second one would get, as
+	// if their IDs collided
+	collidingID := GenerateContractID(second, &chaincfg.TestNet3Params)
+	if err := SaveContractInfo(&ContractInfo{ContractID: collidingID, P2WSHAddress: first.EncodeAddress()}); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	// Saving the second contract under the taken ID is refused
+	err := SaveContractInfo(&ContractInfo{ContractID: collidingID, P2WSHAddress: second.EncodeAddress()})
+	if err == nil || !strings.Contains(err.Error(), "refusing to overwrite") {
+		t.Errorf("Expected overwriting another address's contract to be refused, got %v", err)
+	}
+
+	// The second contract gets a disambiguated ID and both are stored
+	secondID := GenerateContractID(second, &chaincfg.TestNet3Params)
+	if secondID != collidingID+"_2" {
+		t.Errorf("Expected ID %s_2, got %s", collidingID, secondID)
+	}
+	if err := SaveContractInfo(&ContractInfo{ContractID: secondID, P2WSHAddress: second.EncodeAddress()}); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+	for id, want := range map[string]btcutil.Address{collidingID: first, secondID: second} {
+		loaded, err := LoadContractInfo(id)
+		if err != nil {
+			t.Fatalf("LoadContractInfo(%s) failed: %v", id, err)
+		}
+		if loaded.P2WSHAddress != want.EncodeAddress() {
+			t.Errorf("Contract %s: expected address %s, got %s", id, want.EncodeAddress(), loaded.P2WSHAddress)
+		}
+	}
+
+	// Once saved, the second contract keeps its ID
+	if again := GenerateContractID(second, &chaincfg.TestNet3Params); again != secondID {
+		t.Errorf("Expected %s again, got %s", secondID, again)
+	}
+}
+
+func TestGenerateContractID_Legacy(t *testing.T) {
+	useTempContractsDir(t)
+	addr := testScriptAddress(t, 1)
+	addrStr := addr.EncodeAddress()
+
+	// A contract saved under the old ID, the address's last 8 characters
+	legacyID := "testnet_" + addrStr[len(addrStr)-8:]
+	if err := SaveContractInfo(&ContractInfo{ContractID: legacyID, P2WSHAddress: addrStr}); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	if id := GenerateContractID(addr, &chaincfg.TestNet3Params); id != legacyID {
+		t.Errorf("Expected the legacy ID %s, got %s", legacyID, id)
+	}
+}
@@ -0,0 +1,89 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldDiff is a field that differs between two contracts
+type FieldDiff struct {
+	Field string
+	Left  string
+	Right string
+}
+
+// DiffContracts compares two contracts field by field and returns the
+// fields that differ, named by their JSON keys, in declaration order. Private
+// key fields are redacted to a short hash (see redactKey).
+func DiffContracts(left, right *ContractInfo) []FieldDiff {
+	var diffs []FieldDiff
+
+	leftValue := reflect.ValueOf(*left)
+	rightValue := reflect.ValueOf(*right)
+	contractType := leftValue.Type()
+
+	for i := 0; i < contractType.NumField(); i++ {
+		if !contractType.Field(i).IsExported() {
+			continue
+		}
+		leftField := leftValue.Field(i).Interface()
+		rightField := rightValue.Field(i).Interface()
+		if reflect.DeepEqual(leftField, rightField) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(contractType.Field(i).Tag.Get("json"), ",")
+		format := formatField
+		if strings.Contains(name, "wif") {
+			format = redactKey
+		}
+		diffs = append(diffs, FieldDiff{
+			Field: name,
+			Left:  format(leftField),
+			Right: format(rightField),
+		})
+	}
+
+	return diffs
+}
+
+// redactKey renders a private key field without revealing it: the first
+// bytes of its SHA-256 hash are enough to tell whether two keys are the same
+func redactKey(value interface{}) string {
+	key, _ := value.(string)
+	if key == "" {
+		return "(empty)"
+	}
+	hash := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("(redacted, sha256 %x)", hash[:4])
+}
+
+// formatField renders a contract field value for display
+func formatField(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05 MST")
+	case []WithdrawalRecord:
+		txIDs := make([]string, len(v))
+		for i, record := range v {
+			txIDs[i] = record.TxID
+		}
+		return fmt.Sprintf("%d withdrawals %v", len(v), txIDs)
+	case []FundingOutpoint:
+		outpoints := make([]string, len(v))
+		for i, outpoint := range v {
+			outpoints[i] = fmt.Sprintf("%s:%d", outpoint.TxID, outpoint.Vout)
+		}
+		return fmt.Sprintf("%d funding UTXOs %v", len(v), outpoints)
+	case string:
+		if v == "" {
+			return "(empty)"
+		}
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,85 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffContracts(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	left := &ContractInfo{
+		ContractID:   "testnet_aaaaaaaa",
+		CreatedAt:    created,
+		Network:      "testnet3",
+		TimelockDays: 180,
+		OwnerWIF:     "owner-wif",
+		P2WSHAddress: "tb1qaaaa",
+	}
+
+	right := *left
+	right.ContractID = "testnet_bbbbbbbb"
+	right.TimelockDays = 365
+	right.P2WSHAddress = "tb1qbbbb"
+	right.IsFunded = true
+
+	diffs := DiffContracts(left, &right)
+
+	expected := map[string][2]string{
+		"contract_id":   {"testnet_aaaaaaaa", "testnet_bbbbbbbb"},
+		"timelock_days": {"180", "365"},
+		"p2wsh_address": {"tb1qaaaa", "tb1qbbbb"},
+		"is_funded":     {"false", "true"},
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("Expected %d differences, got %d: %+v", len(expected), len(diffs), diffs)
+	}
+	for _, diff := range diffs {
+		values, ok := expected[diff.Field]
+		if !ok {
+			t.Errorf("Unexpected difference in %s", diff.Field)
+			continue
+		}
+		if diff.Left != values[0] || diff.Right != values[1] {
+			t.Errorf("Field %s: expected %s -> %s, got %s -> %s", diff.Field, values[0], values[1], diff.Left, diff.Right)
+		}
+	}
+
+	// Declaration order is preserved
+	if diffs[0].Field != "contract_id" {
+		t.Errorf("Expected contract_id first, got %s", diffs[0].Field)
+	}
+}
+
+func TestDiffContracts_Identical(t *testing.T) {
+	contractInfo := &ContractInfo{ContractID: "testnet_aaaaaaaa", TimelockDays: 180}
+	if diffs := DiffContracts(contractInfo, contractInfo); len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %+v", diffs)
+	}
+}
+
+func TestDiffContracts_RedactsKeys(t *testing.T) {
+	left := &ContractInfo{ContractID: "testnet_aaaaaaaa", OwnerWIF: "cOwnerSecret", InheritorWIF: "cInheritorSecret"}
+	right := *left
+	right.OwnerWIF = "cOtherOwnerSecret"
+	right.InheritorWIF = ""
+	right.InheritorWIFEncrypted = "ciphertext"
+
+	diffs := DiffContracts(left, &right)
+	if len(diffs) != 3 {
+		t.Fatalf("Expected 3 differences, got %+v", diffs)
+	}
+	for _, diff := range diffs {
+		for _, value := range []string{diff.Left, diff.Right} {
+			if strings.Contains(value, "Secret") || strings.Contains(value, "ciphertext") {
+				t.Errorf("Field %s shows a key: %s", diff.Field, value)
+			}
+			if value != "(empty)" && !strings.HasPrefix(value, "(redacted, sha256 ") {
+				t.Errorf("Field %s: expected a redacted value, got %s", diff.Field, value)
+			}
+		}
+		if diff.Left == diff.Right {
+			t.Errorf("Field %s: different keys redact to the same value %s", diff.Field, diff.Left)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package contract
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for deriving the key that encrypts a WIF; N = 2^15 takes
+// about 100ms, which is negligible per command but slows guessing
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+// ErrWrongPassphrase is returned when an encrypted key does not decrypt,
+// which almost always means the passphrase is wrong
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// PassphraseFunc supplies the passphrase of an encrypted contract when
+// LoadContractInfo needs to decrypt its keys. Without it, loading an
+// encrypted contract fails.
+var PassphraseFunc func(contractID string) ([]byte, error)
+
+// EncryptWIF seals a WIF with a key derived from passphrase by scrypt, using
+// AES-256-GCM. The result is base64 of the salt, the nonce and the sealed
+// WIF; every call uses a fresh salt and nonce.
+func EncryptWIF(wif string, passphrase []byte) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := newKeyCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := append(salt, nonce...)
+	sealed = aead.Seal(sealed, nonce, []byte(wif), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptWIF opens a WIF sealed by EncryptWIF. A wrong passphrase, like any
+// tampering, fails with ErrWrongPassphrase.
+func DecryptWIF(encrypted string, passphrase []byte) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted key: %w", err)
+	}
+	if len(sealed) < saltLen {
+		return "", fmt.Errorf("invalid encrypted key: too short")
+	}
+
+	salt, rest := sealed[:saltLen], sealed[saltLen:]
+	aead, err := newKeyCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	if len(rest) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted key: too short")
+	}
+
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	wif, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(wif), nil
+}
+
+// newKeyCipher derives the AES-GCM cipher for a passphrase and salt
+func newKeyCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// IsEncrypted reports whether the contract's keys are stored encrypted
+func (c *ContractInfo) IsEncrypted() bool {
+	return c.passphrase != nil || c.OwnerWIFEncrypted != "" || c.InheritorWIFEncrypted != ""
+}
+
+// SetPassphrase makes SaveContractInfo store the contract's keys encrypted
+// with passphrase from now on
+func (c *ContractInfo) SetPassphrase(passphrase []byte) {
+	c.passphrase = append([]byte(nil), passphrase...)
+}
+
+// sealed returns the contract as it is written to disk. With a passphrase
+// the WIFs are encrypted afresh and left out; otherwise the contract is
+// written as it is, keeping keys that were never decrypted sealed.
+func (c *ContractInfo) sealed() (*ContractInfo, error) {
+	if c.passphrase == nil {
+		return c, nil
+	}
+
+	sealed := *c
+	var err error
+	if c.OwnerWIF != "" {
+		if sealed.OwnerWIFEncrypted, err = EncryptWIF(c.OwnerWIF, c.passphrase); err != nil {
+			return nil, fmt.Errorf("failed to encrypt owner key: %w", err)
+		}
+	}
+	if c.InheritorWIF != "" {
+		if sealed.InheritorWIFEncrypted, err = EncryptWIF(c.InheritorWIF, c.passphrase); err != nil {
+			return nil, fmt.Errorf("failed to encrypt inheritor key: %w", err)
+		}
+	}
+	sealed.OwnerWIF = ""
+	sealed.InheritorWIF = ""
+	return &sealed, nil
+}
+
+// unseal decrypts the keys of an encrypted contract with the passphrase from
+// PassphraseFunc, which is kept so a later save encrypts them again
+func (c *ContractInfo) unseal() error {
+	if PassphraseFunc == nil {
+		return fmt.Errorf("contract %s has encrypted keys and no passphrase is available", c.ContractID)
+	}
+	passphrase, err := PassphraseFunc(c.ContractID)
+	if err != nil {
+		return fmt.Errorf("failed to get passphrase for contract %s: %w", c.ContractID, err)
+	}
+
+	if c.OwnerWIFEncrypted != "" {
+		if c.OwnerWIF, err = DecryptWIF(c.OwnerWIFEncrypted, passphrase); err != nil {
+			return fmt.Errorf("failed to decrypt owner key of contract %s: %w", c.ContractID, err)
+		}
+	}
+	if c.InheritorWIFEncrypted != "" {
+		if c.InheritorWIF, err = DecryptWIF(c.InheritorWIFEncrypted, passphrase); err != nil {
+			return fmt.Errorf("failed to decrypt inheritor key of contract %s: %w", c.ContractID, err)
+		}
+	}
+	c.SetPassphrase(passphrase)
+	return nil
+}
+
+// EncryptContract encrypts the plaintext keys of a saved contract with
+// passphrase. It returns false, leaving the file alone, when the keys are
+// already encrypted.
+func EncryptContract(contractID string, passphrase []byte) (bool, error) {
+	unlock, err := lockContract(contractID)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	contractInfo, err := loadContractFile(contractID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load contract: %w", err)
+	}
+	if contractInfo.IsEncrypted() {
+		return false, nil
+	}
+
+	contractInfo.SetPassphrase(passphrase)
+	if err := SaveContractInfo(contractInfo); err != nil {
+		return false, err
+	}
+	return true, nil
+}
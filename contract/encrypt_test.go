@@ -0,0 +1,139 @@
+package contract
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testOwnerWIF     = "cVt4o7BGAig1UXywgGSmARhxMdzP5qvQsxKkSsc1XEkw3tDTQFpy"
+	testInheritorWIF = "cTpB4YiyKiBcPxnefsDpbnDxFDffjqJob8wGCEDXxgQ7zQoMXJdH"
+)
+
+// usePassphrase makes LoadContractInfo decrypt with passphrase
+func usePassphrase(t *testing.T, passphrase string) {
+	t.Helper()
+
+	original := PassphraseFunc
+	PassphraseFunc = func(contractID string) ([]byte, error) {
+		return []byte(passphrase), nil
+	}
+	t.Cleanup(func() { PassphraseFunc = original })
+}
+
+func TestEncryptWIF(t *testing.T) {
+	encrypted, err := EncryptWIF(testOwnerWIF, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("EncryptWIF failed: %v", err)
+	}
+	if strings.Contains(encrypted, testOwnerWIF) {
+		t.Fatal("Encrypted key contains the plaintext WIF")
+	}
+
+	wif, err := DecryptWIF(encrypted, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("DecryptWIF failed: %v", err)
+	}
+	if wif != testOwnerWIF {
+		t.Errorf("Expected %s, got %s", testOwnerWIF, wif)
+	}
+
+	if _, err := DecryptWIF(encrypted, []byte("battery staple")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+	if _, err := DecryptWIF("bm90IGVub3VnaA==", []byte("correct horse")); err == nil {
+		t.Error("Expected an error for a truncated key")
+	}
+}
+
+func TestEncryptedContract(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+
+	contractInfo := saveTestContract(t, contractID)
+	contractInfo.OwnerWIF = testOwnerWIF
+	contractInfo.InheritorWIF = testInheritorWIF
+	contractInfo.SetPassphrase([]byte("correct horse"))
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	// Only the encrypted keys reach the disk
+	data, err := os.ReadFile(filepath.Join(ContractsDir, contractID+".json"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), testOwnerWIF) || strings.Contains(string(data), testInheritorWIF) {
+		t.Fatal("Contract file contains a plaintext WIF")
+	}
+	if !strings.Contains(string(data), `"owner_wif_encrypted"`) || !strings.Contains(string(data), `"inheritor_wif_encrypted"`) {
+		t.Fatalf("Contract file lacks the encrypted keys:\n%s", data)
+	}
+
+	// Updates that do not need the keys leave them encrypted
+	if err := UpdateLabel(contractID, "savings"); err != nil {
+		t.Fatalf("UpdateLabel failed: %v", err)
+	}
+
+	if _, err := LoadContractInfo(contractID); err == nil {
+		t.Error("Expected an error without a passphrase")
+	}
+
+	usePassphrase(t, "battery staple")
+	if _, err := LoadContractInfo(contractID); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+
+	usePassphrase(t, "correct horse")
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if loaded.OwnerWIF != testOwnerWIF || loaded.InheritorWIF != testInheritorWIF || loaded.Label != "savings" {
+		t.Errorf("Unexpected decrypted contract: %+v", loaded)
+	}
+
+	// Saving a decrypted contract encrypts it again
+	if err := SaveContractInfo(loaded); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+	if reloaded, err := LoadContractInfo(contractID); err != nil || reloaded.OwnerWIF != testOwnerWIF {
+		t.Errorf("Expected the re-saved contract to decrypt, got %v", err)
+	}
+}
+
+func TestEncryptContract(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+
+	contractInfo := saveTestContract(t, contractID)
+	contractInfo.OwnerWIF = testOwnerWIF
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	// A legacy plaintext contract loads without a passphrase
+	if loaded, err := LoadContractInfo(contractID); err != nil || loaded.IsEncrypted() {
+		t.Fatalf("Expected a plaintext contract, got %+v, %v", loaded, err)
+	}
+
+	migrated, err := EncryptContract(contractID, []byte("correct horse"))
+	if err != nil || !migrated {
+		t.Fatalf("EncryptContract failed: %v", err)
+	}
+	if migrated, err := EncryptContract(contractID, []byte("battery staple")); err != nil || migrated {
+		t.Errorf("Expected an encrypted contract to be left alone, got %v, %v", migrated, err)
+	}
+
+	usePassphrase(t, "correct horse")
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if !loaded.IsEncrypted() || loaded.OwnerWIF != testOwnerWIF || loaded.InheritorWIF != "" {
+		t.Errorf("Unexpected migrated contract: %+v", loaded)
+	}
+}
@@ -0,0 +1,109 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+)
+
+// Reminder describes a contract whose inheritor path unlocks soon
+type Reminder struct {
+	ContractID string
+	Label      string
+	Expiry     time.Time
+
+	// Remaining is negative once the inheritor can already spend
+	Remaining time.Duration
+}
+
+// Expiry returns when the inheritor becomes able to spend: the funding block
+// time plus the timelock enforced by the redeem script. Consensus measures the
+// timelock by median-time-past, which trails block time by about an hour, so
+// the result is approximate; a block-based timelock is estimated at
+// script.BlockInterval per block. An absolute timelock expires at its lock time
+// whenever the contract was funded; one given as a block height has no known
+// expiry time.
+func (c *ContractInfo) Expiry() (time.Time, error) {
+	if !c.IsFunded {
+		return time.Time{}, fmt.Errorf("contract %s is not funded", c.ContractID)
+	}
+
+	redeemScript, err := hex.DecodeString(c.RedeemScript)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	parsed, err := script.ParseRedeemScript(redeemScript, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read timelock from redeem script: %w", err)
+	}
+	if parsed.TimelockType == script.Absolute {
+		if !script.IsLockTimeTimestamp(parsed.LockTime) {
+			return time.Time{}, fmt.Errorf("contract %s unlocks at block %d, not at a known time", c.ContractID, parsed.LockTime)
+		}
+		return time.Unix(parsed.LockTime, 0), nil
+	}
+
+	if c.FundingBlockTime == 0 {
+		return time.Time{}, fmt.Errorf("funding time of contract %s is unknown (unconfirmed or not recorded)", c.ContractID)
+	}
+
+	// The whole timelock remains at the moment of funding
+	lock := script.RelativeTimelockDuration(parsed.RelativeTimelock)
+	if !script.IsBlockBasedTimelock(parsed.RelativeTimelock) {
+		lock, err = transaction.TimelockRemaining(parsed.RelativeTimelock, c.FundingBlockTime, c.FundingBlockTime)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Unix(c.FundingBlockTime, 0).Add(lock), nil
+}
+
+// DueReminders returns the funded contracts whose inheritor path unlocks
+// within window of now, including those already unlocked, soonest first.
+// Funded contracts whose expiry cannot be computed, and contract files that
+// cannot be loaded, are returned in unknown so they are not silently left out.
+func DueReminders(now time.Time, window time.Duration) (reminders []Reminder, unknown []string, err error) {
+	contractIDs, err := ListContracts()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, contractID := range contractIDs {
+		// An unreadable file may hold a funded contract, so it is reported
+		// rather than skipped or allowed to hide every other reminder
+		contractInfo, err := loadContractFile(contractID)
+		if err != nil {
+			unknown = append(unknown, contractID)
+			continue
+		}
+		if !contractInfo.IsFunded {
+			continue
+		}
+
+		expiry, err := contractInfo.Expiry()
+		if err != nil {
+			unknown = append(unknown, contractID)
+			continue
+		}
+
+		remaining := expiry.Sub(now)
+		if remaining <= window {
+			reminders = append(reminders, Reminder{
+				ContractID: contractID,
+				Label:      contractInfo.Label,
+				Expiry:     expiry,
+				Remaining:  remaining,
+			})
+		}
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].Expiry.Before(reminders[j].Expiry)
+	})
+	return reminders, unknown, nil
+}
@@ -0,0 +1,97 @@
+package contract
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to save a funded contract with a real redeem script
+func saveFundedContract(t *testing.T, contractID string, timelockDays, fundingBlockTime int64) {
+	t.Helper()
+
+	ownerKey, _ := btcec.NewPrivateKey()
+	inheritorKey, _ := btcec.NewPrivateKey()
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		timelockDays,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	contractInfo := saveTestContract(t, contractID)
+	contractInfo.RedeemScript = hex.EncodeToString(inheritanceScript.RedeemScript)
+	contractInfo.TimelockDays = timelockDays
+	contractInfo.IsFunded = true
+	contractInfo.FundingTxID = "abcd"
+	contractInfo.FundingBlockTime = fundingBlockTime
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+}
+
+func TestContractInfo_Expiry(t *testing.T) {
+	useTempContractsDir(t)
+	fundedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	saveFundedContract(t, "testnet_expiry01", 180, fundedAt.Unix())
+
+	contractInfo, err := LoadContractInfo("testnet_expiry01")
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+
+	expiry, err := contractInfo.Expiry()
+	if err != nil {
+		t.Fatalf("Expiry failed: %v", err)
+	}
+
+	// 180 days is 30375 intervals of 512 seconds, rounded down
+	expected := fundedAt.Add(30375 * 512 * time.Second)
+	if !expiry.Equal(expected) {
+		t.Errorf("Expected expiry %s, got %s", expected, expiry)
+	}
+
+	// An unknown funding time must not produce an expiry
+	contractInfo.FundingBlockTime = 0
+	if _, err := contractInfo.Expiry(); err == nil {
+		t.Error("Expected an error for an unknown funding time")
+	}
+}
+
+func TestDueReminders(t *testing.T) {
+	useTempContractsDir(t)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := int64(24 * 60 * 60)
+
+	saveFundedContract(t, "testnet_soon0001", 180, now.Unix()-170*day)
+	saveFundedContract(t, "testnet_late0001", 180, now.Unix()-10*day)
+	saveFundedContract(t, "testnet_past0001", 30, now.Unix()-40*day)
+	saveFundedContract(t, "testnet_unconfrm", 180, 0)
+	saveTestContract(t, "testnet_unfunded")
+
+	reminders, unknown, err := DueReminders(now, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("DueReminders failed: %v", err)
+	}
+
+	if len(reminders) != 2 {
+		t.Fatalf("Expected 2 reminders, got %+v", reminders)
+	}
+	if reminders[0].ContractID != "testnet_past0001" || reminders[0].Remaining >= 0 {
+		t.Errorf("Expected the unlocked contract first, got %+v", reminders[0])
+	}
+	if reminders[1].ContractID != "testnet_soon0001" || reminders[1].Remaining <= 0 {
+		t.Errorf("Expected the contract unlocking soon second, got %+v", reminders[1])
+	}
+
+	if len(unknown) != 1 || unknown[0] != "testnet_unconfrm" {
+		t.Errorf("Expected [testnet_unconfrm] with unknown expiry, got %v", unknown)
+	}
+}
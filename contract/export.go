@@ -0,0 +1,101 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// ExportContract writes a single contract into a bundle file, in the format
+// of ExportBundle, so it can be moved to another machine with ImportContract.
+// Keys already encrypted stay encrypted; with a passphrase, keys stored in
+// plaintext are encrypted in the exported file, leaving the local contract
+// unchanged.
+func ExportContract(contractID, path string, passphrase []byte) error {
+	contractInfo, err := loadContractFile(contractID)
+	if err != nil {
+		return fmt.Errorf("failed to load contract %s: %w", contractID, err)
+	}
+
+	if passphrase != nil && (contractInfo.OwnerWIF != "" || contractInfo.InheritorWIF != "") {
+		contractInfo.SetPassphrase(passphrase)
+		if contractInfo, err = contractInfo.sealed(); err != nil {
+			return err
+		}
+	}
+
+	return writeBundle(path, &Bundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now(),
+		Contracts:  []*ContractInfo{contractInfo},
+	})
+}
+
+// ImportContract installs the contract of a file written by ExportContract
+// into the contracts directory and returns it. The contract must be for the
+// network of chainParams and its address must match its redeem script. An
+// existing contract with the same ID is only replaced with force.
+func ImportContract(path string, chainParams *chaincfg.Params, force bool) (*ContractInfo, error) {
+	bundle, err := readBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundle.Contracts) != 1 {
+		return nil, fmt.Errorf("expected a single contract, the file holds %d (use import-all for bundles)", len(bundle.Contracts))
+	}
+
+	contractInfo := bundle.Contracts[0]
+	if err := validateImport(contractInfo, chainParams); err != nil {
+		return nil, err
+	}
+
+	existing := filepath.Join(ContractsDir, contractInfo.ContractID+".json")
+	if _, err := os.Stat(existing); err == nil && !force {
+		return nil, fmt.Errorf("contract %s already exists; use --force to overwrite it", contractInfo.ContractID)
+	}
+
+	if err := SaveContractInfo(contractInfo); err != nil {
+		return nil, fmt.Errorf("failed to import contract %s: %w", contractInfo.ContractID, err)
+	}
+	return contractInfo, nil
+}
+
+// validateImport checks that an imported contract can be installed and used
+// on the network of chainParams
+func validateImport(contractInfo *ContractInfo, chainParams *chaincfg.Params) error {
+	if err := validateContractID(contractInfo.ContractID); err != nil {
+		return err
+	}
+	if contractInfo.Network != chainParams.Name {
+		return fmt.Errorf("contract %s is for %s, not %s", contractInfo.ContractID, contractInfo.Network, chainParams.Name)
+	}
+
+	addressType, err := parseContractAddressType(contractInfo)
+	if err != nil {
+		return err
+	}
+	contractInfo.AddressType = addressType
+
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("invalid redeem script of contract %s: %w", contractInfo.ContractID, err)
+	}
+	if _, err := script.ParseRedeemScript(redeemScript, chainParams); err != nil {
+		return fmt.Errorf("invalid redeem script of contract %s: %w", contractInfo.ContractID, err)
+	}
+	inheritanceScript := &script.InheritanceScript{RedeemScript: redeemScript, ChainParams: chainParams}
+	addr, err := inheritanceScript.GetAddress(addressType)
+	if err != nil {
+		return err
+	}
+	if addr.EncodeAddress() != contractInfo.P2WSHAddress {
+		return fmt.Errorf("address %s of contract %s does not match its redeem script (%s)",
+			contractInfo.P2WSHAddress, contractInfo.ContractID, addr.EncodeAddress())
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package contract
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Test helper to save a contract with keys, funding and history to export
+func saveExportContract(t *testing.T, contractID string) *ContractInfo {
+	t.Helper()
+
+	saveStatusContract(t, contractID, 180, "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807")
+	if err := updateContract(contractID, func(contractInfo *ContractInfo) {
+		contractInfo.Label = "family savings"
+		contractInfo.OwnerWIF = testOwnerWIF
+		contractInfo.InheritorWIF = testInheritorWIF
+		contractInfo.FundingBlockTime = 1767225600
+	}); err != nil {
+		t.Fatalf("updateContract failed: %v", err)
+	}
+	record := WithdrawalRecord{TxID: "abcd", Path: "refresh", Fee: 500, BroadcastAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := RecordWithdrawal(contractID, record); err != nil {
+		t.Fatalf("RecordWithdrawal failed: %v", err)
+	}
+
+	contractInfo, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	return contractInfo
+}
+
+func TestExportImportContract_RoundTrip(t *testing.T) {
+	for _, name := range []string{"contract.json", "contract.json.gz"} {
+		t.Run(name, func(t *testing.T) {
+			useTempContractsDir(t)
+			contractID := "testnet_export01"
+			original := saveExportContract(t, contractID)
+
+			path := filepath.Join(t.TempDir(), name)
+			if err := ExportContract(contractID, path, nil); err != nil {
+				t.Fatalf("ExportContract failed: %v", err)
+			}
+
+			// Import on a "different machine" with an empty contracts directory
+			useTempContractsDir(t)
+			imported, err := ImportContract(path, &chaincfg.TestNet3Params, false)
+			if err != nil {
+				t.Fatalf("ImportContract failed: %v", err)
+			}
+			if imported.ContractID != contractID {
+				t.Errorf("Expected contract %s, got %s", contractID, imported.ContractID)
+			}
+
+			loaded, err := LoadContractInfo(contractID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, original) {
+				t.Errorf("Imported contract differs from the exported one:\n got %+v\nwant %+v", loaded, original)
+			}
+		})
+	}
+}
+
+func TestExportContract_Encrypted(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_export02"
+	original := saveExportContract(t, contractID)
+
+	path := filepath.Join(t.TempDir(), "contract.json")
+	if err := ExportContract(contractID, path, []byte("correct horse")); err != nil {
+		t.Fatalf("ExportContract failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if strings.Contains(string(data), testOwnerWIF) || strings.Contains(string(data), testInheritorWIF) {
+		t.Error("Encrypted export contains a plaintext WIF")
+	}
+
+	// The local contract keeps its plaintext keys
+	local, err := loadContractFile(contractID)
+	if err != nil {
+		t.Fatalf("loadContractFile failed: %v", err)
+	}
+	if local.IsEncrypted() {
+		t.Error("Exporting encrypted the local contract")
+	}
+
+	useTempContractsDir(t)
+	usePassphrase(t, "correct horse")
+	if _, err := ImportContract(path, &chaincfg.TestNet3Params, false); err != nil {
+		t.Fatalf("ImportContract failed: %v", err)
+	}
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if loaded.OwnerWIF != original.OwnerWIF || loaded.InheritorWIF != original.InheritorWIF {
+		t.Error("Imported keys do not decrypt to the exported ones")
+	}
+}
+
+func TestImportContract_Refusals(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_export03"
+	saveExportContract(t, contractID)
+
+	path := filepath.Join(t.TempDir(), "contract.json")
+	if err := ExportContract(contractID, path, nil); err != nil {
+		t.Fatalf("ExportContract failed: %v", err)
+	}
+
+	// An existing contract is only replaced with force
+	if _, err := ImportContract(path, &chaincfg.TestNet3Params, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an error for an existing contract, got %v", err)
+	}
+	if _, err := ImportContract(path, &chaincfg.TestNet3Params, true); err != nil {
+		t.Errorf("ImportContract with force failed: %v", err)
+	}
+
+	useTempContractsDir(t)
+	if _, err := ImportContract(path, &chaincfg.MainNetParams, false); err == nil || !strings.Contains(err.Error(), "is for testnet3") {
+		t.Errorf("Expected an error for another network, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	// Taproot contracts cannot be spent by the withdraw commands
+	taprootPath := filepath.Join(t.TempDir(), "taproot.json")
+	taproot := strings.Replace(string(data), `"redeem_script"`, `"address_type": "p2tr", "redeem_script"`, 1)
+	if err := os.WriteFile(taprootPath, []byte(taproot), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+	if _, err := ImportContract(taprootPath, &chaincfg.TestNet3Params, false); err == nil || !strings.Contains(err.Error(), "p2tr") {
+		t.Errorf("Expected an error for a p2tr contract, got %v", err)
+	}
+
+	// A tampered address no longer matches the redeem script
+	bundle, err := readBundle(path)
+	if err != nil {
+		t.Fatalf("readBundle failed: %v", err)
+	}
+	tampered := strings.Replace(string(data), bundle.Contracts[0].P2WSHAddress, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+	if _, err := ImportContract(path, &chaincfg.TestNet3Params, false); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected an error for a tampered address, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ContractsDir, contractID+".json")); !os.IsNotExist(err) {
+		t.Error("A refused contract was installed")
+	}
+}
+
+func TestImportContract_UnsupportedVersion(t *testing.T) {
+	useTempContractsDir(t)
+	path := filepath.Join(t.TempDir(), "contract.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "contracts": []}`), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := ImportContract(path, &chaincfg.TestNet3Params, false); err == nil || !strings.Contains(err.Error(), "unsupported bundle version") {
+		t.Errorf("Expected an unsupported version error, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package contract
+
+import "fmt"
+
+// NextKeyIndex returns the key index for the next contract derived from the
+// master key with fingerprint: one past the highest index of the saved
+// contracts derived from it, or zero if there are none
+func NextKeyIndex(fingerprint string) (uint32, error) {
+	contractIDs, err := ListContracts()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list contracts: %w", err)
+	}
+
+	var next uint32
+	for _, contractID := range contractIDs {
+		// Unreadable files are left to repair
+		contractInfo, err := loadContractFile(contractID)
+		if err != nil {
+			continue
+		}
+		if contractInfo.KeyFingerprint == fingerprint && contractInfo.KeyIndex >= next {
+			next = contractInfo.KeyIndex + 1
+		}
+	}
+	return next, nil
+}
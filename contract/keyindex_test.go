@@ -0,0 +1,59 @@
+package contract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNextKeyIndex(t *testing.T) {
+	useTempContractsDir(t)
+
+	next, err := NextKeyIndex("3442193e")
+	if err != nil {
+		t.Fatalf("NextKeyIndex failed: %v", err)
+	}
+	if next != 0 {
+		t.Errorf("Expected index 0 without contracts, got %d", next)
+	}
+
+	for _, c := range []struct {
+		contractID  string
+		fingerprint string
+		index       uint32
+	}{
+		{"testnet_aaaaaaaa", "3442193e", 0},
+		{"testnet_bbbbbbbb", "3442193e", 2},
+		{"testnet_cccccccc", "deadbeef", 7},
+		{"testnet_dddddddd", "", 0},
+	} {
+		contractInfo := saveTestContract(t, c.contractID)
+		contractInfo.KeySource = KeySourceSeed
+		contractInfo.KeyFingerprint = c.fingerprint
+		contractInfo.KeyIndex = c.index
+		if err := SaveContractInfo(contractInfo); err != nil {
+			t.Fatalf("SaveContractInfo failed: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(ContractsDir, "testnet_broken00.json"), []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write broken contract: %v", err)
+	}
+
+	tests := []struct {
+		fingerprint string
+		want        uint32
+	}{
+		{"3442193e", 3},
+		{"deadbeef", 8},
+		{"01020304", 0},
+	}
+	for _, tt := range tests {
+		next, err := NextKeyIndex(tt.fingerprint)
+		if err != nil {
+			t.Fatalf("NextKeyIndex failed: %v", err)
+		}
+		if next != tt.want {
+			t.Errorf("NextKeyIndex(%s) = %d, want %d", tt.fingerprint, next, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package contract
+
+import (
+	"fmt"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+)
+
+// CheckTimelockMatured reports whether a relative timelock has matured for a
+// funding UTXO, judged by the confirmations of its transaction on backend,
+// and otherwise how many more blocks are needed. A time-based lock is
+// converted to blocks with transaction.TimelockBlocks, so for those the
+// answer is approximate.
+func CheckTimelockMatured(backend rpc.ChainBackend, utxo *transaction.UTXO, relativeTimelock int64) (bool, int64, error) {
+	tx, err := backend.GetRawTransaction(utxo.TxHash.String())
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to fetch funding transaction: %w", err)
+	}
+
+	// A spend can be mined in the next block once the funding transaction
+	// has as many confirmations as the lock requires
+	remaining := transaction.TimelockBlocks(relativeTimelock) - tx.Confirmations
+	if remaining > 0 {
+		return false, remaining, nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,55 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+)
+
+func TestCheckTimelockMatured(t *testing.T) {
+	const txid = "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807"
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		t.Fatalf("Failed to parse txid: %v", err)
+	}
+	utxo := &transaction.UTXO{TxHash: hash}
+
+	tests := []struct {
+		name             string
+		relativeTimelock int64
+		confirmations    int64
+		matured          bool
+		remaining        int64
+	}{
+		{"unconfirmed", 144, 0, false, 144},
+		{"one block short", 144, 143, false, 1},
+		{"exactly matured", 144, 144, true, 0},
+		{"long matured", 144, 5000, true, 0},
+		// One day of 512-second intervals is about 145 blocks
+		{"time-based immature", 169 | 0x400000, 100, false, 45},
+		{"time-based matured", 169 | 0x400000, 145, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &stubBackend{txs: map[string]*rpc.RawTransaction{
+				txid: {TxID: txid, Confirmations: tt.confirmations},
+			}}
+
+			matured, remaining, err := CheckTimelockMatured(backend, utxo, tt.relativeTimelock)
+			if err != nil {
+				t.Fatalf("CheckTimelockMatured failed: %v", err)
+			}
+			if matured != tt.matured || remaining != tt.remaining {
+				t.Errorf("Expected matured=%v with %d blocks remaining, got matured=%v with %d",
+					tt.matured, tt.remaining, matured, remaining)
+			}
+		})
+	}
+
+	if _, _, err := CheckTimelockMatured(&stubBackend{}, utxo, 144); err == nil {
+		t.Error("Expected an error for an unknown funding transaction")
+	}
+}
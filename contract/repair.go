@@ -0,0 +1,72 @@
+package contract
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// QuarantineDir is the subdirectory of ContractsDir that unreadable contract
+// files are moved into, out of the way of every other command
+const QuarantineDir = "quarantine"
+
+// InvalidContract is a saved contract file that cannot be loaded, e.g. one
+// left truncated by a crash or a full disk
+type InvalidContract struct {
+	ContractID string
+	Err        error
+}
+
+// CheckContracts loads every saved contract and returns those that fail to load
+func CheckContracts() ([]InvalidContract, error) {
+	contractIDs, err := ListContracts()
+	if err != nil {
+		return nil, err
+	}
+
+	var invalid []InvalidContract
+	for _, contractID := range contractIDs {
+		if _, err := loadContractFile(contractID); err != nil {
+			invalid = append(invalid, InvalidContract{ContractID: contractID, Err: err})
+		}
+	}
+	return invalid, nil
+}
+
+// QuarantineContract moves a contract file into QuarantineDir under a
+// timestamped name, so it is kept for inspection but no longer listed. It
+// returns the new path.
+func QuarantineContract(contractID string) (string, error) {
+	if err := validateContractID(contractID); err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(ContractsDir, QuarantineDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+
+	source := filepath.Join(ContractsDir, contractID+".json")
+	target := filepath.Join(dir, fmt.Sprintf("%s.%s.json", contractID, time.Now().Format("20060102-150405")))
+	if err := os.Rename(source, target); err != nil {
+		return "", fmt.Errorf("failed to quarantine contract %s: %w", contractID, err)
+	}
+	return target, nil
+}
+
+// BundleContract returns the contract with the given ID from a bundle file,
+// or nil if the bundle does not contain it
+func BundleContract(path, contractID string) (*ContractInfo, error) {
+	bundle, err := readBundle(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, contractInfo := range bundle.Contracts {
+		if contractInfo.ContractID == contractID {
+			return contractInfo, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,107 @@
+package contract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test helper to truncate a saved contract file as an interrupted write would
+func truncateContractFile(t *testing.T, contractID string) {
+	t.Helper()
+
+	path := filepath.Join(ContractsDir, contractID+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read contract file: %v", err)
+	}
+	if err := os.WriteFile(path, data[:len(data)/2], 0644); err != nil {
+		t.Fatalf("Failed to truncate contract file: %v", err)
+	}
+}
+
+func TestCheckContracts(t *testing.T) {
+	useTempContractsDir(t)
+	saveTestContract(t, "testnet_aaaaaaaa")
+	saveTestContract(t, "testnet_bbbbbbbb")
+	truncateContractFile(t, "testnet_bbbbbbbb")
+
+	invalid, err := CheckContracts()
+	if err != nil {
+		t.Fatalf("CheckContracts failed: %v", err)
+	}
+	if len(invalid) != 1 || invalid[0].ContractID != "testnet_bbbbbbbb" || invalid[0].Err == nil {
+		t.Fatalf("Expected testnet_bbbbbbbb to be reported, got %+v", invalid)
+	}
+}
+
+func TestQuarantineContract(t *testing.T) {
+	useTempContractsDir(t)
+	saveTestContract(t, "testnet_aaaaaaaa")
+	truncateContractFile(t, "testnet_aaaaaaaa")
+
+	quarantined, err := QuarantineContract("testnet_aaaaaaaa")
+	if err != nil {
+		t.Fatalf("QuarantineContract failed: %v", err)
+	}
+	if filepath.Dir(quarantined) != filepath.Join(ContractsDir, QuarantineDir) {
+		t.Errorf("Expected the file in the quarantine directory, got %s", quarantined)
+	}
+	if _, err := os.Stat(quarantined); err != nil {
+		t.Errorf("Quarantined file is missing: %v", err)
+	}
+
+	// The quarantine directory is not listed as a contract
+	contractIDs, err := ListContracts()
+	if err != nil {
+		t.Fatalf("ListContracts failed: %v", err)
+	}
+	if len(contractIDs) != 0 {
+		t.Errorf("Expected no contracts after quarantine, got %v", contractIDs)
+	}
+
+	if _, err := QuarantineContract("../escape"); err == nil {
+		t.Error("Expected an invalid contract ID to be rejected")
+	}
+}
+
+func TestBundleContract(t *testing.T) {
+	useTempContractsDir(t)
+	saveTestContract(t, "testnet_aaaaaaaa")
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.json")
+	if _, err := ExportBundle(bundlePath, false); err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+
+	found, err := BundleContract(bundlePath, "testnet_aaaaaaaa")
+	if err != nil {
+		t.Fatalf("BundleContract failed: %v", err)
+	}
+	if found == nil || found.ContractID != "testnet_aaaaaaaa" {
+		t.Errorf("Expected testnet_aaaaaaaa from the bundle, got %+v", found)
+	}
+
+	missing, err := BundleContract(bundlePath, "testnet_zzzzzzzz")
+	if err != nil {
+		t.Fatalf("BundleContract failed: %v", err)
+	}
+	if missing != nil {
+		t.Errorf("Expected no contract for an unknown ID, got %+v", missing)
+	}
+}
+
+func TestDueReminders_UnreadableContract(t *testing.T) {
+	useTempContractsDir(t)
+	saveTestContract(t, "testnet_aaaaaaaa")
+	truncateContractFile(t, "testnet_aaaaaaaa")
+
+	_, unknown, err := DueReminders(time.Now(), 0)
+	if err != nil {
+		t.Fatalf("DueReminders failed: %v", err)
+	}
+	if len(unknown) != 1 || unknown[0] != "testnet_aaaaaaaa" {
+		t.Errorf("Expected the unreadable contract in unknown, got %v", unknown)
+	}
+}
@@ -0,0 +1,102 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+)
+
+// FindContractUTXOs looks up the unspent outputs paying the address of a
+// saved contract through backend and returns them as contract UTXOs, ordered
+// by txid and output index
+func FindContractUTXOs(contractID string, backend rpc.ChainBackend, chainParams *chaincfg.Params) ([]*transaction.UTXO, error) {
+	contractInfo, err := loadContractFile(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := findContractOutputs(contractInfo, backend, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]*transaction.UTXO, len(outputs))
+	for i, output := range outputs {
+		utxos[i] = output.utxo
+	}
+	return utxos, nil
+}
+
+// contractOutput is an unspent output paying a contract and its confirmations
+type contractOutput struct {
+	utxo          *transaction.UTXO
+	confirmations int64
+}
+
+// findContractOutputs looks up the unspent outputs paying a contract's
+// address through backend, ordered by txid and output index
+func findContractOutputs(contractInfo *ContractInfo, backend rpc.ChainBackend, chainParams *chaincfg.Params) ([]contractOutput, error) {
+	addr, err := btcutil.DecodeAddress(contractInfo.P2WSHAddress, chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address %q: %w", contractInfo.P2WSHAddress, err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build contract script: %w", err)
+	}
+
+	unspent, err := backend.ListUnspent(contractInfo.P2WSHAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up contract address: %w", err)
+	}
+
+	outputs := make([]contractOutput, 0, len(unspent))
+	for _, output := range unspent {
+		if output.ScriptPubKey != "" {
+			outputScript, err := hex.DecodeString(output.ScriptPubKey)
+			if err != nil || !bytes.Equal(outputScript, pkScript) {
+				return nil, fmt.Errorf("unspent output %s:%d does not pay the contract script", output.TxID, output.Vout)
+			}
+		}
+
+		txHash, err := chainhash.NewHashFromStr(output.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %q: %w", output.TxID, err)
+		}
+
+		amount, err := output.AmountSats()
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of %s:%d: %w", output.TxID, output.Vout, err)
+		}
+
+		outputs = append(outputs, contractOutput{
+			utxo: &transaction.UTXO{
+				TxHash:   txHash,
+				Vout:     output.Vout,
+				Amount:   btcutil.Amount(amount),
+				PkScript: pkScript,
+
+				AddressType: contractInfo.AddressType,
+			},
+			confirmations: output.Confirmations,
+		})
+	}
+
+	sort.Slice(outputs, func(i, j int) bool {
+		a, b := outputs[i].utxo, outputs[j].utxo
+		if *a.TxHash != *b.TxHash {
+			return a.TxHash.String() < b.TxHash.String()
+		}
+		return a.Vout < b.Vout
+	})
+
+	return outputs, nil
+}
@@ -0,0 +1,155 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+)
+
+// stubBackend is a chain backend returning fixed unspent outputs and
+// transactions
+type stubBackend struct {
+	unspent []*rpc.UTXO
+	txs     map[string]*rpc.RawTransaction
+	height  int64
+}
+
+func (s *stubBackend) ListUnspent(address string) ([]*rpc.UTXO, error) {
+	return s.unspent, nil
+}
+
+func (s *stubBackend) BroadcastTransaction(tx *wire.MsgTx) (string, error) {
+	return tx.TxHash().String(), nil
+}
+
+func (s *stubBackend) GetBlockCount() (int64, error) {
+	return s.height, nil
+}
+
+func (s *stubBackend) GetRawTransaction(txid string) (*rpc.RawTransaction, error) {
+	if tx, ok := s.txs[txid]; ok {
+		return tx, nil
+	}
+	return nil, fmt.Errorf("transaction %s not found", txid)
+}
+
+func TestFindContractUTXOs(t *testing.T) {
+	useTempContractsDir(t)
+	params := &chaincfg.TestNet3Params
+
+	addr, err := btcutil.NewAddressWitnessScriptHash(make([]byte, 32), params)
+	if err != nil {
+		t.Fatalf("Failed to create address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+	contractID := "testnet_scan0001"
+	if err := SaveContractInfo(&ContractInfo{
+		ContractID:   contractID,
+		CreatedAt:    time.Now(),
+		Network:      "testnet3",
+		P2WSHAddress: addr.EncodeAddress(),
+	}); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	const (
+		firstTxID  = "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807"
+		secondTxID = "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001"
+	)
+	backend := &stubBackend{unspent: []*rpc.UTXO{
+		{TxID: firstTxID, Vout: 1, Amount: 0.1, ScriptPubKey: hex.EncodeToString(pkScript)},
+		{TxID: secondTxID, Vout: 0, Amount: 0.00012345},
+	}}
+
+	utxos, err := FindContractUTXOs(contractID, backend, params)
+	if err != nil {
+		t.Fatalf("FindContractUTXOs failed: %v", err)
+	}
+	if len(utxos) != 2 {
+		t.Fatalf("Expected 2 UTXOs, got %d", len(utxos))
+	}
+
+	// Ordered by txid, with float BTC amounts converted to exact satoshis
+	expected := []struct {
+		txid   string
+		vout   uint32
+		amount btcutil.Amount
+	}{
+		{secondTxID, 0, 12345},
+		{firstTxID, 1, 10000000},
+	}
+	for i, want := range expected {
+		utxo := utxos[i]
+		if utxo.TxHash.String() != want.txid || utxo.Vout != want.vout {
+			t.Errorf("UTXO %d: expected %s:%d, got %s:%d", i, want.txid, want.vout, utxo.TxHash, utxo.Vout)
+		}
+		if utxo.Amount != want.amount {
+			t.Errorf("UTXO %d: expected %d satoshis, got %d", i, want.amount, utxo.Amount)
+		}
+		if hex.EncodeToString(utxo.PkScript) != hex.EncodeToString(pkScript) {
+			t.Errorf("UTXO %d: expected script %x, got %x", i, pkScript, utxo.PkScript)
+		}
+	}
+
+	// An output paying another script is refused
+	backend.unspent[1].ScriptPubKey = "0014" + strings.Repeat("00", 20)
+	if _, err := FindContractUTXOs(contractID, backend, params); err == nil {
+		t.Error("Expected an error for an output not paying the contract")
+	}
+}
+
+func TestSetFundingUTXOs(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_abcdefgh"
+	saveTestContract(t, contractID)
+
+	if err := UpdateFundingStatus(contractID, "aaaa", 0, 50000); err != nil {
+		t.Fatalf("UpdateFundingStatus failed: %v", err)
+	}
+	if err := UpdateFundingBlockTime(contractID, 1700000000); err != nil {
+		t.Fatalf("UpdateFundingBlockTime failed: %v", err)
+	}
+
+	// Keeping the first UTXO keeps its block time
+	outpoints := []FundingOutpoint{{TxID: "aaaa", Vout: 0, Amount: 50000}, {TxID: "bbbb", Vout: 1, Amount: 20000}}
+	if err := SetFundingUTXOs(contractID, outpoints); err != nil {
+		t.Fatalf("SetFundingUTXOs failed: %v", err)
+	}
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if len(loaded.FundingUTXOs) != 2 || loaded.TotalFunding() != 70000 {
+		t.Errorf("Expected 2 funding UTXOs totalling 70000, got %+v", loaded.FundingUTXOs)
+	}
+	if loaded.FundingBlockTime != 1700000000 {
+		t.Errorf("Expected the funding block time to be kept, got %d", loaded.FundingBlockTime)
+	}
+
+	// A different first UTXO restarts the clock
+	if err := SetFundingUTXOs(contractID, outpoints[1:]); err != nil {
+		t.Fatalf("SetFundingUTXOs failed: %v", err)
+	}
+	loaded, err = LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if loaded.FundingTxID != "bbbb" || loaded.FundingAmount != 20000 || loaded.FundingBlockTime != 0 {
+		t.Errorf("Expected bbbb:1 with the block time reset, got %+v", loaded)
+	}
+
+	if err := SetFundingUTXOs(contractID, nil); err == nil {
+		t.Error("Expected an error for no funding UTXOs")
+	}
+}
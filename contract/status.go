@@ -0,0 +1,152 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+)
+
+// ChainState is where a contract stands on chain
+type ChainState string
+
+// Chain states reported by CheckChainStatus
+const (
+	// StateUnfunded: nothing pays the address and no funding was ever recorded
+	StateUnfunded ChainState = "unfunded"
+	// StateFunded: unspent outputs pay the address, but the inheritor's
+	// timelock has not matured yet
+	StateFunded ChainState = "funded"
+	// StateMatured: unspent outputs pay the address and the inheritor can spend
+	StateMatured ChainState = "matured"
+	// StateSpent: funding was recorded, but no unspent output is left
+	StateSpent ChainState = "spent"
+)
+
+// OutputStatus is an unspent output paying a contract
+type OutputStatus struct {
+	TxID          string `json:"txid"`
+	Vout          uint32 `json:"vout"`
+	Amount        int64  `json:"amount"` // satoshis
+	Confirmations int64  `json:"confirmations"`
+}
+
+// ChainStatus is a contract's state as seen by a chain backend
+type ChainStatus struct {
+	ContractID string         `json:"contract_id"`
+	State      ChainState     `json:"state"`
+	Outputs    []OutputStatus `json:"outputs,omitempty"`
+	Amount     int64          `json:"amount"` // satoshis, of all unspent outputs
+	TipHeight  int64          `json:"tip_height"`
+
+	// Confirmations counts those of the most recently confirmed output,
+	// which is the last to mature; zero while any output is unconfirmed
+	Confirmations int64 `json:"confirmations"`
+
+	// BlocksRemaining is how many more blocks the inheritor's timelock
+	// runs, zero once a spend can be mined in the next block. For time-based
+	// timelocks it is estimated at script.BlockInterval per block.
+	BlocksRemaining int64 `json:"blocks_remaining"`
+}
+
+// CheckChainStatus looks up the unspent outputs paying a saved contract's
+// address through backend and reports its funding and how many blocks its
+// inheritor timelock still runs at the chain tip (time-based locks measured
+// from now). The contract file is updated to match: the outputs found become
+// its funding UTXOs, and a funded contract with none left is marked unfunded,
+// keeping the recorded funding as the spent state's evidence. With a node
+// backend, the node's wallet must watch the address.
+func CheckChainStatus(contractID string, backend rpc.ChainBackend, chainParams *chaincfg.Params, now time.Time) (*ChainStatus, error) {
+	contractInfo, err := loadContractFile(contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := findContractOutputs(contractInfo, backend, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	tipHeight, err := backend.GetBlockCount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block count: %w", err)
+	}
+
+	status := &ChainStatus{ContractID: contractID, TipHeight: tipHeight}
+	if len(outputs) == 0 {
+		status.State = StateUnfunded
+		if contractInfo.FundingTxID != "" || len(contractInfo.Withdrawals) > 0 {
+			status.State = StateSpent
+		}
+		if contractInfo.IsFunded {
+			if err := updateContract(contractID, func(contractInfo *ContractInfo) {
+				contractInfo.IsFunded = false
+			}); err != nil {
+				return nil, fmt.Errorf("failed to update funding status: %w", err)
+			}
+		}
+		return status, nil
+	}
+
+	outpoints := make([]FundingOutpoint, len(outputs))
+	for i, output := range outputs {
+		outpoint := FundingOutpoint{TxID: output.utxo.TxHash.String(), Vout: output.utxo.Vout, Amount: int64(output.utxo.Amount)}
+		outpoints[i] = outpoint
+		status.Outputs = append(status.Outputs, OutputStatus{
+			TxID:          outpoint.TxID,
+			Vout:          outpoint.Vout,
+			Amount:        outpoint.Amount,
+			Confirmations: output.confirmations,
+		})
+		status.Amount += outpoint.Amount
+		if i == 0 || output.confirmations < status.Confirmations {
+			status.Confirmations = output.confirmations
+		}
+	}
+	if err := SetFundingUTXOs(contractID, outpoints); err != nil {
+		return nil, fmt.Errorf("failed to update funding status: %w", err)
+	}
+
+	status.BlocksRemaining, err = timelockBlocksRemaining(contractInfo, status.Confirmations, tipHeight, now)
+	if err != nil {
+		return nil, err
+	}
+	status.State = StateFunded
+	if status.Confirmations > 0 && status.BlocksRemaining == 0 {
+		status.State = StateMatured
+	}
+	return status, nil
+}
+
+// timelockBlocksRemaining returns how many more blocks a contract's inheritor
+// timelock runs for funding with the given confirmations. An unconfirmed
+// relative timelock has not started, so all of it remains.
+func timelockBlocksRemaining(contractInfo *ContractInfo, confirmations, tipHeight int64, now time.Time) (int64, error) {
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	parsed, err := script.ParseRedeemScript(redeemScript, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read timelock from redeem script: %w", err)
+	}
+
+	var remaining int64
+	switch {
+	case parsed.TimelockType == script.Relative:
+		remaining = transaction.TimelockBlocks(parsed.RelativeTimelock) - confirmations
+	case script.IsLockTimeTimestamp(parsed.LockTime):
+		interval := int64(script.BlockInterval / time.Second)
+		remaining = (parsed.LockTime - now.Unix() + interval - 1) / interval
+	default:
+		// A height lock time lets a spend into the block above it
+		remaining = parsed.LockTime - tipHeight
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, nil
+}
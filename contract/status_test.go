@@ -0,0 +1,167 @@
+package contract
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to save a contract with a real redeem script and address,
+// funded with fundingTxID unless it is empty
+func saveStatusContract(t *testing.T, contractID string, timelockDays int64, fundingTxID string) {
+	t.Helper()
+
+	ownerKey, _ := btcec.NewPrivateKey()
+	inheritorKey, _ := btcec.NewPrivateKey()
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		timelockDays,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	addr, err := inheritanceScript.GetP2WSHAddress()
+	if err != nil {
+		t.Fatalf("GetP2WSHAddress failed: %v", err)
+	}
+
+	contractInfo := &ContractInfo{
+		ContractID:   contractID,
+		CreatedAt:    time.Now(),
+		Network:      "testnet3",
+		TimelockDays: timelockDays,
+		RedeemScript: hex.EncodeToString(inheritanceScript.RedeemScript),
+		P2WSHAddress: addr.EncodeAddress(),
+	}
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+	if fundingTxID != "" {
+		if err := UpdateFundingStatus(contractID, fundingTxID, 0, 50000); err != nil {
+			t.Fatalf("UpdateFundingStatus failed: %v", err)
+		}
+	}
+}
+
+func TestCheckChainStatus(t *testing.T) {
+	const (
+		recordedTxID = "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807"
+		newTxID      = "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001"
+	)
+
+	// 180 days of 512-second intervals is estimated at 25920 blocks
+	tests := []struct {
+		name          string
+		recorded      string // funding txid recorded before the check
+		unspent       []*rpc.UTXO
+		state         ChainState
+		amount        int64
+		confirmations int64
+		remaining     int64
+		funded        bool // IsFunded afterwards
+	}{
+		{"unfunded", "", nil, StateUnfunded, 0, 0, 0, false},
+		{"funded, unconfirmed", "", []*rpc.UTXO{
+			{TxID: newTxID, Vout: 1, Amount: 0.001},
+		}, StateFunded, 100000, 0, 25920, true},
+		{"funded, immature", recordedTxID, []*rpc.UTXO{
+			{TxID: recordedTxID, Vout: 0, Amount: 0.0005, Confirmations: 26000},
+			{TxID: newTxID, Vout: 1, Amount: 0.001, Confirmations: 120},
+		}, StateFunded, 150000, 120, 25800, true},
+		{"funded, mature", recordedTxID, []*rpc.UTXO{
+			{TxID: recordedTxID, Vout: 0, Amount: 0.0005, Confirmations: 25920},
+		}, StateMatured, 50000, 25920, 0, true},
+		{"spent", recordedTxID, nil, StateSpent, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempContractsDir(t)
+			contractID := "testnet_status01"
+			saveStatusContract(t, contractID, 180, tt.recorded)
+			backend := &stubBackend{unspent: tt.unspent, height: 800000}
+
+			status, err := CheckChainStatus(contractID, backend, &chaincfg.TestNet3Params, time.Now())
+			if err != nil {
+				t.Fatalf("CheckChainStatus failed: %v", err)
+			}
+			if status.State != tt.state {
+				t.Errorf("Expected state %s, got %s", tt.state, status.State)
+			}
+			if status.Amount != tt.amount || status.Confirmations != tt.confirmations {
+				t.Errorf("Expected %d satoshis with %d confirmations, got %d with %d",
+					tt.amount, tt.confirmations, status.Amount, status.Confirmations)
+			}
+			if status.BlocksRemaining != tt.remaining {
+				t.Errorf("Expected %d blocks remaining, got %d", tt.remaining, status.BlocksRemaining)
+			}
+			if status.TipHeight != 800000 || len(status.Outputs) != len(tt.unspent) {
+				t.Errorf("Expected tip 800000 and %d outputs, got tip %d and %d outputs",
+					len(tt.unspent), status.TipHeight, len(status.Outputs))
+			}
+
+			// The contract file follows the chain
+			contractInfo, err := LoadContractInfo(contractID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			if contractInfo.IsFunded != tt.funded {
+				t.Errorf("Expected IsFunded=%v, got %v", tt.funded, contractInfo.IsFunded)
+			}
+			if tt.funded && contractInfo.TotalFunding() != tt.amount {
+				t.Errorf("Expected recorded funding of %d satoshis, got %d", tt.amount, contractInfo.TotalFunding())
+			}
+		})
+	}
+}
+
+func TestCheckChainStatus_AbsoluteTimelock(t *testing.T) {
+	useTempContractsDir(t)
+	const txid = "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807"
+	lockTime := time.Now().Add(60 * 24 * time.Hour).Truncate(time.Second)
+
+	ownerKey, _ := btcec.NewPrivateKey()
+	inheritorKey, _ := btcec.NewPrivateKey()
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		0,
+		&chaincfg.TestNet3Params,
+		script.WithAbsoluteTimelock(lockTime.Unix()),
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	addr, err := inheritanceScript.GetP2WSHAddress()
+	if err != nil {
+		t.Fatalf("GetP2WSHAddress failed: %v", err)
+	}
+	contractInfo := &ContractInfo{
+		ContractID:   "testnet_status02",
+		CreatedAt:    time.Now(),
+		Network:      "testnet3",
+		RedeemScript: hex.EncodeToString(inheritanceScript.RedeemScript),
+		P2WSHAddress: addr.EncodeAddress(),
+	}
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	backend := &stubBackend{unspent: []*rpc.UTXO{{TxID: txid, Amount: 0.001, Confirmations: 6}}}
+	status, err := CheckChainStatus(contractInfo.ContractID, backend, &chaincfg.TestNet3Params, lockTime.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("CheckChainStatus failed: %v", err)
+	}
+
+	// A day away is estimated at 144 blocks, whatever the confirmations
+	if status.State != StateFunded || status.BlocksRemaining != 144 {
+		t.Errorf("Expected funded with 144 blocks remaining, got %s with %d", status.State, status.BlocksRemaining)
+	}
+}
@@ -0,0 +1,65 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// StoredWithdrawal is a signed withdrawal saved to a file so it can be
+// broadcast later, e.g. an inheritor spend pre-signed before the timelock
+// matures
+type StoredWithdrawal struct {
+	ContractID       string              `json:"contract_id"`
+	Path             string              `json:"path"` // "owner" or "inheritor"
+	FundingTxID      string              `json:"funding_tx_id"`
+	FundingVout      uint32              `json:"funding_vout"`
+	FundingAmount    int64               `json:"funding_amount"` // satoshis
+	RelativeTimelock int64               `json:"relative_timelock"`
+	LockTime         int64               `json:"lock_time,omitempty"` // absolute timelock, replaces RelativeTimelock
+	CreatedAt        time.Time           `json:"created_at"`
+	Transactions     []StoredTransaction `json:"transactions"` // cheapest fee first
+}
+
+// StoredTransaction is one signed alternative of a stored withdrawal
+type StoredTransaction struct {
+	TxID string `json:"txid"`
+	Hex  string `json:"hex"`
+	Fee  int64  `json:"fee"` // satoshis
+}
+
+// SaveStoredWithdrawal writes a stored withdrawal to path
+func SaveStoredWithdrawal(path string, withdrawal *StoredWithdrawal) error {
+	if len(withdrawal.Transactions) == 0 {
+		return fmt.Errorf("stored withdrawal has no transactions")
+	}
+
+	data, err := json.MarshalIndent(withdrawal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stored withdrawal: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write stored withdrawal: %w", err)
+	}
+	return nil
+}
+
+// LoadStoredWithdrawal reads a stored withdrawal from path
+func LoadStoredWithdrawal(path string) (*StoredWithdrawal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stored withdrawal: %w", err)
+	}
+
+	var withdrawal StoredWithdrawal
+	if err := json.Unmarshal(data, &withdrawal); err != nil {
+		return nil, fmt.Errorf("failed to parse stored withdrawal: %w", err)
+	}
+	if len(withdrawal.Transactions) == 0 {
+		return nil, fmt.Errorf("stored withdrawal has no transactions")
+	}
+
+	return &withdrawal, nil
+}
@@ -0,0 +1,46 @@
+package contract
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoredWithdrawal_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claim.json")
+	withdrawal := &StoredWithdrawal{
+		ContractID:       "testnet_aaaaaaaa",
+		Path:             "inheritor",
+		FundingTxID:      "ab",
+		FundingVout:      1,
+		FundingAmount:    100000,
+		RelativeTimelock: 30375 | 0x400000,
+		CreatedAt:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Transactions: []StoredTransaction{
+			{TxID: "t1", Hex: "0200", Fee: 300},
+			{TxID: "t2", Hex: "0201", Fee: 600},
+		},
+	}
+
+	if err := SaveStoredWithdrawal(path, withdrawal); err != nil {
+		t.Fatalf("SaveStoredWithdrawal failed: %v", err)
+	}
+
+	loaded, err := LoadStoredWithdrawal(path)
+	if err != nil {
+		t.Fatalf("LoadStoredWithdrawal failed: %v", err)
+	}
+	if loaded.ContractID != withdrawal.ContractID || loaded.RelativeTimelock != withdrawal.RelativeTimelock {
+		t.Errorf("Loaded withdrawal does not match: %+v", loaded)
+	}
+	if len(loaded.Transactions) != 2 || loaded.Transactions[1].Fee != 600 {
+		t.Errorf("Loaded transactions do not match: %+v", loaded.Transactions)
+	}
+}
+
+func TestSaveStoredWithdrawal_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claim.json")
+	if err := SaveStoredWithdrawal(path, &StoredWithdrawal{}); err == nil {
+		t.Error("Expected error for withdrawal without transactions")
+	}
+}
@@ -0,0 +1,28 @@
+package contract
+
+import "fmt"
+
+// checkWatchOnly refuses to save private keys in a watch-only contract
+func (c *ContractInfo) checkWatchOnly() error {
+	if !c.IsWatchOnly {
+		return nil
+	}
+	if c.OwnerWIF != "" || c.InheritorWIF != "" || c.OwnerWIFEncrypted != "" || c.InheritorWIFEncrypted != "" {
+		return fmt.Errorf("contract %s is watch-only and must not store private keys", c.ContractID)
+	}
+	return nil
+}
+
+// KeyStorage describes how the contract's private keys are stored
+func (c *ContractInfo) KeyStorage() string {
+	switch {
+	case c.IsWatchOnly:
+		return "watch-only (no private keys; withdrawals are exported as PSBTs)"
+	case c.IsEncrypted():
+		return "encrypted"
+	case c.OwnerWIF == "" && c.InheritorWIF == "":
+		return "none (redacted)"
+	default:
+		return "plaintext (run migrate-encrypt to encrypt)"
+	}
+}
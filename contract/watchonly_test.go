@@ -0,0 +1,82 @@
+package contract
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchOnlyContract(t *testing.T) {
+	useTempContractsDir(t)
+	contractID := "testnet_watchonl"
+
+	contractInfo := &ContractInfo{
+		ContractID:   contractID,
+		CreatedAt:    time.Now(),
+		Network:      "testnet3",
+		TimelockDays: 180,
+		RedeemScript: "63ac67b27568",
+		P2WSHAddress: "tb1qexample",
+		IsWatchOnly:  true,
+	}
+	// A passphrase has nothing to encrypt and must not add key material
+	contractInfo.SetPassphrase([]byte("correct horse"))
+	if err := SaveContractInfo(contractInfo); err != nil {
+		t.Fatalf("SaveContractInfo failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(ContractsDir, contractID+".json"))
+	if err != nil {
+		t.Fatalf("Failed to read contract file: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Failed to parse contract file: %v", err)
+	}
+	for name, value := range fields {
+		if strings.Contains(name, "wif") && value != "" {
+			t.Errorf("Watch-only contract file has private key material in %s: %v", name, value)
+		}
+	}
+	if fields["is_watch_only"] != true {
+		t.Errorf("Expected is_watch_only to be true, got %v", fields["is_watch_only"])
+	}
+
+	loaded, err := LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	if !loaded.IsWatchOnly || loaded.OwnerWIF != "" || loaded.InheritorWIF != "" {
+		t.Errorf("Unexpected watch-only contract: %+v", loaded)
+	}
+
+	loaded.OwnerWIF = testOwnerWIF
+	if err := SaveContractInfo(loaded); err == nil || !strings.Contains(err.Error(), "watch-only") {
+		t.Errorf("Expected saving a private key in a watch-only contract to fail, got %v", err)
+	}
+}
+
+func TestKeyStorage(t *testing.T) {
+	tests := []struct {
+		name     string
+		contract *ContractInfo
+		want     string
+	}{
+		{"watch-only", &ContractInfo{IsWatchOnly: true}, "watch-only"},
+		{"encrypted", &ContractInfo{OwnerWIFEncrypted: "c2VhbGVk"}, "encrypted"},
+		{"plaintext", &ContractInfo{OwnerWIF: testOwnerWIF}, "plaintext"},
+		{"inheritor key only", &ContractInfo{InheritorWIF: testInheritorWIF}, "plaintext"},
+		{"redacted", &ContractInfo{}, "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.contract.KeyStorage(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("Expected key storage %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package fees
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/ratelimit"
+)
+
+// FeeEstimator estimates the fee rate (sat/vB) needed to confirm within confTarget blocks
+type FeeEstimator interface {
+	EstimateFeeRate(confTarget int) (float64, error)
+}
+
+// SmartFeeSource is implemented by node clients supporting estimatesmartfee
+type SmartFeeSource interface {
+	EstimateSmartFee(confTarget int) (float64, error)
+}
+
+// NodeEstimator estimates fee rates using the connected node's estimatesmartfee
+type NodeEstimator struct {
+	source SmartFeeSource
+}
+
+// NewNodeEstimator creates a fee estimator backed by the node RPC
+func NewNodeEstimator(source SmartFeeSource) *NodeEstimator {
+	return &NodeEstimator{source: source}
+}
+
+// EstimateFeeRate returns the node's fee rate estimate in sat/vB
+func (e *NodeEstimator) EstimateFeeRate(confTarget int) (float64, error) {
+	feeRate, err := e.source.EstimateSmartFee(confTarget)
+	if err != nil {
+		return 0, fmt.Errorf("node fee estimation failed: %w", err)
+	}
+	return feeRate, nil
+}
+
+// MempoolSpaceEstimator estimates fee rates using the mempool.space REST API
+type MempoolSpaceEstimator struct {
+	baseURL string
+	client  *http.Client
+}
+
+// recommendedFees is the response of GET /v1/fees/recommended
+type recommendedFees struct {
+	FastestFee  float64 `json:"fastestFee"`
+	HalfHourFee float64 `json:"halfHourFee"`
+	HourFee     float64 `json:"hourFee"`
+	EconomyFee  float64 `json:"economyFee"`
+	MinimumFee  float64 `json:"minimumFee"`
+}
+
+// NewMempoolSpaceEstimator creates a fee estimator for a mempool.space API base URL
+// (e.g. https://mempool.space/api or https://mempool.space/testnet/api)
+func NewMempoolSpaceEstimator(baseURL string) *MempoolSpaceEstimator {
+	return &MempoolSpaceEstimator{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// EstimateFeeRate maps the confirmation target onto mempool.space's recommended fee buckets
+func (e *MempoolSpaceEstimator) EstimateFeeRate(confTarget int) (float64, error) {
+	resp, err := e.client.Get(e.baseURL + "/v1/fees/recommended")
+	if err != nil {
+		return 0, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := ratelimit.CheckResponse(resp); err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var fees recommendedFees
+	if err := json.Unmarshal(body, &fees); err != nil {
+		return 0, fmt.Errorf("failed to parse recommended fees: %w", err)
+	}
+
+	// Buckets are roughly: next block, ~30 minutes, ~1 hour, and economy
+	switch {
+	case confTarget <= 1:
+		return fees.FastestFee, nil
+	case confTarget <= 3:
+		return fees.HalfHourFee, nil
+	case confTarget <= 6:
+		return fees.HourFee, nil
+	default:
+		return fees.EconomyFee, nil
+	}
+}
+
+// StaticEstimator always returns the same fee rate, for fixed fee policies
+type StaticEstimator struct {
+	FeeRate float64
+}
+
+// EstimateFeeRate returns the static fee rate regardless of the confirmation target
+func (e *StaticEstimator) EstimateFeeRate(confTarget int) (float64, error) {
+	if e.FeeRate <= 0 {
+		return 0, fmt.Errorf("static fee rate must be positive")
+	}
+	return e.FeeRate, nil
+}
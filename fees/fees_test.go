@@ -0,0 +1,93 @@
+package fees
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubSmartFeeSource returns a fixed estimatesmartfee result
+type stubSmartFeeSource struct {
+	feeRate float64
+	err     error
+}
+
+func (s *stubSmartFeeSource) EstimateSmartFee(confTarget int) (float64, error) {
+	return s.feeRate, s.err
+}
+
+func TestNodeEstimator(t *testing.T) {
+	estimator := NewNodeEstimator(&stubSmartFeeSource{feeRate: 12.5})
+
+	feeRate, err := estimator.EstimateFeeRate(6)
+	if err != nil {
+		t.Fatalf("EstimateFeeRate failed: %v", err)
+	}
+	if feeRate != 12.5 {
+		t.Errorf("Expected fee rate 12.5, got %f", feeRate)
+	}
+
+	failing := NewNodeEstimator(&stubSmartFeeSource{err: errors.New("insufficient data")})
+	if _, err := failing.EstimateFeeRate(6); err == nil {
+		t.Error("Expected error from failing node but got none")
+	}
+}
+
+func TestMempoolSpaceEstimator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/fees/recommended" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"fastestFee":40,"halfHourFee":30,"hourFee":20,"economyFee":10,"minimumFee":1}`))
+	}))
+	defer server.Close()
+
+	estimator := NewMempoolSpaceEstimator(server.URL + "/api/")
+
+	testCases := []struct {
+		confTarget int
+		expected   float64
+	}{
+		{1, 40},
+		{3, 30},
+		{6, 20},
+		{144, 10},
+	}
+
+	for _, tc := range testCases {
+		feeRate, err := estimator.EstimateFeeRate(tc.confTarget)
+		if err != nil {
+			t.Fatalf("EstimateFeeRate(%d) failed: %v", tc.confTarget, err)
+		}
+		if feeRate != tc.expected {
+			t.Errorf("EstimateFeeRate(%d): expected %f, got %f", tc.confTarget, tc.expected, feeRate)
+		}
+	}
+}
+
+func TestMempoolSpaceEstimator_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if _, err := NewMempoolSpaceEstimator(server.URL).EstimateFeeRate(6); err == nil {
+		t.Error("Expected error but got none")
+	}
+}
+
+func TestStaticEstimator(t *testing.T) {
+	feeRate, err := (&StaticEstimator{FeeRate: 3}).EstimateFeeRate(1)
+	if err != nil {
+		t.Fatalf("EstimateFeeRate failed: %v", err)
+	}
+	if feeRate != 3 {
+		t.Errorf("Expected fee rate 3, got %f", feeRate)
+	}
+
+	if _, err := (&StaticEstimator{}).EstimateFeeRate(1); err == nil {
+		t.Error("Expected error for zero static fee rate")
+	}
+}
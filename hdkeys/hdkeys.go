@@ -0,0 +1,189 @@
+package hdkeys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+)
+
+// Purpose is the hardened first level of every contract key path. It is
+// deliberately not a BIP44/49/84 purpose so contract keys never collide with
+// wallet keys derived from the same seed.
+const Purpose = 1017
+
+// Roles are the last path level selecting the owner or inheritor key
+const (
+	RoleOwner     uint32 = 0
+	RoleInheritor uint32 = 1
+)
+
+// ParseSeed decodes a hex-encoded BIP32 master seed of 16 to 64 bytes
+func ParseSeed(seedHex string) ([]byte, error) {
+	seed, err := hex.DecodeString(strings.TrimSpace(seedHex))
+	if err != nil {
+		return nil, fmt.Errorf("seed must be hex encoded: %w", err)
+	}
+	if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
+		return nil, fmt.Errorf("seed must be %d to %d bytes, got %d",
+			hdkeychain.MinSeedBytes, hdkeychain.MaxSeedBytes, len(seed))
+	}
+	return seed, nil
+}
+
+// Path returns the derivation path of a contract key:
+// m/1017'/coin'/index'/role with coin 0 on mainnet and 1 elsewhere
+func Path(chainParams *chaincfg.Params, index, role uint32) string {
+	return fmt.Sprintf("m/%d'/%d'/%d'/%d", Purpose, coinType(chainParams), index, role)
+}
+
+// ParseMaster parses a master key given as a hex-encoded seed (see
+// ParseSeed) or as a base58 master extended private key (xprv, or tprv on
+// test networks)
+func ParseMaster(value string, chainParams *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "xprv") && !strings.HasPrefix(value, "tprv") {
+		seed, err := ParseSeed(value)
+		if err != nil {
+			return nil, err
+		}
+		master, err := hdkeychain.NewMaster(seed, chainParams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create master key: %w", err)
+		}
+		return master, nil
+	}
+
+	master, err := hdkeychain.NewKeyFromString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended private key: %w", err)
+	}
+	if !master.IsPrivate() {
+		return nil, fmt.Errorf("expected an extended private key, got a public one")
+	}
+	if !master.IsForNet(chainParams) {
+		return nil, fmt.Errorf("extended private key is not for %s", chainParams.Name)
+	}
+	if master.Depth() != 0 {
+		return nil, fmt.Errorf("expected a master key, got a key at depth %d", master.Depth())
+	}
+	return master, nil
+}
+
+// Fingerprint returns the BIP32 fingerprint of a master key: the first four
+// bytes of the hash160 of its public key, in hex. It identifies the seed
+// without revealing anything about it.
+func Fingerprint(master *hdkeychain.ExtendedKey) (string, error) {
+	pubKey, err := master.ECPubKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get master public key: %w", err)
+	}
+	return hex.EncodeToString(btcutil.Hash160(pubKey.SerializeCompressed())[:4]), nil
+}
+
+// DeriveContractKeys deterministically derives the owner and inheritor keys
+// of the contract at index from a master seed
+func DeriveContractKeys(seed []byte, index uint32, chainParams *chaincfg.Params) (*keys.InheritanceKeys, error) {
+	master, err := hdkeychain.NewMaster(seed, chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create master key: %w", err)
+	}
+	return DeriveMasterKeys(master, index, chainParams)
+}
+
+// DeriveMasterKeys derives the owner and inheritor keys of the contract at
+// index from a master extended private key
+func DeriveMasterKeys(master *hdkeychain.ExtendedKey, index uint32, chainParams *chaincfg.Params) (*keys.InheritanceKeys, error) {
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("index %d is out of range", index)
+	}
+
+	// m/1017'/coin'/index'
+	account := master
+	var err error
+	for _, child := range []uint32{Purpose, coinType(chainParams), index} {
+		account, err = account.Derive(hdkeychain.HardenedKeyStart + child)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive contract key: %w", err)
+		}
+	}
+
+	owner, err := deriveKeyPair(account, RoleOwner, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	inheritor, err := deriveKeyPair(account, RoleInheritor, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keys.InheritanceKeys{Owner: owner, Inheritor: inheritor}, nil
+}
+
+// IsExtendedPubKey reports whether value looks like a base58 extended public
+// key (xpub on mainnet, tpub on test networks)
+func IsExtendedPubKey(value string) bool {
+	return strings.HasPrefix(value, "xpub") || strings.HasPrefix(value, "tpub")
+}
+
+// ChildPubKey derives the role child of an account extended public key, the
+// neutered key at m/1017'/coin'/index', and returns it compressed. This lets
+// a contract be built from keys held on a hardware wallet or another machine.
+func ChildPubKey(xpub string, role uint32, chainParams *chaincfg.Params) ([]byte, error) {
+	account, err := hdkeychain.NewKeyFromString(strings.TrimSpace(xpub))
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key: %w", err)
+	}
+	if account.IsPrivate() {
+		return nil, fmt.Errorf("expected an extended public key, got a private one")
+	}
+	if !account.IsForNet(chainParams) {
+		return nil, fmt.Errorf("extended public key is not for %s", chainParams.Name)
+	}
+
+	child, err := account.Derive(role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key for role %d: %w", role, err)
+	}
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+	return pubKey.SerializeCompressed(), nil
+}
+
+// deriveKeyPair derives the role child of an account key as a key pair
+func deriveKeyPair(account *hdkeychain.ExtendedKey, role uint32, chainParams *chaincfg.Params) (*keys.KeyPair, error) {
+	child, err := account.Derive(role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key for role %d: %w", role, err)
+	}
+	privKey, err := child.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private key: %w", err)
+	}
+
+	wif, err := btcutil.NewWIF(privKey, chainParams, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode WIF: %w", err)
+	}
+
+	return &keys.KeyPair{
+		PrivateKey:  privKey,
+		PublicKey:   privKey.PubKey(),
+		WIF:         wif,
+		ChainParams: chainParams,
+	}, nil
+}
+
+// coinType returns the SLIP-44 coin type: 0 for mainnet, 1 for test networks
+func coinType(chainParams *chaincfg.Params) uint32 {
+	if chainParams.Net == chaincfg.MainNetParams.Net {
+		return 0
+	}
+	return 1
+}
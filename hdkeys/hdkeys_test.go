@@ -0,0 +1,248 @@
+package hdkeys
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+)
+
+// BIP32 test vector 1 seed
+const testSeedHex = "000102030405060708090a0b0c0d0e0f"
+
+func TestDeriveContractKeys_Path(t *testing.T) {
+	seed, err := ParseSeed(testSeedHex)
+	if err != nil {
+		t.Fatalf("ParseSeed failed: %v", err)
+	}
+	chainParams := &chaincfg.TestNet3Params
+
+	contractKeys, err := DeriveContractKeys(seed, 5, chainParams)
+	if err != nil {
+		t.Fatalf("DeriveContractKeys failed: %v", err)
+	}
+
+	// Derive m/1017'/1'/5'/{0,1} step by step
+	key, _ := hdkeychain.NewMaster(seed, chainParams)
+	for _, child := range []uint32{
+		hdkeychain.HardenedKeyStart + 1017,
+		hdkeychain.HardenedKeyStart + 1,
+		hdkeychain.HardenedKeyStart + 5,
+	} {
+		key, _ = key.Derive(child)
+	}
+	for role, derived := range map[uint32][]byte{
+		RoleOwner:     contractKeys.Owner.GetCompressedPubKeyBytes(),
+		RoleInheritor: contractKeys.Inheritor.GetCompressedPubKeyBytes(),
+	} {
+		child, _ := key.Derive(role)
+		pubKey, _ := child.ECPubKey()
+		if !bytes.Equal(pubKey.SerializeCompressed(), derived) {
+			t.Errorf("Role %d key does not match %s", role, Path(chainParams, 5, role))
+		}
+	}
+
+	if path := Path(chainParams, 5, RoleInheritor); path != "m/1017'/1'/5'/1" {
+		t.Errorf("Unexpected path %s", path)
+	}
+}
+
+func TestDeriveContractKeys_Deterministic(t *testing.T) {
+	seed, _ := ParseSeed(testSeedHex)
+
+	first, err := DeriveContractKeys(seed, 0, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("DeriveContractKeys failed: %v", err)
+	}
+	again, _ := DeriveContractKeys(seed, 0, &chaincfg.TestNet3Params)
+	next, _ := DeriveContractKeys(seed, 1, &chaincfg.TestNet3Params)
+	mainnet, _ := DeriveContractKeys(seed, 0, &chaincfg.MainNetParams)
+
+	if first.Owner.WIF.String() != again.Owner.WIF.String() || first.Inheritor.WIF.String() != again.Inheritor.WIF.String() {
+		t.Error("Derivation is not deterministic")
+	}
+	if first.Owner.WIF.String() == first.Inheritor.WIF.String() {
+		t.Error("Owner and inheritor keys must differ")
+	}
+	if first.Owner.WIF.String() == next.Owner.WIF.String() {
+		t.Error("Different indexes must give different keys")
+	}
+	if bytes.Equal(first.Owner.GetCompressedPubKeyBytes(), mainnet.Owner.GetCompressedPubKeyBytes()) {
+		t.Error("Mainnet and testnet keys must differ")
+	}
+}
+
+func TestParseSeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		seedHex string
+		wantErr bool
+	}{
+		{"16 bytes", testSeedHex, false},
+		{"64 bytes", hex.EncodeToString(make([]byte, 64)), false},
+		{"surrounding whitespace", " " + testSeedHex + "\n", false},
+		{"too short", "000102", true},
+		{"too long", hex.EncodeToString(make([]byte, 65)), true},
+		{"not hex", "zz", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSeed(tt.seedHex)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSeed(%q) error = %v, wantErr %v", tt.seedHex, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChildPubKey(t *testing.T) {
+	seed, err := ParseSeed(testSeedHex)
+	if err != nil {
+		t.Fatalf("ParseSeed failed: %v", err)
+	}
+	chainParams := &chaincfg.TestNet3Params
+
+	contractKeys, err := DeriveContractKeys(seed, 2, chainParams)
+	if err != nil {
+		t.Fatalf("DeriveContractKeys failed: %v", err)
+	}
+
+	// The neutered account key at m/1017'/1'/2'
+	account, _ := hdkeychain.NewMaster(seed, chainParams)
+	for _, child := range []uint32{1017, 1, 2} {
+		account, _ = account.Derive(hdkeychain.HardenedKeyStart + child)
+	}
+	xprv := account.String()
+	public, err := account.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter failed: %v", err)
+	}
+	xpub := public.String()
+	if !IsExtendedPubKey(xpub) {
+		t.Fatalf("Expected %s to be recognized as an extended public key", xpub)
+	}
+
+	for role, want := range map[uint32][]byte{
+		RoleOwner:     contractKeys.Owner.GetCompressedPubKeyBytes(),
+		RoleInheritor: contractKeys.Inheritor.GetCompressedPubKeyBytes(),
+	} {
+		got, err := ChildPubKey(xpub, role, chainParams)
+		if err != nil {
+			t.Fatalf("ChildPubKey failed: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Role %d: expected %x, got %x", role, want, got)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		value  string
+		params *chaincfg.Params
+	}{
+		{"private key", xprv, chainParams},
+		{"wrong network", xpub, &chaincfg.MainNetParams},
+		{"not a key", "tpubnotakey", chainParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ChildPubKey(tt.value, RoleOwner, tt.params); err == nil {
+				t.Errorf("Expected an error for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestParseMaster(t *testing.T) {
+	chainParams := &chaincfg.TestNet3Params
+	seed, _ := ParseSeed(testSeedHex)
+	master, _ := hdkeychain.NewMaster(seed, chainParams)
+	child, _ := master.Derive(hdkeychain.HardenedKeyStart)
+	public, _ := master.Neuter()
+	mainnet, _ := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"hex seed", testSeedHex, false},
+		{"tprv", master.String(), false},
+		{"tprv with whitespace", " " + master.String() + "\n", false},
+		{"not a master key", child.String(), true},
+		{"public key", public.String(), true},
+		{"other network", mainnet.String(), true},
+		{"bad checksum", master.String()[:len(master.String())-1] + "1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseMaster(tt.value, chainParams)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMaster error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && parsed.String() != master.String() {
+				t.Errorf("Expected master %s, got %s", master, parsed)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	seed, _ := ParseSeed(testSeedHex)
+	master, _ := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+
+	// BIP32 test vector 1: the chain m/0' has parent fingerprint 3442193e
+	fingerprint, err := Fingerprint(master)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	if fingerprint != "3442193e" {
+		t.Errorf("Expected fingerprint 3442193e, got %s", fingerprint)
+	}
+}
+
+func TestDeriveMasterKeys_ManyContracts(t *testing.T) {
+	chainParams := &chaincfg.TestNet3Params
+	seed, _ := ParseSeed(testSeedHex)
+	master, _ := hdkeychain.NewMaster(seed, chainParams)
+
+	// Two contracts from one master key get distinct keys...
+	first, err := DeriveMasterKeys(master, 0, chainParams)
+	if err != nil {
+		t.Fatalf("DeriveMasterKeys failed: %v", err)
+	}
+	second, err := DeriveMasterKeys(master, 1, chainParams)
+	if err != nil {
+		t.Fatalf("DeriveMasterKeys failed: %v", err)
+	}
+	wifs := map[string]bool{}
+	for _, wif := range []string{
+		first.Owner.WIF.String(), first.Inheritor.WIF.String(),
+		second.Owner.WIF.String(), second.Inheritor.WIF.String(),
+	} {
+		if wifs[wif] {
+			t.Errorf("Key %s derived twice", wif)
+		}
+		wifs[wif] = true
+	}
+
+	// ...and both are re-derived identically from the seed alone
+	for index, derived := range []*keys.InheritanceKeys{first, second} {
+		again, err := DeriveContractKeys(seed, uint32(index), chainParams)
+		if err != nil {
+			t.Fatalf("DeriveContractKeys failed: %v", err)
+		}
+		if again.Owner.WIF.String() != derived.Owner.WIF.String() || again.Inheritor.WIF.String() != derived.Inheritor.WIF.String() {
+			t.Errorf("Contract %d did not re-derive identically from the seed", index)
+		}
+	}
+
+	if _, err := DeriveMasterKeys(master, hdkeychain.HardenedKeyStart, chainParams); err == nil {
+		t.Error("Expected an error for a hardened index")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 )
@@ -53,6 +54,54 @@ func (kp *KeyPair) GetCompressedPubKeyBytes() []byte {
 	return kp.PublicKey.SerializeCompressed()
 }
 
+// GetXOnlyPubKeyBytes returns the 32-byte BIP340 x-only public key
+func (kp *KeyPair) GetXOnlyPubKeyBytes() []byte {
+	return schnorr.SerializePubKey(kp.PublicKey)
+}
+
+// SignSchnorr creates a 64-byte BIP340 Schnorr signature over a 32-byte hash
+func (kp *KeyPair) SignSchnorr(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+
+	sig, err := schnorr.Sign(kp.PrivateKey, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Schnorr signature: %w", err)
+	}
+
+	return sig.Serialize(), nil
+}
+
+// ParseXOnlyPubKey parses a 32-byte BIP340 x-only public key
+func ParseXOnlyPubKey(xOnly []byte) (*btcec.PublicKey, error) {
+	if len(xOnly) != schnorr.PubKeyBytesLen {
+		return nil, fmt.Errorf("x-only public key must be %d bytes, got %d", schnorr.PubKeyBytesLen, len(xOnly))
+	}
+
+	pubKey, err := schnorr.ParsePubKey(xOnly)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x-only public key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+// VerifySchnorr checks a BIP340 Schnorr signature over a 32-byte hash
+func VerifySchnorr(xOnly, hash, signature []byte) (bool, error) {
+	pubKey, err := ParseXOnlyPubKey(xOnly)
+	if err != nil {
+		return false, err
+	}
+
+	sig, err := schnorr.ParseSignature(signature)
+	if err != nil {
+		return false, fmt.Errorf("invalid Schnorr signature: %w", err)
+	}
+
+	return sig.Verify(hash, pubKey), nil
+}
+
 // GetP2WPKHAddress returns a P2WPKH address for this key pair
 func (kp *KeyPair) GetP2WPKHAddress() (btcutil.Address, error) {
 	pubKeyBytes := kp.GetCompressedPubKeyBytes()
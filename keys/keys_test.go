@@ -113,3 +113,60 @@ func TestNewKeyPair(t *testing.T) {
 		t.Error("Private keys don't match after WIF roundtrip")
 	}
 }
+
+func TestXOnlyPubKey(t *testing.T) {
+	keyPair, err := NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+
+	xOnly := keyPair.GetXOnlyPubKeyBytes()
+	if len(xOnly) != 32 {
+		t.Fatalf("Expected 32-byte x-only key, got %d bytes", len(xOnly))
+	}
+
+	// The x-only key is the compressed key without its parity byte
+	if !bytes.Equal(xOnly, keyPair.GetCompressedPubKeyBytes()[1:]) {
+		t.Error("X-only key does not match the compressed key's x coordinate")
+	}
+
+	if _, err := ParseXOnlyPubKey(xOnly); err != nil {
+		t.Errorf("Failed to parse x-only key: %v", err)
+	}
+	if _, err := ParseXOnlyPubKey(keyPair.GetCompressedPubKeyBytes()); err == nil {
+		t.Error("Expected error for 33-byte key")
+	}
+}
+
+func TestSignSchnorr(t *testing.T) {
+	keyPair, err := NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+
+	hash := bytes.Repeat([]byte{0x42}, 32)
+	sig, err := keyPair.SignSchnorr(hash)
+	if err != nil {
+		t.Fatalf("SignSchnorr failed: %v", err)
+	}
+	if len(sig) != 64 {
+		t.Fatalf("Expected 64-byte signature, got %d bytes", len(sig))
+	}
+
+	valid, err := VerifySchnorr(keyPair.GetXOnlyPubKeyBytes(), hash, sig)
+	if err != nil {
+		t.Fatalf("VerifySchnorr failed: %v", err)
+	}
+	if !valid {
+		t.Error("Signature did not verify")
+	}
+
+	otherHash := bytes.Repeat([]byte{0x43}, 32)
+	if valid, _ := VerifySchnorr(keyPair.GetXOnlyPubKeyBytes(), otherHash, sig); valid {
+		t.Error("Signature verified against a different hash")
+	}
+
+	if _, err := keyPair.SignSchnorr(hash[:31]); err == nil {
+		t.Error("Expected error for short hash")
+	}
+}
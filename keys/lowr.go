@@ -0,0 +1,81 @@
+package keys
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// maxGrindAttempts bounds the nonce search; each attempt succeeds with
+// probability 1/2, so the bound is never reached in practice
+const maxGrindAttempts = 1 << 16
+
+// SignLowR creates an ECDSA signature whose R value fits in 32 bytes without
+// a sign-padding byte, so its DER encoding is at most 71 bytes. Like Bitcoin
+// Core, it first tries the plain RFC6979 nonce and then retries with a
+// counter passed as extra nonce data, so the result stays deterministic.
+func SignLowR(privKey *btcec.PrivateKey, hash []byte) (*ecdsa.Signature, error) {
+	sig := ecdsa.Sign(privKey, hash)
+	if HasLowR(sig) {
+		return sig, nil
+	}
+
+	privKeyBytes := privKey.Serialize()
+	extra := make([]byte, 32)
+	for counter := uint32(1); counter < maxGrindAttempts; counter++ {
+		binary.LittleEndian.PutUint32(extra, counter)
+		nonce := btcec.NonceRFC6979(privKeyBytes, hash, extra, nil, 0)
+
+		sig, ok := signWithNonce(&privKey.Key, nonce, hash)
+		if ok && HasLowR(sig) {
+			return sig, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no low-R signature found after %d attempts", maxGrindAttempts)
+}
+
+// HasLowR reports whether the signature's R value is below 2^255, i.e. its
+// DER encoding needs no leading zero byte
+func HasLowR(sig *ecdsa.Signature) bool {
+	// DER layout: 0x30 <len> 0x02 <len(R)> <R> 0x02 <len(S)> <S>
+	return sig.Serialize()[3] <= 32
+}
+
+// signWithNonce computes a low-S ECDSA signature over hash using nonce k. It
+// returns false when k yields an invalid (zero) R or S.
+func signWithNonce(privKey, k *btcec.ModNScalar, hash []byte) (*ecdsa.Signature, bool) {
+	// R = kG, r = R.x mod N
+	var point btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(k, &point)
+	point.ToAffine()
+
+	var r btcec.ModNScalar
+	xBytes := point.X.Bytes()
+	r.SetByteSlice(xBytes[:])
+	if r.IsZero() {
+		return nil, false
+	}
+
+	// s = k^-1 (e + r*d) mod N
+	var e btcec.ModNScalar
+	e.SetByteSlice(hash)
+
+	var s btcec.ModNScalar
+	s.Mul2(privKey, &r).Add(&e)
+	var kInv btcec.ModNScalar
+	kInv.InverseValNonConst(k)
+	s.Mul(&kInv)
+	if s.IsZero() {
+		return nil, false
+	}
+
+	// Enforce low S as required by standardness rules
+	if s.IsOverHalfOrder() {
+		s.Negate()
+	}
+
+	return ecdsa.NewSignature(&r, &s), true
+}
@@ -0,0 +1,71 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+func TestSignLowR(t *testing.T) {
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x11}, 32))
+
+	ground := 0
+	for i := 0; i < 64; i++ {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+
+		sig, err := SignLowR(privKey, hash[:])
+		if err != nil {
+			t.Fatalf("SignLowR failed: %v", err)
+		}
+
+		if !HasLowR(sig) {
+			t.Errorf("Hash %d: expected low-R signature, got %x", i, sig.Serialize())
+		}
+		if len(sig.Serialize()) > 71 {
+			t.Errorf("Hash %d: expected at most 71 bytes, got %d", i, len(sig.Serialize()))
+		}
+		if !sig.Verify(hash[:], privKey.PubKey()) {
+			t.Errorf("Hash %d: signature does not verify", i)
+		}
+
+		// Signatures that are already low-R must be left unchanged
+		plain := ecdsa.Sign(privKey, hash[:])
+		if HasLowR(plain) {
+			if !bytes.Equal(plain.Serialize(), sig.Serialize()) {
+				t.Errorf("Hash %d: low-R plain signature was replaced", i)
+			}
+		} else {
+			ground++
+		}
+
+		// Grinding is deterministic
+		again, _ := SignLowR(privKey, hash[:])
+		if !bytes.Equal(again.Serialize(), sig.Serialize()) {
+			t.Errorf("Hash %d: SignLowR is not deterministic", i)
+		}
+	}
+
+	if ground == 0 {
+		t.Errorf("Expected some of the 64 hashes to need grinding")
+	}
+}
+
+func TestSignWithNonce_MatchesRFC6979(t *testing.T) {
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x22}, 32))
+	hash := sha256.Sum256([]byte("inheritance"))
+
+	nonce := btcec.NonceRFC6979(privKey.Serialize(), hash[:], nil, nil, 0)
+	sig, ok := signWithNonce(&privKey.Key, nonce, hash[:])
+	if !ok {
+		t.Fatal("signWithNonce rejected a valid nonce")
+	}
+
+	expected := ecdsa.Sign(privKey, hash[:])
+	if !bytes.Equal(sig.Serialize(), expected.Serialize()) {
+		t.Errorf("Expected %x, got %x", expected.Serialize(), sig.Serialize())
+	}
+}
@@ -0,0 +1,99 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// messageMagic prefixes signed messages so they cannot be confused with transactions
+const messageMagic = "Bitcoin Signed Message:\n"
+
+// SignedPubKey is a public key together with a signed message proving that
+// its holder controls the matching private key
+type SignedPubKey struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"` // base64 compact signature
+	PubKey    string `json:"pubkey"`    // hex compressed public key
+}
+
+// messageHash returns the double SHA-256 hash of a Bitcoin signed message
+func messageHash(message string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, messageMagic)
+	wire.WriteVarString(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+// SignMessage signs a message in the Bitcoin signed message format and
+// returns the base64 compact signature
+func (kp *KeyPair) SignMessage(message string) (string, error) {
+	sig, err := ecdsa.SignCompact(kp.PrivateKey, messageHash(message), true)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign message: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// VerifySignedPubKey checks that signature is a valid signature of message
+// by the private key belonging to pubKey
+func VerifySignedPubKey(message, signature string, pubKey []byte) error {
+	expected, err := btcec.ParsePubKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not valid base64: %w", err)
+	}
+
+	recovered, _, err := ecdsa.RecoverCompact(sig, messageHash(message))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	if !recovered.IsEqual(expected) {
+		return fmt.Errorf("signature was not made by the given public key")
+	}
+	return nil
+}
+
+// LoadSignedPubKey reads a signed public key file, verifies its signature
+// and returns the compressed public key it proves control of
+func LoadSignedPubKey(path string) ([]byte, *SignedPubKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read signed public key file: %w", err)
+	}
+
+	var signed SignedPubKey
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse signed public key file: %w", err)
+	}
+	if signed.Message == "" {
+		return nil, nil, fmt.Errorf("signed public key file has no message")
+	}
+
+	pubKey, err := hex.DecodeString(signed.PubKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid public key hex: %w", err)
+	}
+	if len(pubKey) != btcec.PubKeyBytesLenCompressed {
+		return nil, nil, fmt.Errorf("public key must be %d bytes compressed, got %d", btcec.PubKeyBytesLenCompressed, len(pubKey))
+	}
+
+	if err := VerifySignedPubKey(signed.Message, signed.Signature, pubKey); err != nil {
+		return nil, nil, err
+	}
+
+	return pubKey, &signed, nil
+}
@@ -0,0 +1,88 @@
+package keys
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestVerifySignedPubKey(t *testing.T) {
+	keyPair, err := NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	otherKeyPair, err := NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+
+	message := "I control the inheritor key for contract 2024-01"
+	signature, err := keyPair.SignMessage(message)
+	if err != nil {
+		t.Fatalf("SignMessage failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		message   string
+		signature string
+		pubKey    []byte
+		wantErr   bool
+	}{
+		{"valid", message, signature, keyPair.GetCompressedPubKeyBytes(), false},
+		{"different message", message + "!", signature, keyPair.GetCompressedPubKeyBytes(), true},
+		{"different key", message, signature, otherKeyPair.GetCompressedPubKeyBytes(), true},
+		{"malformed signature", message, "not-base64!", keyPair.GetCompressedPubKeyBytes(), true},
+		{"invalid public key", message, signature, []byte{0x02, 0x01}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignedPubKey(tt.message, tt.signature, tt.pubKey)
+			if tt.wantErr && err == nil {
+				t.Error("Expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadSignedPubKey(t *testing.T) {
+	keyPair, err := NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+
+	signature, err := keyPair.SignMessage("challenge")
+	if err != nil {
+		t.Fatalf("SignMessage failed: %v", err)
+	}
+
+	writeFile := func(signed SignedPubKey) string {
+		path := filepath.Join(t.TempDir(), "inheritor.json")
+		data, _ := json.Marshal(signed)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		return path
+	}
+
+	pubKeyHex := hex.EncodeToString(keyPair.GetCompressedPubKeyBytes())
+	pubKey, _, err := LoadSignedPubKey(writeFile(SignedPubKey{Message: "challenge", Signature: signature, PubKey: pubKeyHex}))
+	if err != nil {
+		t.Fatalf("LoadSignedPubKey failed: %v", err)
+	}
+	if hex.EncodeToString(pubKey) != pubKeyHex {
+		t.Error("Loaded public key does not match")
+	}
+
+	if _, _, err := LoadSignedPubKey(writeFile(SignedPubKey{Message: "tampered", Signature: signature, PubKey: pubKeyHex})); err == nil {
+		t.Error("Expected error for tampered message")
+	}
+}
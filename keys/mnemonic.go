@@ -0,0 +1,112 @@
+package keys
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// GenerateMnemonic returns a new BIP39 mnemonic of 12 or 24 English words,
+// encoding 128 or 256 bits of entropy
+func GenerateMnemonic(words int) (string, error) {
+	var bits int
+	switch words {
+	case 12:
+		bits = 128
+	case 24:
+		bits = 256
+	default:
+		return "", fmt.Errorf("a mnemonic has 12 or 24 words, not %d", words)
+	}
+
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %w", err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode mnemonic: %w", err)
+	}
+	return mnemonic, nil
+}
+
+// MnemonicSeed checks a BIP39 mnemonic's words and checksum and returns its
+// 64-byte seed, without a passphrase
+func MnemonicSeed(phrase string) ([]byte, error) {
+	phrase = strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(phrase, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	return seed, nil
+}
+
+// KeyPairFromMnemonic derives the key at a BIP32 path such as
+// m/1017'/1'/0'/0 from the seed of a BIP39 mnemonic
+func KeyPairFromMnemonic(phrase, path string, chainParams *chaincfg.Params) (*KeyPair, error) {
+	seed, err := MnemonicSeed(phrase)
+	if err != nil {
+		return nil, err
+	}
+	children, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hdkeychain.NewMaster(seed, chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create master key: %w", err)
+	}
+	for _, child := range children {
+		if key, err = key.Derive(child); err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", path, err)
+		}
+	}
+
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private key: %w", err)
+	}
+	wif, err := btcutil.NewWIF(privKey, chainParams, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode WIF: %w", err)
+	}
+
+	return &KeyPair{
+		PrivateKey:  privKey,
+		PublicKey:   privKey.PubKey(),
+		WIF:         wif,
+		ChainParams: chainParams,
+	}, nil
+}
+
+// ParseDerivationPath parses a BIP32 path of the form m/44'/0'/0'/0/1 into
+// child indexes; ' or h marks a hardened level
+func ParseDerivationPath(path string) ([]uint32, error) {
+	levels := strings.Split(strings.TrimSpace(path), "/")
+	if levels[0] != "m" {
+		return nil, fmt.Errorf("derivation path %q must start with m/", path)
+	}
+
+	children := make([]uint32, 0, len(levels)-1)
+	for _, level := range levels[1:] {
+		hardened := strings.HasSuffix(level, "'") || strings.HasSuffix(level, "h")
+		if hardened {
+			level = level[:len(level)-1]
+		}
+		index, err := strconv.ParseUint(level, 10, 32)
+		if err != nil || index >= hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("invalid level %q in derivation path %q", level, path)
+		}
+		if hardened {
+			index += hdkeychain.HardenedKeyStart
+		}
+		children = append(children, uint32(index))
+	}
+	return children, nil
+}
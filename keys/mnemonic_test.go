@@ -0,0 +1,127 @@
+package keys
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// BIP84 test vector mnemonic
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMnemonicSeed(t *testing.T) {
+	seed, err := MnemonicSeed(testMnemonic)
+	if err != nil {
+		t.Fatalf("MnemonicSeed failed: %v", err)
+	}
+	want := "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"
+	if hex.EncodeToString(seed) != want {
+		t.Errorf("Expected seed %s, got %x", want, seed)
+	}
+
+	// Case and spacing do not matter
+	again, err := MnemonicSeed("  " + strings.ToUpper(strings.ReplaceAll(testMnemonic, " ", "  ")) + "\n")
+	if err != nil || hex.EncodeToString(again) != want {
+		t.Errorf("Expected the same seed for a reformatted mnemonic, got %x (%v)", again, err)
+	}
+
+	invalid := []string{
+		"",
+		strings.Replace(testMnemonic, "about", "abandon", 1),  // bad checksum
+		strings.Replace(testMnemonic, "about", "bitcoinx", 1), // not a word
+		"abandon abandon abandon",
+	}
+	for _, phrase := range invalid {
+		if _, err := MnemonicSeed(phrase); err == nil {
+			t.Errorf("Expected an error for %q", phrase)
+		}
+	}
+}
+
+func TestKeyPairFromMnemonic(t *testing.T) {
+	// BIP84: m/84'/0'/0'/0/0 of the test mnemonic
+	keyPair, err := KeyPairFromMnemonic(testMnemonic, "m/84'/0'/0'/0/0", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("KeyPairFromMnemonic failed: %v", err)
+	}
+	if got := keyPair.WIF.String(); got != "KyZpNDKnfs94vbrwhJneDi77V6jF64PWPF8x5cdJb8ifgg2DUc9d" {
+		t.Errorf("Unexpected WIF %s", got)
+	}
+	if got := hex.EncodeToString(keyPair.GetCompressedPubKeyBytes()); got != "0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c" {
+		t.Errorf("Unexpected public key %s", got)
+	}
+
+	// Derivation is deterministic, and the path selects the key
+	again, err := KeyPairFromMnemonic(testMnemonic, "m/84h/0h/0h/0/0", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("KeyPairFromMnemonic failed: %v", err)
+	}
+	if again.WIF.String() != keyPair.WIF.String() {
+		t.Error("Expected the same key for the same mnemonic and path")
+	}
+	other, err := KeyPairFromMnemonic(testMnemonic, "m/84'/0'/0'/0/1", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("KeyPairFromMnemonic failed: %v", err)
+	}
+	if other.WIF.String() == keyPair.WIF.String() {
+		t.Error("Expected a different key for a different path")
+	}
+}
+
+func TestGenerateMnemonic(t *testing.T) {
+	for _, words := range []int{12, 24} {
+		mnemonic, err := GenerateMnemonic(words)
+		if err != nil {
+			t.Fatalf("GenerateMnemonic(%d) failed: %v", words, err)
+		}
+		if got := len(strings.Fields(mnemonic)); got != words {
+			t.Errorf("Expected %d words, got %d", words, got)
+		}
+		if _, err := MnemonicSeed(mnemonic); err != nil {
+			t.Errorf("Generated mnemonic does not validate: %v", err)
+		}
+	}
+
+	if _, err := GenerateMnemonic(18); err == nil {
+		t.Error("Expected an error for 18 words")
+	}
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	const h = hdkeychain.HardenedKeyStart
+	tests := []struct {
+		path    string
+		want    []uint32
+		wantErr bool
+	}{
+		{"m", []uint32{}, false},
+		{"m/1017'/1'/0'/0", []uint32{h + 1017, h + 1, h, 0}, false},
+		{"m/84h/0h/5", []uint32{h + 84, h, 5}, false},
+		{"1017'/1'", nil, true},
+		{"m/x/1", nil, true},
+		{"m/2147483648", nil, true},
+		{"m//1", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseDerivationPath(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDerivationPath failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
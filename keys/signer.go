@@ -0,0 +1,57 @@
+package keys
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// Signer creates ECDSA signatures without exposing the private key, so keys
+// can be held in an HSM or cloud KMS instead of a WIF file
+type Signer interface {
+	// PublicKey returns the key the signatures verify against
+	PublicKey() *btcec.PublicKey
+
+	// Sign returns the DER-encoded ECDSA signature of a 32-byte hash
+	Sign(hash []byte) ([]byte, error)
+}
+
+// LowRSigner is a Signer that can also grind for a low R value (see SignLowR)
+type LowRSigner interface {
+	Signer
+	SignLowR(hash []byte) ([]byte, error)
+}
+
+// LocalSigner signs with a private key held in memory, e.g. decoded from a
+// contract's WIF
+type LocalSigner struct {
+	privKey *btcec.PrivateKey
+}
+
+// NewLocalSigner creates a Signer backed by an in-memory private key
+func NewLocalSigner(privKey *btcec.PrivateKey) *LocalSigner {
+	return &LocalSigner{privKey: privKey}
+}
+
+// Signer returns a LocalSigner for the key pair's private key
+func (kp *KeyPair) Signer() *LocalSigner {
+	return NewLocalSigner(kp.PrivateKey)
+}
+
+// PublicKey returns the public key of the signing key
+func (s *LocalSigner) PublicKey() *btcec.PublicKey {
+	return s.privKey.PubKey()
+}
+
+// Sign creates a deterministic (RFC6979) signature of hash
+func (s *LocalSigner) Sign(hash []byte) ([]byte, error) {
+	return ecdsa.Sign(s.privKey, hash).Serialize(), nil
+}
+
+// SignLowR creates a deterministic signature of hash with a low R value
+func (s *LocalSigner) SignLowR(hash []byte) ([]byte, error) {
+	sig, err := SignLowR(s.privKey, hash)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Serialize(), nil
+}
@@ -0,0 +1,46 @@
+package keys
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestLocalSigner(t *testing.T) {
+	keyPair, err := NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewKeyPair failed: %v", err)
+	}
+	signer := keyPair.Signer()
+
+	if !signer.PublicKey().IsEqual(keyPair.PublicKey) {
+		t.Fatal("Signer public key does not match the key pair")
+	}
+
+	var lowRSigner LowRSigner = signer
+	for i := byte(0); i < 16; i++ {
+		hash := sha256.Sum256([]byte{i})
+
+		der, err := signer.Sign(hash[:])
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		sig, err := ecdsa.ParseDERSignature(der)
+		if err != nil {
+			t.Fatalf("ParseDERSignature failed: %v", err)
+		}
+		if !sig.Verify(hash[:], signer.PublicKey()) {
+			t.Errorf("Hash %d: signature does not verify", i)
+		}
+
+		der, err = lowRSigner.SignLowR(hash[:])
+		if err != nil {
+			t.Fatalf("SignLowR failed: %v", err)
+		}
+		if der[3] > 32 {
+			t.Errorf("Hash %d: expected a low R value, got R of %d bytes", i, der[3])
+		}
+	}
+}
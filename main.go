@@ -2,17 +2,26 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/nikolay.stoev/bitcoin-inheritance/config"
 	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/fees"
+	"github.com/nikolay.stoev/bitcoin-inheritance/hdkeys"
 	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
 	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
 	"github.com/nikolay.stoev/bitcoin-inheritance/script"
@@ -27,10 +36,47 @@ var (
 	// Command line flags
 	testnet      bool
 	timelockDays int64
+	configFile   string
+	feeLadder    []float64
+
+	allowShortTimelock bool
+	inheritorPubKeyArg string
+	generateFromSeed   bool
+	generateMnemonic   bool
+	mnemonicWords      int
+	generateIndex      uint32
+	generateIndexSet   bool
+	generateReplace    bool
+
+	generateTimelockSeconds int64
+	generateUnlockDate      string
+	generateTimelockBlocks  int64
+	generateEncrypt         bool
+	allowSameKey            bool
+
+	withdrawAmount   int64
+	changeToContract bool
+	changeAddress    string
+	ownerRBF         bool
+
+	withdrawFee     int64
+	withdrawFeeRate string
+	grindLowR       bool
+	withdrawSigHash string
+	allowHighFee    bool
+
+	storeWithdrawalPath string
+
+	assumeYes       bool
+	destinationAddr string
+
+	rpcTimeout time.Duration
 )
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		// Errors are printed even with --quiet
+		log.SetOutput(os.Stderr)
 		log.Fatal(err)
 	}
 }
@@ -44,15 +90,27 @@ using OP_IF/OP_ELSE conditional logic and OP_CHECKSEQUENCEVERIFY timelocks.
 The contract allows:
 - Owner to spend funds at any time
 - Inheritor to spend funds after the timelock expires`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Load configuration from environment variables
-		cfg = config.LoadConfig()
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		configureLogging()
+		if err := checkOutputFormat(); err != nil {
+			return err
+		}
+
+		// Load configuration from environment variables and --config
+		var err error
+		if cfg, err = config.LoadConfigFile(configFile); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
 
 		// Override network if testnet flag is explicitly set to false
 		if !testnet {
 			// Force mainnet configuration
-			cfg = config.LoadConfig()
+			if cfg, err = config.LoadConfigFile(configFile); err != nil {
+				return fmt.Errorf("invalid configuration: %w", err)
+			}
 			log.Printf("Using mainnet configuration (forced by --testnet=false)")
+		} else if configFile != "" {
+			log.Printf("Using configuration from environment, %s and .env", configFile)
 		} else {
 			log.Printf("Using configuration from environment (.env file or system env vars)")
 		}
@@ -63,8 +121,24 @@ The contract allows:
 			log.Printf("Timelock overridden via command line: %d days", timelockDays)
 		}
 
+		// Commands give their node requests one deadline of --rpc-timeout,
+		// failover included, so each endpoint gets an equal share of it and
+		// a hung one leaves time to try the next
+		if rpcTimeout > 0 {
+			cfg.RPCConfig.Timeout = rpcTimeout / time.Duration(max(len(cfg.RPCConfig.Hosts), 1))
+		}
+
+		// Encrypted contract keys are decrypted with the passphrase
+		contract.PassphraseFunc = contractPassphrase
+
 		log.Printf("Network: %s", cfg.ChainParams.Name)
 		log.Printf("Timelock duration: %d days", cfg.Contract.TimelockDays)
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if showSummary {
+			session.print()
+		}
 	},
 }
 
@@ -74,6 +148,7 @@ var generateCmd = &cobra.Command{
 	Long: `Generate a new inheritance contract with fresh keys for owner and inheritor.
 This creates the redeem script and derives the P2WSH funding address.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		generateIndexSet = cmd.Flags().Changed("index")
 		return generateContract()
 	},
 }
@@ -81,10 +156,15 @@ This creates the redeem script and derives the P2WSH funding address.`,
 var showCmd = &cobra.Command{
 	Use:   "show [contract-id]",
 	Short: "Show details of a specific inheritance contract",
-	Long:  `Show detailed information about a specific inheritance contract by its ID.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Show detailed information about a specific inheritance contract by its ID.
+Without an ID the active contract (see 'use') is shown.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return showContract(args[0])
+		contractID, err := resolveContractID(args)
+		if err != nil {
+			return err
+		}
+		return showContract(contractID)
 	},
 }
 
@@ -98,22 +178,24 @@ var listCmd = &cobra.Command{
 }
 
 var ownerWithdrawCmd = &cobra.Command{
-	Use:   "owner-withdraw",
+	Use:   "owner-withdraw [contract-id]",
 	Short: "Create owner withdrawal transaction",
 	Long: `Create and sign a transaction for the owner to withdraw funds immediately.
 This uses the IF path of the contract script.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return ownerWithdraw()
+		return ownerWithdraw(args)
 	},
 }
 
 var inheritorWithdrawCmd = &cobra.Command{
-	Use:   "inheritor-withdraw",
+	Use:   "inheritor-withdraw [contract-id]",
 	Short: "Create inheritor withdrawal transaction",
 	Long: `Create and sign a transaction for the inheritor to withdraw funds after timelock.
 This uses the ELSE path of the contract script and requires the timelock to have expired.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return inheritorWithdraw()
+		return inheritorWithdraw(args)
 	},
 }
 
@@ -121,6 +203,45 @@ func init() {
 	// Add persistent flags
 	rootCmd.PersistentFlags().BoolVar(&testnet, "testnet", true, "Use testnet (default: true)")
 	rootCmd.PersistentFlags().Int64Var(&timelockDays, "timelock-days", 0, "Timelock duration in days (default: 180)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "YAML or TOML config file with the settings of .env; environment variables take precedence")
+	rootCmd.PersistentFlags().BoolVar(&showSummary, "summary", false, "Print a summary of the operations performed when the command finishes")
+	rootCmd.PersistentFlags().StringVar(&withdrawFeeRate, "fee-rate", "", "Withdrawal fee rate in sat/vB, or \"auto\" to ask the node; preferred over --fee (default: DEFAULT_FEE_RATE)")
+	rootCmd.PersistentFlags().DurationVar(&rpcTimeout, "rpc-timeout", rpc.DefaultTimeout, "Give up on an RPC call to the node after this long, e.g. 10s or 2m")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to every confirmation prompt (DANGEROUS: broadcasts without asking)")
+
+	// Generate flags
+	generateCmd.Flags().StringVar(&inheritorPubKeyArg, "inheritor-pubkey", "", "Use the inheritor's public key (hex, or a signed public key file from prove-key) instead of generating one")
+	generateCmd.Flags().BoolVar(&allowShortTimelock, "allow-short-timelock", false, "Allow a timelock below the configured minimum (MIN_TIMELOCK_DAYS)")
+	generateCmd.Flags().BoolVar(&allowSameKey, "allow-same-key", false, "Allow the inheritor public key to equal the owner's")
+	generateCmd.Flags().BoolVar(&generateFromSeed, "from-seed", false, "Derive the keys from a master seed (prompted, hex) instead of generating them")
+	generateCmd.Flags().BoolVar(&generateMnemonic, "mnemonic", false, "Derive the keys from a BIP39 mnemonic (prompted; leave empty to generate a new one)")
+	generateCmd.Flags().IntVar(&mnemonicWords, "mnemonic-words", 12, "Length of a newly generated mnemonic: 12 or 24 words")
+	generateCmd.Flags().Uint32Var(&generateIndex, "index", 0, "Contract index under the seed (with --from-seed or --mnemonic; default: the next unused one)")
+	generateCmd.Flags().BoolVar(&generateReplace, "replace", false, "Restore the keys of an existing contract derived from the seed (with --from-seed or --mnemonic)")
+	generateCmd.Flags().Int64Var(&generateTimelockSeconds, "timelock-seconds", 0, "Timelock duration in seconds, rounded up to 512-second intervals (instead of --timelock-days)")
+	generateCmd.Flags().Int64Var(&generateTimelockBlocks, "timelock-blocks", 0, "Timelock in blocks confirmed after funding, at most 65535 (instead of --timelock-days)")
+	generateCmd.Flags().BoolVar(&generateEncrypt, "encrypt", false, "Encrypt the private keys with a passphrase (default: when CONTRACT_PASSPHRASE is set)")
+	generateCmd.Flags().StringVar(&generateUnlockDate, "unlock-date", "", "Let the inheritor spend from this date on (YYYY-MM-DD or RFC 3339, UTC), regardless of when the contract is funded")
+
+	// Owner withdrawal flags
+	ownerWithdrawCmd.Flags().Int64Var(&withdrawAmount, "amount", 0, "Withdraw only this many satoshis (default: sweep everything)")
+	ownerWithdrawCmd.Flags().BoolVar(&changeToContract, "change-to-contract", false, "Send the change of a partial withdrawal back to the contract address")
+	ownerWithdrawCmd.Flags().StringVar(&changeAddress, "change-address", "", "Send the change of a partial withdrawal to this address")
+	ownerWithdrawCmd.Flags().BoolVar(&ownerRBF, "rbf", false, "Signal BIP 125 replaceability so the withdrawal can be fee-bumped")
+
+	// Fee flags shared by both withdrawal commands
+	for _, cmd := range []*cobra.Command{ownerWithdrawCmd, inheritorWithdrawCmd} {
+		cmd.Flags().Int64Var(&withdrawFee, "fee", 0, "Flat fee in satoshis (default: DEFAULT_FEE_SATOSHIS)")
+		cmd.Flags().BoolVar(&allowHighFee, "allow-high-fee-rate", false, "Skip the MAX_FEE_RATE check on the effective fee rate")
+		cmd.Flags().BoolVar(&grindLowR, "low-r", true, "Grind signatures for a low R value (71-byte signatures, as modern wallets do); --low-r=false disables it")
+		cmd.Flags().StringVar(&withdrawSigHash, "sighash", "all", "Signature hash type: all, single, all|anyonecanpay or single|anyonecanpay")
+		cmd.Flags().StringVar(&destinationAddr, "to", "", "Destination address (default: prompt for it)")
+	}
+
+	// Inheritor withdrawal flags
+	inheritorWithdrawCmd.Flags().Int64Var(&withdrawAmount, "amount", 0, "Withdraw only this many satoshis and return the rest to the contract, where the timelock applies again (default: sweep everything)")
+	inheritorWithdrawCmd.Flags().StringVar(&storeWithdrawalPath, "save", "", "Pre-sign and save the withdrawal to this file instead of broadcasting (see broadcast-stored)")
+	inheritorWithdrawCmd.Flags().Float64SliceVar(&feeLadder, "fee-ladder", nil, "Pre-build alternatives at these fee rates in sat/vB (e.g. 2,5,10)")
 
 	// Add subcommands
 	rootCmd.AddCommand(generateCmd)
@@ -130,42 +251,234 @@ func init() {
 	rootCmd.AddCommand(inheritorWithdrawCmd)
 }
 
+// checkNodeNetwork compares the configured network with the chain of the
+// node behind rpcClient and fails when they differ. Generating needs no node,
+// so a node that cannot be asked is returned as unreachable instead.
+func checkNodeNetwork(rpcClient *rpc.RPCClient) (unreachable error, err error) {
+	network, err := rpcClient.DetectNetwork()
+	if err != nil {
+		return err, nil
+	}
+	if network != cfg.ChainParams.Name {
+		return nil, fmt.Errorf("the configured network is %s, but the node at %s is on %s; check BITCOIN_NETWORK, --testnet and the RPC host",
+			cfg.ChainParams.Name, cfg.RPCConfig.Host, network)
+	}
+	log.Printf("Node network: %s (matches the configuration)", network)
+	return nil, nil
+}
+
 func generateContract() error {
 	log.Printf("=== Generating Bitcoin Inheritance Contract ===")
 
 	// Step 1: Generate keys for owner and inheritor
 	log.Printf("Step 1: Generating cryptographic keys...")
-	inheritanceKeys, err := keys.GenerateInheritanceKeys(cfg.ChainParams)
+	var ownerKeys *keys.KeyPair
+	var ownerPubKey, inheritorPubKey []byte
+	var inheritorWIF string
+	var err error
+	seedDerived := generateFromSeed || generateMnemonic
+	if generateFromSeed && generateMnemonic {
+		return fmt.Errorf("use either --from-seed or --mnemonic, not both")
+	}
+	if (generateReplace || generateIndexSet) && !seedDerived {
+		return fmt.Errorf("--index and --replace require --from-seed or --mnemonic")
+	}
+	addressType, err := parseGenerateAddressType()
+	if err != nil {
+		return err
+	}
+
+	// Never derive an address for another network than the node's
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+	nodeUnreachable, err := checkNodeNetwork(rpcClient)
 	if err != nil {
-		return fmt.Errorf("failed to generate keys: %w", err)
+		return err
+	}
+
+	var keySource, keyFingerprint string
+	if generateWatchOnly {
+		// Both keys are held elsewhere; no private key is generated
+		ownerPubKey, inheritorPubKey, err = watchOnlyKeys()
+		if err != nil {
+			return err
+		}
+	} else if seedDerived {
+		if inheritorPubKeyArg != "" {
+			return fmt.Errorf("--from-seed and --mnemonic derive both keys and cannot be combined with --inheritor-pubkey")
+		}
+		var master *hdkeychain.ExtendedKey
+		if generateMnemonic {
+			master, err = readMnemonicMaster()
+			keySource = contract.KeySourceBIP39
+		} else {
+			master, err = readSeedMaster()
+			keySource = contract.KeySourceSeed
+		}
+		if err != nil {
+			return err
+		}
+		if keyFingerprint, err = hdkeys.Fingerprint(master); err != nil {
+			return err
+		}
+		if generateIndex, err = resolveKeyIndex(keyFingerprint); err != nil {
+			return err
+		}
+		inheritanceKeys, err := hdkeys.DeriveMasterKeys(master, generateIndex, cfg.ChainParams)
+		if err != nil {
+			return err
+		}
+		log.Printf("Derived owner key at %s", hdkeys.Path(cfg.ChainParams, generateIndex, hdkeys.RoleOwner))
+		log.Printf("Derived inheritor key at %s", hdkeys.Path(cfg.ChainParams, generateIndex, hdkeys.RoleInheritor))
+		ownerKeys = inheritanceKeys.Owner
+		inheritorPubKey = inheritanceKeys.Inheritor.GetCompressedPubKeyBytes()
+		inheritorWIF = inheritanceKeys.Inheritor.WIF.String()
+	} else if inheritorPubKeyArg != "" {
+		// The inheritor keeps their own private key; only the owner key is generated
+		inheritorPubKey, err = loadInheritorPubKey(inheritorPubKeyArg)
+		if err != nil {
+			return err
+		}
+		ownerKeys, err = keys.NewKeyPair(cfg.ChainParams)
+		if err != nil {
+			return fmt.Errorf("failed to generate owner keys: %w", err)
+		}
+		log.Printf("Generated owner keys - WIF: %s", ownerKeys.WIF.String())
+	} else {
+		inheritanceKeys, err := keys.GenerateInheritanceKeys(cfg.ChainParams)
+		if err != nil {
+			return fmt.Errorf("failed to generate keys: %w", err)
+		}
+		ownerKeys = inheritanceKeys.Owner
+		inheritorPubKey = inheritanceKeys.Inheritor.GetCompressedPubKeyBytes()
+		inheritorWIF = inheritanceKeys.Inheritor.WIF.String()
 	}
 
 	// Step 2: Create the inheritance script
 	log.Printf("Step 2: Building inheritance script...")
-	ownerPubKey := inheritanceKeys.Owner.GetCompressedPubKeyBytes()
-	inheritorPubKey := inheritanceKeys.Inheritor.GetCompressedPubKeyBytes()
+	var ownerSigner keys.Signer
+	var ownerWIF string
+	if ownerKeys != nil {
+		ownerPubKey = ownerKeys.GetCompressedPubKeyBytes()
+		ownerSigner = ownerKeys.Signer()
+		ownerWIF = ownerKeys.WIF.String()
+	}
+
+	scriptOpts := []script.Option{script.WithMinTimelockDays(cfg.Contract.MinTimelockDays)}
+	if allowShortTimelock {
+		scriptOpts = append(scriptOpts, script.AllowShortTimelock())
+	}
+	if allowSameKey {
+		scriptOpts = append(scriptOpts, script.AllowSameKey())
+	}
+	requestedTimelock := fmt.Sprintf("%d days", cfg.Contract.TimelockDays)
+	shortTimelock := cfg.Contract.TimelockDays < cfg.Contract.MinTimelockDays
+	if generateTimelockSeconds != 0 {
+		if timelockDays > 0 {
+			return fmt.Errorf("use either --timelock-days or --timelock-seconds, not both")
+		}
+		if generateTimelockSeconds < 0 {
+			return fmt.Errorf("--timelock-seconds must be positive")
+		}
+		scriptOpts = append(scriptOpts, script.WithTimelockSeconds(generateTimelockSeconds))
+		requestedTimelock = fmt.Sprintf("%d seconds", generateTimelockSeconds)
+		shortTimelock = generateTimelockSeconds < cfg.Contract.MinTimelockDays*24*60*60
+	}
+	if generateTimelockBlocks != 0 {
+		if timelockDays > 0 || generateTimelockSeconds != 0 {
+			return fmt.Errorf("--timelock-blocks cannot be combined with --timelock-days or --timelock-seconds")
+		}
+		if _, err := script.RelativeTimelockFromBlocks(generateTimelockBlocks); err != nil {
+			return err
+		}
+		scriptOpts = append(scriptOpts, script.WithTimelockBlocks(generateTimelockBlocks))
+		requestedTimelock = fmt.Sprintf("%d blocks", generateTimelockBlocks)
+		shortTimelock = time.Duration(generateTimelockBlocks)*script.BlockInterval < time.Duration(cfg.Contract.MinTimelockDays)*24*time.Hour
+	}
+	var lockTime int64
+	if generateUnlockDate != "" {
+		if timelockDays > 0 || generateTimelockSeconds != 0 || generateTimelockBlocks != 0 {
+			return fmt.Errorf("--unlock-date cannot be combined with --timelock-days, --timelock-seconds or --timelock-blocks")
+		}
+		lockTime, err = parseUnlockDate(generateUnlockDate)
+		if err != nil {
+			return err
+		}
+		scriptOpts = append(scriptOpts, script.WithAbsoluteTimelock(lockTime))
+		requestedTimelock = "until " + script.FormatLockTime(lockTime)
+		shortTimelock = time.Until(time.Unix(lockTime, 0)) < time.Duration(cfg.Contract.MinTimelockDays)*24*time.Hour
+	}
+	if shortTimelock {
+		if !allowShortTimelock {
+			return fmt.Errorf("timelock of %s is below the minimum of %d days; pass --allow-short-timelock to override",
+				requestedTimelock, cfg.Contract.MinTimelockDays)
+		}
+		log.Printf("⚠️  WARNING: SHORT TIMELOCK (%s, minimum %d days)", requestedTimelock, cfg.Contract.MinTimelockDays)
+		if lockTime != 0 {
+			log.Printf("⚠️  The inheritor will be able to spend these funds from %s on!", script.FormatLockTime(lockTime))
+		} else {
+			log.Printf("⚠️  The inheritor will be able to spend these funds %s after funding!", requestedTimelock)
+		}
+	}
 
-	inheritanceScript, err := script.NewInheritanceScript(
-		ownerPubKey,
-		inheritorPubKey,
-		cfg.Contract.TimelockDays,
-		cfg.ChainParams,
-	)
+	var inheritanceScript *script.InheritanceScript
+	if len(generateTiers) > 0 {
+		tiers, err := contractTiers(inheritorPubKey)
+		if err != nil {
+			return err
+		}
+		inheritanceScript, err = script.NewTieredInheritanceScript(ownerPubKey, tiers, cfg.ChainParams, scriptOpts...)
+	} else {
+		inheritanceScript, err = script.NewInheritanceScript(
+			ownerPubKey,
+			inheritorPubKey,
+			cfg.Contract.TimelockDays,
+			cfg.ChainParams,
+			scriptOpts...,
+		)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create inheritance script: %w", err)
 	}
 
+	// Record the effective lock, which may be rounded up from the request
+	var timelockSeconds int64
+	if generateTimelockSeconds != 0 {
+		timelockSeconds = (inheritanceScript.RelativeTimelock & 0xffff) * script.TimelockGranularity
+		cfg.Contract.TimelockDays = timelockSeconds / (24 * 60 * 60)
+	}
+	if generateTimelockBlocks != 0 {
+		// Whole days at the expected block interval
+		cfg.Contract.TimelockDays = int64(script.RelativeTimelockDuration(inheritanceScript.RelativeTimelock) / (24 * time.Hour))
+	}
+	if lockTime != 0 {
+		// An absolute timelock has no duration
+		cfg.Contract.TimelockDays = 0
+	}
+
 	// Step 3: Validate the script
 	log.Printf("Step 3: Validating script...")
 	if err := inheritanceScript.ValidateScript(); err != nil {
 		return fmt.Errorf("script validation failed: %w", err)
 	}
 
-	// Step 4: Generate P2WSH address
-	log.Printf("Step 4: Generating P2WSH funding address...")
-	p2wshAddr, err := inheritanceScript.GetP2WSHAddress()
+	// Test-spend both paths before any funds are sent to the script
+	var inheritorSigner keys.Signer
+	if inheritorWIF != "" {
+		inheritorKeys, err := keys.KeyPairFromWIF(inheritorWIF, cfg.ChainParams)
+		if err != nil {
+			return fmt.Errorf("failed to load inheritor keys: %w", err)
+		}
+		inheritorSigner = inheritorKeys.Signer()
+	}
+	if err := inheritanceScript.ValidateSpendable(ownerSigner, inheritorSigner); err != nil {
+		return fmt.Errorf("script validation failed: %w", err)
+	}
+
+	// Step 4: Generate the funding address
+	log.Printf("Step 4: Generating %s funding address...", addressType)
+	p2wshAddr, err := inheritanceScript.GetAddress(addressType)
 	if err != nil {
-		return fmt.Errorf("failed to generate P2WSH address: %w", err)
+		return fmt.Errorf("failed to generate funding address: %w", err)
 	}
 
 	// Step 5: Save contract details and provide funding instructions
@@ -176,44 +489,106 @@ func generateContract() error {
 
 	// Create contract info structure
 	contractInfo := &contract.ContractInfo{
-		ContractID:   contractID,
-		CreatedAt:    time.Now(),
-		Network:      cfg.ChainParams.Name,
-		TimelockDays: cfg.Contract.TimelockDays,
-		OwnerWIF:     inheritanceKeys.Owner.WIF.String(),
-		InheritorWIF: inheritanceKeys.Inheritor.WIF.String(),
-		RedeemScript: fmt.Sprintf("%x", inheritanceScript.RedeemScript),
-		P2WSHAddress: p2wshAddr.EncodeAddress(),
-		ScriptHash:   fmt.Sprintf("%x", inheritanceScript.GetScriptHash()),
-		IsFunded:     false,
+		ContractID:      contractID,
+		CreatedAt:       time.Now(),
+		Network:         cfg.ChainParams.Name,
+		TimelockDays:    cfg.Contract.TimelockDays,
+		TimelockSeconds: timelockSeconds,
+		TimelockBlocks:  generateTimelockBlocks,
+		LockTime:        lockTime,
+		OwnerWIF:        ownerWIF,
+		InheritorWIF:    inheritorWIF,
+		InheritorTiers:  tierInfos(inheritanceScript),
+		IsWatchOnly:     generateWatchOnly,
+		KeySource:       keySource,
+		AddressType:     addressType,
+		RedeemScript:    fmt.Sprintf("%x", inheritanceScript.RedeemScript),
+		P2WSHAddress:    p2wshAddr.EncodeAddress(),
+		ScriptHash:      fmt.Sprintf("%x", inheritanceScript.GetScriptHash()),
+		IsFunded:        false,
+	}
+
+	if seedDerived {
+		contractInfo.KeyFingerprint = keyFingerprint
+		contractInfo.KeyIndex = generateIndex
+		contractInfo.OwnerPath = hdkeys.Path(cfg.ChainParams, generateIndex, hdkeys.RoleOwner)
+		contractInfo.InheritorPath = hdkeys.Path(cfg.ChainParams, generateIndex, hdkeys.RoleInheritor)
+	}
+
+	// A seed-derived contract may already be stored; never duplicate or
+	// silently overwrite it
+	if seedDerived {
+		existing, err := contract.LoadContractInfo(contractID)
+		switch {
+		case err != nil && generateReplace:
+			return fmt.Errorf("no stored contract matches the derived address %s (contract %s); check the seed, --index and --timelock-days",
+				p2wshAddr.EncodeAddress(), contractID)
+		case err == nil && !generateReplace:
+			return fmt.Errorf("contract %s already exists; pass --replace to restore its keys from the seed", contractID)
+		case err == nil:
+			restored, err := restoreContractKeys(existing, contractInfo)
+			if err != nil {
+				return err
+			}
+			contractInfo = restored
+		}
+	}
+
+	// A restored contract keeps the encryption of the stored one, and a
+	// watch-only contract has nothing to encrypt
+	if (generateEncrypt || cfg.Contract.Passphrase != "") && !contractInfo.IsEncrypted() && !generateWatchOnly {
+		passphrase, err := newPassphrase()
+		if err != nil {
+			return err
+		}
+		contractInfo.SetPassphrase(passphrase)
+	}
+	if !contractInfo.IsEncrypted() && !generateWatchOnly {
+		log.Printf("Warning: The private keys are stored in plaintext; pass --encrypt or run migrate-encrypt to protect them")
 	}
 
 	// Save contract to file
 	if err := contract.SaveContractInfo(contractInfo); err != nil {
 		log.Printf("Warning: Failed to save contract info: %v", err)
+	} else if generateReplace {
+		log.Printf("Contract %s restored from %s index %d; funding status and history kept", contractID, contractInfo.KeySource, generateIndex)
+		session.record("contracts restored")
 	} else {
 		log.Printf("Contract details saved to: contracts/%s.json", contractID)
+		session.record("contracts generated")
 	}
 
-	// Test RPC connection (optional)
-	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
-	if err := rpcClient.TestConnection(); err != nil {
-		log.Printf("Warning: RPC connection test failed: %v", err)
+	// Report the RPC connection tested at the start (optional)
+	if nodeUnreachable != nil {
+		log.Printf("Warning: RPC connection test failed: %v", nodeUnreachable)
 		log.Printf("You can still fund the contract manually using the address above")
 	} else {
 		log.Printf("RPC connection successful - ready for automated operations")
-		// TODO: Implement automated funding and transaction broadcasting
+		log.Printf("To fund from the node's wallet: fund %s <amount-satoshis>", contractID)
 	}
 
 	// Provide funding instructions
 	log.Printf("\n=== Next Steps ===")
 	log.Printf("1. Send Bitcoin to the contract address: %s", p2wshAddr.EncodeAddress())
 	log.Printf("2. The contract will be active once funded")
-	log.Printf("3. Use 'owner-withdraw' command to spend as owner (immediate)")
-	log.Printf("4. Use 'inheritor-withdraw' command to spend as inheritor (after %d days)", cfg.Contract.TimelockDays)
+	if generateWatchOnly {
+		log.Printf("3. Use 'owner-withdraw' to write an unsigned owner PSBT (immediate) and sign it where the owner key is kept")
+		log.Printf("4. Use 'inheritor-withdraw' to write an unsigned inheritor PSBT (%s)", inheritorAvailability(contractInfo))
+	} else {
+		log.Printf("3. Use 'owner-withdraw' command to spend as owner (immediate)")
+		log.Printf("4. Use 'inheritor-withdraw' command to spend as inheritor (%s)", inheritorAvailability(contractInfo))
+	}
+	for i := 1; i < len(contractInfo.InheritorTiers); i++ {
+		log.Printf("   Tier %d inheritor: 'inheritor-withdraw --tier %d' after %d days, with their own key",
+			i+1, i+1, contractInfo.InheritorTiers[i].TimelockDays)
+	}
 	log.Printf("5. Contract ID for future reference: %s", contractID)
 
-	return nil
+	if err := renderAddressQR(p2wshAddr.EncodeAddress()); err != nil {
+		return err
+	}
+	printResult("%s", p2wshAddr.EncodeAddress())
+	return emitJSON(contractInfo)
 }
 
 func showContract(contractID string) error {
@@ -225,26 +600,74 @@ func showContract(contractID string) error {
 	}
 
 	log.Printf("Contract ID: %s", contractInfo.ContractID)
+	if contractInfo.Label != "" {
+		log.Printf("Label: %s", contractInfo.Label)
+	}
 	log.Printf("Network: %s", contractInfo.Network)
 	log.Printf("Created: %s", contractInfo.CreatedAt.Format("2006-01-02 15:04:05 MST"))
-	log.Printf("Timelock: %d days", contractInfo.TimelockDays)
+	log.Printf("Timelock: %s", timelockSummary(contractInfo))
+
+	// The disassembly is shown even when the script does not parse, which is
+	// when it helps most
+	redeemScript, scriptErr := hex.DecodeString(contractInfo.RedeemScript)
+	inheritanceScript := &script.InheritanceScript{RedeemScript: redeemScript}
+	if scriptErr == nil {
+		if parsed, err := script.ParseRedeemScript(redeemScript, cfg.ChainParams); err == nil {
+			inheritanceScript = parsed
+			log.Printf("Enforced Timelock: %s", parsed.DescribeTimelock())
+			if err := checkStoredTimelock(contractInfo, parsed); err != nil {
+				log.Printf("Warning: the stored timelock does not match the redeem script: %v; run verify", err)
+			}
+		}
+	}
 	log.Printf("")
-	log.Printf("Funding Address (P2WSH): %s", contractInfo.P2WSHAddress)
+	log.Printf("Funding Address: %s", contractInfo.P2WSHAddress)
+	log.Printf("Address Type: %s", contractInfo.AddressType)
 	log.Printf("Script Hash: %s", contractInfo.ScriptHash)
 	log.Printf("Redeem Script: %s", contractInfo.RedeemScript)
+	if scriptErr == nil {
+		if disasm, err := inheritanceScript.Disassemble(); err == nil {
+			log.Printf("Redeem Script (disassembled): %s", disasm)
+		}
+	}
 	log.Printf("")
-	log.Printf("Owner WIF: %s", contractInfo.OwnerWIF)
-	log.Printf("Inheritor WIF: %s", contractInfo.InheritorWIF)
+	log.Printf("Owner WIF: %s", storedWIF(contractInfo.OwnerWIF))
+	log.Printf("Inheritor WIF: %s", storedWIF(contractInfo.InheritorWIF))
+	for i, tier := range contractInfo.InheritorTiers {
+		log.Printf("Inheritor Tier %d: %s after %d days", i+1, tier.PubKey, tier.TimelockDays)
+	}
+	log.Printf("Key Storage: %s", contractInfo.KeyStorage())
+	if contractInfo.KeySource != "" {
+		log.Printf("Key Derivation: %s, master key %s, index %d (owner %s, inheritor %s)",
+			contractInfo.KeySource, contractInfo.KeyFingerprint, contractInfo.KeyIndex,
+			contractInfo.OwnerPath, contractInfo.InheritorPath)
+	}
 	log.Printf("")
 	log.Printf("Funding Status: %t", contractInfo.IsFunded)
 	if contractInfo.IsFunded {
-		log.Printf("Funding Transaction: %s:%d", contractInfo.FundingTxID, contractInfo.FundingVout)
-		log.Printf("Funding Amount: %d satoshis", contractInfo.FundingAmount)
+		for _, outpoint := range contractInfo.FundingUTXOs {
+			log.Printf("Funding Transaction: %s:%d (%d satoshis)", outpoint.TxID, outpoint.Vout, outpoint.Amount)
+		}
+		log.Printf("Funding Amount: %d satoshis", contractInfo.TotalFunding())
+		if expiry, err := contractInfo.Expiry(); err == nil {
+			log.Printf("Inheritor Unlock: around %s", expiry.Format("2006-01-02 15:04 MST"))
+		}
 	} else {
 		log.Printf("To fund this contract, send Bitcoin to: %s", contractInfo.P2WSHAddress)
 	}
 
-	return nil
+	if err := renderAddressQR(contractInfo.P2WSHAddress); err != nil {
+		return err
+	}
+	return emitJSON(contractInfo)
+}
+
+// storedWIF shows a stored WIF, or notes that none is stored
+func storedWIF(wif string) string {
+	if wif == "" {
+		return "(not stored)"
+	}
+	return wif
 }
 
 func listContracts() error {
@@ -255,45 +678,59 @@ func listContracts() error {
 		return fmt.Errorf("failed to list contracts: %w", err)
 	}
 
+	entries := []contractListEntry{}
 	if len(contractIDs) == 0 {
 		log.Printf("No contracts found. Use 'generate' command to create a new contract.")
-		return nil
+		return emitJSON(entries)
 	}
 
+	var unreadable int
 	for i, contractID := range contractIDs {
 		contractInfo, err := contract.LoadContractInfo(contractID)
 		if err != nil {
 			log.Printf("%d. %s (error loading: %v)", i+1, contractID, err)
+			entries = append(entries, contractListEntry{ContractID: contractID, Error: err.Error()})
+			unreadable++
 			continue
 		}
+		entries = append(entries, newContractListEntry(contractInfo))
 
 		log.Printf("%d. Contract ID: %s", i+1, contractInfo.ContractID)
+		if contractInfo.Label != "" {
+			log.Printf("   Label: %s", contractInfo.Label)
+		}
 		log.Printf("   Network: %s", contractInfo.Network)
 		log.Printf("   Created: %s", contractInfo.CreatedAt.Format("2006-01-02 15:04:05"))
-		log.Printf("   Timelock: %d days", contractInfo.TimelockDays)
+		log.Printf("   Timelock: %s", timelockSummary(contractInfo))
 		log.Printf("   Address: %s", contractInfo.P2WSHAddress)
 		log.Printf("   Funded: %t", contractInfo.IsFunded)
 		if contractInfo.IsFunded {
-			log.Printf("   Funding: %d satoshis (txid: %s:%d)",
-				contractInfo.FundingAmount, contractInfo.FundingTxID, contractInfo.FundingVout)
+			if len(contractInfo.FundingUTXOs) > 1 {
+				log.Printf("   Funding: %d satoshis in %d UTXOs", contractInfo.TotalFunding(), len(contractInfo.FundingUTXOs))
+			} else {
+				log.Printf("   Funding: %d satoshis (txid: %s:%d)",
+					contractInfo.FundingAmount, contractInfo.FundingTxID, contractInfo.FundingVout)
+			}
 		}
 		log.Printf("")
 	}
 
-	return nil
+	if unreadable > 0 {
+		log.Printf("%d contract files could not be loaded; run 'repair' to restore or quarantine them", unreadable)
+	}
+
+	return emitJSON(entries)
 }
 
-func ownerWithdraw() error {
+func ownerWithdraw(args []string) error {
 	log.Printf("=== Owner Withdrawal ===")
 
 	// Step 1: Get contract ID from user
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter contract ID: ")
-	contractID, err := reader.ReadString('\n')
+	contractID, err := withdrawContractID(reader, args)
 	if err != nil {
-		return fmt.Errorf("failed to read contract ID: %w", err)
+		return err
 	}
-	contractID = strings.TrimSpace(contractID)
 
 	// Step 2: Load contract details and UTXO information
 	log.Printf("Step 1: Loading contract details...")
@@ -307,8 +744,10 @@ func ownerWithdraw() error {
 	}
 
 	log.Printf("Contract found: %s", contractInfo.P2WSHAddress)
-	log.Printf("Funding UTXO: %s:%d (%d satoshis)",
-		contractInfo.FundingTxID, contractInfo.FundingVout, contractInfo.FundingAmount)
+	logFundingUTXOs(contractInfo)
+	if contractInfo.IsWatchOnly {
+		return exportWatchOnlyWithdrawal(reader, contractID, "owner")
+	}
 
 	// Step 3: Load owner's private key from WIF
 	log.Printf("Step 2: Loading owner's private key...")
@@ -318,62 +757,71 @@ func ownerWithdraw() error {
 	}
 
 	// Step 4: Get owner's destination address
-	fmt.Print("Enter destination address for withdrawal: ")
-	destAddrStr, err := reader.ReadString('\n')
+	destAddr, err := readDestination(reader)
 	if err != nil {
-		return fmt.Errorf("failed to read destination address: %w", err)
+		return err
 	}
-	destAddrStr = strings.TrimSpace(destAddrStr)
 
-	destAddr, err := btcutil.DecodeAddress(destAddrStr, cfg.ChainParams)
+	// Step 5: Parse redeem script
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
 	if err != nil {
-		return fmt.Errorf("invalid destination address: %w", err)
+		return fmt.Errorf("failed to decode redeem script: %w", err)
 	}
 
-	// Step 5: Parse funding transaction hash
-	fundingHash, err := chainhash.NewHashFromStr(contractInfo.FundingTxID)
-	if err != nil {
-		return fmt.Errorf("invalid funding transaction hash: %w", err)
-	}
+	ctx, cancel := rpcContext()
+	defer cancel()
 
-	// Step 6: Parse redeem script
-	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	// Step 6: Create the contract's funding UTXOs
+	contractUTXOs, err := fundingUTXOs(ctx, contractInfo)
 	if err != nil {
-		return fmt.Errorf("failed to decode redeem script: %w", err)
+		return err
 	}
-
-	// Step 7: Create UTXO for the contract
-	contractUTXO := &transaction.UTXO{
-		TxHash:   fundingHash,
-		Vout:     contractInfo.FundingVout,
-		Amount:   btcutil.Amount(contractInfo.FundingAmount),
-		PkScript: nil, // Will be filled by the signing process
+	if withdrawAmount > 0 && len(contractUTXOs) > 1 {
+		return fmt.Errorf("--amount needs a single funding UTXO; contract has %d, sweep them without --amount", len(contractUTXOs))
 	}
 
-	// Step 8: Build transaction using the IF path
+	// Step 7: Build transaction using the IF path
 	log.Printf("Step 3: Building withdrawal transaction...")
 
-	// Set a reasonable fee (500 satoshis)
-	fee := btcutil.Amount(500)
+	feeChoice, err := resolveWithdrawFee()
+	if err != nil {
+		return err
+	}
 
-	txBuilder := transaction.NewTransactionBuilder(cfg.ChainParams, fee)
-	tx, err := txBuilder.BuildOwnerWithdrawTx(contractUTXO, destAddr, redeemScript)
+	var changeScript []byte
+	if withdrawAmount > 0 {
+		if changeScript, err = ownerChangeScript(redeemScript, contractInfo.AddressType); err != nil {
+			return err
+		}
+	}
+
+	tx, txBuilder, _, err := buildWithFee(feeChoice, redeemScript, func(txBuilder *transaction.TransactionBuilder) (*wire.MsgTx, error) {
+		if withdrawAmount > 0 {
+			return txBuilder.BuildOwnerPartialWithdrawTx(contractUTXOs[0], destAddr, redeemScript,
+				btcutil.Amount(withdrawAmount), changeScript, ownerRBF)
+		}
+		return txBuilder.BuildOwnerWithdrawTxMulti(contractUTXOs, destAddr, redeemScript, ownerRBF)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to build transaction: %w", err)
 	}
 
-	// Step 9: Sign with owner's key and OP_1 selector
+	// Step 8: Sign with owner's key and the IF branch selector
 	log.Printf("Step 4: Signing transaction...")
-	if err := txBuilder.SignOwnerTransaction(tx, contractUTXO, redeemScript, ownerKeys.PrivateKey); err != nil {
+	if err := txBuilder.SignOwnerTransactionMulti(tx, contractUTXOs, redeemScript, ownerKeys.Signer()); err != nil {
 		return fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	// Step 10: Validate transaction
-	if err := txBuilder.ValidateTransaction(tx); err != nil {
+	// Step 9: Validate transaction
+	inputAmount := transaction.TotalAmount(contractUTXOs)
+	if err := txBuilder.ValidateTransactionWithScriptMulti(tx, contractUTXOs, redeemScript); err != nil {
 		return fmt.Errorf("transaction validation failed: %w", err)
 	}
+	if err := checkMaxFeeRate(tx, inputAmount); err != nil {
+		return err
+	}
 
-	// Step 11: Serialize transaction for broadcasting
+	// Step 10: Serialize transaction for broadcasting
 	txHex, err := txBuilder.SerializeTransaction(tx)
 	if err != nil {
 		return fmt.Errorf("failed to serialize transaction: %w", err)
@@ -382,46 +830,50 @@ func ownerWithdraw() error {
 	log.Printf("Transaction built successfully!")
 	log.Printf("Transaction hex: %s", txHex)
 
-	// Step 12: Ask user for confirmation before broadcasting
-	fmt.Print("Do you want to broadcast this transaction? (y/N): ")
-	confirm, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
+	if err := checkMempoolAccept(ctx, tx); err != nil {
+		return err
 	}
-	confirm = strings.TrimSpace(strings.ToLower(confirm))
 
-	if confirm != "y" && confirm != "yes" {
+	// Step 11: Ask user for confirmation before broadcasting
+	fee := inputAmount - totalOutput(tx)
+	if !confirm(reader, "Do you want to broadcast this transaction?") {
 		log.Printf("Transaction not broadcast (user cancelled)")
-		return nil
+		return emitWithdrawal(contractID, "owner", tx, fee, "")
 	}
 
-	// Step 13: Broadcast transaction
+	// Step 12: Broadcast transaction
 	log.Printf("Step 5: Broadcasting transaction...")
-	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
-
-	txid, err := rpcClient.BroadcastTransaction(tx)
+	broadcastCtx, cancelBroadcast := rpcContext()
+	defer cancelBroadcast()
+	txid, err := broadcastTransaction(broadcastCtx, tx)
 	if err != nil {
 		return fmt.Errorf("failed to broadcast transaction: %w", err)
 	}
 
 	log.Printf("✅ Transaction broadcast successfully!")
 	log.Printf("Transaction ID: %s", txid)
+
+	// Record the intended fee so it can be audited later
+	recordWithdrawal(contractID, txid, "owner", btcutil.Amount(tx.TxOut[0].Value), fee)
+
+	if withdrawAmount > 0 && changeToContract {
+		refundContractWithChange(contractID, txid, tx)
+	}
+
 	log.Printf("Owner withdrawal completed!")
 
-	return nil
+	return emitWithdrawal(contractID, "owner", tx, fee, txid)
 }
 
-func inheritorWithdraw() error {
+func inheritorWithdraw(args []string) error {
 	log.Printf("=== Inheritor Withdrawal ===")
 
 	// Step 1: Get contract ID from user
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter contract ID: ")
-	contractID, err := reader.ReadString('\n')
+	contractID, err := withdrawContractID(reader, args)
 	if err != nil {
-		return fmt.Errorf("failed to read contract ID: %w", err)
+		return err
 	}
-	contractID = strings.TrimSpace(contractID)
 
 	// Step 2: Load contract details and UTXO information
 	log.Printf("Step 1: Loading contract details...")
@@ -435,113 +887,1072 @@ func inheritorWithdraw() error {
 	}
 
 	log.Printf("Contract found: %s", contractInfo.P2WSHAddress)
-	log.Printf("Funding UTXO: %s:%d (%d satoshis)",
-		contractInfo.FundingTxID, contractInfo.FundingVout, contractInfo.FundingAmount)
+	logFundingUTXOs(contractInfo)
+	if contractInfo.IsWatchOnly {
+		return exportWatchOnlyWithdrawal(reader, contractID, "inheritor")
+	}
+
+	// The checks against the chain run before the key and destination
+	// prompts, so they get their own deadline
+	checkCtx, cancelCheck := rpcContext()
+	defer cancelCheck()
+
+	// The owner may have moved the funds, which makes the inheritance void
+	backend := newChainBackend()
+	for _, outpoint := range contractInfo.FundingUTXOs {
+		if err := checkFundingUnspent(checkCtx, backend, outpoint.TxID, outpoint.Vout); err != nil {
+			return err
+		}
+	}
 
 	// Step 3: Verify timelock has expired
-	// Calculate the required timelock in blocks (assuming 10 minutes per block)
-	relativeTimelock := contractInfo.TimelockDays * 24 * 6 // days * hours * blocks per hour
+	// Read the timelock from the redeem script itself so the transaction
+	// always matches what OP_CHECKSEQUENCEVERIFY or OP_CHECKLOCKTIMEVERIFY enforces
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	parsedScript, err := script.ParseRedeemScript(redeemScript, cfg.ChainParams)
+	if err != nil {
+		return fmt.Errorf("failed to read timelock from redeem script: %w", err)
+	}
+	tiers := parsedScript.InheritorTiers()
+	if withdrawTier < 1 || withdrawTier > len(tiers) {
+		return fmt.Errorf("--tier %d does not exist; the contract has %d inheritor tiers", withdrawTier, len(tiers))
+	}
 	log.Printf("Step 2: Verifying timelock has expired...")
-	log.Printf("Required timelock: %d blocks (%d days)", relativeTimelock, contractInfo.TimelockDays)
-	log.Printf("Note: This implementation requires manual verification that enough blocks have passed")
+	timelock := tiers[withdrawTier-1].RelativeTimelock
+	var timelockErr error
+	if parsedScript.TimelockType == script.Absolute {
+		timelock = parsedScript.LockTime
+		log.Printf("Required timelock: until %s", script.FormatLockTime(timelock))
+		timelockErr = checkLockTimeExpired(checkCtx, timelock)
+	} else {
+		summary := timelockSummary(contractInfo)
+		if len(tiers) > 1 {
+			summary = fmt.Sprintf("%d days (tier %d)", tiers[withdrawTier-1].TimelockDays, withdrawTier)
+		}
+		log.Printf("Required timelock: %s (BIP68 sequence %d)", summary, timelock)
+
+		// Every input carries the sequence, so every funding UTXO must have matured
+		for _, outpoint := range contractInfo.FundingUTXOs {
+			if timelockErr = checkTimelockExpired(checkCtx, outpoint.TxID, timelock); timelockErr != nil {
+				break
+			}
+		}
+	}
+	if timelockErr != nil {
+		if storeWithdrawalPath == "" {
+			return timelockErr
+		}
+		// A pre-signed withdrawal stays valid; it just cannot be broadcast yet
+		log.Printf("Note: %v", timelockErr)
+		log.Printf("Note: The saved transaction can be broadcast with broadcast-stored once the timelock matures")
+	}
 
 	// Step 4: Load inheritor's private key from WIF
 	log.Printf("Step 3: Loading inheritor's private key...")
-	inheritorKeys, err := keys.KeyPairFromWIF(contractInfo.InheritorWIF, cfg.ChainParams)
-	if err != nil {
-		return fmt.Errorf("failed to load inheritor keys: %w", err)
+	var inheritorKeys *keys.KeyPair
+	if withdrawTier > 1 {
+		// Later tiers hold their own keys
+		if inheritorKeys, err = readTierKey(reader, tiers[withdrawTier-1], withdrawTier); err != nil {
+			return err
+		}
+	} else {
+		if contractInfo.InheritorWIF == "" {
+			return fmt.Errorf("contract has no inheritor private key; it was built from the inheritor's public key and only the inheritor holds the private key")
+		}
+		if inheritorKeys, err = keys.KeyPairFromWIF(contractInfo.InheritorWIF, cfg.ChainParams); err != nil {
+			return fmt.Errorf("failed to load inheritor keys: %w", err)
+		}
 	}
 
 	// Step 5: Get inheritor's destination address
-	fmt.Print("Enter destination address for withdrawal: ")
-	destAddrStr, err := reader.ReadString('\n')
+	destAddr, err := readDestination(reader)
 	if err != nil {
-		return fmt.Errorf("failed to read destination address: %w", err)
+		return err
 	}
-	destAddrStr = strings.TrimSpace(destAddrStr)
 
-	destAddr, err := btcutil.DecodeAddress(destAddrStr, cfg.ChainParams)
-	if err != nil {
-		return fmt.Errorf("invalid destination address: %w", err)
-	}
+	ctx, cancel := rpcContext()
+	defer cancel()
 
-	// Step 6: Parse funding transaction hash
-	fundingHash, err := chainhash.NewHashFromStr(contractInfo.FundingTxID)
+	// Step 6: Create the contract's funding UTXOs
+	contractUTXOs, err := fundingUTXOs(ctx, contractInfo)
 	if err != nil {
-		return fmt.Errorf("invalid funding transaction hash: %w", err)
+		return err
 	}
+	contractUTXO := contractUTXOs[0]
 
-	// Step 7: Parse redeem script
-	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	// Step 7: Build transaction using the ELSE path with correct nSequence
+	log.Printf("Step 4: Building withdrawal transaction...")
+
+	feeChoice, err := resolveWithdrawFee()
 	if err != nil {
-		return fmt.Errorf("failed to decode redeem script: %w", err)
+		return err
 	}
-
-	// Step 8: Create UTXO for the contract
-	contractUTXO := &transaction.UTXO{
-		TxHash:   fundingHash,
-		Vout:     contractInfo.FundingVout,
-		Amount:   btcutil.Amount(contractInfo.FundingAmount),
-		PkScript: nil, // Will be filled by the signing process
+	if len(feeLadder) > 0 && !feeChoice.Source.IsDefault() {
+		return fmt.Errorf("--fee-ladder cannot be combined with --fee or --fee-rate")
+	}
+	if withdrawAmount > 0 && (len(feeLadder) > 0 || storeWithdrawalPath != "") {
+		return fmt.Errorf("--amount cannot be combined with --fee-ladder or --save")
+	}
+	if len(contractUTXOs) > 1 && (withdrawAmount > 0 || len(feeLadder) > 0 || storeWithdrawalPath != "") {
+		return fmt.Errorf("--amount, --fee-ladder and --save need a single funding UTXO; contract has %d", len(contractUTXOs))
 	}
 
-	// Step 9: Build transaction using the ELSE path with correct nSequence
-	log.Printf("Step 4: Building withdrawal transaction...")
-
-	// Set a reasonable fee (500 satoshis)
-	fee := btcutil.Amount(500)
-
-	txBuilder := transaction.NewTransactionBuilder(cfg.ChainParams, fee)
-	tx, err := txBuilder.BuildInheritorWithdrawTx(contractUTXO, destAddr, redeemScript, relativeTimelock)
+	var txs []*wire.MsgTx
+	var txBuilder *transaction.TransactionBuilder
+	if len(feeLadder) > 0 {
+		if txBuilder, err = newSigningBuilder(feeChoice.Fee); err != nil {
+			return err
+		}
+		logSuggestedFeeRate(txBuilder, feeChoice.Source)
+		txs, err = txBuilder.BuildInheritorWithdrawTxAtFees(contractUTXO, destAddr, redeemScript, timelock, feeLadder)
+	} else {
+		var tx *wire.MsgTx
+		tx, txBuilder, _, err = buildWithFee(feeChoice, redeemScript, func(txBuilder *transaction.TransactionBuilder) (*wire.MsgTx, error) {
+			if len(contractUTXOs) > 1 {
+				return txBuilder.BuildInheritorWithdrawTxMulti(contractUTXOs, destAddr, redeemScript, timelock)
+			}
+			return txBuilder.BuildInheritorWithdrawTx(contractUTXO, destAddr, redeemScript, timelock,
+				btcutil.Amount(withdrawAmount))
+		})
+		txs = []*wire.MsgTx{tx}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to build transaction: %w", err)
 	}
 
-	// Step 10: Sign with inheritor's key and OP_0 selector
+	// Step 8: Sign with inheritor's key and the ELSE branch selector
 	log.Printf("Step 5: Signing transaction...")
-	if err := txBuilder.SignInheritorTransaction(tx, contractUTXO, redeemScript, inheritorKeys.PrivateKey); err != nil {
-		return fmt.Errorf("failed to sign transaction: %w", err)
-	}
+	inputAmount := transaction.TotalAmount(contractUTXOs)
+	for i, tx := range txs {
+		if err := txBuilder.SignInheritorTierTransactionMulti(tx, contractUTXOs, redeemScript, withdrawTier-1, inheritorKeys.Signer()); err != nil {
+			return fmt.Errorf("failed to sign transaction: %w", err)
+		}
 
-	// Step 11: Validate transaction
-	if err := txBuilder.ValidateTransaction(tx); err != nil {
-		return fmt.Errorf("transaction validation failed: %w", err)
+		// Step 9: Validate transaction
+		if err := txBuilder.ValidateTransactionWithScriptMulti(tx, contractUTXOs, redeemScript); err != nil {
+			return fmt.Errorf("transaction validation failed: %w", err)
+		}
+		if err := checkMaxFeeRate(tx, inputAmount); err != nil {
+			return err
+		}
+
+		// Step 10: Serialize transaction for broadcasting
+		txHex, err := txBuilder.SerializeTransaction(tx)
+		if err != nil {
+			return fmt.Errorf("failed to serialize transaction: %w", err)
+		}
+
+		if len(txs) > 1 {
+			log.Printf("Alternative %d (%.2f sat/vB) hex: %s", i+1, sortedFeeRate(feeLadder, i), txHex)
+		} else {
+			log.Printf("Transaction built successfully!")
+			log.Printf("Transaction hex: %s", txHex)
+		}
 	}
 
-	// Step 12: Serialize transaction for broadcasting
-	txHex, err := txBuilder.SerializeTransaction(tx)
-	if err != nil {
-		return fmt.Errorf("failed to serialize transaction: %w", err)
+	if storeWithdrawalPath != "" {
+		return storeInheritorWithdrawal(contractInfo, parsedScript, tiers[withdrawTier-1].RelativeTimelock, contractUTXO, txs)
 	}
 
-	log.Printf("Transaction built successfully!")
-	log.Printf("Transaction hex: %s", txHex)
+	// Only the cheapest alternative is broadcast; keep the others for bumping
+	tx := txs[0]
+	if len(txs) > 1 {
+		log.Printf("Built %d fee alternatives. If the first one stalls, broadcast the next one.", len(txs))
+	}
 
-	// Step 13: Ask user for confirmation before broadcasting
-	fmt.Print("Do you want to broadcast this transaction? (y/N): ")
-	confirm, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
+	if err := checkMempoolAccept(ctx, tx); err != nil {
+		return err
 	}
-	confirm = strings.TrimSpace(strings.ToLower(confirm))
 
-	if confirm != "y" && confirm != "yes" {
+	// Step 11: Ask user for confirmation before broadcasting
+	fee := inputAmount - totalOutput(tx)
+	if !confirm(reader, "Do you want to broadcast this transaction?") {
 		log.Printf("Transaction not broadcast (user cancelled)")
-		return nil
+		return emitWithdrawal(contractID, "inheritor", tx, fee, "")
 	}
 
-	// Step 14: Broadcast transaction
+	// Step 12: Broadcast transaction
 	log.Printf("Step 6: Broadcasting transaction...")
-	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
-
-	txid, err := rpcClient.BroadcastTransaction(tx)
+	broadcastCtx, cancelBroadcast := rpcContext()
+	defer cancelBroadcast()
+	txid, err := broadcastTransaction(broadcastCtx, tx)
 	if err != nil {
 		return fmt.Errorf("failed to broadcast transaction: %w", err)
 	}
 
 	log.Printf("✅ Transaction broadcast successfully!")
 	log.Printf("Transaction ID: %s", txid)
+
+	// Record the intended fee so it can be audited later
+	recordWithdrawal(contractID, txid, "inheritor", btcutil.Amount(tx.TxOut[0].Value), fee)
+
+	if withdrawAmount > 0 {
+		refundContractWithChange(contractID, txid, tx)
+	}
+
 	log.Printf("Inheritor withdrawal completed!")
 
+	return emitWithdrawal(contractID, "inheritor", tx, fee, txid)
+}
+
+// refundContractWithChange makes the change output of a partial withdrawal
+// paid back to the contract its new funding UTXO. Without a change output,
+// because the change was dust and went to the fee, the contract is marked
+// as unfunded.
+func refundContractWithChange(contractID, txid string, tx *wire.MsgTx) {
+	if len(tx.TxOut) < 2 {
+		if err := contract.MarkSpent(contractID); err != nil {
+			log.Printf("Warning: Failed to update funding status: %v", err)
+		} else {
+			log.Printf("Note: the change was below the dust threshold and went to the fee; the contract is now empty")
+		}
+		return
+	}
+
+	changeVout := uint32(len(tx.TxOut) - 1)
+	changeValue := tx.TxOut[changeVout].Value
+	if err := contract.UpdateFundingStatus(contractID, txid, changeVout, changeValue); err != nil {
+		log.Printf("Warning: Failed to update funding status: %v", err)
+	} else {
+		log.Printf("Contract re-funded with change: %s:%d (%d satoshis)", txid, changeVout, changeValue)
+	}
+}
+
+// sortedFeeRate returns the i-th fee rate of the ladder in ascending order,
+// matching the order of the transactions built from it
+func sortedFeeRate(rates []float64, i int) float64 {
+	sorted := append([]float64(nil), rates...)
+	sort.Float64s(sorted)
+	return sorted[i]
+}
+
+// recordWithdrawal stores a broadcast withdrawal in the contract file and
+// counts it in the session summary
+func recordWithdrawal(contractID, txid, path string, moved, fee btcutil.Amount) {
+	session.recordTransfer(path+" withdrawals", moved, fee)
+
+	record := contract.WithdrawalRecord{
+		TxID:        txid,
+		Path:        path,
+		Fee:         int64(fee),
+		BroadcastAt: time.Now(),
+	}
+	if err := contract.RecordWithdrawal(contractID, record); err != nil {
+		log.Printf("Warning: Failed to record withdrawal: %v", err)
+	}
+}
+
+// checkTimelockExpired compares the median-time-past elapsed since the
+// funding block, or for a block-based timelock the blocks confirmed since,
+// against the timelock. It fails when the timelock has not yet expired. When
+// the node cannot provide the block data, the funding confirmations on the
+// chain backend decide instead, and only if those are unavailable too does
+// it merely warn.
+func checkTimelockExpired(ctx context.Context, fundingTxID string, relativeTimelock int64) error {
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+
+	warnUnverified := func(err error) error {
+		log.Printf("Warning: Could not verify the timelock against the chain: %v", err)
+
+		matured, remaining, err := checkTimelockConfirmations(fundingTxID, relativeTimelock)
+		if err != nil {
+			log.Printf("Warning: Could not count the funding confirmations either: %v", err)
+			log.Printf("Note: The transaction will be rejected if the timelock has not expired")
+			return nil
+		}
+		if !matured {
+			return fmt.Errorf("timelock has not expired: %s by confirmation count", formatBlocksRemaining(remaining))
+		}
+		log.Printf("Timelock expired by confirmation count")
+		return nil
+	}
+
+	fundingTx, err := rpcClient.GetRawTransactionCtx(ctx, fundingTxID)
+	if err != nil {
+		return warnUnverified(err)
+	}
+	if fundingTx.BlockHash == "" {
+		return fmt.Errorf("funding transaction is not confirmed yet; the timelock starts once it confirms")
+	}
+
+	if script.IsBlockBasedTimelock(relativeTimelock) {
+		fundingHeight, tipHeight, err := fetchHeights(ctx, rpcClient, fundingTx)
+		if err != nil {
+			return warnUnverified(err)
+		}
+		remaining, err := transaction.BlocksRemaining(relativeTimelock, fundingHeight, tipHeight)
+		if err != nil {
+			return fmt.Errorf("failed to check timelock: %w", err)
+		}
+		if remaining > 0 {
+			return fmt.Errorf("timelock has not expired: %s (spendable from block %d)",
+				formatBlocksRemaining(remaining), tipHeight+1+remaining)
+		}
+		log.Printf("Timelock expired: %d blocks confirmed since funding", tipHeight-fundingHeight+1)
+		return nil
+	}
+
+	fundingParentMTP, tipMTP, err := fetchMedianTimes(ctx, rpcClient, fundingTx)
+	if err != nil {
+		return warnUnverified(err)
+	}
+
+	remaining, err := transaction.TimelockRemaining(relativeTimelock, fundingParentMTP, tipMTP)
+	if err != nil {
+		return fmt.Errorf("failed to check timelock: %w", err)
+	}
+	if remaining > 0 {
+		return fmt.Errorf("timelock has not expired: %s remaining (around %s by median-time-past)",
+			remaining.Round(time.Minute), time.Unix(tipMTP, 0).Add(remaining).Format("2006-01-02 15:04 MST"))
+	}
+
+	log.Printf("Timelock expired: %s of median-time-past elapsed since funding",
+		(time.Duration(tipMTP-fundingParentMTP) * time.Second).Round(time.Minute))
 	return nil
 }
+
+// checkTimelockConfirmations checks a relative timelock by the confirmations
+// of the funding transaction on the chain backend
+func checkTimelockConfirmations(fundingTxID string, relativeTimelock int64) (bool, int64, error) {
+	fundingHash, err := chainhash.NewHashFromStr(fundingTxID)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid funding txid: %w", err)
+	}
+	return contract.CheckTimelockMatured(newChainBackend(), &transaction.UTXO{TxHash: fundingHash}, relativeTimelock)
+}
+
+// formatBlocksRemaining describes a number of blocks still to be mined, with
+// the days they take at one block every ten minutes
+func formatBlocksRemaining(blocks int64) string {
+	days := float64(blocks) * script.BlockInterval.Hours() / 24
+	return fmt.Sprintf("%d blocks / ~%.1f days remaining", blocks, days)
+}
+
+// checkLockTimeExpired checks an absolute timelock against the chain tip. It
+// fails when the lock time has not been reached and, like
+// checkTimelockExpired, only warns when the node cannot provide the block data.
+func checkLockTimeExpired(ctx context.Context, lockTime int64) error {
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+
+	remaining, err := lockTimeRemaining(ctx, rpcClient, lockTime)
+	if err != nil {
+		log.Printf("Warning: Could not verify the timelock against the chain: %v", err)
+		log.Printf("Note: The transaction will be rejected if the timelock has not expired")
+		return nil
+	}
+	if remaining > 0 {
+		return fmt.Errorf("timelock has not expired: about %s remaining (unlocks %s)",
+			remaining.Round(time.Minute), script.FormatLockTime(lockTime))
+	}
+
+	log.Printf("Timelock expired: the chain tip is past %s", script.FormatLockTime(lockTime))
+	return nil
+}
+
+// lockTimeRemaining returns how long an absolute timelock still runs. A
+// timestamp must be passed by the median-time-past of the chain tip (BIP 113);
+// the wait for a block height is estimated at ten minutes per block.
+func lockTimeRemaining(ctx context.Context, rpcClient *rpc.RPCClient, lockTime int64) (time.Duration, error) {
+	tipHash, err := rpcClient.GetBestBlockHashCtx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tip, err := rpcClient.GetBlockHeaderCtx(ctx, tipHash)
+	if err != nil {
+		return 0, err
+	}
+
+	// A transaction is final once its lock time is below the next block's
+	// height or the tip's median-time-past
+	if script.IsLockTimeTimestamp(lockTime) {
+		if tip.MedianTime > lockTime {
+			return 0, nil
+		}
+		return time.Duration(lockTime-tip.MedianTime+1) * time.Second, nil
+	}
+	if tip.Height >= lockTime {
+		return 0, nil
+	}
+	return time.Duration(lockTime-tip.Height) * 10 * time.Minute, nil
+}
+
+// timelockSummary describes a contract's timelock as recorded at generation
+func timelockSummary(contractInfo *contract.ContractInfo) string {
+	switch {
+	case contractInfo.LockTime != 0:
+		return "until " + script.FormatLockTime(contractInfo.LockTime)
+	case contractInfo.TimelockSeconds != 0:
+		return fmt.Sprintf("%d seconds", contractInfo.TimelockSeconds)
+	case contractInfo.TimelockBlocks != 0:
+		return fmt.Sprintf("%d blocks", contractInfo.TimelockBlocks)
+	default:
+		return fmt.Sprintf("%d days", contractInfo.TimelockDays)
+	}
+}
+
+// inheritorAvailability describes when the inheritor path opens
+func inheritorAvailability(contractInfo *contract.ContractInfo) string {
+	if contractInfo.LockTime != 0 {
+		return "from " + script.FormatLockTime(contractInfo.LockTime)
+	}
+	return "after " + timelockSummary(contractInfo)
+}
+
+// parseUnlockDate parses an --unlock-date value, either a date (midnight UTC)
+// or an RFC 3339 timestamp, into a BIP 65 timestamp lock time
+func parseUnlockDate(value string) (int64, error) {
+	unlock, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		unlock, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --unlock-date %q: expected YYYY-MM-DD or an RFC 3339 timestamp", value)
+		}
+	}
+	// Earlier values would be read as block heights
+	if !script.IsLockTimeTimestamp(unlock.Unix()) {
+		return 0, fmt.Errorf("--unlock-date %s is before the earliest timestamp lock time (%s)",
+			value, time.Unix(script.LockTimeThreshold, 0).UTC().Format(time.RFC3339))
+	}
+	return unlock.Unix(), nil
+}
+
+// spendScanBlocks bounds how many blocks after funding are scanned when
+// tracing the transaction that spent a contract
+const spendScanBlocks = 4320
+
+// checkFundingUnspent returns an error describing the spending transaction
+// when the funding UTXO has already been spent. The node looks the output up
+// and traces its spender; the Electrum and Esplora backends are checked by
+// checkFundingListed. Failures to query the backend are only logged so the
+// caller can carry on.
+func checkFundingUnspent(ctx context.Context, backend rpc.ChainBackend, fundingTxID string, vout uint32) error {
+	rpcClient, ok := backend.(*rpc.RPCClient)
+	if !ok {
+		return checkFundingListed(backend, fundingTxID, vout)
+	}
+
+	utxo, err := rpcClient.GetTxOutCtx(ctx, fundingTxID, vout)
+	if err != nil {
+		log.Printf("Warning: Could not check whether the funding UTXO is unspent: %v", err)
+		return nil
+	}
+	if utxo != nil {
+		return nil
+	}
+
+	// The node does not know the funding transaction at all; do not claim a spend
+	fundingTx, err := rpcClient.GetRawTransactionCtx(ctx, fundingTxID)
+	if err != nil {
+		log.Printf("Warning: Funding UTXO %s:%d is unknown to the node: %v", fundingTxID, vout, err)
+		return nil
+	}
+
+	fromHeight := int64(0)
+	if fundingTx.BlockHash != "" {
+		if fundingBlock, err := rpcClient.GetBlockHeaderCtx(ctx, fundingTx.BlockHash); err == nil {
+			fromHeight = fundingBlock.Height
+		}
+	}
+
+	spender, err := rpcClient.FindSpenderCtx(ctx, fundingTxID, vout, fromHeight, spendScanBlocks)
+	if err != nil || spender == nil {
+		if err != nil {
+			log.Printf("Warning: Could not trace the spending transaction: %v", err)
+		}
+		return fmt.Errorf("contract already spent: funding UTXO %s:%d no longer exists", fundingTxID, vout)
+	}
+
+	when := "in the mempool (unconfirmed)"
+	if !spender.InMempool {
+		when = "on " + time.Unix(spender.BlockTime, 0).UTC().Format("2006-01-02 15:04 MST")
+	}
+	return fmt.Errorf("contract already spent by %s %s in transaction %s", spendPath(spender.Witness), when, spender.TxID)
+}
+
+// checkFundingListed is checkFundingUnspent for a backend that cannot look
+// up a single output: the funding UTXO must still be listed as unspent at
+// the address it pays. Such a backend cannot trace the spender.
+func checkFundingListed(backend rpc.ChainBackend, fundingTxID string, vout uint32) error {
+	// The node does not know the funding transaction at all; do not claim a spend
+	fundingTx, err := backend.GetRawTransaction(fundingTxID)
+	if err != nil {
+		log.Printf("Warning: Funding UTXO %s:%d is unknown to the %s backend: %v", fundingTxID, vout, cfg.Backend, err)
+		return nil
+	}
+	if int(vout) >= len(fundingTx.Vout) {
+		return fmt.Errorf("funding transaction %s has no output %d", fundingTxID, vout)
+	}
+	pkScript, err := hex.DecodeString(fundingTx.Vout[vout].ScriptPubKey.Hex)
+	if err != nil {
+		log.Printf("Warning: Could not check whether the funding UTXO is unspent: invalid output script: %v", err)
+		return nil
+	}
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, cfg.ChainParams)
+	if err != nil || len(addrs) != 1 {
+		log.Printf("Warning: Could not check whether the funding UTXO is unspent: output %s:%d pays no single address", fundingTxID, vout)
+		return nil
+	}
+
+	utxos, err := backend.ListUnspent(addrs[0].EncodeAddress())
+	if err != nil {
+		log.Printf("Warning: Could not check whether the funding UTXO is unspent: %v", err)
+		return nil
+	}
+	for _, utxo := range utxos {
+		if utxo.TxID == fundingTxID && utxo.Vout == vout {
+			return nil
+		}
+	}
+	return fmt.Errorf("contract already spent: funding UTXO %s:%d no longer exists", fundingTxID, vout)
+}
+
+// spendPath names the script branch selected by a contract input's witness
+// stack <sig> <selector> <redeem script>. The only valid selectors are the
+// minimal 01 and empty pushes (see script.OwnerSelector).
+func spendPath(witness []string) string {
+	if len(witness) != 3 {
+		return "an unknown path"
+	}
+	switch witness[1] {
+	case "01":
+		return "owner"
+	case "":
+		return "inheritor"
+	// Builds before MINIMALIF was respected pushed the opcode bytes 51
+	// (OP_1) and 00 (OP_0). Policy rejects them and nothing produces them
+	// any more; they are matched only to name spends made by those builds.
+	case "51":
+		return "owner"
+	case "00":
+		return "inheritor"
+	default:
+		return "an unknown path"
+	}
+}
+
+// fetchMedianTimes returns the median-time-past of the block before the
+// confirmed funding block and of the current chain tip
+func fetchMedianTimes(ctx context.Context, rpcClient *rpc.RPCClient, fundingTx *rpc.RawTransaction) (int64, int64, error) {
+	fundingBlock, err := rpcClient.GetBlockHeaderCtx(ctx, fundingTx.BlockHash)
+	if err != nil {
+		return 0, 0, err
+	}
+	fundingParent, err := rpcClient.GetBlockHeaderCtx(ctx, fundingBlock.PreviousBlockHash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tipHash, err := rpcClient.GetBestBlockHashCtx(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	tip, err := rpcClient.GetBlockHeaderCtx(ctx, tipHash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fundingParent.MedianTime, tip.MedianTime, nil
+}
+
+// fetchHeights returns the height of the block that confirmed the funding
+// transaction and of the current chain tip
+func fetchHeights(ctx context.Context, rpcClient *rpc.RPCClient, fundingTx *rpc.RawTransaction) (int64, int64, error) {
+	fundingBlock, err := rpcClient.GetBlockHeaderCtx(ctx, fundingTx.BlockHash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tipHeight, err := rpcClient.GetBlockCountCtx(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fundingBlock.Height, tipHeight, nil
+}
+
+// readSeedMaster prompts for the master key of --from-seed: a hex seed or an
+// xprv/tprv
+func readSeedMaster() (*hdkeychain.ExtendedKey, error) {
+	// Read the seed from stdin so it does not end up in shell history
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter master seed (hex) or master key (xprv/tprv): ")
+	value, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed: %w", err)
+	}
+	return hdkeys.ParseMaster(value, cfg.ChainParams)
+}
+
+// readMnemonicMaster prompts for the BIP39 mnemonic of --mnemonic, or
+// generates a new one that is shown once, and returns its master key
+func readMnemonicMaster() (*hdkeychain.ExtendedKey, error) {
+	// Read the mnemonic from stdin so it does not end up in shell history
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter BIP39 mnemonic (leave empty to generate a new one): ")
+	mnemonic, err := reader.ReadString('\n')
+	if err != nil && mnemonic == "" {
+		return nil, fmt.Errorf("failed to read mnemonic: %w", err)
+	}
+	mnemonic = strings.TrimSpace(mnemonic)
+
+	if mnemonic == "" {
+		if generateReplace {
+			return nil, fmt.Errorf("--replace restores keys from an existing mnemonic; enter it")
+		}
+		if mnemonic, err = keys.GenerateMnemonic(mnemonicWords); err != nil {
+			return nil, err
+		}
+		log.Printf("")
+		log.Printf("=== Recovery Mnemonic (%d words) ===", mnemonicWords)
+		for i, word := range strings.Fields(mnemonic) {
+			log.Printf("  %2d. %s", i+1, word)
+		}
+		log.Printf("⚠️  Write these words down and keep them safe. They are NOT stored;")
+		log.Printf("⚠️  with them the keys of every contract derived from them can be restored.")
+		log.Printf("")
+	}
+
+	seed, err := keys.MnemonicSeed(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	master, err := hdkeychain.NewMaster(seed, cfg.ChainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create master key: %w", err)
+	}
+	return master, nil
+}
+
+// resolveKeyIndex returns the contract index to derive: --index if given,
+// otherwise the next one unused by the saved contracts of the master key
+func resolveKeyIndex(fingerprint string) (uint32, error) {
+	if generateIndexSet {
+		return generateIndex, nil
+	}
+	if generateReplace {
+		return 0, fmt.Errorf("--replace needs the --index of the contract to restore")
+	}
+
+	index, err := contract.NextKeyIndex(fingerprint)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Using index %d, the next unused index of master key %s", index, fingerprint)
+	return index, nil
+}
+
+// restoreContractKeys returns the stored contract with its keys and script
+// replaced by the regenerated ones. The regenerated address must match the
+// stored one; funding status, label and history are kept.
+func restoreContractKeys(existing, regenerated *contract.ContractInfo) (*contract.ContractInfo, error) {
+	if existing.P2WSHAddress != regenerated.P2WSHAddress {
+		return nil, fmt.Errorf("regenerated address %s does not match stored address %s; not overwriting",
+			regenerated.P2WSHAddress, existing.P2WSHAddress)
+	}
+	if existing.RedeemScript != regenerated.RedeemScript {
+		return nil, fmt.Errorf("regenerated redeem script does not match the stored one; not overwriting")
+	}
+
+	restored := *existing
+	restored.OwnerWIF = regenerated.OwnerWIF
+	restored.InheritorWIF = regenerated.InheritorWIF
+	restored.ScriptHash = regenerated.ScriptHash
+	restored.KeySource = regenerated.KeySource
+	restored.KeyFingerprint = regenerated.KeyFingerprint
+	restored.KeyIndex = regenerated.KeyIndex
+	restored.OwnerPath = regenerated.OwnerPath
+	restored.InheritorPath = regenerated.InheritorPath
+	return &restored, nil
+}
+
+// autoFeeConfTarget is the confirmation target of --fee-rate auto, in blocks
+const autoFeeConfTarget = 6
+
+// resolveWithdrawFee picks the withdrawal fee from --fee, --fee-rate and the
+// configured defaults
+func resolveWithdrawFee() (transaction.FeeChoice, error) {
+	feeRate, err := withdrawFeeRateValue()
+	if err != nil {
+		return transaction.FeeChoice{}, err
+	}
+	feeChoice, err := transaction.ResolveFee(withdrawFee, feeRate, cfg.Fees.DefaultFeeRate, cfg.Contract.DefaultFee)
+	if err != nil {
+		return transaction.FeeChoice{}, err
+	}
+	if withdrawFee > 0 && feeChoice.Source == transaction.FeeFromRate {
+		log.Printf("Note: --fee %d ignored in favour of --fee-rate %.2f", withdrawFee, feeRate)
+	}
+	return feeChoice, nil
+}
+
+// withdrawFeeRateValue parses --fee-rate. "auto" asks the node's
+// estimatesmartfee for a 6-block target and returns zero, i.e. no fee rate,
+// when the node cannot estimate, so the configured default applies.
+func withdrawFeeRateValue() (float64, error) {
+	switch withdrawFeeRate {
+	case "":
+		return 0, nil
+	case "auto":
+		feeRate, err := rpc.NewRPCClient(&cfg.RPCConfig).EstimateSmartFee(autoFeeConfTarget)
+		if err != nil {
+			log.Printf("Warning: %v; falling back to the configured default fee", err)
+			return 0, nil
+		}
+		log.Printf("Fee rate from estimatesmartfee (%d blocks): %.2f sat/vB", autoFeeConfTarget, feeRate)
+		return feeRate, nil
+	}
+
+	feeRate, err := strconv.ParseFloat(withdrawFeeRate, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --fee-rate %q: expected sat/vB or \"auto\"", withdrawFeeRate)
+	}
+	return feeRate, nil
+}
+
+// buildWithFee builds a withdrawal paying the effective fee. For a fee rate
+// the transaction is built once to measure its size, then rebuilt with the
+// fee that size requires.
+func buildWithFee(
+	feeChoice transaction.FeeChoice,
+	redeemScript []byte,
+	build func(*transaction.TransactionBuilder) (*wire.MsgTx, error),
+) (*wire.MsgTx, *transaction.TransactionBuilder, btcutil.Amount, error) {
+	fee := feeChoice.Fee
+	if feeChoice.Source.IsRate() {
+		template, err := build(transaction.NewTransactionBuilder(cfg.ChainParams, 0))
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		fee = transaction.FeeForRate(template, redeemScript, feeChoice.FeeRate)
+		log.Printf("Fee: %d satoshis (%.2f sat/vB)", int64(fee), feeChoice.FeeRate)
+	}
+
+	txBuilder, err := newSigningBuilder(fee)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	logSuggestedFeeRate(txBuilder, feeChoice.Source)
+
+	tx, err := build(txBuilder)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return tx, txBuilder, fee, nil
+}
+
+// newSigningBuilder returns a transaction builder paying fee that signs as
+// --low-r and --sighash ask
+func newSigningBuilder(fee btcutil.Amount) (*transaction.TransactionBuilder, error) {
+	txBuilder := transaction.NewTransactionBuilder(cfg.ChainParams, fee)
+	txBuilder.SetGrindLowR(grindLowR)
+
+	hashType, err := transaction.ParseSigHashType(withdrawSigHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --sighash: %w", err)
+	}
+	if err := txBuilder.SetSigHashType(hashType); err != nil {
+		return nil, fmt.Errorf("invalid --sighash: %w", err)
+	}
+	if hashType != txscript.SigHashAll {
+		log.Printf("Warning: Signing with %s; the signatures do not commit to the whole transaction", withdrawSigHash)
+	}
+	return txBuilder, nil
+}
+
+// fetchContractPkScript asks the node for the output script of the funding
+// UTXO. It returns nil when the node cannot provide it, in which case the
+// script derived from the redeem script is used unchecked.
+func fetchContractPkScript(ctx context.Context, txid string, vout uint32) []byte {
+	rpcClient := rpc.NewRPCClient(&cfg.RPCConfig)
+	txOut, err := rpcClient.GetTxOutCtx(ctx, txid, vout)
+	if err != nil {
+		log.Printf("Warning: Could not fetch funding output from node: %v", err)
+		return nil
+	}
+	if txOut == nil {
+		log.Printf("Warning: Funding output %s:%d is spent or unknown to the node", txid, vout)
+		return nil
+	}
+
+	pkScript, err := hex.DecodeString(txOut.ScriptPubKey.Hex)
+	if err != nil {
+		log.Printf("Warning: Node returned an invalid output script: %v", err)
+		return nil
+	}
+	return pkScript
+}
+
+// contractUTXOPkScript returns the output script of a contract's funding
+// UTXO, derived from the contract's redeem script and address type. When the
+// node knows the output, its script must be the same: a mismatch means the
+// contract file does not describe the output it claims to spend.
+func contractUTXOPkScript(ctx context.Context, contractInfo *contract.ContractInfo, txid string, vout uint32) ([]byte, error) {
+	redeemScript, err := hex.DecodeString(contractInfo.RedeemScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode redeem script: %w", err)
+	}
+	pkScript, err := contractScriptPubKey(redeemScript, contractInfo.AddressType)
+	if err != nil {
+		return nil, err
+	}
+
+	if fetched := fetchContractPkScript(ctx, txid, vout); fetched != nil && !bytes.Equal(fetched, pkScript) {
+		return nil, fmt.Errorf("funding output %s:%d pays script %x, not the contract script %x", txid, vout, fetched, pkScript)
+	}
+	return pkScript, nil
+}
+
+// fundingUTXOs returns the funding UTXOs of a contract with their output
+// scripts, checked against the node where possible
+func fundingUTXOs(ctx context.Context, contractInfo *contract.ContractInfo) ([]*transaction.UTXO, error) {
+	utxos := make([]*transaction.UTXO, 0, len(contractInfo.FundingUTXOs))
+	for _, outpoint := range contractInfo.FundingUTXOs {
+		fundingHash, err := chainhash.NewHashFromStr(outpoint.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid funding transaction hash: %w", err)
+		}
+		pkScript, err := contractUTXOPkScript(ctx, contractInfo, outpoint.TxID, outpoint.Vout)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, &transaction.UTXO{
+			TxHash:   fundingHash,
+			Vout:     outpoint.Vout,
+			Amount:   btcutil.Amount(outpoint.Amount),
+			PkScript: pkScript,
+
+			AddressType: contractInfo.AddressType,
+		})
+	}
+	return utxos, nil
+}
+
+// logFundingUTXOs logs the funding UTXOs of a contract
+func logFundingUTXOs(contractInfo *contract.ContractInfo) {
+	for _, outpoint := range contractInfo.FundingUTXOs {
+		log.Printf("Funding UTXO: %s:%d (%d satoshis)", outpoint.TxID, outpoint.Vout, outpoint.Amount)
+	}
+	if len(contractInfo.FundingUTXOs) > 1 {
+		log.Printf("Total funding: %d satoshis in %d UTXOs", contractInfo.TotalFunding(), len(contractInfo.FundingUTXOs))
+	}
+}
+
+// withdrawContractID returns the contract ID given with --contract-id or as
+// an argument, or asks for it on stdin. Without a terminal to ask on, the
+// active contract is used.
+func withdrawContractID(reader *bufio.Reader, args []string) (string, error) {
+	if withdrawContract != "" {
+		if len(args) > 0 && args[0] != withdrawContract {
+			return "", fmt.Errorf("contract %s given as argument and %s with --contract-id", args[0], withdrawContract)
+		}
+		return withdrawContract, nil
+	}
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if !stdinIsTerminal() {
+		// Nobody can answer a prompt; fall back to the active contract
+		return resolveContractID(nil)
+	}
+	return promptContractID(reader)
+}
+
+// readDestination returns the withdrawal destination given with --to or
+// --destination, or asks for it on stdin when stdin is a terminal. The
+// address must belong to the configured network.
+func readDestination(reader *bufio.Reader) (btcutil.Address, error) {
+	destAddrStr := destinationAddr
+	if destAddrStr == "" {
+		if assumeYes {
+			return nil, fmt.Errorf("--yes needs the destination address in --to")
+		}
+		if !stdinIsTerminal() {
+			return nil, errNotInteractive("destination address", "--to")
+		}
+		promptf("Enter destination address for withdrawal: ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read destination address: %w", err)
+		}
+		destAddrStr = line
+	}
+	destAddrStr = strings.TrimSpace(destAddrStr)
+
+	destAddr, destType, err := transaction.DecodeDestination(destAddrStr, cfg.ChainParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination address: %w", err)
+	}
+	log.Printf("Destination: %s (%s)", destAddr.EncodeAddress(), destType)
+	return destAddr, nil
+}
+
+// confirm asks a yes/no question on stdin; anything but yes declines. With
+// --yes the question is answered yes without reading stdin; without --yes
+// and a terminal it is declined.
+func confirm(reader *bufio.Reader, question string) bool {
+	if assumeYes {
+		log.Printf("%s yes (--yes)", question)
+		return true
+	}
+	if !stdinIsTerminal() {
+		log.Printf("%s no (stdin is not a terminal; pass --yes to answer yes)", question)
+		return false
+	}
+
+	promptf("%s (y/N): ", question)
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// checkMaxFeeRate rejects a signed withdrawal whose effective fee rate
+// exceeds MAX_FEE_RATE, unless --allow-high-fee-rate is given
+func checkMaxFeeRate(tx *wire.MsgTx, inputAmount btcutil.Amount) error {
+	fee := inputAmount - totalOutput(tx)
+	vsize := transaction.VirtualSize(tx)
+
+	err := transaction.CheckFeeRate(fee, vsize, cfg.Fees.MaxFeeRate)
+	if err == nil {
+		return nil
+	}
+	if allowHighFee {
+		log.Printf("Warning: %v (allowed by --allow-high-fee-rate)", err)
+		return nil
+	}
+	return fmt.Errorf("%w; lower the fee or pass --allow-high-fee-rate", err)
+}
+
+// checkMempoolAccept asks the node whether it would accept a signed
+// withdrawal, so a transaction it would reject is never offered for
+// broadcast. A node that cannot answer only produces a warning; with an
+// Electrum or Esplora backend, which have no such call, the check is skipped.
+func checkMempoolAccept(ctx context.Context, tx *wire.MsgTx) error {
+	if cfg.Backend != config.BackendNode {
+		return nil
+	}
+
+	allowed, reason, err := rpc.NewRPCClient(&cfg.RPCConfig).TestMempoolAcceptCtx(ctx, tx)
+	if err != nil {
+		log.Printf("Warning: could not check mempool acceptance: %v", err)
+		return nil
+	}
+	if !allowed {
+		return fmt.Errorf("the node would reject this transaction: %s", reason)
+	}
+	log.Printf("The node would accept this transaction into its mempool")
+	return nil
+}
+
+// totalOutput returns the sum of all output values of a transaction
+func totalOutput(tx *wire.MsgTx) btcutil.Amount {
+	var total btcutil.Amount
+	for _, out := range tx.TxOut {
+		total += btcutil.Amount(out.Value)
+	}
+	return total
+}
+
+// rpcContext returns the deadline for a command's requests to the node,
+// --rpc-timeout from now. Failing over to another endpoint does not extend
+// it. Commands that prompt start a new one after the prompt, so the time
+// spent answering does not count.
+func rpcContext() (context.Context, context.CancelFunc) {
+	return withRPCDeadline(context.Background())
+}
+
+// withRPCDeadline returns parent bounded by --rpc-timeout from now
+func withRPCDeadline(parent context.Context) (context.Context, context.CancelFunc) {
+	timeout := rpcTimeout
+	if timeout <= 0 {
+		timeout = rpc.DefaultTimeout
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// broadcastTransaction sends a signed transaction through the chain backend.
+// A node gives up when ctx is done; the Electrum and Esplora clients bound
+// each request themselves.
+func broadcastTransaction(ctx context.Context, tx *wire.MsgTx) (string, error) {
+	backend := newChainBackend()
+	if rpcClient, ok := backend.(*rpc.RPCClient); ok {
+		return rpcClient.BroadcastTransactionCtx(ctx, tx)
+	}
+	return backend.BroadcastTransaction(tx)
+}
+
+// newChainBackend returns the backend for UTXO lookups and broadcasts
+// selected by BACKEND: the Electrum server, the Esplora API or the node
+func newChainBackend() rpc.ChainBackend {
+	switch cfg.Backend {
+	case config.BackendElectrum:
+		return rpc.NewElectrumClient(cfg.Electrum.Server, cfg.ChainParams)
+	case config.BackendEsplora:
+		return rpc.NewEsploraClient(cfg.Esplora.URL, cfg.ChainParams)
+	default:
+		return rpc.NewRPCClient(&cfg.RPCConfig)
+	}
+}
+
+// newFeeEstimator returns the fee oracle selected by the FEE_ESTIMATOR setting
+func newFeeEstimator() (fees.FeeEstimator, error) {
+	switch cfg.Fees.Estimator {
+	case "node", "":
+		return fees.NewNodeEstimator(rpc.NewRPCClient(&cfg.RPCConfig)), nil
+	case "mempool":
+		return fees.NewMempoolSpaceEstimator(cfg.Fees.MempoolAPIURL), nil
+	case "static":
+		return &fees.StaticEstimator{FeeRate: cfg.Fees.StaticFeeRate}, nil
+	default:
+		return nil, fmt.Errorf("unknown fee estimator %q (expected node, mempool or static)", cfg.Fees.Estimator)
+	}
+}
+
+// logSuggestedFeeRate attaches the configured fee estimator to the builder
+// and logs its current estimate for confirmation within 6 blocks. The
+// estimate costs a round-trip to the estimator, so it is only asked for when
+// the fee comes from the configuration rather than --fee or --fee-rate.
+func logSuggestedFeeRate(txBuilder *transaction.TransactionBuilder, feeSource transaction.FeeSource) {
+	if !feeSource.IsDefault() {
+		return
+	}
+	estimator, err := newFeeEstimator()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	txBuilder.SetFeeEstimator(estimator)
+
+	feeRate, err := txBuilder.EstimateFeeRate(6)
+	if err != nil {
+		log.Printf("Warning: Fee estimation failed: %v", err)
+		return
+	}
+	log.Printf("Suggested fee rate (%s, 6 blocks): %.2f sat/vB", cfg.Fees.Estimator, feeRate)
+}
+
+// ownerChangeScript returns the output script receiving the change of a
+// partial owner withdrawal, as selected by --change-to-contract or --change-address
+func ownerChangeScript(redeemScript []byte, addressType script.AddressType) ([]byte, error) {
+	switch {
+	case changeToContract && changeAddress != "":
+		return nil, fmt.Errorf("--change-to-contract and --change-address cannot be combined")
+	case changeToContract:
+		return contractScriptPubKey(redeemScript, addressType)
+	case changeAddress != "":
+		addr, addrType, err := transaction.DecodeDestination(changeAddress, cfg.ChainParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid change address: %w", err)
+		}
+		log.Printf("Change address: %s (%s)", addr.EncodeAddress(), addrType)
+		return txscript.PayToAddrScript(addr)
+	default:
+		return nil, fmt.Errorf("partial withdrawals need a change destination: use --change-to-contract or --change-address")
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/contract"
+	"github.com/nikolay.stoev/bitcoin-inheritance/rpc"
+	"github.com/nikolay.stoev/bitcoin-inheritance/transaction"
+)
+
+// stubNode is an RPC server answering with canned results by method and
+// recording the params of every request it receives
+type stubNode struct {
+	host string
+
+	mu    sync.Mutex
+	calls map[string][]json.RawMessage
+}
+
+// newStubNode starts a stub node serving results, which map RPC methods to
+// their JSON result
+func newStubNode(t *testing.T, results map[string]string) *stubNode {
+	t.Helper()
+
+	node := &stubNode{calls: make(map[string][]json.RawMessage)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     int             `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		node.mu.Lock()
+		node.calls[request.Method] = append(node.calls[request.Method], request.Params)
+		node.mu.Unlock()
+
+		result, ok := results[request.Method]
+		if !ok {
+			http.Error(w, "unknown method "+request.Method, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"result":%s,"error":null,"id":%d}`, result, request.ID)
+	}))
+	t.Cleanup(server.Close)
+
+	node.host = strings.TrimPrefix(server.URL, "http://")
+	return node
+}
+
+// params returns the params of every call of method the node received
+func (n *stubNode) params(method string) []json.RawMessage {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+// useTxOutNode points the RPC configuration at a node answering gettxout
+// with an output paying pkScript
+func useTxOutNode(t *testing.T, pkScript []byte) {
+	t.Helper()
+
+	node := newStubNode(t, map[string]string{
+		"gettxout": fmt.Sprintf(`{"value":0.001,"scriptPubKey":{"hex":"%x"}}`, pkScript),
+	})
+	cfg.RPCConfig.Host = node.host
+	cfg.RPCConfig.Hosts = nil
+	cfg.RPCConfig.DisableTLS = true
+}
+
+func TestFundingUTXOs_PkScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    func(t *testing.T, contractScript []byte)
+		wantErr string
+	}{
+		{"derived without a node", func(t *testing.T, contractScript []byte) {}, ""},
+		{"matching node output", func(t *testing.T, contractScript []byte) {
+			useTxOutNode(t, contractScript)
+		}, ""},
+		{"mismatching node output", func(t *testing.T, contractScript []byte) {
+			useTxOutNode(t, append([]byte{0x00, 0x20}, make([]byte, 32)...))
+		}, "not the contract script"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractID, _ := setupNonInteractive(t)
+			// Any command loads the configuration fundingUTXOs reads
+			if err := runCommand(t, "list"); err != nil {
+				t.Fatalf("Failed to load the configuration: %v", err)
+			}
+			contractInfo, err := contract.LoadContractInfo(contractID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			redeemScript, _ := hex.DecodeString(contractInfo.RedeemScript)
+			contractScript, err := contractScriptPubKey(redeemScript, contractInfo.AddressType)
+			if err != nil {
+				t.Fatalf("contractScriptPubKey failed: %v", err)
+			}
+			tt.node(t, contractScript)
+
+			utxos, err := fundingUTXOs(context.Background(), contractInfo)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fundingUTXOs failed: %v", err)
+			}
+			if len(utxos) != 1 || !bytes.Equal(utxos[0].PkScript, contractScript) {
+				t.Errorf("Expected one UTXO paying %x, got %+v", contractScript, utxos)
+			}
+		})
+	}
+}
+
+func TestLogSuggestedFeeRate_OnlyForDefaultFees(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    transaction.FeeSource
+		wantCalls int
+	}{
+		{"configured fee", transaction.FeeFromDefault, 1},
+		{"configured fee rate", transaction.FeeFromDefaultRate, 1},
+		{"--fee", transaction.FeeFromFlag, 0},
+		{"--fee-rate", transaction.FeeFromRate, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupNonInteractive(t)
+			// Any command loads the configuration logSuggestedFeeRate reads
+			if err := runCommand(t, "list"); err != nil {
+				t.Fatalf("Failed to load the configuration: %v", err)
+			}
+			node := newStubNode(t, map[string]string{
+				"estimatesmartfee": `{"feerate":0.00005,"blocks":6}`,
+			})
+			cfg.RPCConfig.Host = node.host
+			cfg.RPCConfig.Hosts = nil
+			cfg.RPCConfig.DisableTLS = true
+			cfg.Fees.Estimator = "node"
+
+			logSuggestedFeeRate(transaction.NewTransactionBuilder(cfg.ChainParams, 1000), tt.source)
+			if calls := len(node.params("estimatesmartfee")); calls != tt.wantCalls {
+				t.Errorf("Expected %d estimatesmartfee calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestPersistentPreRun_InvalidConfigurationReturnsError(t *testing.T) {
+	setupNonInteractive(t)
+	t.Setenv("BACKEND", "carrier-pigeon")
+
+	// The error reaches the caller instead of exiting the process
+	err := runCommand(t, "list")
+	if err == nil || !strings.Contains(err.Error(), "invalid configuration: invalid BACKEND") {
+		t.Errorf("Expected an invalid configuration error, got %v", err)
+	}
+}
+
+func TestRPCTimeout_OneDeadlineAcrossFailover(t *testing.T) {
+	setupNonInteractive(t)
+
+	// Both endpoints hold every request until the client gives up or the
+	// test ends
+	var mu sync.Mutex
+	hit := make(map[string]bool)
+	var hosts []string
+	release := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hit[r.Host] = true
+			mu.Unlock()
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}))
+		t.Cleanup(server.Close)
+		hosts = append(hosts, strings.TrimPrefix(server.URL, "http://"))
+	}
+	t.Cleanup(func() { close(release) })
+	t.Setenv("TESTNET_RPC_HOST", strings.Join(hosts, ","))
+	t.Setenv("TESTNET_RPC_DISABLE_TLS", "true")
+	t.Cleanup(func() { rpcTimeout = rpc.DefaultTimeout })
+
+	start := time.Now()
+	txid := strings.Repeat("ab", 32)
+	if err := runCommand(t, "audit-fee", txid, "--rpc-timeout", "300ms"); err == nil {
+		t.Fatal("Expected audit-fee to fail against hung endpoints")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the command to give up after --rpc-timeout, took %v", elapsed)
+	}
+
+	// The first endpoint's share of the deadline left time to fail over
+	mu.Lock()
+	defer mu.Unlock()
+	for _, host := range hosts {
+		if !hit[host] {
+			t.Errorf("Endpoint %s was never tried", host)
+		}
+	}
+}
+
+// listingBackend is a chain backend that cannot look up a single output,
+// answering getrawtransaction with one transaction and listing the given
+// unspent outputs for any address
+type listingBackend struct {
+	rpc.ChainBackend
+
+	fundingTx *rpc.RawTransaction
+	unspent   []*rpc.UTXO
+	listed    []string
+}
+
+func (b *listingBackend) GetRawTransaction(txid string) (*rpc.RawTransaction, error) {
+	if b.fundingTx == nil || txid != b.fundingTx.TxID {
+		return nil, fmt.Errorf("transaction %s not found", txid)
+	}
+	return b.fundingTx, nil
+}
+
+func (b *listingBackend) ListUnspent(address string) ([]*rpc.UTXO, error) {
+	b.listed = append(b.listed, address)
+	return b.unspent, nil
+}
+
+func TestCheckFundingUnspent_ListingBackend(t *testing.T) {
+	contractID, _ := setupNonInteractive(t)
+	if err := runCommand(t, "list"); err != nil {
+		t.Fatalf("Failed to load the configuration: %v", err)
+	}
+	contractInfo, err := contract.LoadContractInfo(contractID)
+	if err != nil {
+		t.Fatalf("LoadContractInfo failed: %v", err)
+	}
+	redeemScript, _ := hex.DecodeString(contractInfo.RedeemScript)
+	pkScript, err := contractScriptPubKey(redeemScript, contractInfo.AddressType)
+	if err != nil {
+		t.Fatalf("contractScriptPubKey failed: %v", err)
+	}
+	txid := contractInfo.FundingTxID
+	fundingTx := &rpc.RawTransaction{
+		TxID: txid,
+		Vout: []rpc.TxOutput{{Value: 0.001, ScriptPubKey: rpc.ScriptPubKey{Hex: hex.EncodeToString(pkScript)}}},
+	}
+
+	tests := []struct {
+		name      string
+		fundingTx *rpc.RawTransaction
+		unspent   []*rpc.UTXO
+		wantErr   string
+		wantList  bool
+	}{
+		{"still listed", fundingTx, []*rpc.UTXO{{TxID: txid, Vout: 0}}, "", true},
+		{"no longer listed", fundingTx, []*rpc.UTXO{{TxID: txid, Vout: 1}}, "contract already spent", true},
+		{"unknown funding transaction", nil, nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &listingBackend{fundingTx: tt.fundingTx, unspent: tt.unspent}
+			err := checkFundingUnspent(context.Background(), backend, txid, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+
+			if tt.wantList && (len(backend.listed) != 1 || backend.listed[0] != contractInfo.P2WSHAddress) {
+				t.Errorf("Expected the contract address %s to be listed, got %v", contractInfo.P2WSHAddress, backend.listed)
+			}
+			if !tt.wantList && len(backend.listed) != 0 {
+				t.Errorf("Expected no listing for an unknown funding transaction, got %v", backend.listed)
+			}
+		})
+	}
+}
+
+func TestRefundContractWithChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		outputs    []int64
+		wantFunded bool
+	}{
+		{"change output becomes the funding UTXO", []int64{50000, 49000}, true},
+		{"dust change leaves the contract empty", []int64{99000}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractID, _ := setupNonInteractive(t)
+
+			tx := wire.NewMsgTx(wire.TxVersion)
+			for _, value := range tt.outputs {
+				tx.AddTxOut(wire.NewTxOut(value, nil))
+			}
+			txid := tx.TxHash().String()
+			refundContractWithChange(contractID, txid, tx)
+
+			loaded, err := contract.LoadContractInfo(contractID)
+			if err != nil {
+				t.Fatalf("LoadContractInfo failed: %v", err)
+			}
+			if loaded.IsFunded != tt.wantFunded {
+				t.Fatalf("Expected IsFunded %v, got %+v", tt.wantFunded, loaded)
+			}
+			if tt.wantFunded {
+				if loaded.FundingTxID != txid || loaded.FundingVout != 1 || loaded.FundingAmount != 49000 {
+					t.Errorf("Expected funding %s:1 (49000), got %s:%d (%d)",
+						txid, loaded.FundingTxID, loaded.FundingVout, loaded.FundingAmount)
+				}
+			} else if loaded.FundingTxID != "" || loaded.FundingAmount != 0 || len(loaded.FundingUTXOs) != 0 {
+				t.Errorf("Funding state was not cleared: %+v", loaded)
+			}
+		})
+	}
+}
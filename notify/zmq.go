@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/gozmq"
+)
+
+// Topics published by Bitcoin Core with -zmqpubrawtx and -zmqpubhashblock
+const (
+	TopicRawTx     = "rawtx"
+	TopicHashBlock = "hashblock"
+)
+
+// receiveTimeout is how long a receive waits before checking for
+// cancellation; gozmq reconnects on its own after a dropped connection
+const receiveTimeout = 30 * time.Second
+
+// Handlers receive the notifications of a subscription. A nil handler skips
+// its topic. Returning an error ends the subscription with that error.
+type Handlers struct {
+	Tx    func(tx *wire.MsgTx) error
+	Block func(blockHash string) error
+}
+
+// Subscribe connects to a ZMQ publisher such as tcp://127.0.0.1:28332 and
+// passes its notifications to handlers until ctx is cancelled or a handler
+// fails. Cancellation returns nil.
+func Subscribe(ctx context.Context, endpoint string, handlers Handlers) error {
+	var topics []string
+	if handlers.Tx != nil {
+		topics = append(topics, TopicRawTx)
+	}
+	if handlers.Block != nil {
+		topics = append(topics, TopicHashBlock)
+	}
+	if len(topics) == 0 {
+		return fmt.Errorf("no notification handlers given")
+	}
+
+	conn, err := gozmq.Subscribe(endpoint, topics, receiveTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", endpoint, err)
+	}
+
+	// Closing the connection unblocks Receive once the context ends
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	for {
+		parts, err := conn.Receive(nil)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			if err == io.EOF {
+				return fmt.Errorf("ZMQ connection to %s closed", endpoint)
+			}
+			return fmt.Errorf("failed to receive ZMQ notification: %w", err)
+		}
+
+		if err := dispatch(parts, handlers); err != nil {
+			return err
+		}
+	}
+}
+
+// dispatch decodes a [topic, body, sequence] notification and passes it to
+// the matching handler. Unknown topics are ignored.
+func dispatch(parts [][]byte, handlers Handlers) error {
+	if len(parts) < 2 {
+		return nil
+	}
+
+	switch string(parts[0]) {
+	case TopicRawTx:
+		if handlers.Tx == nil {
+			return nil
+		}
+		var tx wire.MsgTx
+		if err := tx.Deserialize(bytes.NewReader(parts[1])); err != nil {
+			return fmt.Errorf("failed to decode rawtx notification: %w", err)
+		}
+		return handlers.Tx(&tx)
+
+	case TopicHashBlock:
+		if handlers.Block == nil {
+			return nil
+		}
+		if len(parts[1]) != 32 {
+			return fmt.Errorf("invalid hashblock notification of %d bytes", len(parts[1]))
+		}
+		// Bitcoin Core publishes the hash in display byte order
+		return handlers.Block(hex.EncodeToString(parts[1]))
+	}
+
+	return nil
+}
+
+// MatchOutputs returns the indexes of the outputs of tx paying to pkScript
+func MatchOutputs(tx *wire.MsgTx, pkScript []byte) []uint32 {
+	var matches []uint32
+	for i, txOut := range tx.TxOut {
+		if bytes.Equal(txOut.PkScript, pkScript) {
+			matches = append(matches, uint32(i))
+		}
+	}
+	return matches
+}
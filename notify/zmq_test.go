@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestDispatch(t *testing.T) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x00, 0x20}))
+	var rawTx bytes.Buffer
+	if err := tx.Serialize(&rawTx); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	blockHash := bytes.Repeat([]byte{0xab}, 32)
+
+	var gotTx *wire.MsgTx
+	var gotBlock string
+	handlers := Handlers{
+		Tx:    func(tx *wire.MsgTx) error { gotTx = tx; return nil },
+		Block: func(hash string) error { gotBlock = hash; return nil },
+	}
+
+	sequence := []byte{0, 0, 0, 0}
+	if err := dispatch([][]byte{[]byte(TopicRawTx), rawTx.Bytes(), sequence}, handlers); err != nil {
+		t.Fatalf("dispatch rawtx failed: %v", err)
+	}
+	if gotTx == nil || gotTx.TxHash() != tx.TxHash() {
+		t.Errorf("Expected transaction %s, got %v", tx.TxHash(), gotTx)
+	}
+
+	if err := dispatch([][]byte{[]byte(TopicHashBlock), blockHash, sequence}, handlers); err != nil {
+		t.Fatalf("dispatch hashblock failed: %v", err)
+	}
+	if gotBlock != "abababababababababababababababababababababababababababababababab" {
+		t.Errorf("Unexpected block hash %s", gotBlock)
+	}
+
+	if err := dispatch([][]byte{[]byte("sequence"), {1}}, handlers); err != nil {
+		t.Errorf("Unknown topics should be ignored, got %v", err)
+	}
+	if err := dispatch([][]byte{[]byte(TopicHashBlock), {1, 2}}, handlers); err == nil {
+		t.Error("Expected an error for a short block hash")
+	}
+
+	stop := errors.New("stop")
+	handlers.Block = func(string) error { return stop }
+	if err := dispatch([][]byte{[]byte(TopicHashBlock), blockHash}, handlers); err != stop {
+		t.Errorf("Expected the handler error, got %v", err)
+	}
+}
+
+func TestMatchOutputs(t *testing.T) {
+	contractScript := append([]byte{0x00, 0x20}, bytes.Repeat([]byte{0x01}, 32)...)
+	otherScript := append([]byte{0x00, 0x14}, bytes.Repeat([]byte{0x02}, 20)...)
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxOut(wire.NewTxOut(1000, otherScript))
+	tx.AddTxOut(wire.NewTxOut(2000, contractScript))
+	tx.AddTxOut(wire.NewTxOut(3000, contractScript))
+
+	matches := MatchOutputs(tx, contractScript)
+	if len(matches) != 2 || matches[0] != 1 || matches[1] != 2 {
+		t.Errorf("Expected outputs [1 2], got %v", matches)
+	}
+	if matches := MatchOutputs(tx, []byte{0x51}); len(matches) != 0 {
+		t.Errorf("Expected no matches, got %v", matches)
+	}
+}
@@ -0,0 +1,45 @@
+package qr
+
+import (
+	"fmt"
+	"os"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// PNGSize is the default width and height of a PNG QR code in pixels
+const PNGSize = 256
+
+// Text renders content as a QR code drawn with Unicode half-block characters,
+// suitable for terminals and printable text files
+func Text(content string) (string, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return code.ToSmallString(false), nil
+}
+
+// PNG renders content as a square PNG QR code of size pixels
+func PNG(content string, size int) ([]byte, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid QR code size %d", size)
+	}
+	data, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return data, nil
+}
+
+// WritePNG renders content as a PNG QR code of size pixels and writes it to path
+func WritePNG(content, path string, size int) error {
+	data, err := PNG(content, size)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write QR code: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testAddress is the BIP 173 P2WSH test vector for testnet
+const testAddress = "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"
+
+func TestText(t *testing.T) {
+	code, err := Text(testAddress)
+	if err != nil {
+		t.Fatalf("Text failed: %v", err)
+	}
+	if !strings.Contains(code, "█") {
+		t.Errorf("Expected a QR code drawn with block characters, got %q", code)
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "address.png")
+	if err := WritePNG(testAddress, path, PNGSize); err != nil {
+		t.Fatalf("WritePNG failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read PNG: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Written file is not a valid PNG: %v", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		t.Errorf("Expected a nonzero image, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	if bounds.Dx() != bounds.Dy() {
+		t.Errorf("Expected a square image, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+}
+
+func TestPNG_InvalidSize(t *testing.T) {
+	if _, err := PNG(testAddress, 0); err == nil {
+		t.Error("Expected an error for a zero size")
+	}
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMinInterval is the shortest poll interval allowed by default
+const DefaultMinInterval = 60 * time.Second
+
+// maxBackoff caps how long the poller waits after repeated rate limiting
+const maxBackoff = 30 * time.Minute
+
+// ErrStop can be returned by a poll function to end polling
+var ErrStop = errors.New("stop polling")
+
+// RateLimitError is returned when a backend answers HTTP 429 Too Many Requests
+type RateLimitError struct {
+	// RetryAfter is the wait requested by the backend, or zero if none was given
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited by backend, retry after %s", e.RetryAfter)
+	}
+	return "rate limited by backend"
+}
+
+// CheckResponse returns a RateLimitError for an HTTP 429 response and nil otherwise
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// Poller calls a function repeatedly, no more often than its interval, with
+// random jitter so many clients do not poll in lockstep. When the backend
+// rate limits, it waits for Retry-After or an exponentially growing backoff.
+type Poller struct {
+	interval time.Duration
+	jitter   float64
+
+	// sleep waits for d or until ctx is done; replaceable in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewPoller creates a poller. Intervals below minInterval are raised to it,
+// and each wait is extended by up to jitter (a fraction of the interval).
+func NewPoller(interval, minInterval time.Duration, jitter float64) *Poller {
+	if interval < minInterval {
+		interval = minInterval
+	}
+	return &Poller{
+		interval: interval,
+		jitter:   jitter,
+		sleep:    sleepContext,
+	}
+}
+
+// Interval returns the effective poll interval
+func (p *Poller) Interval() time.Duration {
+	return p.interval
+}
+
+// Run calls poll until ctx is cancelled or poll returns a non-rate-limit
+// error. Returning ErrStop from poll ends polling without an error.
+func (p *Poller) Run(ctx context.Context, poll func() error) error {
+	backoff := p.interval
+
+	for {
+		wait := p.withJitter(p.interval)
+
+		err := poll()
+		var rateLimited *RateLimitError
+		switch {
+		case errors.Is(err, ErrStop):
+			return nil
+		case errors.As(err, &rateLimited):
+			// Back off exponentially, honoring the backend's requested wait
+			backoff = min(backoff*2, maxBackoff)
+			wait = max(backoff, rateLimited.RetryAfter)
+		case err != nil:
+			return err
+		default:
+			backoff = p.interval
+		}
+
+		if err := p.sleep(ctx, wait); err != nil {
+			return nil
+		}
+	}
+}
+
+// withJitter extends d by a random fraction of up to p.jitter
+func (p *Poller) withJitter(d time.Duration) time.Duration {
+	if p.jitter <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Float64()*p.jitter*float64(d))
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
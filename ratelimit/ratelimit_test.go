@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewPoller_MinimumInterval(t *testing.T) {
+	if got := NewPoller(5*time.Second, DefaultMinInterval, 0).Interval(); got != DefaultMinInterval {
+		t.Errorf("Expected interval raised to %s, got %s", DefaultMinInterval, got)
+	}
+	if got := NewPoller(2*time.Minute, DefaultMinInterval, 0).Interval(); got != 2*time.Minute {
+		t.Errorf("Expected interval of 2m, got %s", got)
+	}
+}
+
+func TestPoller_BacksOffWhenRateLimited(t *testing.T) {
+	poller := NewPoller(time.Minute, time.Minute, 0)
+
+	var waits []time.Duration
+	poller.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	results := []error{
+		nil,
+		&RateLimitError{},
+		&RateLimitError{},
+		&RateLimitError{RetryAfter: time.Hour},
+		nil,
+		ErrStop,
+	}
+	calls := 0
+	err := poller.Run(context.Background(), func() error {
+		result := results[calls]
+		calls++
+		return result
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, time.Hour, time.Minute}
+	if len(waits) != len(expected) {
+		t.Fatalf("Expected %d waits, got %v", len(expected), waits)
+	}
+	for i := range expected {
+		if waits[i] != expected[i] {
+			t.Errorf("Wait %d: expected %s, got %s", i, expected[i], waits[i])
+		}
+	}
+}
+
+func TestPoller_StopsOnError(t *testing.T) {
+	poller := NewPoller(time.Minute, time.Minute, 0)
+	poller.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	boom := errors.New("boom")
+	if err := poller.Run(context.Background(), func() error { return boom }); !errors.Is(err, boom) {
+		t.Errorf("Expected poll error, got %v", err)
+	}
+}
+
+func TestPoller_Jitter(t *testing.T) {
+	poller := NewPoller(time.Minute, time.Minute, 0.1)
+	for i := 0; i < 100; i++ {
+		if wait := poller.withJitter(time.Minute); wait < time.Minute || wait > time.Minute+6*time.Second {
+			t.Fatalf("Jittered wait %s outside [1m, 1m6s]", wait)
+		}
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		expected   time.Duration
+		limited    bool
+	}{
+		{"ok", http.StatusOK, "", 0, false},
+		{"429 with seconds", http.StatusTooManyRequests, "120", 2 * time.Minute, true},
+		{"429 without header", http.StatusTooManyRequests, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if tt.retryAfter != "" {
+				recorder.Header().Set("Retry-After", tt.retryAfter)
+			}
+			recorder.WriteHeader(tt.status)
+
+			err := CheckResponse(recorder.Result())
+			var rateLimited *RateLimitError
+			if errors.As(err, &rateLimited) != tt.limited {
+				t.Fatalf("Expected rate limited %t, got %v", tt.limited, err)
+			}
+			if tt.limited && rateLimited.RetryAfter != tt.expected {
+				t.Errorf("Expected retry after %s, got %s", tt.expected, rateLimited.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter_HTTPDate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	date := now.Add(90 * time.Second).Format(http.TimeFormat)
+	if got := parseRetryAfter(date, now); got != 90*time.Second {
+		t.Errorf("Expected 90s, got %s", got)
+	}
+}
@@ -0,0 +1,26 @@
+package rpc
+
+import "github.com/btcsuite/btcd/wire"
+
+// ChainBackend is the chain access needed to find and spend contract UTXOs.
+// RPCClient implements it against a full node, ElectrumClient against an
+// Electrum server and EsploraClient against an Esplora REST API.
+type ChainBackend interface {
+	// ListUnspent returns the unspent outputs paying an address
+	ListUnspent(address string) ([]*UTXO, error)
+
+	// BroadcastTransaction sends a signed transaction and returns its txid
+	BroadcastTransaction(tx *wire.MsgTx) (string, error)
+
+	// GetBlockCount returns the height of the chain tip
+	GetBlockCount() (int64, error)
+
+	// GetRawTransaction returns a transaction with its confirmations
+	GetRawTransaction(txid string) (*RawTransaction, error)
+}
+
+var (
+	_ ChainBackend = (*RPCClient)(nil)
+	_ ChainBackend = (*ElectrumClient)(nil)
+	_ ChainBackend = (*EsploraClient)(nil)
+)
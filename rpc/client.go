@@ -2,20 +2,59 @@ package rpc
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/nikolay.stoev/bitcoin-inheritance/config"
+	"github.com/nikolay.stoev/bitcoin-inheritance/ratelimit"
 )
 
-// RPCClient provides Bitcoin RPC functionality
+// DefaultTimeout bounds each RPC request when RPCConfig.Timeout is zero
+const DefaultTimeout = 30 * time.Second
+
+// unixSocketPrefix marks an RPC host that is a Unix domain socket path
+const unixSocketPrefix = "unix://"
+
+// RPCClient provides Bitcoin RPC functionality. With several hosts
+// configured, requests go to the last endpoint that answered and fail over
+// to the others in order when it cannot be reached.
 type RPCClient struct {
-	config *config.RPCConfig
+	config    *config.RPCConfig
+	endpoints []*endpoint
+
+	// preferred is the index of the last endpoint that answered
+	preferred atomic.Int64
+
+	// nextID assigns a unique ID to every request for response correlation
+	nextID atomic.Int64
+
+	// setupErr is returned by every request when the client could not be
+	// set up, e.g. because the CA certificate is unreadable
+	setupErr error
+}
+
+// endpoint is a single RPC host and the HTTP client that reaches it
+type endpoint struct {
+	host   string
 	client *http.Client
+	url    string
 }
 
 // RPCRequest represents a Bitcoin RPC request
@@ -38,20 +77,84 @@ type RPCError struct {
 	Message string `json:"message"`
 }
 
-// NewRPCClient creates a new RPC client
+// NewRPCClient creates a new RPC client. Each host is either host:port or a
+// unix:///path/to/socket URL for a node listening on a Unix domain socket.
+// TCP hosts are reached over HTTPS unless DisableTLS is set, trusting
+// CACertPath when given; Unix sockets always use plain HTTP.
 func NewRPCClient(cfg *config.RPCConfig) *RPCClient {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	hosts := cfg.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{cfg.Host}
+	}
+
+	r := &RPCClient{config: cfg}
+	var tlsConfig *tls.Config
+	if !cfg.DisableTLS {
+		tlsConfig, r.setupErr = newTLSConfig(cfg.CACertPath)
+	}
+	for _, host := range hosts {
+		r.endpoints = append(r.endpoints, newEndpoint(host, tlsConfig))
+	}
+	return r
+}
+
+// newTLSConfig returns the TLS settings for RPC connections, trusting the
+// PEM certificates in caCertPath instead of the system roots when given
+func newTLSConfig(caCertPath string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caCertPath == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RPC CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("RPC CA certificate %s contains no PEM certificates", caCertPath)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// newEndpoint creates the HTTP client for one RPC host, using HTTPS when
+// tlsConfig is set
+func newEndpoint(host string, tlsConfig *tls.Config) *endpoint {
+	client := &http.Client{}
+	url := fmt.Sprintf("http://%s", host)
+	if tlsConfig != nil {
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+		url = fmt.Sprintf("https://%s", host)
 	}
 
-	return &RPCClient{
-		config: cfg,
-		client: client,
+	if socketPath, ok := strings.CutPrefix(host, unixSocketPrefix); ok {
+		// Dial the socket regardless of the host in the request URL
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		url = "http://unix"
 	}
+
+	return &endpoint{host: host, client: client, url: url}
 }
 
-// BroadcastTransaction broadcasts a transaction to the Bitcoin network
+// BroadcastTransaction broadcasts a transaction to the Bitcoin network. The
+// node's chain is checked first so a transaction is never sent to a node on
+// a different network than it was built for.
 func (r *RPCClient) BroadcastTransaction(tx *wire.MsgTx) (string, error) {
+	return r.BroadcastTransactionCtx(context.Background(), tx)
+}
+
+// BroadcastTransactionCtx is BroadcastTransaction, giving up when ctx is done
+func (r *RPCClient) BroadcastTransactionCtx(ctx context.Context, tx *wire.MsgTx) (string, error) {
+	if err := r.checkChain(ctx); err != nil {
+		return "", fmt.Errorf("refusing to broadcast: %w", err)
+	}
+
 	// Serialize transaction to hex
 	var buf bytes.Buffer
 	if err := tx.Serialize(&buf); err != nil {
@@ -60,8 +163,9 @@ func (r *RPCClient) BroadcastTransaction(tx *wire.MsgTx) (string, error) {
 
 	txHex := fmt.Sprintf("%x", buf.Bytes())
 
-	// Call sendrawtransaction RPC method
-	result, err := r.call("sendrawtransaction", []interface{}{txHex})
+	// Call sendrawtransaction RPC method on the endpoint whose chain was
+	// just checked, without failing over to an unchecked one
+	result, err := r.callPreferred(ctx, "sendrawtransaction", []interface{}{txHex})
 	if err != nil {
 		return "", fmt.Errorf("failed to broadcast transaction: %w", err)
 	}
@@ -74,9 +178,131 @@ func (r *RPCClient) BroadcastTransaction(tx *wire.MsgTx) (string, error) {
 	return txid, nil
 }
 
+// mempoolAcceptResult is one entry of the testmempoolaccept RPC result
+type mempoolAcceptResult struct {
+	TxID         string `json:"txid"`
+	Allowed      bool   `json:"allowed"`
+	RejectReason string `json:"reject-reason"`
+}
+
+// TestMempoolAccept asks the node whether it would accept a transaction into
+// its mempool without broadcasting it. It returns the node's reject reason
+// when the transaction would be refused.
+func (r *RPCClient) TestMempoolAccept(tx *wire.MsgTx) (bool, string, error) {
+	return r.TestMempoolAcceptCtx(context.Background(), tx)
+}
+
+// TestMempoolAcceptCtx is TestMempoolAccept, giving up when ctx is done
+func (r *RPCClient) TestMempoolAcceptCtx(ctx context.Context, tx *wire.MsgTx) (bool, string, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return false, "", fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+
+	result, err := r.call(ctx, "testmempoolaccept", []interface{}{[]string{hex.EncodeToString(buf.Bytes())}})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to test mempool acceptance: %w", err)
+	}
+
+	var results []mempoolAcceptResult
+	if err := json.Unmarshal(result, &results); err != nil {
+		return false, "", fmt.Errorf("failed to parse mempool acceptance: %w", err)
+	}
+	if len(results) != 1 {
+		return false, "", fmt.Errorf("expected one mempool acceptance result, got %d", len(results))
+	}
+
+	return results[0].Allowed, results[0].RejectReason, nil
+}
+
+// BlockchainInfo is the getblockchaininfo RPC result
+type BlockchainInfo struct {
+	Chain  string `json:"chain"`
+	Blocks int64  `json:"blocks"`
+}
+
+// GetBlockchainInfo returns the node's chain name and height
+func (r *RPCClient) GetBlockchainInfo() (*BlockchainInfo, error) {
+	return r.getBlockchainInfo(context.Background())
+}
+
+func (r *RPCClient) getBlockchainInfo(ctx context.Context) (*BlockchainInfo, error) {
+	result, err := r.call(ctx, "getblockchaininfo", []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockchain info: %w", err)
+	}
+
+	var info BlockchainInfo
+	if err := json.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse blockchain info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// nodeChainNames maps chaincfg network names to the chain names reported by
+// Bitcoin Core and btcd in getblockchaininfo
+var nodeChainNames = map[string][]string{
+	"mainnet":  {"main", "mainnet"},
+	"testnet3": {"test", "testnet3"},
+	"testnet4": {"testnet4"},
+	"signet":   {"signet"},
+	"regtest":  {"regtest"},
+	"simnet":   {"simnet"},
+}
+
+// networkForChain returns the chaincfg network name of a chain name reported
+// by getblockchaininfo, or the chain name itself when it is not known
+func networkForChain(chain string) string {
+	for network, names := range nodeChainNames {
+		for _, name := range names {
+			if name == chain {
+				return network
+			}
+		}
+	}
+	return chain
+}
+
+// DetectNetwork asks the node which chain it is on with getblockchaininfo and
+// returns it as a chaincfg network name, such as "testnet3" for Bitcoin
+// Core's "test", to compare with chaincfg.Params.Name
+func (r *RPCClient) DetectNetwork() (string, error) {
+	info, err := r.GetBlockchainInfo()
+	if err != nil {
+		return "", err
+	}
+	return networkForChain(info.Chain), nil
+}
+
+// checkChain confirms the node is on the configured network. Failing to ask
+// the node is an error too, since the chain cannot be confirmed.
+func (r *RPCClient) checkChain(ctx context.Context) error {
+	network := r.config.Network
+	if network == "" {
+		return nil
+	}
+
+	info, err := r.getBlockchainInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("could not confirm the node's chain: %w", err)
+	}
+
+	if networkForChain(info.Chain) == network {
+		return nil
+	}
+
+	return fmt.Errorf("node is on chain %q but the transaction is for %s", info.Chain, network)
+}
+
 // GetBlockCount returns the current block count
 func (r *RPCClient) GetBlockCount() (int64, error) {
-	result, err := r.call("getblockcount", []interface{}{})
+	return r.GetBlockCountCtx(context.Background())
+}
+
+// GetBlockCountCtx is GetBlockCount, giving up when ctx is done
+func (r *RPCClient) GetBlockCountCtx(ctx context.Context) (int64, error) {
+	result, err := r.call(ctx, "getblockcount", []interface{}{})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get block count: %w", err)
 	}
@@ -89,6 +315,141 @@ func (r *RPCClient) GetBlockCount() (int64, error) {
 	return blockCount, nil
 }
 
+// TxOutResult is the gettxout RPC result
+type TxOutResult struct {
+	BestBlock     string       `json:"bestblock"`
+	Confirmations int64        `json:"confirmations"`
+	Value         float64      `json:"value"`
+	ScriptPubKey  ScriptPubKey `json:"scriptPubKey"`
+	Coinbase      bool         `json:"coinbase"`
+}
+
+// GetTxOut returns an unspent transaction output, or nil if the output is
+// spent or unknown to the node
+func (r *RPCClient) GetTxOut(txid string, vout uint32) (*TxOutResult, error) {
+	return r.GetTxOutCtx(context.Background(), txid, vout)
+}
+
+// GetTxOutCtx is GetTxOut, giving up when ctx is done
+func (r *RPCClient) GetTxOutCtx(ctx context.Context, txid string, vout uint32) (*TxOutResult, error) {
+	result, err := r.call(ctx, "gettxout", []interface{}{txid, vout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction output: %w", err)
+	}
+
+	var txOut *TxOutResult
+	if err := json.Unmarshal(result, &txOut); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction output: %w", err)
+	}
+
+	return txOut, nil
+}
+
+// BlockHeader is the verbose getblockheader RPC result
+type BlockHeader struct {
+	Hash              string `json:"hash"`
+	Height            int64  `json:"height"`
+	Time              int64  `json:"time"`
+	MedianTime        int64  `json:"mediantime"`
+	PreviousBlockHash string `json:"previousblockhash,omitempty"`
+}
+
+// GetBlockHeader returns the header of the block with the given hash
+func (r *RPCClient) GetBlockHeader(blockHash string) (*BlockHeader, error) {
+	return r.GetBlockHeaderCtx(context.Background(), blockHash)
+}
+
+// GetBlockHeaderCtx is GetBlockHeader, giving up when ctx is done
+func (r *RPCClient) GetBlockHeaderCtx(ctx context.Context, blockHash string) (*BlockHeader, error) {
+	result, err := r.call(ctx, "getblockheader", []interface{}{blockHash, true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header: %w", err)
+	}
+
+	var header BlockHeader
+	if err := json.Unmarshal(result, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse block header: %w", err)
+	}
+
+	return &header, nil
+}
+
+// GetBestBlockHash returns the hash of the chain tip
+func (r *RPCClient) GetBestBlockHash() (string, error) {
+	return r.GetBestBlockHashCtx(context.Background())
+}
+
+// GetBestBlockHashCtx is GetBestBlockHash, giving up when ctx is done
+func (r *RPCClient) GetBestBlockHashCtx(ctx context.Context) (string, error) {
+	result, err := r.call(ctx, "getbestblockhash", []interface{}{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get best block hash: %w", err)
+	}
+
+	var blockHash string
+	if err := json.Unmarshal(result, &blockHash); err != nil {
+		return "", fmt.Errorf("failed to parse best block hash: %w", err)
+	}
+
+	return blockHash, nil
+}
+
+// DescriptorInfo is the getdescriptorinfo RPC result
+type DescriptorInfo struct {
+	Descriptor     string `json:"descriptor"`
+	Checksum       string `json:"checksum"`
+	IsRange        bool   `json:"isrange"`
+	IsSolvable     bool   `json:"issolvable"`
+	HasPrivateKeys bool   `json:"hasprivatekeys"`
+}
+
+// GetDescriptorInfo asks the node to analyze a descriptor. The result holds
+// the canonical descriptor with its checksum and the checksum of the input.
+func (r *RPCClient) GetDescriptorInfo(descriptor string) (*DescriptorInfo, error) {
+	result, err := r.call(context.Background(), "getdescriptorinfo", []interface{}{descriptor})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get descriptor info: %w", err)
+	}
+
+	var info DescriptorInfo
+	if err := json.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// smartFeeResult is the estimatesmartfee RPC result
+type smartFeeResult struct {
+	FeeRate float64  `json:"feerate"` // BTC/kvB
+	Errors  []string `json:"errors"`
+	Blocks  int      `json:"blocks"`
+}
+
+// EstimateSmartFee returns the node's fee rate estimate in sat/vB for
+// confirmation within confTarget blocks
+func (r *RPCClient) EstimateSmartFee(confTarget int) (float64, error) {
+	result, err := r.call(context.Background(), "estimatesmartfee", []interface{}{confTarget})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate fee: %w", err)
+	}
+
+	var estimate smartFeeResult
+	if err := json.Unmarshal(result, &estimate); err != nil {
+		return 0, fmt.Errorf("failed to parse fee estimate: %w", err)
+	}
+
+	if estimate.FeeRate <= 0 {
+		if len(estimate.Errors) > 0 {
+			return 0, fmt.Errorf("fee estimation unavailable: %s", estimate.Errors[0])
+		}
+		return 0, fmt.Errorf("fee estimation unavailable")
+	}
+
+	// Convert BTC/kvB to sat/vB
+	return estimate.FeeRate * 1e8 / 1000, nil
+}
+
 // TestConnection tests the RPC connection
 func (r *RPCClient) TestConnection() error {
 	_, err := r.GetBlockCount()
@@ -105,9 +466,33 @@ type UTXO struct {
 	ScriptPubKey  string  `json:"scriptPubKey"`
 }
 
+// AmountSats returns Amount in satoshis. The amount is formatted to 8
+// decimals and the digits parsed as an integer, so 0.1 BTC is exactly
+// 10000000 satoshis whatever 0.1 * 1e8 evaluates to in floating point.
+func (u *UTXO) AmountSats() (int64, error) {
+	if math.IsNaN(u.Amount) || math.IsInf(u.Amount, 0) || u.Amount < 0 {
+		return 0, fmt.Errorf("invalid amount %v BTC", u.Amount)
+	}
+
+	formatted := strconv.FormatFloat(u.Amount, 'f', 8, 64)
+	sats, err := strconv.ParseInt(strings.Replace(formatted, ".", "", 1), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %s BTC overflows: %w", formatted, err)
+	}
+	if sats > btcutil.MaxSatoshi {
+		return 0, fmt.Errorf("amount %s BTC exceeds the 21 million BTC supply", formatted)
+	}
+	return sats, nil
+}
+
 // ListUnspent returns unspent outputs for a given address
 func (r *RPCClient) ListUnspent(address string) ([]*UTXO, error) {
-	result, err := r.call("listunspent", []interface{}{0, 9999999, []string{address}})
+	return r.ListUnspentCtx(context.Background(), address)
+}
+
+// ListUnspentCtx is ListUnspent, giving up when ctx is done
+func (r *RPCClient) ListUnspentCtx(ctx context.Context, address string) ([]*UTXO, error) {
+	result, err := r.call(ctx, "listunspent", []interface{}{0, 9999999, []string{address}})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list unspent outputs: %w", err)
 	}
@@ -122,20 +507,200 @@ func (r *RPCClient) ListUnspent(address string) ([]*UTXO, error) {
 
 // GetTransaction gets detailed information about a transaction
 func (r *RPCClient) GetTransaction(txid string) (json.RawMessage, error) {
-	result, err := r.call("getrawtransaction", []interface{}{txid, true})
+	return r.GetTransactionCtx(context.Background(), txid)
+}
+
+// GetTransactionCtx is GetTransaction, giving up when ctx is done
+func (r *RPCClient) GetTransactionCtx(ctx context.Context, txid string) (json.RawMessage, error) {
+	result, err := r.call(ctx, "getrawtransaction", []interface{}{txid, true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction: %w", err)
 	}
 	return result, nil
 }
 
-// call makes an RPC call to the Bitcoin node
-func (r *RPCClient) call(method string, params []interface{}) (json.RawMessage, error) {
-	// Create RPC request
-	request := RPCRequest{
+// RawTransaction is the verbose getrawtransaction result
+type RawTransaction struct {
+	TxID          string     `json:"txid"`
+	Hash          string     `json:"hash"`
+	Size          int64      `json:"size"`
+	VSize         int64      `json:"vsize"`
+	Weight        int64      `json:"weight"`
+	LockTime      uint32     `json:"locktime"`
+	Vin           []TxInput  `json:"vin"`
+	Vout          []TxOutput `json:"vout"`
+	BlockHash     string     `json:"blockhash,omitempty"`
+	Confirmations int64      `json:"confirmations,omitempty"`
+	BlockTime     int64      `json:"blocktime,omitempty"`
+}
+
+// TxInput is a transaction input in a verbose transaction
+type TxInput struct {
+	TxID     string   `json:"txid"`
+	Vout     uint32   `json:"vout"`
+	Coinbase string   `json:"coinbase,omitempty"`
+	Witness  []string `json:"txinwitness,omitempty"`
+	Sequence uint32   `json:"sequence"`
+}
+
+// TxOutput is a transaction output in a verbose transaction
+type TxOutput struct {
+	Value        float64      `json:"value"`
+	N            uint32       `json:"n"`
+	ScriptPubKey ScriptPubKey `json:"scriptPubKey"`
+}
+
+// ScriptPubKey describes an output script in a verbose transaction
+type ScriptPubKey struct {
+	Hex     string `json:"hex"`
+	Address string `json:"address,omitempty"`
+	Type    string `json:"type"`
+}
+
+// FindOutputsByScript returns the outputs whose scriptPubKey matches the given script
+func (tx *RawTransaction) FindOutputsByScript(pkScript []byte) []TxOutput {
+	scriptHex := hex.EncodeToString(pkScript)
+
+	var matches []TxOutput
+	for _, vout := range tx.Vout {
+		if strings.EqualFold(vout.ScriptPubKey.Hex, scriptHex) {
+			matches = append(matches, vout)
+		}
+	}
+	return matches
+}
+
+// GetRawTransaction gets a transaction and decodes the verbose result
+func (r *RPCClient) GetRawTransaction(txid string) (*RawTransaction, error) {
+	return r.GetRawTransactionCtx(context.Background(), txid)
+}
+
+// GetRawTransactionCtx is GetRawTransaction, giving up when ctx is done
+func (r *RPCClient) GetRawTransactionCtx(ctx context.Context, txid string) (*RawTransaction, error) {
+	result, err := r.GetTransactionCtx(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx RawTransaction
+	if err := json.Unmarshal(result, &tx); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+
+	return &tx, nil
+}
+
+// call makes an RPC call to the Bitcoin node, failing over to the next
+// endpoint when one cannot be reached. Errors from a node that answered,
+// including rate limiting, are returned without failing over, and so is the
+// end of ctx.
+func (r *RPCClient) call(ctx context.Context, method string, params []interface{}) (json.RawMessage, error) {
+	request := r.newRequest(method, params)
+
+	start := int(r.preferred.Load())
+	var errs []string
+	for i := range r.endpoints {
+		index := (start + i) % len(r.endpoints)
+		ep := r.endpoints[index]
+
+		result, err := r.send(ctx, ep, request)
+		var unreachable *unreachableError
+		if !errors.As(err, &unreachable) {
+			if err == nil {
+				r.preferred.Store(int64(index))
+				r.logServed(method, ep)
+			}
+			return result, err
+		}
+
+		if len(r.endpoints) == 1 {
+			return nil, err
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", ep.host, err))
+		if i+1 < len(r.endpoints) {
+			log.Printf("Warning: RPC endpoint %s unreachable, failing over to %s: %v",
+				ep.host, r.endpoints[(index+1)%len(r.endpoints)].host, err)
+		}
+	}
+
+	return nil, fmt.Errorf("all RPC endpoints failed: %s", strings.Join(errs, "; "))
+}
+
+// callPreferred makes an RPC call to the last endpoint that answered only
+func (r *RPCClient) callPreferred(ctx context.Context, method string, params []interface{}) (json.RawMessage, error) {
+	request := r.newRequest(method, params)
+
+	ep := r.endpoints[r.preferred.Load()]
+	result, err := r.send(ctx, ep, request)
+	if err == nil {
+		r.logServed(method, ep)
+	}
+	return result, err
+}
+
+// logServed reports which endpoint answered a request when debugging
+func (r *RPCClient) logServed(method string, ep *endpoint) {
+	if r.config.Debug {
+		log.Printf("RPC debug: %s served by %s", method, ep.host)
+	}
+}
+
+// credentials returns the RPC user and password, read from the cookie file
+// when one is configured
+func (r *RPCClient) credentials() (string, string, error) {
+	if r.config.CookieFile == "" {
+		return r.config.User, r.config.Pass, nil
+	}
+	return readCookie(r.config.CookieFile)
+}
+
+// readCookie reads the user and password from a node's cookie file, which
+// holds a single __cookie__:password line
+func readCookie(path string) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read RPC cookie file: %w", err)
+	}
+	user, pass, ok := strings.Cut(strings.TrimSpace(string(data)), ":")
+	if !ok || user == "" {
+		return "", "", fmt.Errorf("RPC cookie file %s is not in user:password form", path)
+	}
+	return user, pass, nil
+}
+
+// unreachableError marks a request that never got a response from the
+// endpoint, so another endpoint may be tried
+type unreachableError struct {
+	err error
+}
+
+func (e *unreachableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unreachableError) Unwrap() error {
+	return e.err
+}
+
+// newRequest creates an RPC request with a unique ID
+func (r *RPCClient) newRequest(method string, params []interface{}) *RPCRequest {
+	return &RPCRequest{
 		Method: method,
 		Params: params,
-		ID:     1,
+		ID:     int(r.nextID.Add(1)),
+	}
+}
+
+// send posts an RPC request to one endpoint and returns its result. The
+// request is bounded by the configured timeout; when it expires the endpoint
+// counts as unreachable, but when ctx itself ends its error is returned.
+func (r *RPCClient) send(ctx context.Context, ep *endpoint, request *RPCRequest) (json.RawMessage, error) {
+	if r.setupErr != nil {
+		return nil, r.setupErr
+	}
+	user, pass, err := r.credentials()
+	if err != nil {
+		return nil, err
 	}
 
 	// Marshal request to JSON
@@ -145,30 +710,44 @@ func (r *RPCClient) call(method string, params []interface{}) (json.RawMessage,
 	}
 
 	// Create HTTP request
-	url := fmt.Sprintf("http://%s", r.config.Host)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestData))
+	timeout := r.config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(callCtx, "POST", ep.url, bytes.NewBuffer(requestData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Set headers and authentication
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(r.config.User, r.config.Pass)
+	req.SetBasicAuth(user, pass)
 
 	// Make the request
-	resp, err := r.client.Do(req)
+	resp, err := ep.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("RPC %s abandoned: %w", request.Method, ctx.Err())
+		}
+		return nil, &unreachableError{fmt.Errorf("HTTP request failed: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("RPC %s abandoned: %w", request.Method, ctx.Err())
+		}
+		return nil, &unreachableError{fmt.Errorf("failed to read response body: %w", err)}
 	}
 
-	// Check HTTP status
+	// Check HTTP status, surfacing rate limiting so pollers can back off
+	if err := ratelimit.CheckResponse(resp); err != nil {
+		return nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
 	}
@@ -179,6 +758,11 @@ func (r *RPCClient) call(method string, params []interface{}) (json.RawMessage,
 		return nil, fmt.Errorf("failed to parse RPC response: %w", err)
 	}
 
+	// Make sure the response belongs to this request
+	if rpcResp.ID != request.ID {
+		return nil, fmt.Errorf("RPC response ID %d does not match request ID %d", rpcResp.ID, request.ID)
+	}
+
 	// Check for RPC error
 	if rpcResp.Error != nil {
 		return nil, fmt.Errorf("RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
@@ -0,0 +1,728 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/config"
+	"github.com/nikolay.stoev/bitcoin-inheritance/ratelimit"
+)
+
+// newStubHandler returns a handler answering JSON-RPC calls with fixed results per method
+func newStubHandler(t *testing.T, results map[string]string) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request RPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, ok := results[request.Method]
+		if !ok {
+			http.Error(w, "unknown method "+request.Method, http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"result": json.RawMessage(result),
+			"error":  nil,
+			"id":     request.ID,
+		})
+	})
+}
+
+func TestRPCClient_TCPHost(t *testing.T) {
+	server := httptest.NewServer(newStubHandler(t, map[string]string{"getblockcount": "123"}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+
+	blockCount, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount failed: %v", err)
+	}
+	if blockCount != 123 {
+		t.Errorf("Expected block count 123, got %d", blockCount)
+	}
+}
+
+func TestRPCClient_UnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "node.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix sockets not available: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(newStubHandler(t, map[string]string{"getblockcount": "456"}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{Host: "unix://" + socketPath})
+
+	blockCount, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount over Unix socket failed: %v", err)
+	}
+	if blockCount != 456 {
+		t.Errorf("Expected block count 456, got %d", blockCount)
+	}
+}
+
+func TestRPCClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(newStubHandler(t, map[string]string{"getblockcount": "789"}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	// The test server's self-signed certificate stands in for btcd's rpc.cert
+	caPath := filepath.Join(t.TempDir(), "rpc.cert")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, certPEM, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	notPEM := filepath.Join(t.TempDir(), "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  config.RPCConfig
+		wantErr string
+	}{
+		{"trusted CA", config.RPCConfig{Host: host, CACertPath: caPath}, ""},
+		{"system roots", config.RPCConfig{Host: host}, "certificate"},
+		{"TLS disabled", config.RPCConfig{Host: host, DisableTLS: true}, "HTTP error 400"},
+		{"missing CA file", config.RPCConfig{Host: host, CACertPath: filepath.Join(t.TempDir(), "missing.cert")}, "failed to read RPC CA certificate"},
+		{"CA file without PEM", config.RPCConfig{Host: host, CACertPath: notPEM}, "no PEM certificates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewRPCClient(&tt.config)
+			blockCount, err := client.GetBlockCount()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBlockCount over TLS failed: %v", err)
+			}
+			if blockCount != 789 {
+				t.Errorf("Expected block count 789, got %d", blockCount)
+			}
+		})
+	}
+}
+
+func TestRPCClient_CookieFile(t *testing.T) {
+	// Like the node, the server accepts whatever its cookie file holds
+	cookiePath := filepath.Join(t.TempDir(), ".cookie")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		cookie, err := os.ReadFile(cookiePath)
+		if !ok || err != nil || user+":"+pass != strings.TrimSpace(string(cookie)) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		newStubHandler(t, map[string]string{"getblockcount": "42"}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	writeCookie := func(content string) {
+		if err := os.WriteFile(cookiePath, []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	writeCookie("__cookie__:first")
+
+	// The cookie replaces the configured user and password
+	client := NewRPCClient(&config.RPCConfig{
+		Host:       strings.TrimPrefix(server.URL, "http://"),
+		DisableTLS: true,
+		User:       "user",
+		Pass:       "pass",
+		CookieFile: cookiePath,
+	})
+	if _, err := client.GetBlockCount(); err != nil {
+		t.Fatalf("GetBlockCount with cookie failed: %v", err)
+	}
+
+	// A restarted node writes a new cookie, which the next request picks up
+	writeCookie("__cookie__:second\n")
+	if _, err := client.GetBlockCount(); err != nil {
+		t.Fatalf("GetBlockCount with the new cookie failed: %v", err)
+	}
+
+	writeCookie("no separator")
+	if _, err := client.GetBlockCount(); err == nil || !strings.Contains(err.Error(), "user:password") {
+		t.Errorf("Expected a malformed cookie error, got %v", err)
+	}
+
+	os.Remove(cookiePath)
+	if _, err := client.GetBlockCount(); err == nil || !strings.Contains(err.Error(), "cookie file") {
+		t.Errorf("Expected a missing cookie error, got %v", err)
+	}
+}
+
+func TestRPCClient_ContextCanceled(t *testing.T) {
+	// The server holds every request until the client gives up
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{
+		Host:       strings.TrimPrefix(server.URL, "http://"),
+		DisableTLS: true,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := client.GetBlockCountCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRPCClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{
+		Host:       strings.TrimPrefix(server.URL, "http://"),
+		DisableTLS: true,
+		Timeout:    50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if _, err := client.GetBlockCount(); err == nil {
+		t.Fatal("Expected an error from a node slower than the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the call to give up after the timeout, took %v", elapsed)
+	}
+}
+
+func TestRPCClient_UniqueRequestIDs(t *testing.T) {
+	var seen []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request RPCRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		seen = append(seen, request.ID)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": 1, "error": nil, "id": request.ID})
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetBlockCount(); err != nil {
+			t.Fatalf("GetBlockCount failed: %v", err)
+		}
+	}
+
+	if len(seen) != 3 || seen[0] == seen[1] || seen[1] == seen[2] || seen[0] == seen[2] {
+		t.Errorf("Expected three distinct request IDs, got %v", seen)
+	}
+}
+
+func TestRPCClient_ResponseIDMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request RPCRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": 1, "error": nil, "id": request.ID + 100})
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+	if _, err := client.GetBlockCount(); err == nil {
+		t.Error("Expected error for mismatched response ID but got none")
+	}
+}
+
+func TestRawTransaction_FindOutputsByScript(t *testing.T) {
+	server := httptest.NewServer(newStubHandler(t, map[string]string{
+		"getrawtransaction": `{"txid":"ab","vout":[
+			{"value":0.001,"n":0,"scriptPubKey":{"hex":"0014aaaa","type":"witness_v0_keyhash"}},
+			{"value":0.002,"n":1,"scriptPubKey":{"hex":"0020BBBB","type":"witness_v0_scripthash"}}]}`,
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+	tx, err := client.GetRawTransaction("ab")
+	if err != nil {
+		t.Fatalf("GetRawTransaction failed: %v", err)
+	}
+
+	matches := tx.FindOutputsByScript([]byte{0x00, 0x20, 0xbb, 0xbb})
+	if len(matches) != 1 || matches[0].N != 1 {
+		t.Fatalf("Expected output 1 to match, got %+v", matches)
+	}
+
+	if matches := tx.FindOutputsByScript([]byte{0x51}); len(matches) != 0 {
+		t.Errorf("Expected no matches, got %+v", matches)
+	}
+}
+
+func TestRPCClient_GetTxOut(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		expected string
+	}{
+		{"unspent", `{"bestblock":"00","confirmations":3,"value":0.001,"scriptPubKey":{"hex":"0020abcd","type":"witness_v0_scripthash"}}`, "0020abcd"},
+		{"spent", `null`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(newStubHandler(t, map[string]string{"gettxout": tt.result}))
+			defer server.Close()
+
+			client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+			txOut, err := client.GetTxOut("ab", 0)
+			if err != nil {
+				t.Fatalf("GetTxOut failed: %v", err)
+			}
+
+			if tt.expected == "" {
+				if txOut != nil {
+					t.Errorf("Expected nil for spent output, got %+v", txOut)
+				}
+				return
+			}
+			if txOut == nil || txOut.ScriptPubKey.Hex != tt.expected {
+				t.Errorf("Expected script %s, got %+v", tt.expected, txOut)
+			}
+		})
+	}
+}
+
+func TestRPCClient_GetDescriptorInfo(t *testing.T) {
+	server := httptest.NewServer(newStubHandler(t, map[string]string{
+		"getdescriptorinfo": `{"descriptor":"raw(deadbeef)#89f8spxm","checksum":"89f8spxm","isrange":false,"issolvable":false,"hasprivatekeys":false}`,
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+	info, err := client.GetDescriptorInfo("raw(deadbeef)")
+	if err != nil {
+		t.Fatalf("GetDescriptorInfo failed: %v", err)
+	}
+
+	if info.Checksum != "89f8spxm" || info.Descriptor != "raw(deadbeef)#89f8spxm" {
+		t.Errorf("Unexpected descriptor info: %+v", info)
+	}
+	if info.IsRange || info.IsSolvable {
+		t.Errorf("Expected unranged, unsolvable descriptor: %+v", info)
+	}
+}
+
+func TestRPCClient_EstimateSmartFee(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		expected float64
+		wantErr  bool
+	}{
+		{"estimate", `{"feerate":0.00012345,"blocks":6}`, 12.345, false},
+		{"minimum relay fee", `{"feerate":0.00001,"blocks":2}`, 1, false},
+		{"insufficient data", `{"errors":["Insufficient data or no feerate found"],"blocks":0}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(newStubHandler(t, map[string]string{"estimatesmartfee": tt.result}))
+			defer server.Close()
+
+			client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+			feeRate, err := client.EstimateSmartFee(6)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %.3f sat/vB", feeRate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EstimateSmartFee failed: %v", err)
+			}
+			if math.Abs(feeRate-tt.expected) > 1e-9 {
+				t.Errorf("Expected %.3f sat/vB, got %.3f", tt.expected, feeRate)
+			}
+		})
+	}
+}
+
+func TestRPCClient_TestMempoolAccept(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  string
+		allowed bool
+		reason  string
+		wantErr bool
+	}{
+		{"accepted", `[{"txid":"aa","allowed":true,"vsize":150}]`, true, "", false},
+		{"rejected", `[{"txid":"aa","allowed":false,"reject-reason":"non-BIP68-final"}]`, false, "non-BIP68-final", false},
+		{"no result", `[]`, false, "", true},
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(newStubHandler(t, map[string]string{"testmempoolaccept": tt.result}))
+			defer server.Close()
+
+			client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+			allowed, reason, err := client.TestMempoolAccept(tx)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TestMempoolAccept failed: %v", err)
+			}
+			if allowed != tt.allowed || reason != tt.reason {
+				t.Errorf("Expected allowed=%v reason %q, got allowed=%v reason %q", tt.allowed, tt.reason, allowed, reason)
+			}
+		})
+	}
+}
+
+func TestUTXO_AmountSats(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		expected int64
+		wantErr  bool
+	}{
+		{"0.1 BTC", 0.1, 10000000, false},
+		{"21 million BTC", 21000000, 2100000000000000, false},
+		{"1 satoshi", 0.00000001, 1, false},
+		{"0.29 BTC, which truncates when multiplied", 0.29, 29000000, false},
+		{"zero", 0, 0, false},
+		{"above supply", 21000001, 0, true},
+		{"overflow", 1e30, 0, true},
+		{"negative", -0.1, 0, true},
+		{"NaN", math.NaN(), 0, true},
+		{"infinite", math.Inf(1), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utxo := &UTXO{Amount: tt.amount}
+			sats, err := utxo.AmountSats()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %d satoshis", sats)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AmountSats failed: %v", err)
+			}
+			if sats != tt.expected {
+				t.Errorf("Expected %d satoshis, got %d", tt.expected, sats)
+			}
+		})
+	}
+}
+
+func TestRPCClient_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+	_, err := client.GetBlockCount()
+
+	var rateLimited *ratelimit.RateLimitError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if rateLimited.RetryAfter != 30*time.Second {
+		t.Errorf("Expected retry after 30s, got %s", rateLimited.RetryAfter)
+	}
+}
+
+func TestRPCClient_FindSpender(t *testing.T) {
+	block := `{"hash":"blk","height":100,"time":1700000000,"tx":[
+		{"txid":"other","vin":[{"txid":"ff","vout":0}],"vout":[]},
+		{"txid":"spend","vin":[{"txid":"ab","vout":1,"txinwitness":["30","01","63"]}],"vout":[]}]}`
+
+	tests := []struct {
+		name        string
+		results     map[string]string
+		expectTxID  string
+		expectPool  bool
+		expectFound bool
+	}{
+		{
+			name: "mempool",
+			results: map[string]string{
+				"gettxspendingprevout": `[{"txid":"ab","vout":1,"spendingtxid":"pooled"}]`,
+				"getrawtransaction":    `{"txid":"pooled","vin":[{"txid":"ab","vout":1,"txinwitness":["30","","63"]}],"vout":[]}`,
+			},
+			expectTxID:  "pooled",
+			expectPool:  true,
+			expectFound: true,
+		},
+		{
+			name: "confirmed",
+			results: map[string]string{
+				"gettxspendingprevout": `[{"txid":"ab","vout":1}]`,
+				"getblockcount":        "100",
+				"getblockhash":         `"blk"`,
+				"getblock":             block,
+			},
+			expectTxID:  "spend",
+			expectFound: true,
+		},
+		{
+			name: "not found",
+			results: map[string]string{
+				"getblockcount": "100",
+				"getblockhash":  `"blk"`,
+				"getblock":      `{"hash":"blk","height":100,"time":1700000000,"tx":[]}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(newStubHandler(t, tt.results))
+			defer server.Close()
+
+			client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+			spender, err := client.FindSpender("ab", 1, 99, 10)
+			if err != nil {
+				t.Fatalf("FindSpender failed: %v", err)
+			}
+
+			if !tt.expectFound {
+				if spender != nil {
+					t.Errorf("Expected no spender, got %+v", spender)
+				}
+				return
+			}
+			if spender == nil {
+				t.Fatalf("Expected spender %s, got nil", tt.expectTxID)
+			}
+			if spender.TxID != tt.expectTxID || spender.InMempool != tt.expectPool {
+				t.Errorf("Unexpected spender: %+v", spender)
+			}
+			if len(spender.Witness) != 3 {
+				t.Errorf("Expected 3 witness items, got %v", spender.Witness)
+			}
+		})
+	}
+}
+
+func TestRPCClient_BroadcastChecksChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		network   string
+		chainInfo string
+		wantErr   string
+	}{
+		{"core testnet", "testnet3", `{"chain":"test","blocks":100}`, ""},
+		{"btcd testnet", "testnet3", `{"chain":"testnet3","blocks":100}`, ""},
+		{"core mainnet", "mainnet", `{"chain":"main","blocks":100}`, ""},
+		{"testnet tx to mainnet node", "testnet3", `{"chain":"main","blocks":100}`, "node is on chain \"main\""},
+		{"mainnet tx to testnet node", "mainnet", `{"chain":"test","blocks":100}`, "node is on chain \"test\""},
+		{"chain unknown", "testnet3", "", "could not confirm"},
+		{"check disabled", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broadcast := false
+			results := map[string]string{"sendrawtransaction": `"txid"`}
+			if tt.chainInfo != "" {
+				results["getblockchaininfo"] = tt.chainInfo
+			}
+			stub := newStubHandler(t, results)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if strings.Contains(string(body), "sendrawtransaction") {
+					broadcast = true
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				stub.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true, Network: tt.network})
+			tx := wire.NewMsgTx(wire.TxVersion)
+			tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+			tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+			_, err := client.BroadcastTransaction(tx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !broadcast {
+					t.Error("Expected the transaction to be broadcast")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if broadcast {
+				t.Error("Transaction was broadcast despite the chain check failing")
+			}
+		})
+	}
+}
+
+func TestRPCClient_DetectNetwork(t *testing.T) {
+	tests := []struct {
+		name      string
+		chainInfo string
+		expected  string
+		wantErr   bool
+	}{
+		{"core testnet", `{"chain":"test","blocks":100}`, "testnet3", false},
+		{"core mainnet", `{"chain":"main","blocks":100}`, "mainnet", false},
+		{"btcd testnet", `{"chain":"testnet3","blocks":100}`, "testnet3", false},
+		{"testnet4", `{"chain":"testnet4","blocks":100}`, "testnet4", false},
+		{"regtest", `{"chain":"regtest","blocks":100}`, "regtest", false},
+		{"unknown chain", `{"chain":"liquidv1","blocks":100}`, "liquidv1", false},
+		{"no answer", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := map[string]string{}
+			if tt.chainInfo != "" {
+				results["getblockchaininfo"] = tt.chainInfo
+			}
+			server := httptest.NewServer(newStubHandler(t, results))
+			defer server.Close()
+
+			client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true})
+			network, err := client.DetectNetwork()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got network %q", network)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectNetwork failed: %v", err)
+			}
+			if network != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, network)
+			}
+		})
+	}
+}
+
+// countingServer wraps a handler and counts the requests it serves
+func countingServer(t *testing.T, handler http.Handler, count *int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*count++
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// deadHost returns the address of a server that has been shut down
+func deadHost(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	host := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+	return host
+}
+
+func TestRPCClient_Failover(t *testing.T) {
+	var primaryHits, backupHits int
+	primary := countingServer(t, newStubHandler(t, map[string]string{"getblockcount": "100"}), &primaryHits)
+	backup := countingServer(t, newStubHandler(t, map[string]string{"getblockcount": "200"}), &backupHits)
+	primaryHost := strings.TrimPrefix(primary.URL, "http://")
+	backupHost := strings.TrimPrefix(backup.URL, "http://")
+
+	t.Run("primary answers", func(t *testing.T) {
+		client := NewRPCClient(&config.RPCConfig{Hosts: []string{primaryHost, backupHost}, DisableTLS: true})
+		if height, err := client.GetBlockCount(); err != nil || height != 100 {
+			t.Fatalf("Expected height 100 from the primary, got %d (%v)", height, err)
+		}
+		if backupHits != 0 {
+			t.Errorf("Expected the backup to be unused, got %d requests", backupHits)
+		}
+	})
+
+	t.Run("unreachable endpoint fails over and stays skipped", func(t *testing.T) {
+		backupHits = 0
+		client := NewRPCClient(&config.RPCConfig{Hosts: []string{deadHost(t), backupHost}, DisableTLS: true})
+		for i := 0; i < 2; i++ {
+			if height, err := client.GetBlockCount(); err != nil || height != 200 {
+				t.Fatalf("Expected height 200 from the backup, got %d (%v)", height, err)
+			}
+		}
+		if client.preferred.Load() != 1 {
+			t.Errorf("Expected the backup to be preferred, got endpoint %d", client.preferred.Load())
+		}
+		if backupHits != 2 {
+			t.Errorf("Expected 2 requests to the backup, got %d", backupHits)
+		}
+	})
+
+	t.Run("node errors do not fail over", func(t *testing.T) {
+		primaryHits, backupHits = 0, 0
+		client := NewRPCClient(&config.RPCConfig{Hosts: []string{primaryHost, backupHost}, DisableTLS: true})
+		if _, err := client.GetBestBlockHash(); err == nil {
+			t.Fatal("Expected an error for a method the primary does not know")
+		}
+		if primaryHits != 1 || backupHits != 0 {
+			t.Errorf("Expected only the primary to be asked, got %d and %d requests", primaryHits, backupHits)
+		}
+	})
+
+	t.Run("all endpoints unreachable", func(t *testing.T) {
+		client := NewRPCClient(&config.RPCConfig{Hosts: []string{deadHost(t), deadHost(t)}, DisableTLS: true})
+		_, err := client.GetBlockCount()
+		if err == nil || !strings.Contains(err.Error(), "all RPC endpoints failed") {
+			t.Errorf("Expected every endpoint to fail, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package rpc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// electrumProtocolVersion is the Electrum protocol version negotiated with servers
+const electrumProtocolVersion = "1.4"
+
+// electrumTLSPrefix marks an Electrum server reached over TLS
+const electrumTLSPrefix = "ssl://"
+
+// ElectrumClient talks to an ElectrumX or Fulcrum server using the Electrum
+// protocol (newline-delimited JSON-RPC over TCP). Each call uses its own
+// connection, so the client needs no subscription handling.
+type ElectrumClient struct {
+	address     string
+	useTLS      bool
+	chainParams *chaincfg.Params
+	timeout     time.Duration
+
+	// nextID assigns a unique ID to every request for response correlation
+	nextID atomic.Int64
+}
+
+// electrumRequest is a single Electrum protocol request
+type electrumRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// electrumResponse is a response or, without an ID, a notification
+type electrumResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// ElectrumUTXO is an entry of the blockchain.scripthash.listunspent result
+type ElectrumUTXO struct {
+	TxHash string `json:"tx_hash"`
+	TxPos  uint32 `json:"tx_pos"`
+	Height int64  `json:"height"` // zero or negative while unconfirmed
+	Value  int64  `json:"value"`  // satoshis
+}
+
+// NewElectrumClient creates a client for an Electrum server given as
+// host:port, or ssl://host:port for TLS
+func NewElectrumClient(server string, chainParams *chaincfg.Params) *ElectrumClient {
+	address, useTLS := strings.CutPrefix(server, electrumTLSPrefix)
+	if !useTLS {
+		address = strings.TrimPrefix(server, "tcp://")
+	}
+
+	return &ElectrumClient{
+		address:     address,
+		useTLS:      useTLS,
+		chainParams: chainParams,
+		timeout:     30 * time.Second,
+	}
+}
+
+// GetBlockCount returns the height of the server's chain tip
+func (e *ElectrumClient) GetBlockCount() (int64, error) {
+	result, err := e.call("blockchain.headers.subscribe")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chain tip: %w", err)
+	}
+
+	var header struct {
+		Height int64 `json:"height"`
+	}
+	if err := json.Unmarshal(result, &header); err != nil {
+		return 0, fmt.Errorf("failed to parse chain tip: %w", err)
+	}
+
+	return header.Height, nil
+}
+
+// ListUnspent returns the unspent outputs paying an address, looked up by
+// the address's Electrum scripthash
+func (e *ElectrumClient) ListUnspent(address string) ([]*UTXO, error) {
+	addr, err := btcutil.DecodeAddress(address, e.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build output script: %w", err)
+	}
+
+	result, err := e.call("blockchain.scripthash.listunspent", hex.EncodeToString(script.ElectrumScriptHash(pkScript)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unspent outputs: %w", err)
+	}
+
+	var entries []ElectrumUTXO
+	if err := json.Unmarshal(result, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse unspent outputs: %w", err)
+	}
+
+	// Confirmations are counted from the tip, which is only needed once an
+	// output is confirmed
+	var tip int64
+	utxos := make([]*UTXO, 0, len(entries))
+	for _, entry := range entries {
+		var confirmations int64
+		if entry.Height > 0 {
+			if tip == 0 {
+				if tip, err = e.GetBlockCount(); err != nil {
+					return nil, err
+				}
+			}
+			confirmations = tip - entry.Height + 1
+		}
+
+		utxos = append(utxos, &UTXO{
+			TxID:          entry.TxHash,
+			Vout:          entry.TxPos,
+			Address:       address,
+			Amount:        btcutil.Amount(entry.Value).ToBTC(),
+			Confirmations: confirmations,
+			ScriptPubKey:  hex.EncodeToString(pkScript),
+		})
+	}
+
+	return utxos, nil
+}
+
+// BroadcastTransaction sends a transaction through the server. The server's
+// genesis block is checked first so a transaction is never sent to a server
+// on a different network than it was built for.
+func (e *ElectrumClient) BroadcastTransaction(tx *wire.MsgTx) (string, error) {
+	if err := e.checkGenesis(); err != nil {
+		return "", fmt.Errorf("refusing to broadcast: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+
+	result, err := e.call("blockchain.transaction.broadcast", hex.EncodeToString(buf.Bytes()))
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	var txid string
+	if err := json.Unmarshal(result, &txid); err != nil {
+		return "", fmt.Errorf("failed to parse transaction ID: %w", err)
+	}
+
+	return txid, nil
+}
+
+// GetRawTransaction gets a transaction with blockchain.transaction.get in
+// verbose mode, which servers answer with the node's getrawtransaction result
+func (e *ElectrumClient) GetRawTransaction(txid string) (*RawTransaction, error) {
+	result, err := e.call("blockchain.transaction.get", txid, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	var tx RawTransaction
+	if err := json.Unmarshal(result, &tx); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+	return &tx, nil
+}
+
+// checkGenesis confirms the server follows the configured network
+func (e *ElectrumClient) checkGenesis() error {
+	result, err := e.call("server.features")
+	if err != nil {
+		return fmt.Errorf("could not confirm the server's chain: %w", err)
+	}
+
+	var features struct {
+		GenesisHash string `json:"genesis_hash"`
+	}
+	if err := json.Unmarshal(result, &features); err != nil {
+		return fmt.Errorf("could not confirm the server's chain: %w", err)
+	}
+
+	if features.GenesisHash != e.chainParams.GenesisHash.String() {
+		return fmt.Errorf("server has genesis block %s, which is not %s", features.GenesisHash, e.chainParams.Name)
+	}
+	return nil
+}
+
+// call sends one request on a fresh connection, negotiating the protocol
+// version first as servers expect, and returns its result
+func (e *ElectrumClient) call(method string, params ...interface{}) (json.RawMessage, error) {
+	conn, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(e.timeout)); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	version := e.newRequest("server.version", "bitcoin-inheritance", electrumProtocolVersion)
+	request := e.newRequest(method, params...)
+
+	// Encode writes each request followed by the newline that frames it
+	encoder := json.NewEncoder(conn)
+	for _, r := range []*electrumRequest{version, request} {
+		if err := encoder.Encode(r); err != nil {
+			return nil, fmt.Errorf("failed to send %s: %w", r.Method, err)
+		}
+	}
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var response electrumResponse
+		if err := decoder.Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		switch {
+		case response.ID == nil:
+			// Notifications carry no ID
+			continue
+		case *response.ID == version.ID:
+			if err := electrumErr(response.Error); err != nil {
+				return nil, fmt.Errorf("server rejected protocol version %s: %w", electrumProtocolVersion, err)
+			}
+		case *response.ID == request.ID:
+			if err := electrumErr(response.Error); err != nil {
+				return nil, err
+			}
+			return response.Result, nil
+		default:
+			return nil, fmt.Errorf("unexpected response ID %d", *response.ID)
+		}
+	}
+}
+
+// dial connects to the server, over TLS for ssl:// addresses
+func (e *ElectrumClient) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: e.timeout}
+	var conn net.Conn
+	var err error
+	if e.useTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", e.address, &tls.Config{MinVersion: tls.VersionTLS12})
+	} else {
+		conn, err = dialer.Dial("tcp", e.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Electrum server %s: %w", e.address, err)
+	}
+	return conn, nil
+}
+
+// newRequest creates a request with a unique ID
+func (e *ElectrumClient) newRequest(method string, params ...interface{}) *electrumRequest {
+	if params == nil {
+		params = []interface{}{}
+	}
+	return &electrumRequest{
+		JSONRPC: "2.0",
+		ID:      e.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	}
+}
+
+// electrumErr converts the error member of a response, which servers send
+// either as a {code, message} object or as a plain string
+func electrumErr(raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var object struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil && object.Message != "" {
+		return fmt.Errorf("electrum error %d: %s", object.Code, object.Message)
+	}
+
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		return fmt.Errorf("electrum error: %s", message)
+	}
+	return fmt.Errorf("electrum error: %s", raw)
+}
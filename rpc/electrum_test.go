@@ -0,0 +1,184 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// startElectrumStub serves Electrum protocol requests with fixed results per
+// method and records every request it receives
+func startElectrumStub(t *testing.T, results map[string]string) (string, chan electrumRequest) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	requests := make(chan electrumRequest, 32)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				encoder := json.NewEncoder(conn)
+				for scanner.Scan() {
+					var request electrumRequest
+					if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+						return
+					}
+					requests <- request
+
+					// A notification before the response must be skipped
+					encoder.Encode(map[string]interface{}{"method": "blockchain.headers.subscribe", "params": []int{}})
+
+					result, ok := results[request.Method]
+					if !ok {
+						encoder.Encode(map[string]interface{}{"id": request.ID, "error": map[string]interface{}{"code": 1, "message": "unknown method"}})
+						continue
+					}
+					encoder.Encode(map[string]interface{}{"id": request.ID, "result": json.RawMessage(result)})
+				}
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String(), requests
+}
+
+func TestElectrumClient_GetBlockCount(t *testing.T) {
+	address, requests := startElectrumStub(t, map[string]string{
+		"server.version":               `["ElectrumX 1.16", "1.4"]`,
+		"blockchain.headers.subscribe": `{"height": 2500000, "hex": "00"}`,
+	})
+	client := NewElectrumClient(address, &chaincfg.TestNet3Params)
+
+	height, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount failed: %v", err)
+	}
+	if height != 2500000 {
+		t.Errorf("Expected height 2500000, got %d", height)
+	}
+
+	// The protocol version is negotiated before the request
+	if first := <-requests; first.Method != "server.version" {
+		t.Errorf("Expected server.version first, got %s", first.Method)
+	}
+}
+
+func TestElectrumClient_ListUnspent(t *testing.T) {
+	addr, err := btcutil.NewAddressWitnessScriptHash(make([]byte, 32), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessScriptHash failed: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+
+	address, requests := startElectrumStub(t, map[string]string{
+		"server.version":               `["ElectrumX 1.16", "1.4"]`,
+		"blockchain.headers.subscribe": `{"height": 110, "hex": "00"}`,
+		"blockchain.scripthash.listunspent": `[
+			{"tx_hash": "aa", "tx_pos": 1, "height": 101, "value": 150000},
+			{"tx_hash": "bb", "tx_pos": 0, "height": 0, "value": 2000}
+		]`,
+	})
+	client := NewElectrumClient(address, &chaincfg.TestNet3Params)
+
+	utxos, err := client.ListUnspent(addr.EncodeAddress())
+	if err != nil {
+		t.Fatalf("ListUnspent failed: %v", err)
+	}
+	if len(utxos) != 2 {
+		t.Fatalf("Expected 2 UTXOs, got %d", len(utxos))
+	}
+	if utxos[0].TxID != "aa" || utxos[0].Vout != 1 || utxos[0].Amount != 0.0015 || utxos[0].Confirmations != 10 {
+		t.Errorf("Unexpected confirmed UTXO: %+v", utxos[0])
+	}
+	if utxos[1].Confirmations != 0 {
+		t.Errorf("Expected the mempool UTXO to have 0 confirmations, got %d", utxos[1].Confirmations)
+	}
+	if utxos[0].ScriptPubKey != hex.EncodeToString(pkScript) {
+		t.Errorf("Expected script %x, got %s", pkScript, utxos[0].ScriptPubKey)
+	}
+
+	// The lookup is keyed by the address's Electrum scripthash
+	expected := hex.EncodeToString(script.ElectrumScriptHash(pkScript))
+	for request := range requests {
+		if request.Method != "blockchain.scripthash.listunspent" {
+			continue
+		}
+		if len(request.Params) != 1 || request.Params[0] != expected {
+			t.Errorf("Expected scripthash %s, got %v", expected, request.Params)
+		}
+		break
+	}
+}
+
+func TestElectrumClient_BroadcastChecksGenesis(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+	tests := []struct {
+		name    string
+		genesis string
+		wantErr string
+	}{
+		{"matching network", chaincfg.TestNet3Params.GenesisHash.String(), ""},
+		{"mainnet server", chaincfg.MainNetParams.GenesisHash.String(), "refusing to broadcast"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, _ := startElectrumStub(t, map[string]string{
+				"server.version":                   `["ElectrumX 1.16", "1.4"]`,
+				"server.features":                  `{"genesis_hash": "` + tt.genesis + `"}`,
+				"blockchain.transaction.broadcast": `"` + tx.TxHash().String() + `"`,
+			})
+			client := NewElectrumClient(address, &chaincfg.TestNet3Params)
+
+			txid, err := client.BroadcastTransaction(tx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BroadcastTransaction failed: %v", err)
+			}
+			if txid != tx.TxHash().String() {
+				t.Errorf("Expected txid %s, got %s", tx.TxHash(), txid)
+			}
+		})
+	}
+}
+
+func TestElectrumClient_ServerError(t *testing.T) {
+	address, _ := startElectrumStub(t, map[string]string{
+		"server.version": `["ElectrumX 1.16", "1.4"]`,
+	})
+	client := NewElectrumClient(address, &chaincfg.TestNet3Params)
+
+	if _, err := client.GetBlockCount(); err == nil || !strings.Contains(err.Error(), "unknown method") {
+		t.Errorf("Expected the server error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// EsploraClient talks to an Esplora REST API, as served by Blockstream's
+// blockstream.info and mempool.space, for users without a node of their own
+type EsploraClient struct {
+	baseURL     string
+	chainParams *chaincfg.Params
+	client      *http.Client
+}
+
+// EsploraStatus is the confirmation status of an Esplora transaction or output
+type EsploraStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int64  `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockTime   int64  `json:"block_time"`
+}
+
+// EsploraUTXO is an entry of the /address/{addr}/utxo result
+type EsploraUTXO struct {
+	TxID   string        `json:"txid"`
+	Vout   uint32        `json:"vout"`
+	Value  int64         `json:"value"` // satoshis
+	Status EsploraStatus `json:"status"`
+}
+
+// esploraTx is the /tx/{txid} result
+type esploraTx struct {
+	TxID     string `json:"txid"`
+	LockTime uint32 `json:"locktime"`
+	Size     int64  `json:"size"`
+	Weight   int64  `json:"weight"`
+	Vin      []struct {
+		TxID       string   `json:"txid"`
+		Vout       uint32   `json:"vout"`
+		Witness    []string `json:"witness"`
+		IsCoinbase bool     `json:"is_coinbase"`
+		ScriptSig  string   `json:"scriptsig"`
+		Sequence   uint32   `json:"sequence"`
+	} `json:"vin"`
+	Vout []struct {
+		ScriptPubKey        string `json:"scriptpubkey"`
+		ScriptPubKeyType    string `json:"scriptpubkey_type"`
+		ScriptPubKeyAddress string `json:"scriptpubkey_address"`
+		Value               int64  `json:"value"`
+	} `json:"vout"`
+	Status EsploraStatus `json:"status"`
+}
+
+// NewEsploraClient creates a client for the Esplora API at baseURL, such as
+// https://blockstream.info/testnet/api
+func NewEsploraClient(baseURL string, chainParams *chaincfg.Params) *EsploraClient {
+	return &EsploraClient{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		chainParams: chainParams,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetBlockCount returns the height of the API's chain tip
+func (e *EsploraClient) GetBlockCount() (int64, error) {
+	body, err := e.get("/blocks/tip/height")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chain tip: %w", err)
+	}
+
+	height, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse chain tip: %w", err)
+	}
+	return height, nil
+}
+
+// ListUnspent returns the unspent outputs paying an address
+func (e *EsploraClient) ListUnspent(address string) ([]*UTXO, error) {
+	addr, err := btcutil.DecodeAddress(address, e.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build output script: %w", err)
+	}
+
+	body, err := e.get("/address/" + url.PathEscape(address) + "/utxo")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unspent outputs: %w", err)
+	}
+
+	var entries []EsploraUTXO
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse unspent outputs: %w", err)
+	}
+
+	// Confirmations are counted from the tip, which is only needed once an
+	// output is confirmed
+	var tip int64
+	utxos := make([]*UTXO, 0, len(entries))
+	for _, entry := range entries {
+		confirmations, err := e.confirmations(entry.Status, &tip)
+		if err != nil {
+			return nil, err
+		}
+
+		utxos = append(utxos, &UTXO{
+			TxID:          entry.TxID,
+			Vout:          entry.Vout,
+			Address:       address,
+			Amount:        btcutil.Amount(entry.Value).ToBTC(),
+			Confirmations: confirmations,
+			ScriptPubKey:  hex.EncodeToString(pkScript),
+		})
+	}
+
+	return utxos, nil
+}
+
+// BroadcastTransaction posts a transaction to the API. The API's genesis
+// block is checked first so a transaction is never sent to an API on a
+// different network than it was built for.
+func (e *EsploraClient) BroadcastTransaction(tx *wire.MsgTx) (string, error) {
+	if err := e.checkGenesis(); err != nil {
+		return "", fmt.Errorf("refusing to broadcast: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+
+	body, err := e.do(http.MethodPost, "/tx", strings.NewReader(hex.EncodeToString(buf.Bytes())))
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	txid := strings.TrimSpace(string(body))
+	if _, err := hex.DecodeString(txid); err != nil || len(txid) != 64 {
+		return "", fmt.Errorf("failed to parse transaction ID %q", txid)
+	}
+	return txid, nil
+}
+
+// GetRawTransaction gets a transaction in the form of the node's verbose
+// getrawtransaction result
+func (e *EsploraClient) GetRawTransaction(txid string) (*RawTransaction, error) {
+	body, err := e.get("/tx/" + url.PathEscape(txid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	var tx esploraTx
+	if err := json.Unmarshal(body, &tx); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+
+	var tip int64
+	confirmations, err := e.confirmations(tx.Status, &tip)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := &RawTransaction{
+		TxID:          tx.TxID,
+		Size:          tx.Size,
+		VSize:         (tx.Weight + 3) / 4,
+		Weight:        tx.Weight,
+		LockTime:      tx.LockTime,
+		Confirmations: confirmations,
+	}
+	if tx.Status.Confirmed {
+		raw.BlockHash = tx.Status.BlockHash
+		raw.BlockTime = tx.Status.BlockTime
+	}
+	for _, in := range tx.Vin {
+		input := TxInput{TxID: in.TxID, Vout: in.Vout, Witness: in.Witness, Sequence: in.Sequence}
+		if in.IsCoinbase {
+			input = TxInput{Coinbase: in.ScriptSig, Sequence: in.Sequence}
+		}
+		raw.Vin = append(raw.Vin, input)
+	}
+	for n, out := range tx.Vout {
+		raw.Vout = append(raw.Vout, TxOutput{
+			Value: btcutil.Amount(out.Value).ToBTC(),
+			N:     uint32(n),
+			ScriptPubKey: ScriptPubKey{
+				Hex:     out.ScriptPubKey,
+				Address: out.ScriptPubKeyAddress,
+				Type:    out.ScriptPubKeyType,
+			},
+		})
+	}
+
+	return raw, nil
+}
+
+// confirmations counts the confirmations of status, fetching the tip into
+// *tip the first time it is needed
+func (e *EsploraClient) confirmations(status EsploraStatus, tip *int64) (int64, error) {
+	if !status.Confirmed || status.BlockHeight <= 0 {
+		return 0, nil
+	}
+	if *tip == 0 {
+		height, err := e.GetBlockCount()
+		if err != nil {
+			return 0, err
+		}
+		*tip = height
+	}
+	return *tip - status.BlockHeight + 1, nil
+}
+
+// checkGenesis confirms the API follows the configured network
+func (e *EsploraClient) checkGenesis() error {
+	body, err := e.get("/block-height/0")
+	if err != nil {
+		return fmt.Errorf("could not confirm the API's chain: %w", err)
+	}
+
+	genesis := strings.TrimSpace(string(body))
+	if genesis != e.chainParams.GenesisHash.String() {
+		return fmt.Errorf("API has genesis block %s, which is not %s", genesis, e.chainParams.Name)
+	}
+	return nil
+}
+
+// get fetches path from the API
+func (e *EsploraClient) get(path string) ([]byte, error) {
+	return e.do(http.MethodGet, path, nil)
+}
+
+// do sends a request to the API and returns the body of a 200 response.
+// Esplora reports errors as plain text, which is included in the error.
+func (e *EsploraClient) do(method, path string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, e.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("esplora error %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
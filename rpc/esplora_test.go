@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// startEsploraStub serves fixed REST payloads per "METHOD /path" and hands
+// every POST body to posted
+func startEsploraStub(t *testing.T, responses map[string]string, posted chan<- string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && posted != nil {
+			body, _ := io.ReadAll(r.Body)
+			posted <- string(body)
+		}
+		response, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/"
+}
+
+func TestEsploraClient_GetBlockCount(t *testing.T) {
+	baseURL := startEsploraStub(t, map[string]string{"GET /blocks/tip/height": "2575123"}, nil)
+	client := NewEsploraClient(baseURL, &chaincfg.TestNet3Params)
+
+	height, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount failed: %v", err)
+	}
+	if height != 2575123 {
+		t.Errorf("Expected height 2575123, got %d", height)
+	}
+}
+
+func TestEsploraClient_ListUnspent(t *testing.T) {
+	addr, err := btcutil.NewAddressWitnessScriptHash(make([]byte, 32), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessScriptHash failed: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+
+	baseURL := startEsploraStub(t, map[string]string{
+		"GET /blocks/tip/height": "110",
+		"GET /address/" + addr.EncodeAddress() + "/utxo": `[
+			{"txid": "aa", "vout": 1, "status": {"confirmed": true, "block_height": 101, "block_hash": "00ff", "block_time": 1700000000}, "value": 150000},
+			{"txid": "bb", "vout": 0, "status": {"confirmed": false}, "value": 2000}
+		]`,
+	}, nil)
+	client := NewEsploraClient(baseURL, &chaincfg.TestNet3Params)
+
+	utxos, err := client.ListUnspent(addr.EncodeAddress())
+	if err != nil {
+		t.Fatalf("ListUnspent failed: %v", err)
+	}
+	if len(utxos) != 2 {
+		t.Fatalf("Expected 2 UTXOs, got %d", len(utxos))
+	}
+	if utxos[0].TxID != "aa" || utxos[0].Vout != 1 || utxos[0].Amount != 0.0015 || utxos[0].Confirmations != 10 {
+		t.Errorf("Unexpected confirmed UTXO: %+v", utxos[0])
+	}
+	if utxos[1].Confirmations != 0 {
+		t.Errorf("Expected the mempool UTXO to have 0 confirmations, got %d", utxos[1].Confirmations)
+	}
+	if utxos[0].ScriptPubKey != hex.EncodeToString(pkScript) {
+		t.Errorf("Expected script %x, got %s", pkScript, utxos[0].ScriptPubKey)
+	}
+}
+
+func TestEsploraClient_GetRawTransaction(t *testing.T) {
+	const txid = "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807"
+	baseURL := startEsploraStub(t, map[string]string{
+		"GET /blocks/tip/height": "200",
+		"GET /tx/" + txid: `{
+			"txid": "` + txid + `", "version": 2, "locktime": 0, "size": 222, "weight": 561,
+			"vin": [{"txid": "bb", "vout": 3, "witness": ["3044", "01"], "is_coinbase": false, "sequence": 4294967293}],
+			"vout": [{"scriptpubkey": "0020aa", "scriptpubkey_type": "v0_p2wsh", "scriptpubkey_address": "tb1qexample", "value": 99000}],
+			"status": {"confirmed": true, "block_height": 191, "block_hash": "00ff", "block_time": 1700000000}
+		}`,
+	}, nil)
+	client := NewEsploraClient(baseURL, &chaincfg.TestNet3Params)
+
+	tx, err := client.GetRawTransaction(txid)
+	if err != nil {
+		t.Fatalf("GetRawTransaction failed: %v", err)
+	}
+	if tx.TxID != txid || tx.VSize != 141 || tx.Confirmations != 10 || tx.BlockHash != "00ff" || tx.BlockTime != 1700000000 {
+		t.Errorf("Unexpected transaction: %+v", tx)
+	}
+	if len(tx.Vin) != 1 || tx.Vin[0].TxID != "bb" || tx.Vin[0].Vout != 3 || tx.Vin[0].Sequence != 4294967293 {
+		t.Errorf("Unexpected inputs: %+v", tx.Vin)
+	}
+	if len(tx.Vout) != 1 || tx.Vout[0].Value != 0.00099 || tx.Vout[0].ScriptPubKey.Hex != "0020aa" {
+		t.Errorf("Unexpected outputs: %+v", tx.Vout)
+	}
+}
+
+func TestEsploraClient_BroadcastChecksGenesis(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		genesis string
+		wantErr string
+	}{
+		{"matching network", chaincfg.TestNet3Params.GenesisHash.String(), ""},
+		{"mainnet API", chaincfg.MainNetParams.GenesisHash.String(), "refusing to broadcast"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted := make(chan string, 1)
+			baseURL := startEsploraStub(t, map[string]string{
+				"GET /block-height/0": tt.genesis,
+				"POST /tx":            tx.TxHash().String(),
+			}, posted)
+			client := NewEsploraClient(baseURL, &chaincfg.TestNet3Params)
+
+			txid, err := client.BroadcastTransaction(tx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if len(posted) != 0 {
+					t.Error("Expected nothing to be posted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BroadcastTransaction failed: %v", err)
+			}
+			if txid != tx.TxHash().String() {
+				t.Errorf("Expected txid %s, got %s", tx.TxHash(), txid)
+			}
+			if body := <-posted; body != hex.EncodeToString(buf.Bytes()) {
+				t.Errorf("Expected the raw transaction hex to be posted, got %q", body)
+			}
+		})
+	}
+}
+
+func TestEsploraClient_APIError(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+	baseURL := startEsploraStub(t, map[string]string{
+		"GET /block-height/0": chaincfg.TestNet3Params.GenesisHash.String(),
+	}, nil)
+	client := NewEsploraClient(baseURL, &chaincfg.TestNet3Params)
+
+	if _, err := client.BroadcastTransaction(tx); err == nil || !strings.Contains(err.Error(), "esplora error 404") {
+		t.Errorf("Expected an esplora error, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Spender describes the transaction spending an output
+type Spender struct {
+	TxID      string
+	InMempool bool
+	BlockHash string
+	BlockTime int64
+
+	// Witness is the witness stack of the spending input (hex items)
+	Witness []string
+}
+
+// Block is the getblock RPC result at verbosity 2
+type Block struct {
+	Hash   string           `json:"hash"`
+	Height int64            `json:"height"`
+	Time   int64            `json:"time"`
+	Tx     []RawTransaction `json:"tx"`
+}
+
+// GetBlockHash returns the hash of the block at the given height
+func (r *RPCClient) GetBlockHash(height int64) (string, error) {
+	return r.GetBlockHashCtx(context.Background(), height)
+}
+
+// GetBlockHashCtx is GetBlockHash, giving up when ctx is done
+func (r *RPCClient) GetBlockHashCtx(ctx context.Context, height int64) (string, error) {
+	result, err := r.call(ctx, "getblockhash", []interface{}{height})
+	if err != nil {
+		return "", fmt.Errorf("failed to get block hash: %w", err)
+	}
+
+	var blockHash string
+	if err := json.Unmarshal(result, &blockHash); err != nil {
+		return "", fmt.Errorf("failed to parse block hash: %w", err)
+	}
+
+	return blockHash, nil
+}
+
+// GetBlock returns a block with fully decoded transactions
+func (r *RPCClient) GetBlock(blockHash string) (*Block, error) {
+	return r.GetBlockCtx(context.Background(), blockHash)
+}
+
+// GetBlockCtx is GetBlock, giving up when ctx is done
+func (r *RPCClient) GetBlockCtx(ctx context.Context, blockHash string) (*Block, error) {
+	result, err := r.call(ctx, "getblock", []interface{}{blockHash, 2})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block: %w", err)
+	}
+
+	var block Block
+	if err := json.Unmarshal(result, &block); err != nil {
+		return nil, fmt.Errorf("failed to parse block: %w", err)
+	}
+
+	return &block, nil
+}
+
+// mempoolSpender is an entry of the gettxspendingprevout RPC result
+type mempoolSpender struct {
+	TxID         string `json:"txid"`
+	Vout         uint32 `json:"vout"`
+	SpendingTxID string `json:"spendingtxid,omitempty"`
+}
+
+// FindSpender locates the transaction spending txid:vout. The mempool is
+// checked first, then blocks are scanned from fromHeight for at most
+// maxBlocks blocks. It returns nil if no spender was found.
+func (r *RPCClient) FindSpender(txid string, vout uint32, fromHeight int64, maxBlocks int) (*Spender, error) {
+	return r.FindSpenderCtx(context.Background(), txid, vout, fromHeight, maxBlocks)
+}
+
+// FindSpenderCtx is FindSpender, giving up when ctx is done
+func (r *RPCClient) FindSpenderCtx(ctx context.Context, txid string, vout uint32, fromHeight int64, maxBlocks int) (*Spender, error) {
+	outpoint := map[string]interface{}{"txid": txid, "vout": vout}
+	if result, err := r.call(ctx, "gettxspendingprevout", []interface{}{[]interface{}{outpoint}}); err == nil {
+		var spenders []mempoolSpender
+		if err := json.Unmarshal(result, &spenders); err == nil && len(spenders) > 0 && spenders[0].SpendingTxID != "" {
+			spender := &Spender{TxID: spenders[0].SpendingTxID, InMempool: true}
+			if tx, err := r.GetRawTransactionCtx(ctx, spender.TxID); err == nil {
+				spender.Witness = inputWitness(tx, txid, vout)
+			}
+			return spender, nil
+		}
+	}
+
+	tipHeight, err := r.GetBlockCountCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for height := fromHeight; height <= tipHeight && height < fromHeight+int64(maxBlocks); height++ {
+		blockHash, err := r.GetBlockHashCtx(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		block, err := r.GetBlockCtx(ctx, blockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range block.Tx {
+			if witness := inputWitness(&block.Tx[i], txid, vout); witness != nil {
+				return &Spender{
+					TxID:      block.Tx[i].TxID,
+					BlockHash: block.Hash,
+					BlockTime: block.Time,
+					Witness:   witness,
+				}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// inputWitness returns the witness of the input of tx spending txid:vout, or
+// nil if tx does not spend it
+func inputWitness(tx *RawTransaction, txid string, vout uint32) []string {
+	for _, vin := range tx.Vin {
+		if vin.TxID == txid && vin.Vout == vout {
+			if vin.Witness == nil {
+				return []string{}
+			}
+			return vin.Witness
+		}
+	}
+	return nil
+}
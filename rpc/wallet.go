@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// SendToAddress asks the node's wallet to pay amount satoshis to address and
+// returns the txid. The wallet selects the inputs, adds change, signs and
+// broadcasts, so the node must have a single wallet loaded. Like
+// BroadcastTransaction, it checks the node's chain first and never fails
+// over, so coins are not sent twice.
+func (r *RPCClient) SendToAddress(address string, amount int64) (string, error) {
+	if amount <= 0 || amount > btcutil.MaxSatoshi {
+		return "", fmt.Errorf("invalid amount of %d satoshis", amount)
+	}
+
+	ctx := context.Background()
+	if err := r.checkChain(ctx); err != nil {
+		return "", fmt.Errorf("refusing to send: %w", err)
+	}
+
+	// The amount is passed as an exact 8-decimal BTC number, not a float
+	btc := json.Number(fmt.Sprintf("%d.%08d", amount/btcutil.SatoshiPerBitcoin, amount%btcutil.SatoshiPerBitcoin))
+	result, err := r.callPreferred(ctx, "sendtoaddress", []interface{}{address, btc})
+	if err != nil {
+		return "", fmt.Errorf("failed to send to address: %w", err)
+	}
+
+	var txid string
+	if err := json.Unmarshal(result, &txid); err != nil {
+		return "", fmt.Errorf("failed to parse transaction ID: %w", err)
+	}
+
+	return txid, nil
+}
+
+// FundAddress pays amount satoshis to address from the node's wallet with
+// SendToAddress, then fetches the transaction and returns it with the output
+// paying pkScript, the output script of address. The wallet places its change
+// output at random, so the output is found by its script, not its index.
+func (r *RPCClient) FundAddress(address string, amount int64, pkScript []byte) (*RawTransaction, *TxOutput, error) {
+	txid, err := r.SendToAddress(address, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := r.GetRawTransaction(txid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sent %s, but failed to fetch it: %w", txid, err)
+	}
+
+	for _, output := range tx.FindOutputsByScript(pkScript) {
+		sats, err := btcutil.NewAmount(output.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid output amount: %w", err)
+		}
+		if int64(sats) == amount {
+			return tx, &output, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("transaction %s has no output of %d satoshis paying %s", txid, amount, address)
+}
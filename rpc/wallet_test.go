@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nikolay.stoev/bitcoin-inheritance/config"
+)
+
+// fundingTx is a wallet transaction with change at output 0 and the
+// contract payment of 0.1 BTC at output 1
+const fundingTx = `{"txid":"f00d","vout":[
+	{"value":0.4,"n":0,"scriptPubKey":{"hex":"0014aaaa","type":"witness_v0_keyhash"}},
+	{"value":0.1,"n":1,"scriptPubKey":{"hex":"0020bbbb","type":"witness_v0_scripthash"}}]}`
+
+func TestRPCClient_FundAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		pkScript  []byte
+		chainInfo string
+		wantVout  uint32
+		wantSent  bool
+		wantErr   string
+	}{
+		{"contract output found", 10000000, []byte{0x00, 0x20, 0xbb, 0xbb}, `{"chain":"test"}`, 1, true, ""},
+		{"no output pays the contract", 10000000, []byte{0x00, 0x20, 0xcc, 0xcc}, `{"chain":"test"}`, 0, true, "has no output"},
+		{"amount differs", 20000000, []byte{0x00, 0x20, 0xbb, 0xbb}, `{"chain":"test"}`, 0, true, "has no output"},
+		{"wrong chain", 10000000, []byte{0x00, 0x20, 0xbb, 0xbb}, `{"chain":"main"}`, 0, false, "refusing to send"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sendParams []json.RawMessage
+			stub := newStubHandler(t, map[string]string{
+				"getblockchaininfo": tt.chainInfo,
+				"sendtoaddress":     `"f00d"`,
+				"getrawtransaction": fundingTx,
+			})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				var request struct {
+					Method string            `json:"method"`
+					Params []json.RawMessage `json:"params"`
+				}
+				if err := json.Unmarshal(body, &request); err == nil && request.Method == "sendtoaddress" {
+					sendParams = request.Params
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				stub.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client := NewRPCClient(&config.RPCConfig{Host: strings.TrimPrefix(server.URL, "http://"), DisableTLS: true, Network: "testnet3"})
+			tx, output, err := client.FundAddress("tb1qcontract", tt.amount, tt.pkScript)
+
+			if tt.wantSent != (sendParams != nil) {
+				t.Errorf("Expected sendtoaddress called: %t", tt.wantSent)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FundAddress failed: %v", err)
+			}
+
+			if tx.TxID != "f00d" {
+				t.Errorf("Expected txid f00d, got %s", tx.TxID)
+			}
+			if output.N != tt.wantVout {
+				t.Errorf("Expected vout %d, got %d", tt.wantVout, output.N)
+			}
+			if len(sendParams) != 2 || string(sendParams[0]) != `"tb1qcontract"` || string(sendParams[1]) != "0.10000000" {
+				t.Errorf("Unexpected sendtoaddress params: %s", sendParams)
+			}
+		})
+	}
+}
+
+func TestRPCClient_SendToAddress_InvalidAmount(t *testing.T) {
+	client := NewRPCClient(&config.RPCConfig{Host: "127.0.0.1:1", DisableTLS: true})
+	for _, amount := range []int64{0, -1, 21e14 + 1} {
+		if _, err := client.SendToAddress("tb1qcontract", amount); err == nil || !strings.Contains(err.Error(), "invalid amount") {
+			t.Errorf("Amount %d: expected an invalid amount error, got %v", amount, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package script
+
+import "fmt"
+
+// AddressType identifies the output type committing to a contract's redeem script
+type AddressType string
+
+const (
+	// AddressTypeP2WSH is a native segwit v0 script hash output
+	AddressTypeP2WSH AddressType = "p2wsh"
+
+	// AddressTypeP2SHP2WSH is a P2WSH output nested in P2SH for legacy wallets
+	AddressTypeP2SHP2WSH AddressType = "p2sh-p2wsh"
+
+	// AddressTypeP2TR is a taproot output
+	AddressTypeP2TR AddressType = "p2tr"
+)
+
+// ParseAddressType validates an address type name. An empty name is
+// p2wsh, the type of every contract created before the field existed.
+func ParseAddressType(name string) (AddressType, error) {
+	switch addressType := AddressType(name); addressType {
+	case "":
+		return AddressTypeP2WSH, nil
+	case AddressTypeP2WSH, AddressTypeP2SHP2WSH, AddressTypeP2TR:
+		return addressType, nil
+	default:
+		return "", fmt.Errorf("unknown address type %q (expected p2wsh, p2sh-p2wsh or p2tr)", name)
+	}
+}
@@ -0,0 +1,38 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CheckKeyBranches confirms that ownerPubKey is the key in the IF branch of
+// the redeem script (immediate spend) and inheritorPubKey the key in the
+// ELSE branch (timelocked spend). Finding a key in the wrong branch means
+// the roles are inverted. A nil key is not checked.
+func CheckKeyBranches(redeemScript, ownerPubKey, inheritorPubKey []byte) error {
+	parsed, err := ParseRedeemScript(redeemScript, nil)
+	if err != nil {
+		return err
+	}
+
+	ownerOK := ownerPubKey == nil || bytes.Equal(parsed.OwnerPubKey, ownerPubKey)
+	inheritorOK := inheritorPubKey == nil || bytes.Equal(parsed.InheritorPubKey, inheritorPubKey)
+	if ownerOK && inheritorOK {
+		return nil
+	}
+
+	ownerSwapped := ownerPubKey != nil && bytes.Equal(parsed.InheritorPubKey, ownerPubKey)
+	inheritorSwapped := inheritorPubKey != nil && bytes.Equal(parsed.OwnerPubKey, inheritorPubKey)
+	switch {
+	case ownerSwapped && inheritorSwapped:
+		return fmt.Errorf("owner and inheritor keys are swapped: the inheritor can spend immediately and the owner only after the timelock")
+	case ownerSwapped:
+		return fmt.Errorf("owner key is in the ELSE (timelocked) branch instead of the IF branch")
+	case inheritorSwapped:
+		return fmt.Errorf("inheritor key is in the IF (immediate) branch instead of the ELSE branch")
+	case !ownerOK:
+		return fmt.Errorf("owner key %x does not appear in the IF branch (found %x)", ownerPubKey, parsed.OwnerPubKey)
+	default:
+		return fmt.Errorf("inheritor key %x does not appear in the ELSE branch (found %x)", inheritorPubKey, parsed.InheritorPubKey)
+	}
+}
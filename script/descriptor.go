@@ -0,0 +1,122 @@
+package script
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// descriptorInputCharset is the character set accepted in output descriptors (BIP 380)
+const descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+	"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+	"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+// descriptorChecksumCharset is the bech32 character set used for the checksum
+const descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// descriptorGenerator holds the BCH code generator constants from BIP 380
+var descriptorGenerator = [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+// Descriptor returns the output descriptor of the contract's P2WSH output.
+// The witness script is embedded verbatim as wsh(raw(<hex>))#<checksum>, since
+// the inheritance script has no equivalent miniscript expression.
+func (is *InheritanceScript) Descriptor() (string, error) {
+	desc := fmt.Sprintf("wsh(raw(%x))", is.RedeemScript)
+
+	checksum, err := DescriptorChecksum(desc)
+	if err != nil {
+		return "", err
+	}
+
+	return desc + "#" + checksum, nil
+}
+
+// ParseDescriptor parses a wsh(raw(...)) descriptor, validates its checksum
+// and returns the witness script it commits to
+func ParseDescriptor(descriptor string) ([]byte, error) {
+	descriptor = strings.TrimSpace(descriptor)
+
+	body, checksum, found := strings.Cut(descriptor, "#")
+	if !found {
+		return nil, fmt.Errorf("descriptor is missing its checksum")
+	}
+
+	expected, err := DescriptorChecksum(body)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != expected {
+		return nil, fmt.Errorf("descriptor checksum mismatch: got %s, expected %s", checksum, expected)
+	}
+
+	if !strings.HasPrefix(body, "wsh(raw(") || !strings.HasSuffix(body, "))") {
+		return nil, fmt.Errorf("unsupported descriptor: expected wsh(raw(<witness script hex>))")
+	}
+
+	scriptHex := strings.TrimSuffix(strings.TrimPrefix(body, "wsh(raw("), "))")
+	witnessScript, err := hex.DecodeString(scriptHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode witness script: %w", err)
+	}
+
+	if len(witnessScript) == 0 {
+		return nil, fmt.Errorf("witness script is empty")
+	}
+
+	return witnessScript, nil
+}
+
+// DescriptorChecksum computes the 8-character BIP 380 checksum of a descriptor
+// (without the trailing #checksum part)
+func DescriptorChecksum(desc string) (string, error) {
+	var symbols []uint64
+	var groups []uint64
+
+	for _, c := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, c)
+		if pos < 0 {
+			return "", fmt.Errorf("invalid character %q in descriptor", c)
+		}
+
+		// Emit the low 5 bits immediately and pack the high bits in groups of three
+		symbols = append(symbols, uint64(pos&31))
+		groups = append(groups, uint64(pos>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+
+	// Pad with zeroes so the checksum occupies the last 8 symbols
+	symbols = append(symbols, 0, 0, 0, 0, 0, 0, 0, 0)
+	checksum := descriptorPolymod(symbols) ^ 1
+
+	result := make([]byte, 8)
+	for i := range result {
+		result[i] = descriptorChecksumCharset[(checksum>>(5*(7-i)))&31]
+	}
+
+	return string(result), nil
+}
+
+// descriptorPolymod evaluates the BIP 380 checksum polynomial over the symbols
+func descriptorPolymod(symbols []uint64) uint64 {
+	chk := uint64(1)
+	for _, value := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= descriptorGenerator[i]
+			}
+		}
+	}
+	return chk
+}
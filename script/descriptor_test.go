@@ -0,0 +1,79 @@
+package script
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestDescriptorChecksum_KnownVectors(t *testing.T) {
+	testCases := []struct {
+		descriptor string
+		checksum   string
+	}{
+		{"raw(deadbeef)", "89f8spxm"},
+		{"wsh(raw(51))", "u6kam3xp"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.descriptor, func(t *testing.T) {
+			checksum, err := DescriptorChecksum(tc.descriptor)
+			if err != nil {
+				t.Fatalf("DescriptorChecksum failed: %v", err)
+			}
+			if checksum != tc.checksum {
+				t.Errorf("Expected checksum %s, got %s", tc.checksum, checksum)
+			}
+		})
+	}
+}
+
+func TestDescriptor_RoundTrip(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	chainParams := &chaincfg.TestNet3Params
+
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 365, chainParams)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	descriptor, err := script.Descriptor()
+	if err != nil {
+		t.Fatalf("Descriptor failed: %v", err)
+	}
+
+	if !strings.HasPrefix(descriptor, "wsh(raw(") {
+		t.Errorf("Descriptor should start with wsh(raw(, got: %s", descriptor)
+	}
+
+	witnessScript, err := ParseDescriptor(descriptor)
+	if err != nil {
+		t.Fatalf("ParseDescriptor failed: %v", err)
+	}
+
+	if !bytes.Equal(witnessScript, script.RedeemScript) {
+		t.Error("Parsed witness script does not match the redeem script")
+	}
+}
+
+func TestParseDescriptor_Invalid(t *testing.T) {
+	testCases := []struct {
+		name       string
+		descriptor string
+	}{
+		{"Missing checksum", "wsh(raw(51))"},
+		{"Wrong checksum", "wsh(raw(51))#u6kam3xq"},
+		{"Unsupported type", "raw(deadbeef)#89f8spxm"},
+		{"Invalid character", "wsh(raw(51))\x00#u6kam3xp"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseDescriptor(tc.descriptor); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
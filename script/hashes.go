@@ -0,0 +1,53 @@
+package script
+
+import (
+	"crypto/sha256"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// ScriptHashes holds the hash forms different tools use to key a redeem script
+type ScriptHashes struct {
+	// WitnessScriptHash is SHA256(redeem script), the P2WSH witness program
+	WitnessScriptHash []byte
+
+	// RedeemScriptHash160 is HASH160(redeem script), as a legacy P2SH output
+	// wrapping the script directly would commit to
+	RedeemScriptHash160 []byte
+
+	// NestedScriptHash160 is HASH160(OP_0 <SHA256(redeem script)>), the hash
+	// a P2SH-P2WSH output commits to
+	NestedScriptHash160 []byte
+
+	// ElectrumScriptHash is the Electrum server scripthash of the P2WSH output
+	ElectrumScriptHash []byte
+}
+
+// ComputeScriptHashes derives every hash form of a redeem script
+func ComputeScriptHashes(redeemScript []byte) (*ScriptHashes, error) {
+	witnessHash := sha256.Sum256(redeemScript)
+
+	witnessProgram, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(witnessHash[:]).Script()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScriptHashes{
+		WitnessScriptHash:   witnessHash[:],
+		RedeemScriptHash160: btcutil.Hash160(redeemScript),
+		NestedScriptHash160: btcutil.Hash160(witnessProgram),
+		ElectrumScriptHash:  ElectrumScriptHash(witnessProgram),
+	}, nil
+}
+
+// ElectrumScriptHash returns the key Electrum servers index an output script
+// by: SHA256 of the scriptPubKey with its bytes reversed
+func ElectrumScriptHash(pkScript []byte) []byte {
+	hash := sha256.Sum256(pkScript)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hash[:]
+}
@@ -0,0 +1,48 @@
+package script
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestElectrumScriptHash(t *testing.T) {
+	// Example from the Electrum protocol documentation (the genesis P2PKH address)
+	pkScript, _ := hex.DecodeString("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+	expected := "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161"
+
+	if got := hex.EncodeToString(ElectrumScriptHash(pkScript)); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestComputeScriptHashes(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	inheritanceScript, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	hashes, err := ComputeScriptHashes(inheritanceScript.RedeemScript)
+	if err != nil {
+		t.Fatalf("ComputeScriptHashes failed: %v", err)
+	}
+
+	if !bytes.Equal(hashes.WitnessScriptHash, inheritanceScript.GetScriptHash()) {
+		t.Errorf("Witness script hash %x does not match GetScriptHash %x", hashes.WitnessScriptHash, inheritanceScript.GetScriptHash())
+	}
+	if len(hashes.RedeemScriptHash160) != 20 || len(hashes.NestedScriptHash160) != 20 {
+		t.Errorf("Expected 20-byte HASH160 values, got %d and %d", len(hashes.RedeemScriptHash160), len(hashes.NestedScriptHash160))
+	}
+
+	// The Electrum hash keys the P2WSH output script
+	pkScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	if !bytes.Equal(hashes.ElectrumScriptHash, ElectrumScriptHash(pkScript)) {
+		t.Errorf("Electrum scripthash %x is not the hash of the P2WSH output", hashes.ElectrumScriptHash)
+	}
+}
@@ -0,0 +1,68 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// MaxMultisigKeys is the largest number of owner keys in an M-of-N owner
+// path, the most OP_1 to OP_16 can push as N
+const MaxMultisigKeys = 16
+
+// NewMultisigInheritanceScript creates an inheritance script whose owner
+// path needs required signatures from ownerPubKeys instead of one key:
+//
+//	OP_IF
+//	    <M> <Owner_PublicKey_1> ... <Owner_PublicKey_N> <N> OP_CHECKMULTISIG
+//	OP_ELSE
+//	    <Timelock_Value> OP_CHECKSEQUENCEVERIFY OP_DROP
+//	    <Inheritor_PublicKey> OP_CHECKSIG
+//	OP_ENDIF
+//
+// The keys are kept in the order given, which signatures must follow. It
+// takes the same options as NewInheritanceScript.
+func NewMultisigInheritanceScript(ownerPubKeys [][]byte, required int, inheritorPubKey []byte, timelockDays int64, chainParams *chaincfg.Params, opts ...Option) (*InheritanceScript, error) {
+	if len(ownerPubKeys) == 0 || len(ownerPubKeys) > MaxMultisigKeys {
+		return nil, fmt.Errorf("an M-of-N owner needs 1 to %d keys, got %d", MaxMultisigKeys, len(ownerPubKeys))
+	}
+	if required < 1 || required > len(ownerPubKeys) {
+		return nil, fmt.Errorf("required signatures must be between 1 and %d, got %d", len(ownerPubKeys), required)
+	}
+
+	options := scriptOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	for i, pubKey := range ownerPubKeys {
+		if err := validatePubKey(pubKey); err != nil {
+			return nil, fmt.Errorf("invalid owner public key %d: %w", i+1, err)
+		}
+		for _, other := range ownerPubKeys[:i] {
+			if bytes.Equal(pubKey, other) {
+				return nil, fmt.Errorf("owner public key %d is listed twice", i+1)
+			}
+		}
+		if !options.allowSameKey && bytes.Equal(pubKey, inheritorPubKey) {
+			return nil, fmt.Errorf("owner public key %d is the inheritor's key, which lets the inheritor sign the owner path", i+1)
+		}
+	}
+
+	return NewInheritanceScript(nil, inheritorPubKey, timelockDays, chainParams,
+		append(opts, withOwnerMultisig(ownerPubKeys, required))...)
+}
+
+// withOwnerMultisig replaces the single owner key with an M-of-N owner
+func withOwnerMultisig(ownerPubKeys [][]byte, required int) Option {
+	return func(o *scriptOptions) {
+		o.ownerPubKeys = ownerPubKeys
+		o.ownerRequired = required
+	}
+}
+
+// IsMultisigOwner reports whether the owner path is M-of-N rather than a
+// single key
+func (is *InheritanceScript) IsMultisigOwner() bool {
+	return is.OwnerRequired > 0
+}
@@ -0,0 +1,106 @@
+package script
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+func createTestMultisigKeys(t *testing.T, n int) ([][]byte, []byte) {
+	t.Helper()
+
+	var ownerPubKeys [][]byte
+	for i := 0; i < n; i++ {
+		key, err := btcec.NewPrivateKey()
+		if err != nil {
+			t.Fatalf("Failed to generate owner key: %v", err)
+		}
+		ownerPubKeys = append(ownerPubKeys, key.PubKey().SerializeCompressed())
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	return ownerPubKeys, inheritorKey.PubKey().SerializeCompressed()
+}
+
+func TestNewMultisigInheritanceScript(t *testing.T) {
+	ownerPubKeys, inheritorPubKey := createTestMultisigKeys(t, 3)
+
+	is, err := NewMultisigInheritanceScript(ownerPubKeys, 2, inheritorPubKey, 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewMultisigInheritanceScript failed: %v", err)
+	}
+	if err := is.ValidateScript(); err != nil {
+		t.Errorf("ValidateScript failed: %v", err)
+	}
+
+	disasm, err := is.Disassemble()
+	if err != nil {
+		t.Fatalf("Disassemble failed: %v", err)
+	}
+	wantPrefix := "OP_IF 2 " // OP_2 disassembles as 2
+	if !strings.HasPrefix(disasm, wantPrefix) || !strings.Contains(disasm, " 3 OP_CHECKMULTISIG OP_ELSE ") {
+		t.Errorf("Unexpected owner branch: %s", disasm)
+	}
+
+	// The script round-trips through ParseRedeemScript
+	parsed, err := ParseRedeemScript(is.RedeemScript, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("ParseRedeemScript failed: %v", err)
+	}
+	if !parsed.IsMultisigOwner() || parsed.OwnerRequired != 2 || len(parsed.OwnerPubKeys) != 3 {
+		t.Fatalf("Expected a 2-of-3 owner, got %d-of-%d", parsed.OwnerRequired, len(parsed.OwnerPubKeys))
+	}
+	for i := range ownerPubKeys {
+		if !bytes.Equal(parsed.OwnerPubKeys[i], ownerPubKeys[i]) {
+			t.Errorf("Owner key %d: expected %x, got %x", i, ownerPubKeys[i], parsed.OwnerPubKeys[i])
+		}
+	}
+	if !bytes.Equal(parsed.InheritorPubKey, inheritorPubKey) || parsed.RelativeTimelock != is.RelativeTimelock {
+		t.Errorf("ELSE branch did not round-trip")
+	}
+
+	// The absolute timelock option applies as for a single owner
+	absolute, err := NewMultisigInheritanceScript(ownerPubKeys, 2, inheritorPubKey, 0, &chaincfg.TestNet3Params,
+		WithAbsoluteTimelock(4102444800))
+	if err != nil {
+		t.Fatalf("NewMultisigInheritanceScript with an absolute timelock failed: %v", err)
+	}
+	if absolute.TimelockType != Absolute || !bytes.Contains(absolute.RedeemScript, []byte{txscript.OP_CHECKLOCKTIMEVERIFY}) {
+		t.Errorf("Expected an absolute timelock")
+	}
+}
+
+func TestNewMultisigInheritanceScript_Errors(t *testing.T) {
+	ownerPubKeys, inheritorPubKey := createTestMultisigKeys(t, 3)
+
+	tests := []struct {
+		name      string
+		owners    [][]byte
+		required  int
+		inheritor []byte
+		wantErr   string
+	}{
+		{"no keys", nil, 1, inheritorPubKey, "1 to 16 keys"},
+		{"too many keys", make([][]byte, 17), 1, inheritorPubKey, "1 to 16 keys"},
+		{"zero required", ownerPubKeys, 0, inheritorPubKey, "between 1 and 3"},
+		{"more required than keys", ownerPubKeys, 4, inheritorPubKey, "between 1 and 3"},
+		{"duplicate key", [][]byte{ownerPubKeys[0], ownerPubKeys[0]}, 2, inheritorPubKey, "listed twice"},
+		{"inheritor among owners", [][]byte{ownerPubKeys[0], inheritorPubKey}, 2, inheritorPubKey, "inheritor's key"},
+		{"invalid key", [][]byte{ownerPubKeys[0], make([]byte, 33)}, 2, inheritorPubKey, "invalid owner public key 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMultisigInheritanceScript(tt.owners, tt.required, tt.inheritor, 180, &chaincfg.TestNet3Params)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
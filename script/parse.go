@@ -0,0 +1,209 @@
+package script
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// scriptToken is a single opcode of a parsed script together with its push data
+type scriptToken struct {
+	opcode byte
+	data   []byte
+}
+
+// ParseRedeemScript parses a redeem script built by NewInheritanceScript,
+// NewMultisigInheritanceScript or NewTieredInheritanceScript and recovers the
+// owner key (or M-of-N keys) from the IF branch, the inheritor key (or tiers)
+// from the ELSE branch and the timelock: a relative one enforced by
+// OP_CHECKSEQUENCEVERIFY or an absolute one enforced by
+// OP_CHECKLOCKTIMEVERIFY
+func ParseRedeemScript(redeemScript []byte, chainParams *chaincfg.Params) (*InheritanceScript, error) {
+	tokens, err := tokenizeScript(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) < 2 || tokens[0].opcode != txscript.OP_IF {
+		return nil, fmt.Errorf("script does not start with OP_IF")
+	}
+
+	parsed := &InheritanceScript{
+		RedeemScript: redeemScript,
+		ChainParams:  chainParams,
+	}
+
+	// OP_IF <owner> OP_CHECKSIG, or OP_IF <M> <owners...> <N> OP_CHECKMULTISIG
+	var expected []byte
+	if tokens[1].opcode == txscript.OP_DATA_33 {
+		expected = []byte{txscript.OP_IF, txscript.OP_DATA_33, txscript.OP_CHECKSIG}
+		parsed.OwnerPubKey = tokens[1].data
+	} else {
+		ownerPubKeys, required, err := parseMultisigBranch(tokens[1:])
+		if err != nil {
+			return nil, err
+		}
+		expected = []byte{txscript.OP_IF, tokens[1].opcode}
+		for range ownerPubKeys {
+			expected = append(expected, txscript.OP_DATA_33)
+		}
+		expected = append(expected, tokens[len(ownerPubKeys)+2].opcode, txscript.OP_CHECKMULTISIG)
+		parsed.OwnerPubKeys = ownerPubKeys
+		parsed.OwnerRequired = required
+	}
+
+	// OP_ELSE, then for each tier but the last of a tiered script
+	// OP_IF <timelock> OP_CSV OP_DROP <inheritor> OP_CHECKSIG OP_ELSE
+	expected = append(expected, txscript.OP_ELSE)
+	var tierStarts []int
+	for len(tokens) > len(expected) && tokens[len(expected)].opcode == txscript.OP_IF {
+		tierStarts = append(tierStarts, len(expected)+1)
+		expected = append(expected,
+			txscript.OP_IF, 0, txscript.OP_CHECKSEQUENCEVERIFY, txscript.OP_DROP,
+			txscript.OP_DATA_33, txscript.OP_CHECKSIG, txscript.OP_ELSE,
+		)
+	}
+
+	// <timelock> OP_CSV|OP_CLTV OP_DROP <inheritor> OP_CHECKSIG OP_ENDIF...
+	lastTier := len(expected)
+	tierStarts = append(tierStarts, lastTier)
+	expected = append(expected, 0, txscript.OP_CHECKSEQUENCEVERIFY, txscript.OP_DROP,
+		txscript.OP_DATA_33, txscript.OP_CHECKSIG)
+	for range tierStarts {
+		expected = append(expected, txscript.OP_ENDIF)
+	}
+	if len(tokens) != len(expected) {
+		return nil, fmt.Errorf("unexpected script length: %d opcodes, expected %d", len(tokens), len(expected))
+	}
+
+	// Only a single inheritor may have an absolute timelock
+	absolute := len(tierStarts) == 1 && tokens[lastTier+1].opcode == txscript.OP_CHECKLOCKTIMEVERIFY
+	for i, opcode := range expected {
+		if opcode == 0 {
+			continue // timelock value, checked below
+		}
+		if i == lastTier+1 && absolute {
+			continue // absolute timelock
+		}
+		if tokens[i].opcode != opcode {
+			return nil, fmt.Errorf("unexpected opcode %s at position %d, expected %s",
+				opcodeName(tokens[i].opcode), i, opcodeName(opcode))
+		}
+	}
+
+	var tiers []InheritorTier
+	for i, start := range tierStarts {
+		timelock, err := tokenInt64(tokens[start])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timelock value of tier %d: %w", i+1, err)
+		}
+		tiers = append(tiers, InheritorTier{
+			PubKey:           tokens[start+3].data,
+			TimelockDays:     relativeTimelockDays(timelock),
+			RelativeTimelock: timelock,
+		})
+	}
+
+	parsed.InheritorPubKey = tiers[0].PubKey
+	if absolute {
+		parsed.TimelockType = Absolute
+		parsed.LockTime = tiers[0].RelativeTimelock
+	} else {
+		parsed.RelativeTimelock = tiers[0].RelativeTimelock
+	}
+	if len(tiers) > 1 {
+		parsed.Tiers = tiers
+	}
+	return parsed, nil
+}
+
+// parseMultisigBranch reads <M> <pubkeys...> <N> from the start of tokens and
+// returns the keys and M; the caller checks the opcodes that follow
+func parseMultisigBranch(tokens []scriptToken) ([][]byte, int, error) {
+	required, err := tokenInt64(tokens[0])
+	if err != nil {
+		return nil, 0, fmt.Errorf("owner branch is neither a key nor a multisig: %w", err)
+	}
+
+	var ownerPubKeys [][]byte
+	for _, tok := range tokens[1:] {
+		if tok.opcode != txscript.OP_DATA_33 {
+			break
+		}
+		ownerPubKeys = append(ownerPubKeys, tok.data)
+	}
+	if len(ownerPubKeys)+1 >= len(tokens) {
+		return nil, 0, fmt.Errorf("owner multisig is truncated")
+	}
+
+	total, err := tokenInt64(tokens[len(ownerPubKeys)+1])
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid owner key count: %w", err)
+	}
+	if total != int64(len(ownerPubKeys)) || required < 1 || required > total {
+		return nil, 0, fmt.Errorf("owner multisig is %d-of-%d with %d keys", required, total, len(ownerPubKeys))
+	}
+	return ownerPubKeys, int(required), nil
+}
+
+// ParseRelativeTimelock returns the OP_CHECKSEQUENCEVERIFY value of an
+// inheritance redeem script. Scripts with an absolute timelock are rejected.
+func ParseRelativeTimelock(redeemScript []byte) (int64, error) {
+	parsed, err := ParseRedeemScript(redeemScript, nil)
+	if err != nil {
+		return 0, err
+	}
+	if parsed.TimelockType == Absolute {
+		return 0, fmt.Errorf("redeem script has an absolute timelock (%s), not a relative one", FormatLockTime(parsed.LockTime))
+	}
+	return parsed.RelativeTimelock, nil
+}
+
+// tokenizeScript splits a script into its opcodes and push data
+func tokenizeScript(s []byte) ([]scriptToken, error) {
+	var tokens []scriptToken
+
+	tokenizer := txscript.MakeScriptTokenizer(0, s)
+	for tokenizer.Next() {
+		tokens = append(tokens, scriptToken{
+			opcode: tokenizer.Opcode(),
+			data:   tokenizer.Data(),
+		})
+	}
+	if err := tokenizer.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// tokenInt64 decodes a script number pushed either as a small-integer opcode or as data
+func tokenInt64(tok scriptToken) (int64, error) {
+	switch {
+	case tok.opcode == txscript.OP_0:
+		return 0, nil
+	case tok.opcode >= txscript.OP_1 && tok.opcode <= txscript.OP_16:
+		return int64(tok.opcode - (txscript.OP_1 - 1)), nil
+	case tok.opcode >= txscript.OP_DATA_1 && tok.opcode <= txscript.OP_DATA_5:
+		num, err := txscript.MakeScriptNum(tok.data, true, 5)
+		if err != nil {
+			return 0, err
+		}
+		return int64(num), nil
+	default:
+		return 0, fmt.Errorf("opcode %s is not a number push", opcodeName(tok.opcode))
+	}
+}
+
+// opcodeName returns the human-readable name of an opcode
+func opcodeName(opcode byte) string {
+	if opcode >= txscript.OP_DATA_1 && opcode <= txscript.OP_DATA_75 {
+		return fmt.Sprintf("OP_DATA_%d", opcode)
+	}
+
+	disasm, err := txscript.DisasmString([]byte{opcode})
+	if err != nil || disasm == "" {
+		return fmt.Sprintf("0x%02x", opcode)
+	}
+	return disasm
+}
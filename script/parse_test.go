@@ -0,0 +1,95 @@
+package script
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestParseRedeemScript_RoundTrip(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	chainParams := &chaincfg.TestNet3Params
+
+	for _, days := range []int64{1, 30, 180, 365, 388} {
+		original, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, days, chainParams, AllowShortTimelock())
+		if err != nil {
+			t.Fatalf("NewInheritanceScript failed: %v", err)
+		}
+
+		parsed, err := ParseRedeemScript(original.RedeemScript, chainParams)
+		if err != nil {
+			t.Fatalf("ParseRedeemScript failed for %d days: %v", days, err)
+		}
+
+		if !bytes.Equal(parsed.OwnerPubKey, ownerPubKey) {
+			t.Errorf("Owner public key not recovered from IF branch (%d days)", days)
+		}
+		if !bytes.Equal(parsed.InheritorPubKey, inheritorPubKey) {
+			t.Errorf("Inheritor public key not recovered from ELSE branch (%d days)", days)
+		}
+		if parsed.RelativeTimelock != original.RelativeTimelock {
+			t.Errorf("Expected relative timelock %d, got %d", original.RelativeTimelock, parsed.RelativeTimelock)
+		}
+	}
+}
+
+func TestParseRedeemScript_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script []byte
+	}{
+		{"Empty script", []byte{}},
+		{"OP_TRUE", []byte{0x51}},
+		{"Truncated push", []byte{0x63, 0x21, 0x02}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseRedeemScript(tc.script, &chaincfg.TestNet3Params); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestCheckKeyBranches(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	otherPubKey := append([]byte{0x02}, bytes.Repeat([]byte{0x11}, 32)...)
+
+	inheritanceScript, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		owner     []byte
+		inheritor []byte
+		wantErr   string
+	}{
+		{"correct branches", ownerPubKey, inheritorPubKey, ""},
+		{"owner only", ownerPubKey, nil, ""},
+		{"swapped keys", inheritorPubKey, ownerPubKey, "swapped"},
+		{"owner in ELSE branch", inheritorPubKey, nil, "owner key is in the ELSE"},
+		{"inheritor in IF branch", nil, ownerPubKey, "inheritor key is in the IF"},
+		{"unknown owner key", otherPubKey, inheritorPubKey, "does not appear in the IF branch"},
+		{"unknown inheritor key", ownerPubKey, otherPubKey, "does not appear in the ELSE branch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckKeyBranches(inheritanceScript.RedeemScript, tt.owner, tt.inheritor)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
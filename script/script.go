@@ -1,10 +1,13 @@
 package script
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
@@ -13,35 +16,273 @@ import (
 // InheritanceScript represents the Bitcoin script for inheritance contract
 type InheritanceScript struct {
 	OwnerPubKey      []byte
+	OwnerPubKeys     [][]byte // set instead of OwnerPubKey for an M-of-N owner
+	OwnerRequired    int      // signatures the owner path needs; zero for a single key
 	InheritorPubKey  []byte
+	TimelockType     TimelockType
 	RelativeTimelock int64
 	RedeemScript     []byte
 	ChainParams      *chaincfg.Params
+
+	// LockTime is the OP_CHECKLOCKTIMEVERIFY value of an Absolute script: a
+	// Unix time when at least LockTimeThreshold, otherwise a block height
+	LockTime int64
+
+	// Tiers lists the inheritors of a script built by
+	// NewTieredInheritanceScript; InheritorPubKey and RelativeTimelock then
+	// hold the first tier. It is nil for a single inheritor.
+	Tiers []InheritorTier
+}
+
+// TimelockType selects how the inheritor path is locked
+type TimelockType int
+
+const (
+	// Relative locks count from the funding confirmation (BIP 68, OP_CHECKSEQUENCEVERIFY)
+	Relative TimelockType = iota
+
+	// Absolute locks expire at a fixed time or block height (BIP 65, OP_CHECKLOCKTIMEVERIFY)
+	Absolute
+)
+
+// String returns the lowercase name of the timelock type
+func (t TimelockType) String() string {
+	switch t {
+	case Relative:
+		return "relative"
+	case Absolute:
+		return "absolute"
+	default:
+		return fmt.Sprintf("TimelockType(%d)", int(t))
+	}
+}
+
+// LockTimeThreshold is the BIP 65 boundary: lock times below it are block
+// heights, lock times at or above it are Unix timestamps
+const LockTimeThreshold = txscript.LockTimeThreshold
+
+// maxLockTime is the largest value nLockTime can hold
+const maxLockTime = 0xffffffff
+
+// TimelockGranularity is the resolution in seconds of time-based BIP 68 timelocks
+const TimelockGranularity = 512
+
+// secondsPerDay converts day-based settings to seconds
+const secondsPerDay = 24 * 60 * 60
+
+// DefaultMinTimelockDays is the shortest timelock accepted unless short timelocks are explicitly allowed
+const DefaultMinTimelockDays = 30
+
+// Option configures optional behaviour of NewInheritanceScript
+type Option func(*scriptOptions)
+
+// scriptOptions holds the settings applied by Option values
+type scriptOptions struct {
+	minTimelockDays    int64
+	allowShortTimelock bool
+	allowSameKey       bool
+	timelockSeconds    int64
+	timelockBlocks     int64
+	timelockType       TimelockType
+	lockTime           int64
+	ownerPubKeys       [][]byte
+	ownerRequired      int
+}
+
+// WithMinTimelockDays overrides the minimum timelock duration (DefaultMinTimelockDays)
+func WithMinTimelockDays(days int64) Option {
+	return func(o *scriptOptions) {
+		o.minTimelockDays = days
+	}
+}
+
+// AllowShortTimelock disables the minimum timelock check. A short timelock
+// gives the inheritor near-immediate access to the funds.
+func AllowShortTimelock() Option {
+	return func(o *scriptOptions) {
+		o.allowShortTimelock = true
+	}
+}
+
+// AllowSameKey permits the same public key for owner and inheritor. Both
+// paths then belong to one person, which is almost always a mistake.
+func AllowSameKey() Option {
+	return func(o *scriptOptions) {
+		o.allowSameKey = true
+	}
+}
+
+// WithTimelockSeconds sets the timelock in seconds instead of days. The
+// timelockDays argument of NewInheritanceScript is then ignored.
+func WithTimelockSeconds(seconds int64) Option {
+	return func(o *scriptOptions) {
+		o.timelockSeconds = seconds
+	}
+}
+
+// WithTimelockBlocks counts the timelock in blocks confirmed after the
+// funding transaction instead of 512-second intervals, which is not affected
+// by miner timestamp drift. The timelockDays argument of NewInheritanceScript
+// is then ignored.
+func WithTimelockBlocks(blocks int64) Option {
+	return func(o *scriptOptions) {
+		o.timelockBlocks = blocks
+	}
+}
+
+// WithAbsoluteTimelock locks the inheritor path until lockTime with
+// OP_CHECKLOCKTIMEVERIFY instead of counting from the funding confirmation.
+// Values at or above LockTimeThreshold are Unix timestamps, lower values
+// block heights. The timelockDays argument of NewInheritanceScript is then
+// ignored.
+func WithAbsoluteTimelock(lockTime int64) Option {
+	return func(o *scriptOptions) {
+		o.timelockType = Absolute
+		o.lockTime = lockTime
+	}
 }
 
 // NewInheritanceScript creates a new inheritance script
-func NewInheritanceScript(ownerPubKey, inheritorPubKey []byte, timelockDays int64, chainParams *chaincfg.Params) (*InheritanceScript, error) {
-	// Calculate relative timelock value according to BIP 68
-	relativeTimelock := calculateRelativeTimelock(timelockDays)
+func NewInheritanceScript(ownerPubKey, inheritorPubKey []byte, timelockDays int64, chainParams *chaincfg.Params, opts ...Option) (*InheritanceScript, error) {
+	options := scriptOptions{minTimelockDays: DefaultMinTimelockDays}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if !options.allowSameKey && len(ownerPubKey) > 0 && bytes.Equal(ownerPubKey, inheritorPubKey) {
+		return nil, fmt.Errorf("owner and inheritor public keys are identical, which makes the two spend paths redundant")
+	}
+	if options.timelockType == Absolute {
+		return newAbsoluteInheritanceScript(ownerPubKey, inheritorPubKey, chainParams, options)
+	}
+
+	relativeTimelock, duration, err := resolveRelativeTimelock(timelockDays, options)
+	if err != nil {
+		return nil, err
+	}
 
 	// Build the redeem script
-	redeemScript, err := buildRedeemScript(ownerPubKey, inheritorPubKey, relativeTimelock)
+	redeemScript, err := buildRedeemScript(ownerPubKey, inheritorPubKey, relativeTimelock, txscript.OP_CHECKSEQUENCEVERIFY, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build redeem script: %w", err)
 	}
 
-	log.Printf("Built redeem script with timelock: %d days (%d BIP68 value)", timelockDays, relativeTimelock)
+	log.Printf("Built redeem script with timelock: %s (%d BIP68 value)", duration, relativeTimelock)
 	log.Printf("Redeem script hex: %x", redeemScript)
 
 	return &InheritanceScript{
 		OwnerPubKey:      ownerPubKey,
+		OwnerPubKeys:     options.ownerPubKeys,
+		OwnerRequired:    options.ownerRequired,
 		InheritorPubKey:  inheritorPubKey,
+		TimelockType:     Relative,
 		RelativeTimelock: relativeTimelock,
 		RedeemScript:     redeemScript,
 		ChainParams:      chainParams,
 	}, nil
 }
 
+// resolveRelativeTimelock returns the BIP 68 value of the relative timelock
+// selected by timelockDays and the options, with a description of its
+// duration, after checking it against the minimum timelock
+func resolveRelativeTimelock(timelockDays int64, options scriptOptions) (int64, string, error) {
+	var relativeTimelock int64
+	duration := fmt.Sprintf("%d days", timelockDays)
+	if options.timelockBlocks != 0 {
+		if options.timelockSeconds != 0 {
+			return 0, "", fmt.Errorf("a timelock in blocks cannot be combined with a timelock in seconds")
+		}
+
+		var err error
+		relativeTimelock, err = RelativeTimelockFromBlocks(options.timelockBlocks)
+		if err != nil {
+			return 0, "", err
+		}
+
+		// Guard against dangerously short timelocks, at the expected block interval
+		if !options.allowShortTimelock && RelativeTimelockDuration(relativeTimelock) < time.Duration(options.minTimelockDays)*secondsPerDay*time.Second {
+			return 0, "", fmt.Errorf("timelock of %d blocks is below the safety minimum of %d days (%d blocks)",
+				options.timelockBlocks, options.minTimelockDays, options.minTimelockDays*secondsPerDay/int64(BlockInterval/time.Second))
+		}
+		duration = fmt.Sprintf("%d blocks", options.timelockBlocks)
+	} else if options.timelockSeconds != 0 {
+		// Guard against dangerously short timelocks
+		if !options.allowShortTimelock && options.timelockSeconds < options.minTimelockDays*secondsPerDay {
+			return 0, "", fmt.Errorf("timelock of %d seconds is below the safety minimum of %d days",
+				options.timelockSeconds, options.minTimelockDays)
+		}
+
+		var effectiveSeconds int64
+		var err error
+		relativeTimelock, effectiveSeconds, err = RelativeTimelockFromSeconds(options.timelockSeconds)
+		if err != nil {
+			return 0, "", err
+		}
+		if effectiveSeconds != options.timelockSeconds {
+			log.Printf("Warning: CSV timelocks count in %d-second intervals; requested %d seconds, effective %d seconds (rounded up)",
+				TimelockGranularity, options.timelockSeconds, effectiveSeconds)
+		}
+		duration = fmt.Sprintf("%d seconds", effectiveSeconds)
+	} else {
+		// Guard against dangerously short timelocks
+		if !options.allowShortTimelock && timelockDays < options.minTimelockDays {
+			return 0, "", fmt.Errorf("timelock of %d days is below the safety minimum of %d days",
+				timelockDays, options.minTimelockDays)
+		}
+
+		// Calculate relative timelock value according to BIP 68
+		var err error
+		relativeTimelock, err = calculateRelativeTimelock(timelockDays)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	return relativeTimelock, duration, nil
+}
+
+// newAbsoluteInheritanceScript builds an inheritance script whose ELSE branch
+// is locked with OP_CHECKLOCKTIMEVERIFY until options.lockTime
+func newAbsoluteInheritanceScript(ownerPubKey, inheritorPubKey []byte, chainParams *chaincfg.Params, options scriptOptions) (*InheritanceScript, error) {
+	lockTime := options.lockTime
+	if options.timelockSeconds != 0 {
+		return nil, fmt.Errorf("an absolute timelock cannot be combined with a timelock in seconds")
+	}
+	if lockTime <= 0 || lockTime > maxLockTime {
+		return nil, fmt.Errorf("lock time %d is out of range (1 to %d)", lockTime, int64(maxLockTime))
+	}
+
+	// Guard against dangerously short timelocks. The current block height is
+	// unknown here, so only timestamps can be checked.
+	if IsLockTimeTimestamp(lockTime) {
+		earliest := time.Now().Add(time.Duration(options.minTimelockDays) * secondsPerDay * time.Second)
+		if !options.allowShortTimelock && time.Unix(lockTime, 0).Before(earliest) {
+			return nil, fmt.Errorf("unlock time %s is less than the safety minimum of %d days away",
+				FormatLockTime(lockTime), options.minTimelockDays)
+		}
+	} else {
+		log.Printf("Note: lock time %d is a block height; check it against the current height", lockTime)
+	}
+
+	redeemScript, err := buildRedeemScript(ownerPubKey, inheritorPubKey, lockTime, txscript.OP_CHECKLOCKTIMEVERIFY, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build redeem script: %w", err)
+	}
+
+	log.Printf("Built redeem script with absolute timelock: %s", FormatLockTime(lockTime))
+	log.Printf("Redeem script hex: %x", redeemScript)
+
+	return &InheritanceScript{
+		OwnerPubKey:     ownerPubKey,
+		OwnerPubKeys:    options.ownerPubKeys,
+		OwnerRequired:   options.ownerRequired,
+		InheritorPubKey: inheritorPubKey,
+		TimelockType:    Absolute,
+		LockTime:        lockTime,
+		RedeemScript:    redeemScript,
+		ChainParams:     chainParams,
+	}, nil
+}
+
 // buildRedeemScript constructs the inheritance redeem script
 // Script structure:
 // OP_IF
@@ -50,24 +291,27 @@ func NewInheritanceScript(ownerPubKey, inheritorPubKey []byte, timelockDays int6
 //
 // OP_ELSE
 //
-//	<Relative_Timelock_Value> OP_CHECKSEQUENCEVERIFY OP_DROP
+//	<Timelock_Value> OP_CHECKSEQUENCEVERIFY OP_DROP
 //	<Inheritor_PublicKey> OP_CHECKSIG
 //
 // OP_ENDIF
-func buildRedeemScript(ownerPubKey, inheritorPubKey []byte, relativeTimelock int64) ([]byte, error) {
+//
+// timelockOp is OP_CHECKSEQUENCEVERIFY for a relative timelock or
+// OP_CHECKLOCKTIMEVERIFY for an absolute one. An M-of-N owner set in options
+// replaces the IF branch with <M> <Owner_PublicKeys...> <N> OP_CHECKMULTISIG.
+func buildRedeemScript(ownerPubKey, inheritorPubKey []byte, timelock int64, timelockOp byte, options scriptOptions) ([]byte, error) {
 	builder := txscript.NewScriptBuilder()
 
 	// Start conditional block
 	builder.AddOp(txscript.OP_IF)
 
 	// IF branch: Owner's immediate spend path
-	builder.AddData(ownerPubKey)
-	builder.AddOp(txscript.OP_CHECKSIG)
+	addOwnerBranch(builder, ownerPubKey, options)
 
 	// ELSE branch: Inheritor's time-delayed spend path
 	builder.AddOp(txscript.OP_ELSE)
-	builder.AddInt64(relativeTimelock)
-	builder.AddOp(txscript.OP_CHECKSEQUENCEVERIFY)
+	builder.AddInt64(timelock)
+	builder.AddOp(timelockOp)
 	builder.AddOp(txscript.OP_DROP)
 	builder.AddData(inheritorPubKey)
 	builder.AddOp(txscript.OP_CHECKSIG)
@@ -78,18 +322,162 @@ func buildRedeemScript(ownerPubKey, inheritorPubKey []byte, relativeTimelock int
 	return builder.Script()
 }
 
+// addOwnerBranch adds the owner's spend path: <Owner_PublicKey> OP_CHECKSIG,
+// or the M-of-N OP_CHECKMULTISIG of an owner set in options
+func addOwnerBranch(builder *txscript.ScriptBuilder, ownerPubKey []byte, options scriptOptions) {
+	if options.ownerRequired > 0 {
+		builder.AddInt64(int64(options.ownerRequired))
+		for _, pubKey := range options.ownerPubKeys {
+			builder.AddData(pubKey)
+		}
+		builder.AddInt64(int64(len(options.ownerPubKeys)))
+		builder.AddOp(txscript.OP_CHECKMULTISIG)
+		return
+	}
+	builder.AddData(ownerPubKey)
+	builder.AddOp(txscript.OP_CHECKSIG)
+}
+
 // calculateRelativeTimelock converts days to BIP 68 encoded timelock value
-// BIP 68 uses 512-second intervals when the type flag (bit 22) is set
-func calculateRelativeTimelock(days int64) int64 {
+// BIP 68 uses 512-second intervals when the type flag (bit 22) is set. Only
+// 16 bits of interval count are enforced, so timelocks longer than about
+// 388 days are rejected rather than silently wrapping to a shorter lock.
+func calculateRelativeTimelock(days int64) (int64, error) {
 	// Convert days to seconds
 	totalSeconds := days * 24 * 60 * 60
 
 	// Convert to 512-second intervals
 	intervals := totalSeconds / 512
+	if intervals > 0xffff {
+		return 0, fmt.Errorf("timelock of %d days exceeds the BIP 68 maximum of %d days",
+			days, int64(0xffff)*TimelockGranularity/secondsPerDay)
+	}
 
 	// Set bit 22 to indicate time-based (not block-based) timelock
 	// Bit 22 = 0x400000
-	return intervals | 0x400000
+	return intervals | 0x400000, nil
+}
+
+// BlockInterval is the expected time between blocks, used to estimate how long
+// a block-based timelock runs
+const BlockInterval = 10 * time.Minute
+
+// maxRelativeTimelockBlocks is the largest block count BIP 68 can encode
+const maxRelativeTimelockBlocks = 0xffff
+
+// IsBlockBasedTimelock reports whether a BIP 68 value counts blocks rather
+// than 512-second intervals, i.e. whether the type flag (bit 22) is clear
+func IsBlockBasedTimelock(relativeTimelock int64) bool {
+	return relativeTimelock&0x400000 == 0
+}
+
+// RelativeTimelockFromBlocks encodes a block count as a block-based BIP 68
+// value: the raw count with the type flag (bit 22) clear
+func RelativeTimelockFromBlocks(blocks int64) (int64, error) {
+	if blocks <= 0 {
+		return 0, fmt.Errorf("timelock of %d blocks must be positive", blocks)
+	}
+	if blocks > maxRelativeTimelockBlocks {
+		return 0, fmt.Errorf("timelock of %d blocks exceeds the BIP 68 maximum of %d blocks",
+			blocks, maxRelativeTimelockBlocks)
+	}
+	return blocks, nil
+}
+
+// RelativeTimelockDuration returns how long a BIP 68 value locks a UTXO. A
+// block-based value is estimated at BlockInterval per block.
+func RelativeTimelockDuration(relativeTimelock int64) time.Duration {
+	// Consensus only reads the low 16 bits
+	count := time.Duration(relativeTimelock & 0xffff)
+	if IsBlockBasedTimelock(relativeTimelock) {
+		return count * BlockInterval
+	}
+	return count * TimelockGranularity * time.Second
+}
+
+// RelativeTimelockFromSeconds encodes a duration as a time-based BIP 68 value.
+// The duration is rounded up to whole 512-second intervals so the lock is never
+// shorter than requested; the effective duration in seconds is returned with it.
+func RelativeTimelockFromSeconds(seconds int64) (relativeTimelock, effectiveSeconds int64, err error) {
+	if seconds < 0 {
+		return 0, 0, fmt.Errorf("timelock of %d seconds is negative", seconds)
+	}
+
+	intervals := (seconds + TimelockGranularity - 1) / TimelockGranularity
+	if intervals > 0xffff {
+		return 0, 0, fmt.Errorf("timelock of %d seconds exceeds the BIP 68 maximum of %d seconds",
+			seconds, int64(0xffff)*TimelockGranularity)
+	}
+
+	return intervals | 0x400000, intervals * TimelockGranularity, nil
+}
+
+// FormatRelativeTimelock describes a time-based BIP 68 value in days, hours,
+// 512-second intervals and hex so it can be cross-checked against other tools.
+// A block-based value is described by its block count and estimated days.
+func FormatRelativeTimelock(relativeTimelock int64) string {
+	if IsBlockBasedTimelock(relativeTimelock) {
+		blocks := relativeTimelock & 0xffff
+		return fmt.Sprintf("%d blocks = about %d days = 0x%06x",
+			blocks, int64((RelativeTimelockDuration(relativeTimelock)+12*time.Hour)/(24*time.Hour)), relativeTimelock)
+	}
+
+	// Consensus only reads the low 16 bits as the interval count
+	intervals := relativeTimelock & 0xffff
+	seconds := intervals * 512
+
+	// Days and hours are rounded since whole days rarely divide into 512 seconds
+	return fmt.Sprintf("%d days = %d hours = %d intervals = 0x%06x",
+		(seconds+43200)/86400, (seconds+1800)/3600, intervals, relativeTimelock)
+}
+
+// validatePubKey checks that pubKey is a compressed secp256k1 public key
+func validatePubKey(pubKey []byte) error {
+	if len(pubKey) != 33 {
+		return fmt.Errorf("must be 33 bytes (compressed), got %d", len(pubKey))
+	}
+	if pubKey[0] != 0x02 && pubKey[0] != 0x03 {
+		return fmt.Errorf("must start with 0x02 or 0x03 (compressed), got 0x%02x", pubKey[0])
+	}
+	if _, err := btcec.ParsePubKey(pubKey); err != nil {
+		return fmt.Errorf("not a point on secp256k1: %w", err)
+	}
+	return nil
+}
+
+// IsLockTimeTimestamp reports whether an absolute lock time is a Unix
+// timestamp rather than a block height (BIP 65)
+func IsLockTimeTimestamp(lockTime int64) bool {
+	return lockTime >= LockTimeThreshold
+}
+
+// FormatLockTime describes an absolute lock time as a UTC date or a block height
+func FormatLockTime(lockTime int64) string {
+	if IsLockTimeTimestamp(lockTime) {
+		return fmt.Sprintf("%s (lock time %d)", time.Unix(lockTime, 0).UTC().Format("2006-01-02 15:04:05 MST"), lockTime)
+	}
+	return fmt.Sprintf("block %d", lockTime)
+}
+
+// DescribeTimelock describes the script's timelock with FormatRelativeTimelock
+// or, for an absolute timelock, FormatLockTime
+func (is *InheritanceScript) DescribeTimelock() string {
+	if is.TimelockType == Absolute {
+		return "until " + FormatLockTime(is.LockTime)
+	}
+	return FormatRelativeTimelock(is.RelativeTimelock)
+}
+
+// TimelockDays decodes the script's relative timelock back into whole days,
+// reversing calculateRelativeTimelock: the 512-second intervals below the
+// type flag (bit 22), rounded to the nearest day. A block-based timelock is
+// estimated at BlockInterval per block (check IsBlockBasedTimelock to tell
+// the two apart), and an absolute timelock has no days, so it returns 0.
+func (is *InheritanceScript) TimelockDays() int64 {
+	if is.TimelockType == Absolute {
+		return 0
+	}
+	return relativeTimelockDays(is.RelativeTimelock)
 }
 
 // GetP2WSHAddress derives the P2WSH address from the redeem script
@@ -106,12 +494,52 @@ func (is *InheritanceScript) GetP2WSHAddress() (btcutil.Address, error) {
 	return addr, nil
 }
 
+// GetP2SHP2WSHAddress derives the P2SH-P2WSH address from the redeem script:
+// the P2WSH witness program nested in a P2SH ("3..." or "2...") address, for
+// exchanges and older wallets that cannot pay to bech32. Its spends carry the
+// witness program in the signature script and the usual witness.
+func (is *InheritanceScript) GetP2SHP2WSHAddress() (btcutil.Address, error) {
+	witnessProgram, err := is.GetScriptPubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := btcutil.NewAddressScriptHash(witnessProgram, is.ChainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create P2SH-P2WSH address: %w", err)
+	}
+
+	return addr, nil
+}
+
+// GetAddress derives the contract address of the given type
+func (is *InheritanceScript) GetAddress(addressType AddressType) (btcutil.Address, error) {
+	switch addressType {
+	case "", AddressTypeP2WSH:
+		return is.GetP2WSHAddress()
+	case AddressTypeP2SHP2WSH:
+		return is.GetP2SHP2WSHAddress()
+	default:
+		return nil, fmt.Errorf("cannot derive a %s address from an inheritance script", addressType)
+	}
+}
+
 // GetScriptHash returns the SHA256 hash of the redeem script
 func (is *InheritanceScript) GetScriptHash() []byte {
 	scriptHash := sha256.Sum256(is.RedeemScript)
 	return scriptHash[:]
 }
 
+// Disassemble returns the redeem script as opcodes, e.g.
+// OP_IF <owner> OP_CHECKSIG OP_ELSE <timelock> OP_CHECKSEQUENCEVERIFY ...
+func (is *InheritanceScript) Disassemble() (string, error) {
+	disasm, err := txscript.DisasmString(is.RedeemScript)
+	if err != nil {
+		return "", fmt.Errorf("failed to disassemble redeem script: %w", err)
+	}
+	return disasm, nil
+}
+
 // GetScriptPubKey returns the scriptPubKey for P2WSH
 func (is *InheritanceScript) GetScriptPubKey() ([]byte, error) {
 	addr, err := is.GetP2WSHAddress()
@@ -129,17 +557,36 @@ func (is *InheritanceScript) ValidateScript() error {
 		return fmt.Errorf("redeem script is empty")
 	}
 
-	// Check if public keys are valid length (33 bytes for compressed)
-	if len(is.OwnerPubKey) != 33 {
-		return fmt.Errorf("owner public key must be 33 bytes (compressed)")
+	// Check if public keys are valid compressed points on secp256k1; any other
+	// 33 bytes give a fundable address that can never be spent
+	if is.IsMultisigOwner() {
+		for i, pubKey := range is.OwnerPubKeys {
+			if err := validatePubKey(pubKey); err != nil {
+				return fmt.Errorf("owner public key %d: %w", i+1, err)
+			}
+		}
+	} else if err := validatePubKey(is.OwnerPubKey); err != nil {
+		return fmt.Errorf("owner public key: %w", err)
 	}
 
-	if len(is.InheritorPubKey) != 33 {
-		return fmt.Errorf("inheritor public key must be 33 bytes (compressed)")
+	if err := validatePubKey(is.InheritorPubKey); err != nil {
+		return fmt.Errorf("inheritor public key: %w", err)
+	}
+	for i, tier := range is.Tiers {
+		if err := validatePubKey(tier.PubKey); err != nil {
+			return fmt.Errorf("tier %d public key: %w", i+1, err)
+		}
+		if tier.RelativeTimelock <= 0 {
+			return fmt.Errorf("tier %d relative timelock must be positive", i+1)
+		}
 	}
 
-	// Check if timelock is valid (positive and within BIP 68 limits)
-	if is.RelativeTimelock <= 0 {
+	// Check if timelock is valid (positive and within BIP 65/68 limits)
+	if is.TimelockType == Absolute {
+		if is.LockTime <= 0 || is.LockTime > maxLockTime {
+			return fmt.Errorf("lock time must be between 1 and %d", int64(maxLockTime))
+		}
+	} else if is.RelativeTimelock <= 0 {
 		return fmt.Errorf("relative timelock must be positive")
 	}
 
@@ -2,13 +2,16 @@ package script
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 )
 
-// Test helper to create valid compressed public keys
+// Test helper to create valid compressed public keys (points on secp256k1)
 func createTestPubKeys() ([]byte, []byte) {
 	// Valid compressed public key examples (33 bytes each)
 	ownerPubKey := []byte{
@@ -16,8 +19,8 @@ func createTestPubKeys() ([]byte, []byte) {
 		0x1a, 0x3b, 0x4c, 0x5d, 0x6e, 0x7f, 0x8a, 0x9b, 0x0c, 0x1d, 0x2e, 0x3f, 0x4a, 0x5b, 0x6c, 0x7d, 0x8e,
 	}
 	inheritorPubKey := []byte{
-		0x02, 0x4a, 0x5b, 0x6c, 0x7d, 0x8e, 0x9f, 0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6, 0x07, 0x18, 0x29,
-		0x3a, 0x4b, 0x5c, 0x6d, 0x7e, 0x8f, 0x90, 0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6, 0x07, 0x18, 0x29, 0x3a,
+		0x02, 0xc6, 0x04, 0x7f, 0x94, 0x41, 0xed, 0x7d, 0x6d, 0x30, 0x45, 0x40, 0x6e, 0x95, 0xc0, 0x7c,
+		0xd8, 0x5c, 0x77, 0x8e, 0x4b, 0x8c, 0xef, 0x3c, 0xa7, 0xab, 0xac, 0x09, 0xb9, 0x5c, 0x70, 0x9e, 0xe5,
 	}
 	return ownerPubKey, inheritorPubKey
 }
@@ -57,7 +60,7 @@ func TestNewInheritanceScript_ValidInput(t *testing.T) {
 	}
 
 	// Verify relative timelock is calculated correctly
-	expectedTimelock := calculateRelativeTimelock(timelockDays)
+	expectedTimelock := mustRelativeTimelock(t, timelockDays)
 	if script.RelativeTimelock != expectedTimelock {
 		t.Errorf("Expected relative timelock %d, got %d", expectedTimelock, script.RelativeTimelock)
 	}
@@ -80,13 +83,14 @@ func TestNewInheritanceScript_DifferentTimelocks(t *testing.T) {
 		{"1 day", 1, false},
 		{"30 days", 30, false},
 		{"365 days", 365, false},
-		{"1000 days", 1000, false},
-		{"Zero days", 0, false}, // Should work but result in zero timelock
+		{"388 days", 388, false},
+		{"1000 days", 1000, true}, // Beyond the 16-bit BIP 68 interval count
+		{"Zero days", 0, false},   // Should work but result in zero timelock
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, tc.timelockDays, chainParams)
+			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, tc.timelockDays, chainParams, AllowShortTimelock())
 
 			if tc.expectError {
 				if err == nil {
@@ -104,7 +108,7 @@ func TestNewInheritanceScript_DifferentTimelocks(t *testing.T) {
 			}
 
 			// Verify timelock calculation
-			expectedTimelock := calculateRelativeTimelock(tc.timelockDays)
+			expectedTimelock := mustRelativeTimelock(t, tc.timelockDays)
 			if script.RelativeTimelock != expectedTimelock {
 				t.Errorf("Expected relative timelock %d, got %d", expectedTimelock, script.RelativeTimelock)
 			}
@@ -201,6 +205,46 @@ func TestNewInheritanceScript_InvalidPublicKeys(t *testing.T) {
 	}
 }
 
+func TestValidateScript_PublicKeyNotOnCurve(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	notOnCurve := bytes.Repeat([]byte{0xff}, 33)
+	offCurveX := append([]byte{0x02}, bytes.Repeat([]byte{0x11}, 32)...)
+	uncompressedPrefix := append([]byte{0x04}, ownerPubKey[1:]...)
+
+	tests := []struct {
+		name      string
+		owner     []byte
+		inheritor []byte
+		wantErr   string
+	}{
+		{"valid keys", ownerPubKey, inheritorPubKey, ""},
+		{"owner all 0xFF", notOnCurve, inheritorPubKey, "owner public key: must start with 0x02 or 0x03"},
+		{"inheritor all 0xFF", ownerPubKey, notOnCurve, "inheritor public key: must start with 0x02 or 0x03"},
+		{"owner x not on curve", offCurveX, inheritorPubKey, "owner public key: not a point on secp256k1"},
+		{"inheritor uncompressed prefix", ownerPubKey, uncompressedPrefix, "inheritor public key: must start with 0x02 or 0x03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := NewInheritanceScript(tt.owner, tt.inheritor, 180, &chaincfg.TestNet3Params)
+			if err != nil {
+				t.Fatalf("NewInheritanceScript failed: %v", err)
+			}
+
+			err = script.ValidateScript()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateScript failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestNewInheritanceScript_DifferentChainParams(t *testing.T) {
 	ownerPubKey, inheritorPubKey := createTestPubKeys()
 	timelockDays := int64(365)
@@ -327,7 +371,7 @@ func TestNewInheritanceScript_NegativeTimelock(t *testing.T) {
 	timelockDays := int64(-1)
 	chainParams := &chaincfg.TestNet3Params
 
-	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, timelockDays, chainParams)
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, timelockDays, chainParams, AllowShortTimelock())
 	if err != nil {
 		t.Fatalf("NewInheritanceScript failed: %v", err)
 	}
@@ -355,7 +399,7 @@ func TestNewInheritanceScript_TimelockCalculation(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, tc.days, chainParams)
+			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, tc.days, chainParams, AllowShortTimelock())
 			if err != nil {
 				t.Fatalf("NewInheritanceScript failed: %v", err)
 			}
@@ -376,6 +420,111 @@ func TestNewInheritanceScript_TimelockCalculation(t *testing.T) {
 	}
 }
 
+// mustRelativeTimelock returns the BIP 68 value of a timelock in days
+func mustRelativeTimelock(t *testing.T, days int64) int64 {
+	t.Helper()
+	relativeTimelock, err := calculateRelativeTimelock(days)
+	if err != nil {
+		t.Fatalf("calculateRelativeTimelock(%d) failed: %v", days, err)
+	}
+	return relativeTimelock
+}
+
+func TestNewInheritanceScript_MaximumTimelock(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	chainParams := &chaincfg.TestNet3Params
+
+	// 388 days is the last whole day whose interval count fits in 16 bits
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 388, chainParams)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript(388 days) failed: %v", err)
+	}
+	if script.RelativeTimelock != 0x400000|65475 {
+		t.Errorf("Expected 0x%06x, got 0x%06x", 0x400000|65475, script.RelativeTimelock)
+	}
+
+	// 400 days would wrap to 0x4107ac, a lock of about 12 days
+	for _, days := range []int64{389, 400, 1000} {
+		if _, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, days, chainParams); err == nil || !strings.Contains(err.Error(), "BIP 68 maximum of 388 days") {
+			t.Errorf("%d days: expected the BIP 68 maximum to be enforced, got %v", days, err)
+		}
+	}
+}
+
+func TestTimelockDays_RoundTrip(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	chainParams := &chaincfg.TestNet3Params
+
+	for _, days := range []int64{1, 2, 7, 30, 31, 90, 180, 200, 365, 388} {
+		t.Run(fmt.Sprintf("%d days", days), func(t *testing.T) {
+			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, days, chainParams, AllowShortTimelock())
+			if err != nil {
+				t.Fatalf("NewInheritanceScript failed: %v", err)
+			}
+			if got := script.TimelockDays(); got != days {
+				t.Errorf("Expected %d days from the built script, got %d", days, got)
+			}
+
+			// Decoding from the script bytes alone recovers the same days
+			parsed, err := ParseRedeemScript(script.RedeemScript, chainParams)
+			if err != nil {
+				t.Fatalf("ParseRedeemScript failed: %v", err)
+			}
+			if got := parsed.TimelockDays(); got != days {
+				t.Errorf("Expected %d days from the parsed script, got %d", days, got)
+			}
+		})
+	}
+
+	// Block-based timelocks are estimated at one block every ten minutes
+	blocks, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 0, chainParams, WithTimelockBlocks(26208))
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	if !IsBlockBasedTimelock(blocks.RelativeTimelock) || blocks.TimelockDays() != 182 {
+		t.Errorf("Expected a block-based timelock of about 182 days, got 0x%06x and %d days", blocks.RelativeTimelock, blocks.TimelockDays())
+	}
+
+	absolute, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 0, chainParams, WithAbsoluteTimelock(time.Now().AddDate(1, 0, 0).Unix()))
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	if got := absolute.TimelockDays(); got != 0 {
+		t.Errorf("Expected no days for an absolute timelock, got %d", got)
+	}
+}
+
+func TestNewInheritanceScript_MinimumTimelock(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	chainParams := &chaincfg.TestNet3Params
+
+	testCases := []struct {
+		name         string
+		timelockDays int64
+		opts         []Option
+		expectError  bool
+	}{
+		{"Below default minimum", DefaultMinTimelockDays - 1, nil, true},
+		{"At default minimum", DefaultMinTimelockDays, nil, false},
+		{"1 day rejected", 1, nil, true},
+		{"1 day explicitly allowed", 1, []Option{AllowShortTimelock()}, false},
+		{"Below custom minimum", 89, []Option{WithMinTimelockDays(90)}, true},
+		{"At custom minimum", 90, []Option{WithMinTimelockDays(90)}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, tc.timelockDays, chainParams, tc.opts...)
+			if tc.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestNewInheritanceScript_SameKeysError(t *testing.T) {
 	ownerPubKey, _ := createTestPubKeys()
 	// Use the same key for both owner and inheritor
@@ -383,18 +532,21 @@ func TestNewInheritanceScript_SameKeysError(t *testing.T) {
 	timelockDays := int64(365)
 	chainParams := &chaincfg.TestNet3Params
 
-	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, timelockDays, chainParams)
-	if err != nil {
-		t.Fatalf("NewInheritanceScript failed: %v", err)
+	_, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, timelockDays, chainParams)
+	if err == nil || !strings.Contains(err.Error(), "identical") {
+		t.Fatalf("Expected identical keys error, got %v", err)
 	}
 
-	// While the script creation succeeds, this creates a potentially problematic scenario
-	// where the owner and inheritor are the same entity
+	// AllowSameKey permits it for the rare legitimate case
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, timelockDays, chainParams, AllowSameKey())
+	if err != nil {
+		t.Fatalf("NewInheritanceScript with AllowSameKey failed: %v", err)
+	}
 	if !bytes.Equal(script.OwnerPubKey, script.InheritorPubKey) {
 		t.Error("Owner and inheritor public keys should be identical in this test")
 	}
 
-	// The script should still be valid from a technical perspective
+	// The script is still valid from a technical perspective
 	if err := script.ValidateScript(); err != nil {
 		t.Errorf("Script validation should pass even with same keys: %v", err)
 	}
@@ -431,6 +583,78 @@ func TestNewInheritanceScript_P2WSHAddressGeneration(t *testing.T) {
 	}
 }
 
+func TestGetP2SHP2WSHAddress(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+
+	tests := []struct {
+		params *chaincfg.Params
+		prefix string
+	}{
+		{&chaincfg.TestNet3Params, "2"},
+		{&chaincfg.RegressionNetParams, "2"},
+		{&chaincfg.MainNetParams, "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.params.Name, func(t *testing.T) {
+			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 365, tt.params)
+			if err != nil {
+				t.Fatalf("NewInheritanceScript failed: %v", err)
+			}
+
+			addr, err := script.GetP2SHP2WSHAddress()
+			if err != nil {
+				t.Fatalf("GetP2SHP2WSHAddress failed: %v", err)
+			}
+			if !strings.HasPrefix(addr.EncodeAddress(), tt.prefix) {
+				t.Errorf("Expected a P2SH address starting with %q, got %s", tt.prefix, addr.EncodeAddress())
+			}
+
+			// The P2SH hash commits to the P2WSH witness program
+			witnessProgram, err := script.GetScriptPubKey()
+			if err != nil {
+				t.Fatalf("GetScriptPubKey failed: %v", err)
+			}
+			if !bytes.Equal(addr.ScriptAddress(), btcutil.Hash160(witnessProgram)) {
+				t.Errorf("P2SH hash %x does not commit to the witness program %x", addr.ScriptAddress(), witnessProgram)
+			}
+
+			nested, err := script.GetAddress(AddressTypeP2SHP2WSH)
+			if err != nil {
+				t.Fatalf("GetAddress failed: %v", err)
+			}
+			if nested.EncodeAddress() != addr.EncodeAddress() {
+				t.Errorf("GetAddress(%s) = %s, want %s", AddressTypeP2SHP2WSH, nested.EncodeAddress(), addr.EncodeAddress())
+			}
+		})
+	}
+}
+
+func TestGetAddress_Types(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 365, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	p2wshAddr, err := script.GetP2WSHAddress()
+	if err != nil {
+		t.Fatalf("GetP2WSHAddress failed: %v", err)
+	}
+
+	for _, addressType := range []AddressType{"", AddressTypeP2WSH} {
+		addr, err := script.GetAddress(addressType)
+		if err != nil {
+			t.Fatalf("GetAddress(%q) failed: %v", addressType, err)
+		}
+		if addr.EncodeAddress() != p2wshAddr.EncodeAddress() {
+			t.Errorf("GetAddress(%q) = %s, want %s", addressType, addr.EncodeAddress(), p2wshAddr.EncodeAddress())
+		}
+	}
+	if _, err := script.GetAddress(AddressTypeP2TR); err == nil {
+		t.Error("Expected an error for a taproot address")
+	}
+}
+
 func TestNewInheritanceScript_ScriptHash(t *testing.T) {
 	ownerPubKey, inheritorPubKey := createTestPubKeys()
 	timelockDays := int64(365)
@@ -522,3 +746,291 @@ func BenchmarkNewInheritanceScript_WithAddressGeneration(b *testing.B) {
 		}
 	}
 }
+
+func TestFormatRelativeTimelock(t *testing.T) {
+	tests := []struct {
+		name     string
+		days     int64
+		expected string
+	}{
+		{"180 days", 180, "180 days = 4320 hours = 30375 intervals = 0x4076a7"},
+		{"30 days", 30, "30 days = 720 hours = 5062 intervals = 0x4013c6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatRelativeTimelock(mustRelativeTimelock(t, tt.days))
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRelativeTimelockFromSeconds(t *testing.T) {
+	tests := []struct {
+		name              string
+		seconds           int64
+		expectedTimelock  int64
+		expectedEffective int64
+		expectError       bool
+	}{
+		{"Exact multiple", 1024, 0x400002, 1024, false},
+		{"Rounded up", 1000, 0x400002, 1024, false},
+		{"One second", 1, 0x400001, 512, false},
+		{"Zero", 0, 0x400000, 0, false},
+		{"Maximum", 0xffff * 512, 0x40ffff, 0xffff * 512, false},
+		{"Rounds past maximum", 0xffff*512 + 1, 0, 0, true},
+		{"Negative", -1, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timelock, effective, err := RelativeTimelockFromSeconds(tt.seconds)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if timelock != tt.expectedTimelock {
+				t.Errorf("Expected timelock 0x%06x, got 0x%06x", tt.expectedTimelock, timelock)
+			}
+			if effective != tt.expectedEffective {
+				t.Errorf("Expected effective %d seconds, got %d", tt.expectedEffective, effective)
+			}
+			if effective < tt.seconds {
+				t.Errorf("Effective %d seconds is shorter than requested %d", effective, tt.seconds)
+			}
+		})
+	}
+}
+
+func TestNewInheritanceScript_TimelockSeconds(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+	chainParams := &chaincfg.TestNet3Params
+
+	// 30 days is not a multiple of 512 seconds, so it rounds up by one interval
+	seconds := int64(30 * 24 * 60 * 60)
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 0, chainParams, WithTimelockSeconds(seconds))
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	if script.RelativeTimelock != 0x4013c7 {
+		t.Errorf("Expected timelock 0x4013c7, got 0x%06x", script.RelativeTimelock)
+	}
+	parsed, err := ParseRelativeTimelock(script.RedeemScript)
+	if err != nil {
+		t.Fatalf("ParseRelativeTimelock failed: %v", err)
+	}
+	if parsed != script.RelativeTimelock {
+		t.Errorf("Redeem script holds 0x%06x, expected 0x%06x", parsed, script.RelativeTimelock)
+	}
+
+	// The minimum applies to the seconds, not the ignored days argument
+	if _, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 365, chainParams, WithTimelockSeconds(3600)); err == nil {
+		t.Error("Expected short seconds timelock to be rejected")
+	}
+	if _, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 0, chainParams, WithTimelockSeconds(3600), AllowShortTimelock()); err != nil {
+		t.Errorf("Unexpected error with short timelock allowed: %v", err)
+	}
+}
+
+func TestNewInheritanceScript_AbsoluteTimelock(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+
+	tests := []struct {
+		name          string
+		lockTime      int64
+		opts          []Option
+		wantTimestamp bool
+		wantErr       string
+	}{
+		{"Date (2030-01-01)", 1893456000, nil, true, ""},
+		{"Block height", 900000, nil, false, ""},
+		{"Last block height", LockTimeThreshold - 1, nil, false, ""},
+		{"First timestamp", LockTimeThreshold, []Option{AllowShortTimelock()}, true, ""},
+		{"Maximum lock time", 0xffffffff, nil, true, ""},
+		{"Timestamp below minimum", LockTimeThreshold, nil, true, "safety minimum"},
+		{"Zero", 0, nil, false, "out of range"},
+		{"Above 32 bits", 0x100000000, nil, true, "out of range"},
+		{"Combined with seconds", 1893456000, []Option{WithTimelockSeconds(86400 * 90)}, true, "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLockTimeTimestamp(tt.lockTime); got != tt.wantTimestamp {
+				t.Errorf("IsLockTimeTimestamp(%d) = %t, expected %t", tt.lockTime, got, tt.wantTimestamp)
+			}
+
+			opts := append([]Option{WithAbsoluteTimelock(tt.lockTime)}, tt.opts...)
+			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 180, &chaincfg.TestNet3Params, opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewInheritanceScript failed: %v", err)
+			}
+
+			if script.TimelockType != Absolute || script.LockTime != tt.lockTime || script.RelativeTimelock != 0 {
+				t.Errorf("Expected absolute lock time %d, got %s lock time %d (relative %d)",
+					tt.lockTime, script.TimelockType, script.LockTime, script.RelativeTimelock)
+			}
+			if !bytes.Contains(script.RedeemScript, []byte{0xb1, 0x75}) { // OP_CHECKLOCKTIMEVERIFY OP_DROP
+				t.Errorf("Redeem script %x does not use OP_CHECKLOCKTIMEVERIFY", script.RedeemScript)
+			}
+			if err := script.ValidateScript(); err != nil {
+				t.Errorf("ValidateScript failed: %v", err)
+			}
+
+			parsed, err := ParseRedeemScript(script.RedeemScript, nil)
+			if err != nil {
+				t.Fatalf("ParseRedeemScript failed: %v", err)
+			}
+			if parsed.TimelockType != Absolute || parsed.LockTime != tt.lockTime {
+				t.Errorf("Parsed %s lock time %d, expected absolute %d", parsed.TimelockType, parsed.LockTime, tt.lockTime)
+			}
+			if _, err := ParseRelativeTimelock(script.RedeemScript); err == nil {
+				t.Error("Expected ParseRelativeTimelock to reject an absolute timelock")
+			}
+		})
+	}
+}
+
+func TestParseRedeemScript_TimelockType(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	parsed, err := ParseRedeemScript(script.RedeemScript, nil)
+	if err != nil {
+		t.Fatalf("ParseRedeemScript failed: %v", err)
+	}
+	if parsed.TimelockType != Relative || parsed.RelativeTimelock != script.RelativeTimelock || parsed.LockTime != 0 {
+		t.Errorf("Parsed %s timelock %d (lock time %d), expected relative %d",
+			parsed.TimelockType, parsed.RelativeTimelock, parsed.LockTime, script.RelativeTimelock)
+	}
+	if !strings.HasPrefix(parsed.DescribeTimelock(), "180 days") {
+		t.Errorf("Unexpected description %q", parsed.DescribeTimelock())
+	}
+}
+
+func TestFormatLockTime(t *testing.T) {
+	tests := []struct {
+		lockTime int64
+		expected string
+	}{
+		{1893456000, "2030-01-01 00:00:00 UTC (lock time 1893456000)"},
+		{LockTimeThreshold, "1985-11-05 00:53:20 UTC (lock time 500000000)"},
+		{LockTimeThreshold - 1, "block 499999999"},
+		{900000, "block 900000"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatLockTime(tt.lockTime); got != tt.expected {
+			t.Errorf("FormatLockTime(%d) = %q, expected %q", tt.lockTime, got, tt.expected)
+		}
+	}
+}
+
+func TestNewInheritanceScript_TimelockBlocks(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+
+	tests := []struct {
+		name    string
+		blocks  int64
+		opts    []Option
+		wantErr string
+	}{
+		{"180 days of blocks", 180 * 144, nil, ""},
+		{"BIP 68 maximum", 0xffff, nil, ""},
+		{"One block", 1, []Option{AllowShortTimelock()}, ""},
+		{"Above BIP 68 maximum", 0x10000, nil, "exceeds the BIP 68 maximum of 65535 blocks"},
+		{"Negative", -1, nil, "must be positive"},
+		{"Below minimum", 29 * 144, nil, "safety minimum"},
+		{"Combined with seconds", 180 * 144, []Option{WithTimelockSeconds(86400 * 90)}, "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithTimelockBlocks(tt.blocks)}, tt.opts...)
+			script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 180, &chaincfg.TestNet3Params, opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewInheritanceScript failed: %v", err)
+			}
+
+			if script.RelativeTimelock&0x400000 != 0 {
+				t.Errorf("Bit 22 is set in block-based timelock 0x%06x", script.RelativeTimelock)
+			}
+			if script.RelativeTimelock != tt.blocks {
+				t.Errorf("Expected timelock %d, got %d", tt.blocks, script.RelativeTimelock)
+			}
+			if !IsBlockBasedTimelock(script.RelativeTimelock) {
+				t.Error("Expected a block-based timelock")
+			}
+
+			relativeTimelock, err := ParseRelativeTimelock(script.RedeemScript)
+			if err != nil {
+				t.Fatalf("ParseRelativeTimelock failed: %v", err)
+			}
+			if relativeTimelock != tt.blocks {
+				t.Errorf("Round trip returned %d, expected %d", relativeTimelock, tt.blocks)
+			}
+		})
+	}
+}
+
+func TestFormatRelativeTimelock_Blocks(t *testing.T) {
+	if got, expected := FormatRelativeTimelock(180*144), "25920 blocks = about 180 days = 0x006540"; got != expected {
+		t.Errorf("FormatRelativeTimelock = %q, expected %q", got, expected)
+	}
+	if got := RelativeTimelockDuration(144); got != 24*time.Hour {
+		t.Errorf("RelativeTimelockDuration(144) = %v, expected 24h", got)
+	}
+	if got := RelativeTimelockDuration(mustRelativeTimelock(t, 1)); got != 168*512*time.Second {
+		t.Errorf("RelativeTimelockDuration of one day = %v, expected %v", got, 168*512*time.Second)
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	ownerPubKey, inheritorPubKey := createTestPubKeys()
+
+	script, err := NewInheritanceScript(ownerPubKey, inheritorPubKey, 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	disasm, err := script.Disassemble()
+	if err != nil {
+		t.Fatalf("Disassemble failed: %v", err)
+	}
+	for _, want := range []string{"OP_IF", fmt.Sprintf("%x", ownerPubKey), "OP_CHECKSEQUENCEVERIFY", "OP_DROP", fmt.Sprintf("%x", inheritorPubKey), "OP_ENDIF"} {
+		if !strings.Contains(disasm, want) {
+			t.Errorf("Disassembly %q does not contain %s", disasm, want)
+		}
+	}
+	if !strings.HasPrefix(disasm, "OP_IF ") || !strings.HasSuffix(disasm, " OP_ENDIF") {
+		t.Errorf("Unexpected disassembly %q", disasm)
+	}
+
+	// A truncated push cannot be disassembled
+	truncated := &InheritanceScript{RedeemScript: script.RedeemScript[:5]}
+	if _, err := truncated.Disassemble(); err == nil {
+		t.Error("Expected error for truncated script")
+	}
+}
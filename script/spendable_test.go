@@ -0,0 +1,67 @@
+package script
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+)
+
+func TestValidateSpendable(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	owner := keys.NewLocalSigner(ownerKey)
+	inheritor := keys.NewLocalSigner(inheritorKey)
+
+	tests := []struct {
+		name            string
+		ownerSigner     keys.Signer
+		inheritorSigner keys.Signer
+		opts            []Option
+		sequenceOffset  int64
+		wantErr         string
+	}{
+		{"Both paths", owner, inheritor, nil, 0, ""},
+		{"Owner path only", owner, nil, nil, 0, ""},
+		{"Wrong owner key", inheritor, inheritor, nil, 0, "owner path is not spendable"},
+		{"Wrong inheritor key", owner, owner, nil, 0, "inheritor path is not spendable"},
+		{"Immature sequence", owner, inheritor, nil, -1, "inheritor path is not spendable"},
+		{"Block-based", owner, inheritor, []Option{WithTimelockBlocks(180 * 144)}, 0, ""},
+		{"Immature block count", owner, inheritor, []Option{WithTimelockBlocks(180 * 144)}, -1, "inheritor path is not spendable"},
+		{"Absolute date", owner, inheritor, []Option{WithAbsoluteTimelock(1893456000)}, 0, ""},
+		{"Absolute block height", owner, inheritor, []Option{WithAbsoluteTimelock(900000)}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := NewInheritanceScript(
+				ownerKey.PubKey().SerializeCompressed(),
+				inheritorKey.PubKey().SerializeCompressed(),
+				180, &chaincfg.TestNet3Params, tt.opts...,
+			)
+			if err != nil {
+				t.Fatalf("NewInheritanceScript failed: %v", err)
+			}
+			script.RelativeTimelock += tt.sequenceOffset
+
+			err = script.ValidateSpendable(tt.ownerSigner, tt.inheritorSigner)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateSpendable failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
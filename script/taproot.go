@@ -0,0 +1,159 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// TaprootInheritanceScript is the taproot (BIP 341) variant of the contract.
+// The owner's key is the internal key, so the owner spends by key path and
+// the spend looks like any single-key payment; the inheritor's timelocked
+// path is the only script leaf and is revealed only when it is used:
+//
+//	<Timelock_Value> OP_CHECKSEQUENCEVERIFY OP_DROP
+//	<Inheritor_XOnlyKey> OP_CHECKSIG
+type TaprootInheritanceScript struct {
+	OwnerPubKey      []byte // compressed; the internal key is its x-only form
+	InheritorPubKey  []byte // compressed; the leaf holds its x-only form
+	RelativeTimelock int64
+	LeafScript       []byte
+	ChainParams      *chaincfg.Params
+}
+
+// NewTaprootInheritanceScript creates a taproot inheritance contract. It
+// takes the timelock options of NewInheritanceScript, except that the
+// inheritor path is always a relative (OP_CHECKSEQUENCEVERIFY) timelock.
+func NewTaprootInheritanceScript(ownerPubKey, inheritorPubKey []byte, timelockDays int64, chainParams *chaincfg.Params, opts ...Option) (*TaprootInheritanceScript, error) {
+	options := scriptOptions{minTimelockDays: DefaultMinTimelockDays}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.timelockType == Absolute {
+		return nil, fmt.Errorf("taproot contracts support relative timelocks only")
+	}
+	if err := validatePubKey(ownerPubKey); err != nil {
+		return nil, fmt.Errorf("invalid owner public key: %w", err)
+	}
+	if err := validatePubKey(inheritorPubKey); err != nil {
+		return nil, fmt.Errorf("invalid inheritor public key: %w", err)
+	}
+	// Keys differing only in parity share an x-only key
+	if !options.allowSameKey && bytes.Equal(ownerPubKey[1:], inheritorPubKey[1:]) {
+		return nil, fmt.Errorf("owner and inheritor public keys are identical, which makes the two spend paths redundant")
+	}
+
+	relativeTimelock, duration, err := resolveRelativeTimelock(timelockDays, options)
+	if err != nil {
+		return nil, err
+	}
+
+	leafScript, err := txscript.NewScriptBuilder().
+		AddInt64(relativeTimelock).
+		AddOp(txscript.OP_CHECKSEQUENCEVERIFY).
+		AddOp(txscript.OP_DROP).
+		AddData(inheritorPubKey[1:]).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build inheritor leaf script: %w", err)
+	}
+
+	log.Printf("Built taproot inheritor leaf with timelock: %s (%d BIP68 value)", duration, relativeTimelock)
+	log.Printf("Leaf script hex: %x", leafScript)
+
+	return &TaprootInheritanceScript{
+		OwnerPubKey:      ownerPubKey,
+		InheritorPubKey:  inheritorPubKey,
+		RelativeTimelock: relativeTimelock,
+		LeafScript:       leafScript,
+		ChainParams:      chainParams,
+	}, nil
+}
+
+// TapLeaf returns the inheritor's script leaf
+func (ts *TaprootInheritanceScript) TapLeaf() txscript.TapLeaf {
+	return txscript.NewBaseTapLeaf(ts.LeafScript)
+}
+
+// MerkleRoot returns the root of the script tree, which with a single leaf
+// is the leaf hash
+func (ts *TaprootInheritanceScript) MerkleRoot() []byte {
+	leafHash := ts.TapLeaf().TapHash()
+	return leafHash[:]
+}
+
+// InternalKey returns the owner's key as the taproot internal key
+func (ts *TaprootInheritanceScript) InternalKey() (*btcec.PublicKey, error) {
+	internalKey, err := schnorr.ParsePubKey(ts.OwnerPubKey[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner public key: %w", err)
+	}
+	return internalKey, nil
+}
+
+// OutputKey returns the taproot output key: the internal key tweaked with
+// the merkle root of the script tree
+func (ts *TaprootInheritanceScript) OutputKey() (*btcec.PublicKey, error) {
+	internalKey, err := ts.InternalKey()
+	if err != nil {
+		return nil, err
+	}
+	return txscript.ComputeTaprootOutputKey(internalKey, ts.MerkleRoot()), nil
+}
+
+// GetScriptPubKey returns the segwit v1 output script paying to the contract
+func (ts *TaprootInheritanceScript) GetScriptPubKey() ([]byte, error) {
+	outputKey, err := ts.OutputKey()
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create P2TR script: %w", err)
+	}
+	return pkScript, nil
+}
+
+// GetP2TRAddress returns the contract's bc1p (tb1p on test networks) address
+func (ts *TaprootInheritanceScript) GetP2TRAddress() (*btcutil.AddressTaproot, error) {
+	outputKey, err := ts.OutputKey()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), ts.ChainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create P2TR address: %w", err)
+	}
+	return addr, nil
+}
+
+// ControlBlock returns the serialized control block proving the inheritor
+// leaf is committed to by the output key
+func (ts *TaprootInheritanceScript) ControlBlock() ([]byte, error) {
+	internalKey, err := ts.InternalKey()
+	if err != nil {
+		return nil, err
+	}
+	outputKey, err := ts.OutputKey()
+	if err != nil {
+		return nil, err
+	}
+
+	controlBlock := txscript.ControlBlock{
+		InternalKey:     internalKey,
+		OutputKeyYIsOdd: outputKey.SerializeCompressed()[0] == 0x03,
+		LeafVersion:     txscript.BaseLeafVersion,
+	}
+	serialized, err := controlBlock.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize control block: %w", err)
+	}
+	return serialized, nil
+}
@@ -0,0 +1,149 @@
+package script
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// The secp256k1 generator G and 2G, valid keys with known values
+const (
+	testTaprootOwnerKey     = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	testTaprootInheritorKey = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+)
+
+func newTestTaprootScript(t *testing.T, chainParams *chaincfg.Params) *TaprootInheritanceScript {
+	t.Helper()
+
+	ownerPubKey, _ := hex.DecodeString(testTaprootOwnerKey)
+	inheritorPubKey, _ := hex.DecodeString(testTaprootInheritorKey)
+	tapScript, err := NewTaprootInheritanceScript(ownerPubKey, inheritorPubKey, 180, chainParams)
+	if err != nil {
+		t.Fatalf("NewTaprootInheritanceScript failed: %v", err)
+	}
+	return tapScript
+}
+
+func TestTaprootInheritanceScript_Address(t *testing.T) {
+	tapScript := newTestTaprootScript(t, &chaincfg.TestNet3Params)
+
+	addr, err := tapScript.GetP2TRAddress()
+	if err != nil {
+		t.Fatalf("GetP2TRAddress failed: %v", err)
+	}
+	if !strings.HasPrefix(addr.EncodeAddress(), "tb1p") {
+		t.Errorf("Expected a tb1p address, got %s", addr.EncodeAddress())
+	}
+
+	// The address decodes back to a segwit v1 output paying the output key
+	decoded, err := btcutil.DecodeAddress(addr.EncodeAddress(), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("DecodeAddress failed: %v", err)
+	}
+	pkScript, err := tapScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	decodedScript, _ := txscript.PayToAddrScript(decoded)
+	if !bytes.Equal(decodedScript, pkScript) {
+		t.Errorf("Address script %x does not match output script %x", decodedScript, pkScript)
+	}
+	if txscript.GetScriptClass(pkScript) != txscript.WitnessV1TaprootTy {
+		t.Errorf("Expected a taproot output script, got %s", txscript.GetScriptClass(pkScript))
+	}
+
+	// The output key commits to the owner key and the inheritor leaf
+	internalKey, _ := schnorr.ParsePubKey(mustDecodeHex(t, testTaprootOwnerKey)[1:])
+	tree := txscript.AssembleTaprootScriptTree(txscript.NewBaseTapLeaf(tapScript.LeafScript))
+	rootHash := tree.RootNode.TapHash()
+	want := txscript.ComputeTaprootOutputKey(internalKey, rootHash[:])
+	if !bytes.Equal(pkScript[2:], schnorr.SerializePubKey(want)) {
+		t.Errorf("Output key %x does not commit to the script tree", pkScript[2:])
+	}
+
+	mainnet := newTestTaprootScript(t, &chaincfg.MainNetParams)
+	mainnetAddr, err := mainnet.GetP2TRAddress()
+	if err != nil {
+		t.Fatalf("GetP2TRAddress failed: %v", err)
+	}
+	if !strings.HasPrefix(mainnetAddr.EncodeAddress(), "bc1p") {
+		t.Errorf("Expected a bc1p address on mainnet, got %s", mainnetAddr.EncodeAddress())
+	}
+}
+
+func TestTaprootInheritanceScript_Leaf(t *testing.T) {
+	tapScript := newTestTaprootScript(t, &chaincfg.TestNet3Params)
+
+	disasm, err := txscript.DisasmString(tapScript.LeafScript)
+	if err != nil {
+		t.Fatalf("DisasmString failed: %v", err)
+	}
+	want := "a77640 OP_CHECKSEQUENCEVERIFY OP_DROP " + testTaprootInheritorKey[2:] + " OP_CHECKSIG"
+	if disasm != want {
+		t.Errorf("Expected leaf %q, got %q", want, disasm)
+	}
+	if tapScript.RelativeTimelock != mustRelativeTimelock(t, 180) {
+		t.Errorf("Expected timelock %d, got %d", mustRelativeTimelock(t, 180), tapScript.RelativeTimelock)
+	}
+
+	// The control block proves the leaf is in the output key
+	controlBlockBytes, err := tapScript.ControlBlock()
+	if err != nil {
+		t.Fatalf("ControlBlock failed: %v", err)
+	}
+	controlBlock, err := txscript.ParseControlBlock(controlBlockBytes)
+	if err != nil {
+		t.Fatalf("ParseControlBlock failed: %v", err)
+	}
+	pkScript, _ := tapScript.GetScriptPubKey()
+	if err := txscript.VerifyTaprootLeafCommitment(controlBlock, pkScript[2:], tapScript.LeafScript); err != nil {
+		t.Errorf("Leaf commitment does not verify: %v", err)
+	}
+}
+
+func TestNewTaprootInheritanceScript_Errors(t *testing.T) {
+	ownerPubKey := mustDecodeHex(t, testTaprootOwnerKey)
+	inheritorPubKey := mustDecodeHex(t, testTaprootInheritorKey)
+	// The same x-only key as the owner, with the other parity
+	oddOwner := append([]byte{0x03}, ownerPubKey[1:]...)
+
+	tests := []struct {
+		name      string
+		owner     []byte
+		inheritor []byte
+		days      int64
+		opts      []Option
+		wantErr   string
+	}{
+		{"short timelock", ownerPubKey, inheritorPubKey, 1, nil, "below the safety minimum"},
+		{"absolute timelock", ownerPubKey, inheritorPubKey, 180, []Option{WithAbsoluteTimelock(4102444800)}, "relative timelocks only"},
+		{"same x-only key", ownerPubKey, oddOwner, 180, nil, "identical"},
+		{"uncompressed owner key", make([]byte, 65), inheritorPubKey, 180, nil, "invalid owner public key"},
+		{"invalid inheritor key", ownerPubKey, make([]byte, 33), 180, nil, "invalid inheritor public key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTaprootInheritanceScript(tt.owner, tt.inheritor, tt.days, &chaincfg.TestNet3Params, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Failed to decode hex: %v", err)
+	}
+	return b
+}
@@ -0,0 +1,173 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// InheritorTier is one inheritor of a tiered contract, who may spend once
+// the tier's relative timelock has passed since funding
+type InheritorTier struct {
+	PubKey           []byte
+	TimelockDays     int64
+	RelativeTimelock int64 // BIP 68 value, set by NewTieredInheritanceScript
+}
+
+// NewTieredInheritanceScript creates an inheritance script with an ordered
+// list of inheritors, each locked for longer than the one before, so a later
+// tier can only spend if the earlier ones never claimed the funds:
+//
+//	OP_IF
+//	    <Owner_PublicKey> OP_CHECKSIG
+//	OP_ELSE
+//	    OP_IF
+//	        <Tier1_Timelock> OP_CHECKSEQUENCEVERIFY OP_DROP
+//	        <Tier1_PublicKey> OP_CHECKSIG
+//	    OP_ELSE
+//	        <Tier2_Timelock> OP_CHECKSEQUENCEVERIFY OP_DROP
+//	        <Tier2_PublicKey> OP_CHECKSIG
+//	    OP_ENDIF
+//	OP_ENDIF
+//
+// Each further tier nests another OP_IF in the innermost OP_ELSE. A single
+// tier gives the script of NewInheritanceScript. Timelocks are in days only,
+// so the seconds, blocks and absolute timelock options are rejected.
+func NewTieredInheritanceScript(ownerPubKey []byte, tiers []InheritorTier, chainParams *chaincfg.Params, opts ...Option) (*InheritanceScript, error) {
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("at least one inheritor tier is required")
+	}
+	if len(tiers) == 1 {
+		return NewInheritanceScript(ownerPubKey, tiers[0].PubKey, tiers[0].TimelockDays, chainParams, opts...)
+	}
+
+	options := scriptOptions{minTimelockDays: DefaultMinTimelockDays}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.timelockType == Absolute || options.timelockSeconds != 0 || options.timelockBlocks != 0 {
+		return nil, fmt.Errorf("tiered contracts take a timelock in days for each tier")
+	}
+
+	resolved := make([]InheritorTier, len(tiers))
+	for i, tier := range tiers {
+		if err := validatePubKey(tier.PubKey); err != nil {
+			return nil, fmt.Errorf("invalid tier %d public key: %w", i+1, err)
+		}
+		if !options.allowSameKey && bytes.Equal(tier.PubKey, ownerPubKey) {
+			return nil, fmt.Errorf("tier %d public key is the owner's, which makes its spend path redundant", i+1)
+		}
+		for j, earlier := range tiers[:i] {
+			if bytes.Equal(tier.PubKey, earlier.PubKey) {
+				return nil, fmt.Errorf("tier %d public key is also tier %d's", i+1, j+1)
+			}
+			if tier.TimelockDays <= earlier.TimelockDays {
+				return nil, fmt.Errorf("tier %d timelock of %d days must be longer than tier %d's %d days",
+					i+1, tier.TimelockDays, j+1, earlier.TimelockDays)
+			}
+		}
+
+		relativeTimelock, _, err := resolveRelativeTimelock(tier.TimelockDays, options)
+		if err != nil {
+			return nil, fmt.Errorf("tier %d: %w", i+1, err)
+		}
+		resolved[i] = InheritorTier{PubKey: tier.PubKey, TimelockDays: tier.TimelockDays, RelativeTimelock: relativeTimelock}
+	}
+
+	redeemScript, err := buildTieredRedeemScript(ownerPubKey, resolved, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build redeem script: %w", err)
+	}
+
+	for i, tier := range resolved {
+		log.Printf("Inheritor tier %d: %d days (%d BIP68 value)", i+1, tier.TimelockDays, tier.RelativeTimelock)
+	}
+	log.Printf("Redeem script hex: %x", redeemScript)
+
+	return &InheritanceScript{
+		OwnerPubKey:      ownerPubKey,
+		OwnerPubKeys:     options.ownerPubKeys,
+		OwnerRequired:    options.ownerRequired,
+		InheritorPubKey:  resolved[0].PubKey,
+		TimelockType:     Relative,
+		RelativeTimelock: resolved[0].RelativeTimelock,
+		RedeemScript:     redeemScript,
+		ChainParams:      chainParams,
+		Tiers:            resolved,
+	}, nil
+}
+
+// buildTieredRedeemScript constructs the redeem script of
+// NewTieredInheritanceScript
+func buildTieredRedeemScript(ownerPubKey []byte, tiers []InheritorTier, options scriptOptions) ([]byte, error) {
+	builder := txscript.NewScriptBuilder()
+
+	builder.AddOp(txscript.OP_IF)
+	addOwnerBranch(builder, ownerPubKey, options)
+	builder.AddOp(txscript.OP_ELSE)
+
+	// Every tier but the last takes an OP_IF branch; the last one is the
+	// innermost OP_ELSE
+	for i, tier := range tiers {
+		if i < len(tiers)-1 {
+			builder.AddOp(txscript.OP_IF)
+		}
+		builder.AddInt64(tier.RelativeTimelock)
+		builder.AddOp(txscript.OP_CHECKSEQUENCEVERIFY)
+		builder.AddOp(txscript.OP_DROP)
+		builder.AddData(tier.PubKey)
+		builder.AddOp(txscript.OP_CHECKSIG)
+		if i < len(tiers)-1 {
+			builder.AddOp(txscript.OP_ELSE)
+		}
+	}
+
+	for range tiers {
+		builder.AddOp(txscript.OP_ENDIF)
+	}
+
+	return builder.Script()
+}
+
+// InheritorTiers returns the inheritors of the script in the order they may
+// spend; a script with a single inheritor has one tier
+func (is *InheritanceScript) InheritorTiers() []InheritorTier {
+	if len(is.Tiers) > 0 {
+		return is.Tiers
+	}
+	return []InheritorTier{{
+		PubKey:           is.InheritorPubKey,
+		TimelockDays:     is.TimelockDays(),
+		RelativeTimelock: is.RelativeTimelock,
+	}}
+}
+
+// InheritorSelectors returns the witness items between the signature and the
+// redeem script that select the spend path of inheritor tier (0 for the
+// first). The top of the stack, the last item, picks the outer OP_ELSE; each
+// further item picks a branch one level deeper.
+func (is *InheritanceScript) InheritorSelectors(tier int) ([][]byte, error) {
+	count := len(is.InheritorTiers())
+	if tier < 0 || tier >= count {
+		return nil, fmt.Errorf("tier %d does not exist; the contract has %d inheritor tiers", tier+1, count)
+	}
+
+	var selectors [][]byte
+	if tier < count-1 {
+		// The OP_IF of this tier's level
+		selectors = append(selectors, OwnerSelector)
+	}
+	for i := 0; i <= tier; i++ {
+		selectors = append(selectors, InheritorSelector)
+	}
+	return selectors, nil
+}
+
+// relativeTimelockDays returns the whole days of a BIP 68 value, rounded
+func relativeTimelockDays(relativeTimelock int64) int64 {
+	return int64((RelativeTimelockDuration(relativeTimelock) + 12*time.Hour) / (24 * time.Hour))
+}
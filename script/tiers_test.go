@@ -0,0 +1,156 @@
+package script
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func createTestTiers(t *testing.T) ([]byte, []InheritorTier) {
+	t.Helper()
+
+	var pubKeys [][]byte
+	for i := 0; i < 3; i++ {
+		key, err := btcec.NewPrivateKey()
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		pubKeys = append(pubKeys, key.PubKey().SerializeCompressed())
+	}
+	return pubKeys[0], []InheritorTier{
+		{PubKey: pubKeys[1], TimelockDays: 180},
+		{PubKey: pubKeys[2], TimelockDays: 365},
+	}
+}
+
+func TestNewTieredInheritanceScript(t *testing.T) {
+	ownerPubKey, tiers := createTestTiers(t)
+
+	is, err := NewTieredInheritanceScript(ownerPubKey, tiers, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewTieredInheritanceScript failed: %v", err)
+	}
+	if err := is.ValidateScript(); err != nil {
+		t.Errorf("ValidateScript failed: %v", err)
+	}
+
+	disasm, err := is.Disassemble()
+	if err != nil {
+		t.Fatalf("Disassemble failed: %v", err)
+	}
+	if !strings.Contains(disasm, "OP_ELSE OP_IF") || !strings.HasSuffix(disasm, "OP_CHECKSIG OP_ENDIF OP_ENDIF") {
+		t.Errorf("Expected a nested OP_IF in the ELSE branch, got %s", disasm)
+	}
+
+	// The tiers round-trip through ParseRedeemScript
+	parsed, err := ParseRedeemScript(is.RedeemScript, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("ParseRedeemScript failed: %v", err)
+	}
+	if len(parsed.Tiers) != 2 {
+		t.Fatalf("Expected 2 tiers, got %d", len(parsed.Tiers))
+	}
+	for i, tier := range tiers {
+		if !bytes.Equal(parsed.Tiers[i].PubKey, tier.PubKey) {
+			t.Errorf("Tier %d: expected key %x, got %x", i+1, tier.PubKey, parsed.Tiers[i].PubKey)
+		}
+		if parsed.Tiers[i].RelativeTimelock != mustRelativeTimelock(t, tier.TimelockDays) {
+			t.Errorf("Tier %d: expected timelock %d, got %d", i+1, mustRelativeTimelock(t, tier.TimelockDays), parsed.Tiers[i].RelativeTimelock)
+		}
+		if parsed.Tiers[i].TimelockDays != tier.TimelockDays {
+			t.Errorf("Tier %d: expected %d days, got %d", i+1, tier.TimelockDays, parsed.Tiers[i].TimelockDays)
+		}
+	}
+	if !bytes.Equal(parsed.InheritorPubKey, tiers[0].PubKey) || parsed.RelativeTimelock != parsed.Tiers[0].RelativeTimelock {
+		t.Errorf("Expected the first tier as the inheritor")
+	}
+
+	// A single tier is the standard script
+	single, err := NewTieredInheritanceScript(ownerPubKey, tiers[:1], &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewTieredInheritanceScript with one tier failed: %v", err)
+	}
+	standard, err := NewInheritanceScript(ownerPubKey, tiers[0].PubKey, 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	if !bytes.Equal(single.RedeemScript, standard.RedeemScript) || single.Tiers != nil {
+		t.Errorf("Expected a single tier to build the standard script")
+	}
+}
+
+func TestInheritorSelectors(t *testing.T) {
+	ownerPubKey, tiers := createTestTiers(t)
+	is, err := NewTieredInheritanceScript(ownerPubKey, tiers, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewTieredInheritanceScript failed: %v", err)
+	}
+	standard, err := NewInheritanceScript(ownerPubKey, tiers[0].PubKey, 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		script *InheritanceScript
+		tier   int
+		want   [][]byte
+	}{
+		{"single inheritor", standard, 0, [][]byte{InheritorSelector}},
+		{"first of two tiers", is, 0, [][]byte{OwnerSelector, InheritorSelector}},
+		{"second of two tiers", is, 1, [][]byte{InheritorSelector, InheritorSelector}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.script.InheritorSelectors(tt.tier)
+			if err != nil {
+				t.Fatalf("InheritorSelectors failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d selectors, got %d", len(tt.want), len(got))
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tt.want[i]) {
+					t.Errorf("Selector %d: expected %x, got %x", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+
+	if _, err := is.InheritorSelectors(2); err == nil {
+		t.Errorf("Expected an error for a tier that does not exist")
+	}
+}
+
+func TestNewTieredInheritanceScript_Errors(t *testing.T) {
+	ownerPubKey, tiers := createTestTiers(t)
+
+	tests := []struct {
+		name    string
+		tiers   []InheritorTier
+		opts    []Option
+		wantErr string
+	}{
+		{"no tiers", nil, nil, "at least one"},
+		{"not increasing", []InheritorTier{tiers[1], {PubKey: tiers[0].PubKey, TimelockDays: 365}}, nil, "must be longer"},
+		{"same key twice", []InheritorTier{tiers[0], {PubKey: tiers[0].PubKey, TimelockDays: 365}}, nil, "also tier 1's"},
+		{"owner key", []InheritorTier{tiers[0], {PubKey: ownerPubKey, TimelockDays: 365}}, nil, "owner's"},
+		{"tier beyond BIP 68", []InheritorTier{tiers[0], {PubKey: tiers[1].PubKey, TimelockDays: 400}}, nil, "tier 2: timelock of 400 days exceeds the BIP 68 maximum"},
+		{"short timelock", []InheritorTier{{PubKey: tiers[0].PubKey, TimelockDays: 1}, tiers[1]}, nil, "below the safety minimum"},
+		{"absolute timelock", tiers, []Option{WithAbsoluteTimelock(4102444800)}, "in days"},
+		{"invalid key", []InheritorTier{tiers[0], {PubKey: make([]byte, 33), TimelockDays: 365}}, nil, "invalid tier 2 public key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTieredInheritanceScript(ownerPubKey, tt.tiers, &chaincfg.TestNet3Params, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
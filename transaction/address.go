@@ -0,0 +1,92 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// ErrWrongNetwork is returned for a destination address that belongs to a
+// different network than the one configured
+var ErrWrongNetwork = errors.New("address is for a different network")
+
+// DestinationType classifies a destination address by the output it pays to
+type DestinationType string
+
+const (
+	DestinationP2PKH  DestinationType = "P2PKH"
+	DestinationP2SH   DestinationType = "P2SH"
+	DestinationP2WPKH DestinationType = "P2WPKH"
+	DestinationP2WSH  DestinationType = "P2WSH"
+	DestinationP2TR   DestinationType = "P2TR"
+)
+
+// knownNetworks are tried when an address does not decode for the configured
+// network, to tell which network it belongs to. Some share their encodings,
+// e.g. testnet and signet both use the tb prefix, so an address can match
+// several of them.
+var knownNetworks = []*chaincfg.Params{
+	&chaincfg.MainNetParams,
+	&chaincfg.TestNet3Params,
+	&chaincfg.RegressionNetParams,
+	&chaincfg.SigNetParams,
+}
+
+// ClassifyDestination returns the type of the output addr pays to. Addresses
+// that are not one of the standard single-key or script types (e.g. raw
+// public keys or future witness versions) are rejected. The dust threshold
+// and size estimates work from the output script itself, so the type is
+// only used to refuse those addresses and to report the destination.
+func ClassifyDestination(addr btcutil.Address) (DestinationType, error) {
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return DestinationP2PKH, nil
+	case *btcutil.AddressScriptHash:
+		return DestinationP2SH, nil
+	case *btcutil.AddressWitnessPubKeyHash:
+		return DestinationP2WPKH, nil
+	case *btcutil.AddressWitnessScriptHash:
+		return DestinationP2WSH, nil
+	case *btcutil.AddressTaproot:
+		return DestinationP2TR, nil
+	default:
+		return "", fmt.Errorf("unsupported address type %T for %s (expected P2PKH, P2SH, P2WPKH, P2WSH or P2TR)", addr, addr.String())
+	}
+}
+
+// DecodeDestination decodes a destination address, checks that it belongs
+// to chainParams and classifies it. An address of another network fails
+// with ErrWrongNetwork, naming every network it could be for.
+func DecodeDestination(address string, chainParams *chaincfg.Params) (btcutil.Address, DestinationType, error) {
+	addr, err := btcutil.DecodeAddress(address, chainParams)
+	if err != nil || !addr.IsForNet(chainParams) {
+		if otherNets := addressNetworks(address); len(otherNets) > 0 {
+			return nil, "", fmt.Errorf("%w: %s is a %s address, but the configured network is %s",
+				ErrWrongNetwork, address, strings.Join(otherNets, "/"), chainParams.Name)
+		}
+		if err == nil {
+			err = ErrWrongNetwork
+		}
+		return nil, "", fmt.Errorf("failed to decode address %s: %w", address, err)
+	}
+
+	addrType, err := ClassifyDestination(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	return addr, addrType, nil
+}
+
+// addressNetworks returns the names of the known networks address decodes for
+func addressNetworks(address string) []string {
+	var names []string
+	for _, params := range knownNetworks {
+		if addr, err := btcutil.DecodeAddress(address, params); err == nil && addr.IsForNet(params) {
+			names = append(names, params.Name)
+		}
+	}
+	return names
+}
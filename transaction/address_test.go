@@ -0,0 +1,94 @@
+package transaction
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestDecodeDestination(t *testing.T) {
+	params := &chaincfg.TestNet3Params
+
+	key, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	p2pkh, _ := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	p2sh, _ := btcutil.NewAddressScriptHashFromHash(make([]byte, 20), params)
+	p2wpkh, _ := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), params)
+	p2wsh, _ := btcutil.NewAddressWitnessScriptHash(make([]byte, 32), params)
+	p2tr, _ := btcutil.NewAddressTaproot(make([]byte, 32), params)
+	p2pk, _ := btcutil.NewAddressPubKey(key.PubKey().SerializeCompressed(), params)
+	mainnetP2WPKH, _ := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.MainNetParams)
+	mainnetP2PKH, _ := btcutil.NewAddressPubKeyHash(make([]byte, 20), &chaincfg.MainNetParams)
+
+	tests := []struct {
+		name     string
+		address  string
+		expected DestinationType
+		wantErr  string
+	}{
+		{"P2PKH", p2pkh.EncodeAddress(), DestinationP2PKH, ""},
+		{"P2SH", p2sh.EncodeAddress(), DestinationP2SH, ""},
+		{"P2WPKH", p2wpkh.EncodeAddress(), DestinationP2WPKH, ""},
+		{"P2WSH", p2wsh.EncodeAddress(), DestinationP2WSH, ""},
+		{"P2TR", p2tr.EncodeAddress(), DestinationP2TR, ""},
+		{"raw public key", p2pk.String(), "", "unsupported address type"},
+		{"mainnet P2WPKH", mainnetP2WPKH.EncodeAddress(), "", "is a mainnet address"},
+		{"mainnet P2PKH", mainnetP2PKH.EncodeAddress(), "", "is a mainnet address"},
+		{"garbage", "not-an-address", "", "failed to decode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, addrType, err := DecodeDestination(tt.address, params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if strings.HasPrefix(tt.name, "mainnet") && !errors.Is(err, ErrWrongNetwork) {
+					t.Errorf("Expected ErrWrongNetwork, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeDestination failed: %v", err)
+			}
+			if addrType != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, addrType)
+			}
+			if addr.EncodeAddress() != tt.address {
+				t.Errorf("Expected address %s, got %s", tt.address, addr.EncodeAddress())
+			}
+		})
+	}
+}
+
+func TestDecodeDestination_SharedEncodings(t *testing.T) {
+	testnetP2WPKH, _ := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.TestNet3Params)
+	testnetP2PKH, _ := btcutil.NewAddressPubKeyHash(make([]byte, 20), &chaincfg.TestNet3Params)
+	regtestP2WPKH, _ := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.RegressionNetParams)
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr string
+	}{
+		{"tb prefix", testnetP2WPKH.EncodeAddress(), "is a testnet3/signet address"},
+		{"base58 test prefix", testnetP2PKH.EncodeAddress(), "is a testnet3/regtest/signet address"},
+		{"bcrt prefix", regtestP2WPKH.EncodeAddress(), "is a regtest address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := DecodeDestination(tt.address, &chaincfg.MainNetParams)
+			if !errors.Is(err, ErrWrongNetwork) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected ErrWrongNetwork containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package transaction
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// p2wpkhWitness has the size of a worst-case P2WPKH witness: a 73-byte
+// signature and a 33-byte compressed public key
+var p2wpkhWitness = wire.TxWitness{make([]byte, 73), make([]byte, 33)}
+
+// EstimateCPFPChildVSize estimates the virtual size of a signed child
+// spending one P2WPKH output to destinationScript
+func EstimateCPFPChildVSize(destinationScript []byte) int64 {
+	tx := wire.NewMsgTx(txVersion)
+	txIn := wire.NewTxIn(&wire.OutPoint{}, nil, p2wpkhWitness)
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, destinationScript))
+	return VirtualSize(tx)
+}
+
+// CPFPChildFeeRate returns the fee rate the child must pay on its own size
+// so that parent and child together pay packageFeeRate. It fails when the
+// parent alone already pays that rate, since a child would not help.
+func CPFPChildFeeRate(parentFee btcutil.Amount, parentVSize, childVSize int64, packageFeeRate float64) (float64, error) {
+	if float64(parentFee) >= float64(parentVSize)*packageFeeRate {
+		return 0, fmt.Errorf("parent already pays %.2f sat/vB, at or above the requested %.2f sat/vB",
+			float64(parentFee)/float64(parentVSize), packageFeeRate)
+	}
+	packageFee := math.Ceil(float64(parentVSize+childVSize) * packageFeeRate)
+	return (packageFee - float64(parentFee)) / float64(childVSize), nil
+}
+
+// BuildCPFPChildTx builds and signs a child transaction spending parentOut, a
+// P2WPKH output of an unconfirmed withdrawal paying privKey's key, to
+// destinationAddr. The child pays feeRate (sat/vB) on its own size; use
+// CPFPChildFeeRate to derive it from a target rate for the package. The
+// input signals replaceability so the child itself can be bumped.
+func (tb *TransactionBuilder) BuildCPFPChildTx(
+	parentOut *UTXO,
+	destinationAddr btcutil.Address,
+	privKey *btcec.PrivateKey,
+	feeRate float64,
+) (*wire.MsgTx, error) {
+
+	pubKeyHash := btcutil.Hash160(privKey.PubKey().SerializeCompressed())
+	keyAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, tb.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive P2WPKH address: %w", err)
+	}
+	keyScript, err := txscript.PayToAddrScript(keyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create P2WPKH script: %w", err)
+	}
+	if parentOut.PkScript != nil && string(parentOut.PkScript) != string(keyScript) {
+		return nil, fmt.Errorf("parent output %s:%d does not pay the key's address %s",
+			parentOut.TxHash, parentOut.Vout, keyAddr.EncodeAddress())
+	}
+
+	destinationScript, err := txscript.PayToAddrScript(destinationAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination script: %w", err)
+	}
+
+	fee := btcutil.Amount(math.Ceil(float64(EstimateCPFPChildVSize(destinationScript)) * feeRate))
+	outputAmount := parentOut.Amount - fee
+	if outputAmount <= 0 {
+		return nil, fmt.Errorf("insufficient funds: fee (%v) exceeds parent output amount (%v)", fee, parentOut.Amount)
+	}
+	if err := checkDust(outputAmount, destinationScript); err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(txVersion)
+	txIn := wire.NewTxIn(wire.NewOutPoint(parentOut.TxHash, parentOut.Vout), nil, nil)
+	txIn.Sequence = RBFSequence
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(int64(outputAmount), destinationScript))
+
+	prevOuts := txscript.NewCannedPrevOutputFetcher(keyScript, int64(parentOut.Amount))
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	witness, err := txscript.WitnessSignature(tx, sigHashes, 0, int64(parentOut.Amount),
+		keyScript, txscript.SigHashAll, privKey, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign child transaction: %w", err)
+	}
+	tx.TxIn[0].Witness = witness
+
+	engine, err := txscript.NewEngine(keyScript, tx, 0, txscript.StandardVerifyFlags,
+		nil, sigHashes, int64(parentOut.Amount), prevOuts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create script engine: %w", err)
+	}
+	if err := engine.Execute(); err != nil {
+		return nil, fmt.Errorf("signed child does not satisfy the parent output: %w", err)
+	}
+
+	log.Printf("Built CPFP child transaction")
+	log.Printf("  Input: %s:%d (%v satoshis)", parentOut.TxHash, parentOut.Vout, parentOut.Amount)
+	log.Printf("  Output: %s (%v satoshis)", destinationAddr.EncodeAddress(), outputAmount)
+	log.Printf("  Fee: %v satoshis (%.2f sat/vB)", fee, feeRate)
+
+	return tx, nil
+}
@@ -0,0 +1,112 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestCPFPChildFeeRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		parentFee   btcutil.Amount
+		parentVSize int64
+		childVSize  int64
+		target      float64
+		expected    float64
+		wantErr     bool
+	}{
+		{"parent at 1 sat/vB", 200, 200, 100, 5, 13, false},
+		{"free parent", 0, 200, 100, 2, 6, false},
+		{"parent already at target", 1000, 200, 100, 5, 0, true},
+		{"parent above target", 2000, 200, 100, 5, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := CPFPChildFeeRate(tt.parentFee, tt.parentVSize, tt.childVSize, tt.target)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %.2f sat/vB", rate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rate != tt.expected {
+				t.Errorf("Expected %.2f sat/vB, got %.2f", tt.expected, rate)
+			}
+		})
+	}
+}
+
+func TestBuildCPFPChildTx(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keyAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(privKey.PubKey().SerializeCompressed()), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to create address: %v", err)
+	}
+	keyScript, err := txscript.PayToAddrScript(keyAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+	parentHash, err := chainhash.NewHashFromStr("6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807")
+	if err != nil {
+		t.Fatalf("Failed to parse parent hash: %v", err)
+	}
+	destAddr := createTestDestination(t)
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, 0)
+
+	parentOut := &UTXO{TxHash: parentHash, Vout: 1, Amount: 50000, PkScript: keyScript}
+	tx, err := txBuilder.BuildCPFPChildTx(parentOut, destAddr, privKey, 20)
+	if err != nil {
+		t.Fatalf("BuildCPFPChildTx failed: %v", err)
+	}
+
+	if len(tx.TxIn) != 1 || tx.TxIn[0].PreviousOutPoint != *wire.NewOutPoint(parentHash, 1) {
+		t.Fatalf("Child does not spend the parent output: %+v", tx.TxIn)
+	}
+	if tx.TxIn[0].Sequence != RBFSequence {
+		t.Errorf("Expected sequence 0x%08x, got 0x%08x", RBFSequence, tx.TxIn[0].Sequence)
+	}
+
+	// The estimate assumes the largest signature, so the signed child pays
+	// at least the requested rate
+	fee := parentOut.Amount - btcutil.Amount(tx.TxOut[0].Value)
+	if vsize := EstimateCPFPChildVSize(destScript); fee != btcutil.Amount(vsize*20) {
+		t.Errorf("Expected fee %d for %d vbytes at 20 sat/vB, got %d", vsize*20, vsize, fee)
+	}
+	if rate := float64(fee) / float64(VirtualSize(tx)); rate < 20 {
+		t.Errorf("Signed child pays %.2f sat/vB, below 20", rate)
+	}
+
+	// Another key's output cannot be spent
+	otherKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if _, err := txBuilder.BuildCPFPChildTx(parentOut, destAddr, otherKey, 20); err == nil || !strings.Contains(err.Error(), "does not pay") {
+		t.Errorf("Expected an error for another key's output, got %v", err)
+	}
+
+	// A fee consuming the output is rejected
+	small := &UTXO{TxHash: parentHash, Vout: 1, Amount: 1000, PkScript: keyScript}
+	if _, err := txBuilder.BuildCPFPChildTx(small, destAddr, privKey, 20); err == nil {
+		t.Error("Expected an error for a fee exceeding the output")
+	}
+}
@@ -0,0 +1,47 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ErrDustOutput is returned when a withdrawal output would be below the dust
+// limit of its script, which nodes reject as non-standard
+var ErrDustOutput = errors.New("output below dust limit")
+
+// dustRelayFeeRate is Bitcoin Core's default dust relay fee rate in sat/vB
+const dustRelayFeeRate = 3
+
+// DustThreshold returns the smallest value an output paying to pkScript can
+// carry without being rejected as dust by Bitcoin Core's default policy. The
+// threshold covers the cost of creating the output and later spending it, so
+// it depends on the script type: 546 sat for P2PKH, 540 for P2SH, 294 for
+// P2WPKH and 330 for P2WSH and P2TR.
+func DustThreshold(pkScript []byte) btcutil.Amount {
+	// Serialized output: 8-byte value, script length and script
+	outputSize := int64(8 + wire.VarIntSerializeSize(uint64(len(pkScript))) + len(pkScript))
+
+	// Size of the input spending it: outpoint, script length and sequence,
+	// plus a typical signature and pubkey (discounted for witness programs)
+	spendSize := int64(32 + 4 + 1 + 4)
+	if txscript.IsWitnessProgram(pkScript) {
+		spendSize += 107 / blockchain.WitnessScaleFactor
+	} else {
+		spendSize += 107
+	}
+
+	return btcutil.Amount((outputSize + spendSize) * dustRelayFeeRate)
+}
+
+// checkDust rejects an output whose value is below the dust threshold of its script
+func checkDust(value btcutil.Amount, pkScript []byte) error {
+	if threshold := DustThreshold(pkScript); value < threshold {
+		return fmt.Errorf("%w: %v is below the threshold of %v for this address type", ErrDustOutput, value, threshold)
+	}
+	return nil
+}
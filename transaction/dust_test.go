@@ -0,0 +1,120 @@
+package transaction
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+func TestDustThreshold(t *testing.T) {
+	params := &chaincfg.TestNet3Params
+
+	p2pkh, _ := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	p2sh, _ := btcutil.NewAddressScriptHashFromHash(make([]byte, 20), params)
+	p2wpkh, _ := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), params)
+	p2wsh, _ := btcutil.NewAddressWitnessScriptHash(make([]byte, 32), params)
+	p2tr, _ := btcutil.NewAddressTaproot(make([]byte, 32), params)
+
+	tests := []struct {
+		name     string
+		addr     btcutil.Address
+		expected btcutil.Amount
+	}{
+		{"P2PKH", p2pkh, 546},
+		{"P2SH", p2sh, 540},
+		{"P2WPKH", p2wpkh, 294},
+		{"P2WSH", p2wsh, 330},
+		{"P2TR", p2tr, 330},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkScript, err := txscript.PayToAddrScript(tt.addr)
+			if err != nil {
+				t.Fatalf("PayToAddrScript failed: %v", err)
+			}
+			if got := DustThreshold(pkScript); got != tt.expected {
+				t.Errorf("Expected dust threshold %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildOwnerWithdrawTx_RejectsDust(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	destAddr := createTestDestination(t)
+
+	// 100000 - 99800 leaves 200 sat, below the 294 sat P2WPKH threshold
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(99800))
+	_, err := txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, 0, false)
+	if err == nil || !strings.Contains(err.Error(), "dust") {
+		t.Fatalf("Expected dust error, got %v", err)
+	}
+
+	// 300 sat clears the P2WPKH threshold
+	txBuilder = NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(99700))
+	if _, err := txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, 0, false); err != nil {
+		t.Errorf("Unexpected error for output above threshold: %v", err)
+	}
+}
+
+func TestWithdraw_DustLimit(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	destAddr := createTestDestination(t)
+	redeemScript := inheritanceScript.RedeemScript
+
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+	limit := DustThreshold(destScript)
+	if limit != 294 {
+		t.Fatalf("Expected the P2WPKH dust limit of 294 sat, got %d", limit)
+	}
+
+	builds := []struct {
+		name  string
+		build func(*TransactionBuilder) error
+	}{
+		{"owner", func(tb *TransactionBuilder) error {
+			_, err := tb.BuildOwnerWithdrawTx(utxo, destAddr, redeemScript, 0, false)
+			return err
+		}},
+		{"inheritor", func(tb *TransactionBuilder) error {
+			_, err := tb.BuildInheritorWithdrawTx(utxo, destAddr, redeemScript, inheritanceScript.RelativeTimelock, 0)
+			return err
+		}},
+	}
+	outputs := []struct {
+		name    string
+		output  btcutil.Amount
+		wantErr bool
+	}{
+		{"just below", limit - 1, true},
+		{"at the limit", limit, false},
+		{"just above", limit + 1, false},
+	}
+
+	for _, build := range builds {
+		for _, tt := range outputs {
+			t.Run(build.name+" "+tt.name, func(t *testing.T) {
+				// The fee leaves exactly tt.output for the destination
+				txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, utxo.Amount-tt.output)
+				err := build.build(txBuilder)
+				if tt.wantErr {
+					if !errors.Is(err, ErrDustOutput) {
+						t.Errorf("Expected ErrDustOutput, got %v", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Errorf("Unexpected error for a %d sat output: %v", tt.output, err)
+				}
+			})
+		}
+	}
+}
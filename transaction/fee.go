@@ -0,0 +1,118 @@
+package transaction
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// FeeSource identifies which setting determines a withdrawal fee
+type FeeSource int
+
+const (
+	// FeeFromDefault uses the configured default flat fee
+	FeeFromDefault FeeSource = iota
+	// FeeFromFlag uses the flat fee given with --fee
+	FeeFromFlag
+	// FeeFromRate derives the fee from the fee rate given with --fee-rate
+	FeeFromRate
+	// FeeFromDefaultRate derives the fee from the configured default fee rate
+	FeeFromDefaultRate
+)
+
+// IsDefault reports whether the fee comes from the configuration rather than
+// a command line flag
+func (s FeeSource) IsDefault() bool {
+	return s == FeeFromDefault || s == FeeFromDefaultRate
+}
+
+// IsRate reports whether the fee is derived from a fee rate
+func (s FeeSource) IsRate() bool {
+	return s == FeeFromRate || s == FeeFromDefaultRate
+}
+
+// FeeChoice is the single effective fee setting of a withdrawal
+type FeeChoice struct {
+	Source  FeeSource
+	Fee     btcutil.Amount // flat fee in satoshis, unless Source.IsRate()
+	FeeRate float64        // sat/vB when Source.IsRate()
+}
+
+// ResolveFee picks the effective fee from a flat fee, a fee rate and the
+// configured defaults. Zero means "not supplied". A fee rate wins over a flat
+// fee, and either flag wins over the defaults, of which the default fee rate
+// wins over the default flat fee.
+func ResolveFee(fee int64, feeRate float64, defaultFeeRate float64, defaultFee int64) (FeeChoice, error) {
+	switch {
+	case fee < 0:
+		return FeeChoice{}, fmt.Errorf("fee must not be negative")
+	case feeRate < 0:
+		return FeeChoice{}, fmt.Errorf("fee rate must not be negative")
+	case feeRate > 0:
+		return FeeChoice{Source: FeeFromRate, FeeRate: feeRate}, nil
+	case fee > 0:
+		return FeeChoice{Source: FeeFromFlag, Fee: btcutil.Amount(fee)}, nil
+	case defaultFeeRate > 0:
+		return FeeChoice{Source: FeeFromDefaultRate, FeeRate: defaultFeeRate}, nil
+	case defaultFee > 0:
+		return FeeChoice{Source: FeeFromDefault, Fee: btcutil.Amount(defaultFee)}, nil
+	default:
+		return FeeChoice{}, fmt.Errorf("no fee given and no default fee configured")
+	}
+}
+
+// FeeForRate returns the fee that makes a contract spend shaped like tx pay
+// the given fee rate in sat/vB once signed
+func FeeForRate(tx *wire.MsgTx, redeemScript []byte, feeRate float64) btcutil.Amount {
+	return EstimateFee(withEstimatedWitness(tx, redeemScript), feeRate)
+}
+
+// EstimateFee returns the fee that makes tx, with its witness as it stands,
+// pay satPerVByte. The witness counts at a quarter of its size, as in
+// VirtualSize; fractional satoshis are rounded up.
+func EstimateFee(tx *wire.MsgTx, satPerVByte float64) btcutil.Amount {
+	return btcutil.Amount(math.Ceil(float64(VirtualSize(tx)) * satPerVByte))
+}
+
+// EstimateSpendVSize estimates the virtual size of a signed contract spend
+// with a single input paying the given output scripts. Both spend paths put
+// a signature, a one-byte branch selector and the redeem script in the
+// witness, so the estimate holds for either path.
+func EstimateSpendVSize(redeemScript []byte, outputScripts ...[]byte) int64 {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	for _, pkScript := range outputScripts {
+		tx.AddTxOut(wire.NewTxOut(0, pkScript))
+	}
+	return estimateVirtualSize(tx, redeemScript)
+}
+
+// DefaultMaxFeeRate is the highest fee rate in sat/vB accepted unless
+// configured otherwise
+const DefaultMaxFeeRate = 1000
+
+// VirtualSize returns the virtual size of a signed transaction in vbytes
+func VirtualSize(tx *wire.MsgTx) int64 {
+	weight := blockchain.GetTransactionWeight(btcutil.NewTx(tx))
+	return (weight + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor
+}
+
+// CheckFeeRate rejects a fee whose effective rate over vsize exceeds
+// maxFeeRate sat/vB. A small UTXO with a flat fee can reach an absurd rate
+// even when the absolute fee looks modest. A maxFeeRate of zero disables
+// the check.
+func CheckFeeRate(fee btcutil.Amount, vsize int64, maxFeeRate float64) error {
+	if maxFeeRate <= 0 || vsize <= 0 {
+		return nil
+	}
+
+	feeRate := float64(fee) / float64(vsize)
+	if feeRate > maxFeeRate {
+		return fmt.Errorf("effective fee rate %.2f sat/vB (%d satoshis over %d vbytes) exceeds the maximum of %.2f sat/vB",
+			feeRate, int64(fee), vsize, maxFeeRate)
+	}
+	return nil
+}
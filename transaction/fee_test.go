@@ -0,0 +1,169 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+)
+
+func TestResolveFee(t *testing.T) {
+	tests := []struct {
+		name           string
+		fee            int64
+		feeRate        float64
+		defaultFeeRate float64
+		defaultFee     int64
+		expected       FeeChoice
+		wantErr        bool
+	}{
+		{"both supplied prefers the rate", 1000, 5, 0, 2000, FeeChoice{Source: FeeFromRate, FeeRate: 5}, false},
+		{"neither supplied", 0, 0, 0, 2000, FeeChoice{Source: FeeFromDefault, Fee: 2000}, false},
+		{"fee only", 1000, 0, 0, 2000, FeeChoice{Source: FeeFromFlag, Fee: 1000}, false},
+		{"fee rate only", 0, 5, 0, 2000, FeeChoice{Source: FeeFromRate, FeeRate: 5}, false},
+		{"default fee rate", 0, 0, 3, 2000, FeeChoice{Source: FeeFromDefaultRate, FeeRate: 3}, false},
+		{"fee overrides default fee rate", 1000, 0, 3, 2000, FeeChoice{Source: FeeFromFlag, Fee: 1000}, false},
+		{"fee rate overrides default fee rate", 0, 5, 3, 2000, FeeChoice{Source: FeeFromRate, FeeRate: 5}, false},
+		{"negative fee", -1, 0, 0, 2000, FeeChoice{}, true},
+		{"negative fee rate", 0, -1, 0, 2000, FeeChoice{}, true},
+		{"no default", 0, 0, 0, 0, FeeChoice{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choice, err := ResolveFee(tt.fee, tt.feeRate, tt.defaultFeeRate, tt.defaultFee)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", choice)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if choice != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, choice)
+			}
+		})
+	}
+}
+
+func TestFeeForRate(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, 0, false)
+	if err != nil {
+		t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+	}
+
+	vsize := estimateVirtualSize(tx, inheritanceScript.RedeemScript)
+	if fee := FeeForRate(tx, inheritanceScript.RedeemScript, 2); fee != btcutil.Amount(2*vsize) {
+		t.Errorf("Expected fee %d at 2 sat/vB, got %d", 2*vsize, fee)
+	}
+	if fee := FeeForRate(tx, inheritanceScript.RedeemScript, 1.5); fee < btcutil.Amount(vsize*3/2) {
+		t.Errorf("Fee %d at 1.5 sat/vB is below %d", fee, vsize*3/2)
+	}
+}
+
+func TestEstimateFee(t *testing.T) {
+	// A P2WPKH spend with one input and one P2WPKH output: 82 bytes
+	// stripped, 192 bytes with the 72-byte signature and 33-byte key, so
+	// 438 weight units or 110 vbytes
+	tx := wire.NewMsgTx(2)
+	txIn := wire.NewTxIn(&wire.OutPoint{}, nil, nil)
+	txIn.Witness = wire.TxWitness{make([]byte, 72), make([]byte, 33)}
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(90000, append([]byte{txscript.OP_0, 20}, make([]byte, 20)...)))
+
+	if vsize := VirtualSize(tx); vsize != 110 {
+		t.Fatalf("Expected 110 vbytes, got %d", vsize)
+	}
+
+	tests := []struct {
+		feeRate  float64
+		expected btcutil.Amount
+	}{
+		{1, 110},
+		{2, 220},
+		{1.5, 165},
+		{0.1, 11},
+		{2.01, 222},
+	}
+	for _, tt := range tests {
+		if fee := EstimateFee(tx, tt.feeRate); fee != tt.expected {
+			t.Errorf("EstimateFee at %.2f sat/vB: expected %d, got %d", tt.feeRate, tt.expected, fee)
+		}
+	}
+}
+
+func TestCheckFeeRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		fee        btcutil.Amount
+		vsize      int64
+		maxFeeRate float64
+		wantErr    bool
+	}{
+		{"below maximum", 999, 100, 10, false},
+		{"exactly at maximum", 1000, 100, 10, false},
+		{"one satoshi above maximum", 1001, 100, 10, true},
+		{"fractional maximum at boundary", 250, 100, 2.5, false},
+		{"fractional maximum exceeded", 251, 100, 2.5, true},
+		{"small UTXO with flat fee", 2000, 100, DefaultMaxFeeRate, false},
+		{"absurd effective rate", 500000, 100, DefaultMaxFeeRate, true},
+		{"check disabled", 500000, 100, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckFeeRate(tt.fee, tt.vsize, tt.maxFeeRate)
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected an error for %d satoshis over %d vbytes", tt.fee, tt.vsize)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEstimateSpendVSize(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	destAddr := createTestDestination(t)
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, 0, false)
+	if err != nil {
+		t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+	}
+	if err := txBuilder.SignOwnerTransaction(tx, utxo, inheritanceScript.RedeemScript, keys.NewLocalSigner(ownerKey)); err != nil {
+		t.Fatalf("SignOwnerTransaction failed: %v", err)
+	}
+
+	// The estimate assumes the largest signature, so it may exceed the
+	// signed size by at most one vbyte
+	estimate := EstimateSpendVSize(inheritanceScript.RedeemScript, destScript)
+	actual := VirtualSize(tx)
+	if estimate < actual || estimate > actual+1 {
+		t.Errorf("Expected an estimate of %d or %d vbytes, got %d", actual, actual+1, estimate)
+	}
+
+	// A second output adds its full size
+	twoOutputs := EstimateSpendVSize(inheritanceScript.RedeemScript, destScript, destScript)
+	if extra := twoOutputs - estimate; extra != int64(wire.NewTxOut(0, destScript).SerializeSize()) {
+		t.Errorf("Expected the second output to add %d vbytes, got %d", wire.NewTxOut(0, destScript).SerializeSize(), extra)
+	}
+}
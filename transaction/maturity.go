@@ -0,0 +1,69 @@
+package transaction
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// TimelockRemaining returns how much longer a time-based BIP 68 relative
+// timelock keeps a UTXO locked, or zero once a spend can be mined in the next
+// block. As in consensus, time is measured by median-time-past: from the MTP
+// of the block before the one that confirmed the UTXO to the MTP of the
+// current tip.
+func TimelockRemaining(relativeTimelock, fundingParentMTP, tipMTP int64) (time.Duration, error) {
+	if relativeTimelock&wire.SequenceLockTimeDisabled != 0 {
+		return 0, nil
+	}
+	if relativeTimelock&wire.SequenceLockTimeIsSeconds == 0 {
+		return 0, fmt.Errorf("timelock %d is block-based, not time-based", relativeTimelock)
+	}
+
+	lockSeconds := (relativeTimelock & wire.SequenceLockTimeMask) << wire.SequenceLockTimeGranularity
+	elapsed := tipMTP - fundingParentMTP
+	if elapsed >= lockSeconds {
+		return 0, nil
+	}
+
+	return time.Duration(lockSeconds-elapsed) * time.Second, nil
+}
+
+// BlocksRemaining returns how many more blocks a block-based BIP 68 relative
+// timelock keeps a UTXO locked, or zero once a spend can be mined in the next
+// block. A UTXO confirmed at fundingHeight with a lock of n blocks can be
+// spent in a block at height fundingHeight + n.
+func BlocksRemaining(relativeTimelock, fundingHeight, tipHeight int64) (int64, error) {
+	if relativeTimelock&wire.SequenceLockTimeDisabled != 0 {
+		return 0, nil
+	}
+	if relativeTimelock&wire.SequenceLockTimeIsSeconds != 0 {
+		return 0, fmt.Errorf("timelock %d is time-based, not block-based", relativeTimelock)
+	}
+
+	spendableHeight := fundingHeight + relativeTimelock&wire.SequenceLockTimeMask
+	if tipHeight+1 >= spendableHeight {
+		return 0, nil
+	}
+	return spendableHeight - (tipHeight + 1), nil
+}
+
+// TimelockBlocks returns how many confirmations a BIP 68 relative timelock
+// needs: the block count of a block-based lock, or for a time-based lock its
+// 512-second intervals converted at script.BlockInterval per block, rounded
+// up. The time-based figure is an estimate, as blocks are not evenly spaced.
+func TimelockBlocks(relativeTimelock int64) int64 {
+	if relativeTimelock&wire.SequenceLockTimeDisabled != 0 {
+		return 0
+	}
+
+	count := relativeTimelock & wire.SequenceLockTimeMask
+	if relativeTimelock&wire.SequenceLockTimeIsSeconds == 0 {
+		return count
+	}
+
+	lockSeconds := count << wire.SequenceLockTimeGranularity
+	blockSeconds := int64(script.BlockInterval / time.Second)
+	return (lockSeconds + blockSeconds - 1) / blockSeconds
+}
@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimelockRemaining(t *testing.T) {
+	// 10 intervals of 512 seconds, time-based
+	const relativeTimelock = 10 | 0x400000
+	const start = 1700000000
+
+	tests := []struct {
+		name     string
+		tipMTP   int64
+		expected time.Duration
+	}{
+		{"just funded", start, 5120 * time.Second},
+		{"partially elapsed", start + 5000, 120 * time.Second},
+		{"one second short", start + 5119, time.Second},
+		{"exactly mature", start + 5120, 0},
+		{"long expired", start + 100000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, err := TimelockRemaining(relativeTimelock, start, tt.tipMTP)
+			if err != nil {
+				t.Fatalf("TimelockRemaining failed: %v", err)
+			}
+			if remaining != tt.expected {
+				t.Errorf("Expected %v remaining, got %v", tt.expected, remaining)
+			}
+		})
+	}
+}
+
+func TestTimelockRemaining_BlockBased(t *testing.T) {
+	if _, err := TimelockRemaining(144, 0, 0); err == nil {
+		t.Error("Expected error for block-based timelock")
+	}
+}
+
+func TestBlocksRemaining(t *testing.T) {
+	const relativeTimelock = 144
+	const fundingHeight = 800000
+
+	tests := []struct {
+		name      string
+		tipHeight int64
+		expected  int64
+	}{
+		{"just funded", fundingHeight, 143},
+		{"one block short", fundingHeight + 142, 1},
+		{"mature in next block", fundingHeight + 143, 0},
+		{"long expired", fundingHeight + 1000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, err := BlocksRemaining(relativeTimelock, fundingHeight, tt.tipHeight)
+			if err != nil {
+				t.Fatalf("BlocksRemaining failed: %v", err)
+			}
+			if remaining != tt.expected {
+				t.Errorf("Expected %d blocks remaining, got %d", tt.expected, remaining)
+			}
+		})
+	}
+
+	if _, err := BlocksRemaining(10|0x400000, fundingHeight, fundingHeight); err == nil {
+		t.Error("Expected error for time-based timelock")
+	}
+}
+
+func TestTimelockBlocks(t *testing.T) {
+	tests := []struct {
+		name             string
+		relativeTimelock int64
+		expected         int64
+	}{
+		{"block-based", 144, 144},
+		{"one day of intervals", 169 | 0x400000, 145},
+		{"one interval", 1 | 0x400000, 1},
+		{"disabled", 1 << 31, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if blocks := TimelockBlocks(tt.relativeTimelock); blocks != tt.expected {
+				t.Errorf("Expected %d blocks, got %d", tt.expected, blocks)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package transaction
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// TotalAmount returns the sum of the amounts of utxos
+func TotalAmount(utxos []*UTXO) btcutil.Amount {
+	var total btcutil.Amount
+	for _, utxo := range utxos {
+		total += utxo.Amount
+	}
+	return total
+}
+
+// BuildOwnerWithdrawTxMulti builds a transaction for the owner to sweep
+// every funding UTXO of a contract to destinationAddr, paying the fee once.
+// rbf is as for BuildOwnerWithdrawTx. Sign it with SignOwnerTransactionMulti.
+func (tb *TransactionBuilder) BuildOwnerWithdrawTxMulti(
+	contractUTXOs []*UTXO,
+	destinationAddr btcutil.Address,
+	redeemScript []byte,
+	rbf bool,
+) (*wire.MsgTx, error) {
+
+	tx, err := newMultiInputTx(contractUTXOs)
+	if err != nil {
+		return nil, err
+	}
+	for _, txIn := range tx.TxIn {
+		txIn.Sequence = ownerSequence(rbf)
+	}
+
+	log.Printf("Built owner withdrawal transaction")
+	logInputs(contractUTXOs)
+	if err := tb.addWithdrawalOutputs(tx, TotalAmount(contractUTXOs), destinationAddr, 0, nil); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// BuildInheritorWithdrawTxMulti builds a transaction for the inheritor to
+// sweep every funding UTXO of a contract to destinationAddr. Every input
+// carries the timelock, so for a relative timelock each UTXO must have
+// matured. Sign it with SignInheritorTransactionMulti.
+func (tb *TransactionBuilder) BuildInheritorWithdrawTxMulti(
+	contractUTXOs []*UTXO,
+	destinationAddr btcutil.Address,
+	redeemScript []byte,
+	timelock int64,
+) (*wire.MsgTx, error) {
+
+	parsed, err := tb.checkScriptTimelock(redeemScript, timelock)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := newMultiInputTx(contractUTXOs)
+	if err != nil {
+		return nil, err
+	}
+	setInheritorTimelock(tx, parsed, timelock)
+
+	log.Printf("Built inheritor withdrawal transaction")
+	logInputs(contractUTXOs)
+	if err := tb.addWithdrawalOutputs(tx, TotalAmount(contractUTXOs), destinationAddr, 0, nil); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// SignOwnerTransactionMulti signs every input of a transaction built by
+// BuildOwnerWithdrawTxMulti using the IF path
+func (tb *TransactionBuilder) SignOwnerTransactionMulti(
+	tx *wire.MsgTx,
+	contractUTXOs []*UTXO,
+	redeemScript []byte,
+	ownerSigner keys.Signer,
+) error {
+	if err := tb.signContractInputs(tx, contractUTXOs, redeemScript, ownerSigner, [][]byte{script.OwnerSelector}); err != nil {
+		return err
+	}
+
+	log.Printf("Transaction signed successfully with owner's key (IF path, %d inputs)", len(tx.TxIn))
+	return nil
+}
+
+// SignInheritorTransactionMulti signs every input of a transaction built by
+// BuildInheritorWithdrawTxMulti using the ELSE path
+func (tb *TransactionBuilder) SignInheritorTransactionMulti(
+	tx *wire.MsgTx,
+	contractUTXOs []*UTXO,
+	redeemScript []byte,
+	inheritorSigner keys.Signer,
+) error {
+	if err := tb.signContractInputs(tx, contractUTXOs, redeemScript, inheritorSigner, [][]byte{script.InheritorSelector}); err != nil {
+		return err
+	}
+
+	log.Printf("Transaction signed successfully with inheritor's key (ELSE path, %d inputs)", len(tx.TxIn))
+	return nil
+}
+
+// newMultiInputTx creates a transaction with one input per contract UTXO,
+// refusing an empty list or a UTXO given twice
+func newMultiInputTx(contractUTXOs []*UTXO) (*wire.MsgTx, error) {
+	if len(contractUTXOs) == 0 {
+		return nil, fmt.Errorf("at least one contract UTXO is required")
+	}
+
+	tx := wire.NewMsgTx(txVersion)
+	seen := make(map[wire.OutPoint]bool, len(contractUTXOs))
+	for _, contractUTXO := range contractUTXOs {
+		outPoint := wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout)
+		if seen[*outPoint] {
+			return nil, fmt.Errorf("UTXO %s is given more than once", outPoint)
+		}
+		seen[*outPoint] = true
+		tx.AddTxIn(wire.NewTxIn(outPoint, nil, nil))
+	}
+	return tx, nil
+}
+
+// logInputs logs the inputs of a withdrawal
+func logInputs(contractUTXOs []*UTXO) {
+	for _, contractUTXO := range contractUTXOs {
+		log.Printf("  Input: %s:%d (%v satoshis)", contractUTXO.TxHash, contractUTXO.Vout, contractUTXO.Amount)
+	}
+}
@@ -0,0 +1,138 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+func TestWithdrawMulti_TwoUTXOSweep(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		180,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	redeemScript := inheritanceScript.RedeemScript
+	pkScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+
+	firstHash, err := chainhash.NewHashFromStr("6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807")
+	if err != nil {
+		t.Fatalf("Failed to parse funding hash: %v", err)
+	}
+	secondHash, err := chainhash.NewHashFromStr("1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001")
+	if err != nil {
+		t.Fatalf("Failed to parse funding hash: %v", err)
+	}
+	utxos := []*UTXO{
+		{TxHash: firstHash, Vout: 0, Amount: 100000, PkScript: pkScript},
+		{TxHash: secondHash, Vout: 3, Amount: 40000, PkScript: pkScript},
+	}
+	const fee = 700
+
+	tests := []struct {
+		name     string
+		build    func(*TransactionBuilder) (*wire.MsgTx, error)
+		sign     func(*TransactionBuilder, *wire.MsgTx) error
+		sequence uint32
+	}{
+		{"owner", func(tb *TransactionBuilder) (*wire.MsgTx, error) {
+			return tb.BuildOwnerWithdrawTxMulti(utxos, createTestDestination(t), redeemScript, true)
+		}, func(tb *TransactionBuilder, tx *wire.MsgTx) error {
+			return tb.SignOwnerTransactionMulti(tx, utxos, redeemScript, keys.NewLocalSigner(ownerKey))
+		}, RBFSequence},
+		{"inheritor", func(tb *TransactionBuilder) (*wire.MsgTx, error) {
+			return tb.BuildInheritorWithdrawTxMulti(utxos, createTestDestination(t), redeemScript, inheritanceScript.RelativeTimelock)
+		}, func(tb *TransactionBuilder, tx *wire.MsgTx) error {
+			return tb.SignInheritorTransactionMulti(tx, utxos, redeemScript, keys.NewLocalSigner(inheritorKey))
+		}, uint32(inheritanceScript.RelativeTimelock)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(fee))
+
+			tx, err := tt.build(txBuilder)
+			if err != nil {
+				t.Fatalf("Building the withdrawal failed: %v", err)
+			}
+			if len(tx.TxIn) != len(utxos) {
+				t.Fatalf("Expected %d inputs, got %d", len(utxos), len(tx.TxIn))
+			}
+			for i, txIn := range tx.TxIn {
+				if txIn.PreviousOutPoint != *wire.NewOutPoint(utxos[i].TxHash, utxos[i].Vout) {
+					t.Errorf("Input %d spends %s, expected %s:%d", i, txIn.PreviousOutPoint, utxos[i].TxHash, utxos[i].Vout)
+				}
+				if txIn.Sequence != tt.sequence {
+					t.Errorf("Input %d: expected sequence %d, got %d", i, tt.sequence, txIn.Sequence)
+				}
+			}
+
+			// One output carrying the sum of the inputs minus the fee
+			if len(tx.TxOut) != 1 {
+				t.Fatalf("Expected 1 output, got %d", len(tx.TxOut))
+			}
+			if expected := int64(TotalAmount(utxos)) - fee; tx.TxOut[0].Value != expected {
+				t.Errorf("Expected output of %d satoshis, got %d", expected, tx.TxOut[0].Value)
+			}
+
+			if err := tt.sign(txBuilder, tx); err != nil {
+				t.Fatalf("Signing failed: %v", err)
+			}
+			if err := txBuilder.ValidateTransactionWithScriptMulti(tx, utxos, redeemScript); err != nil {
+				t.Errorf("Signed withdrawal does not satisfy the contract: %v", err)
+			}
+
+			// Each input commits to every input amount
+			tx.TxIn[1].Witness = wire.TxWitness{}
+			if err := txBuilder.ValidateTransactionWithScriptMulti(tx, utxos, redeemScript); err == nil {
+				t.Error("Expected an error for an unsigned second input")
+			}
+		})
+	}
+}
+
+func TestBuildOwnerWithdrawTxMulti_Errors(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	redeemScript := inheritanceScript.RedeemScript
+
+	tests := []struct {
+		name    string
+		utxos   []*UTXO
+		wantErr string
+	}{
+		{"no UTXOs", nil, "at least one"},
+		{"same UTXO twice", []*UTXO{utxo, utxo}, "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := txBuilder.BuildOwnerWithdrawTxMulti(tt.utxos, createTestDestination(t), redeemScript, false)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
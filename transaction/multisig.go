@@ -0,0 +1,111 @@
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// SignOwnerMultisigTransaction signs a transaction for an M-of-N owner
+// using the IF path of a script built by NewMultisigInheritanceScript.
+// ownerSigners may be given in any order and may hold more than M signers;
+// the first M owner keys in script order that have a signer are used.
+func (tb *TransactionBuilder) SignOwnerMultisigTransaction(
+	tx *wire.MsgTx,
+	contractUTXO *UTXO,
+	redeemScript []byte,
+	ownerSigners []keys.Signer,
+) error {
+	return tb.SignOwnerMultisigTransactionMulti(tx, []*UTXO{contractUTXO}, redeemScript, ownerSigners)
+}
+
+// SignOwnerMultisigTransactionMulti is SignOwnerMultisigTransaction for a
+// transaction built by BuildOwnerWithdrawTxMulti
+func (tb *TransactionBuilder) SignOwnerMultisigTransactionMulti(
+	tx *wire.MsgTx,
+	contractUTXOs []*UTXO,
+	redeemScript []byte,
+	ownerSigners []keys.Signer,
+) error {
+	parsed, err := script.ParseRedeemScript(redeemScript, tb.chainParams)
+	if err != nil {
+		return fmt.Errorf("failed to parse redeem script: %w", err)
+	}
+	if !parsed.IsMultisigOwner() {
+		return fmt.Errorf("redeem script has a single owner key; use SignOwnerTransaction")
+	}
+
+	signers, err := orderMultisigSigners(parsed, ownerSigners)
+	if err != nil {
+		return err
+	}
+
+	sigHashes, sigScripts, err := contractSigHashes(tx, contractUTXOs, redeemScript)
+	if err != nil {
+		return err
+	}
+	hashType := tb.sigHashType()
+
+	for i, contractUTXO := range contractUTXOs {
+		if err := checkSigHashType(tx, i, hashType); err != nil {
+			return err
+		}
+		sigHash, err := txscript.CalcWitnessSigHash(redeemScript, sigHashes, hashType, tx, i, int64(contractUTXO.Amount))
+		if err != nil {
+			return fmt.Errorf("failed to calculate signature hash: %w", err)
+		}
+
+		// OP_CHECKMULTISIG pops one element more than it uses, so the
+		// witness starts with an empty dummy (which must be empty, BIP 147)
+		witness := wire.TxWitness{nil}
+		for _, signer := range signers {
+			sig, err := tb.sign(signer, sigHash)
+			if err != nil {
+				return err
+			}
+			witness = append(witness, withHashType(sig, hashType))
+		}
+		tx.TxIn[i].Witness = append(witness, script.OwnerSelector, redeemScript)
+		tx.TxIn[i].SignatureScript = sigScripts[i]
+	}
+
+	log.Printf("Transaction signed successfully with %d of %d owner keys (IF path, %d inputs)",
+		parsed.OwnerRequired, len(parsed.OwnerPubKeys), len(tx.TxIn))
+	return nil
+}
+
+// orderMultisigSigners returns the signers of the first OwnerRequired owner
+// keys, in the order of the keys in the script as OP_CHECKMULTISIG expects
+func orderMultisigSigners(parsed *script.InheritanceScript, ownerSigners []keys.Signer) ([]keys.Signer, error) {
+	for _, signer := range ownerSigners {
+		pubKey := signer.PublicKey().SerializeCompressed()
+		known := false
+		for _, ownerPubKey := range parsed.OwnerPubKeys {
+			known = known || bytes.Equal(pubKey, ownerPubKey)
+		}
+		if !known {
+			return nil, fmt.Errorf("signer key %x is not one of the contract's owner keys", pubKey)
+		}
+	}
+
+	var ordered []keys.Signer
+	for _, ownerPubKey := range parsed.OwnerPubKeys {
+		for _, signer := range ownerSigners {
+			if bytes.Equal(signer.PublicKey().SerializeCompressed(), ownerPubKey) {
+				ordered = append(ordered, signer)
+				break
+			}
+		}
+		if len(ordered) == parsed.OwnerRequired {
+			return ordered, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the owner path needs %d of %d owner signatures, but only %d owner keys can sign",
+		parsed.OwnerRequired, len(parsed.OwnerPubKeys), len(ordered))
+}
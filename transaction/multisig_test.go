@@ -0,0 +1,118 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to build a 2-of-3 owner contract, its owner keys and a funded
+// UTXO paying to it
+func createTestMultisigContract(t *testing.T) (*script.InheritanceScript, []*keys.KeyPair, *UTXO) {
+	t.Helper()
+
+	inheritanceScript, keyPairs, utxo := newTestContract(t, 4, func(keyPairs []*keys.KeyPair) (*script.InheritanceScript, error) {
+		var ownerPubKeys [][]byte
+		for _, kp := range keyPairs[:3] {
+			ownerPubKeys = append(ownerPubKeys, kp.GetCompressedPubKeyBytes())
+		}
+		return script.NewMultisigInheritanceScript(ownerPubKeys, 2, keyPairs[3].GetCompressedPubKeyBytes(), 180, &chaincfg.TestNet3Params)
+	})
+
+	// The last key is the inheritor's
+	return inheritanceScript, keyPairs[:3], utxo
+}
+
+func TestSignOwnerMultisigTransaction(t *testing.T) {
+	inheritanceScript, ownerKeys, utxo := createTestMultisigContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	tests := []struct {
+		name    string
+		signers []keys.Signer
+		wantErr string
+	}{
+		{"keys 1 and 2", []keys.Signer{ownerKeys[0].Signer(), ownerKeys[1].Signer()}, ""},
+		{"keys 3 and 1 out of order", []keys.Signer{ownerKeys[2].Signer(), ownerKeys[0].Signer()}, ""},
+		{"all three keys", []keys.Signer{ownerKeys[1].Signer(), ownerKeys[2].Signer(), ownerKeys[0].Signer()}, ""},
+		{"one key", []keys.Signer{ownerKeys[1].Signer()}, "needs 2 of 3"},
+		{"same key twice", []keys.Signer{ownerKeys[1].Signer(), ownerKeys[1].Signer()}, "needs 2 of 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, 0, false)
+			if err != nil {
+				t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+			}
+
+			err = txBuilder.SignOwnerMultisigTransaction(tx, utxo, inheritanceScript.RedeemScript, tt.signers)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SignOwnerMultisigTransaction failed: %v", err)
+			}
+
+			// dummy, two signatures, selector, redeem script
+			witness := tx.TxIn[0].Witness
+			if len(witness) != 5 || len(witness[0]) != 0 {
+				t.Fatalf("Expected an empty dummy and 5 witness items, got %d", len(witness))
+			}
+			if err := txBuilder.ValidateTransactionWithScript(tx, utxo, inheritanceScript.RedeemScript); err != nil {
+				t.Errorf("Owner multisig spend does not validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignOwnerMultisigTransaction_Errors(t *testing.T) {
+	inheritanceScript, ownerKeys, utxo := createTestMultisigContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, 0, false)
+	if err != nil {
+		t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+	}
+
+	stranger, err := keys.NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	err = txBuilder.SignOwnerMultisigTransaction(tx, utxo, inheritanceScript.RedeemScript,
+		[]keys.Signer{ownerKeys[0].Signer(), stranger.Signer()})
+	if err == nil || !strings.Contains(err.Error(), "not one of the contract's owner keys") {
+		t.Errorf("Expected an unknown signer error, got %v", err)
+	}
+
+	singleScript, singleUTXO := createTestContract(t)
+	err = txBuilder.SignOwnerMultisigTransaction(tx, singleUTXO, singleScript.RedeemScript,
+		[]keys.Signer{ownerKeys[0].Signer()})
+	if err == nil || !strings.Contains(err.Error(), "single owner key") {
+		t.Errorf("Expected a single-key script error, got %v", err)
+	}
+}
+
+func TestEstimateVirtualSize_Multisig(t *testing.T) {
+	inheritanceScript, ownerKeys, utxo := createTestMultisigContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, 0, false)
+	if err != nil {
+		t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+	}
+
+	estimate := estimateVirtualSize(tx, inheritanceScript.RedeemScript)
+	if err := txBuilder.SignOwnerMultisigTransaction(tx, utxo, inheritanceScript.RedeemScript,
+		[]keys.Signer{ownerKeys[0].Signer(), ownerKeys[1].Signer()}); err != nil {
+		t.Fatalf("SignOwnerMultisigTransaction failed: %v", err)
+	}
+	if signed := VirtualSize(tx); estimate < signed {
+		t.Errorf("Estimated %d vbytes, below the signed size of %d", estimate, signed)
+	}
+}
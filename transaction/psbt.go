@@ -0,0 +1,200 @@
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// BuildOwnerWithdrawPSBT builds an unsigned owner withdrawal (see
+// BuildOwnerWithdrawTx) as a PSBT for signing on another machine
+func (tb *TransactionBuilder) BuildOwnerWithdrawPSBT(
+	contractUTXO *UTXO,
+	destinationAddr btcutil.Address,
+	redeemScript []byte,
+	rbf bool,
+) (*psbt.Packet, error) {
+	tx, err := tb.BuildOwnerWithdrawTx(contractUTXO, destinationAddr, redeemScript, 0, rbf)
+	if err != nil {
+		return nil, err
+	}
+	return newContractPSBT(tx, contractUTXO, redeemScript)
+}
+
+// BuildInheritorWithdrawPSBT builds an unsigned inheritor withdrawal (see
+// BuildInheritorWithdrawTx) as a PSBT for signing on another machine
+func (tb *TransactionBuilder) BuildInheritorWithdrawPSBT(
+	contractUTXO *UTXO,
+	destinationAddr btcutil.Address,
+	redeemScript []byte,
+	timelock int64,
+) (*psbt.Packet, error) {
+	tx, err := tb.BuildInheritorWithdrawTx(contractUTXO, destinationAddr, redeemScript, timelock, 0)
+	if err != nil {
+		return nil, err
+	}
+	return newContractPSBT(tx, contractUTXO, redeemScript)
+}
+
+// newContractPSBT wraps an unsigned contract spend in a PSBT carrying what a
+// signer needs: the spent output, the redeem script as witnessScript, the
+// nested witness program for P2SH-P2WSH and the SIGHASH_ALL type
+func newContractPSBT(tx *wire.MsgTx, contractUTXO *UTXO, redeemScript []byte) (*psbt.Packet, error) {
+	pkScript, err := contractOutputScript(contractUTXO, redeemScript)
+	if err != nil {
+		return nil, err
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PSBT: %w", err)
+	}
+
+	input := &packet.Inputs[0]
+	input.WitnessUtxo = wire.NewTxOut(int64(contractUTXO.Amount), pkScript)
+	input.WitnessScript = redeemScript
+	input.SighashType = txscript.SigHashAll
+	if contractUTXO.AddressType == script.AddressTypeP2SHP2WSH {
+		if input.RedeemScript, err = derivePkScript(script.AddressTypeP2WSH, redeemScript); err != nil {
+			return nil, err
+		}
+	}
+
+	return packet, nil
+}
+
+// SerializePSBT encodes a PSBT as base64
+func SerializePSBT(packet *psbt.Packet) (string, error) {
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize PSBT: %w", err)
+	}
+	return encoded, nil
+}
+
+// ParsePSBT decodes a base64 PSBT, ignoring surrounding whitespace
+func ParsePSBT(encoded string) (*psbt.Packet, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(strings.TrimSpace(encoded)), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PSBT: %w", err)
+	}
+	if len(packet.Inputs) != 1 {
+		return nil, fmt.Errorf("expected a PSBT spending one contract input, got %d inputs", len(packet.Inputs))
+	}
+	return packet, nil
+}
+
+// SignPSBT adds the signer's partial signature to a contract PSBT. The
+// signer's public key selects the path: the owner's key signs for the IF
+// branch, the inheritor's for the ELSE branch.
+func (tb *TransactionBuilder) SignPSBT(packet *psbt.Packet, signer keys.Signer) error {
+	input := packet.Inputs[0]
+	if input.WitnessUtxo == nil || input.WitnessScript == nil {
+		return fmt.Errorf("PSBT input lacks the witness UTXO or witness script")
+	}
+
+	pubKey := signer.PublicKey().SerializeCompressed()
+	path, _, err := tb.spendPath(input.WitnessScript, pubKey)
+	if err != nil {
+		return err
+	}
+
+	tx := packet.UnsignedTx
+	amount := input.WitnessUtxo.Value
+	prevOuts := txscript.NewCannedPrevOutputFetcher(input.WitnessUtxo.PkScript, amount)
+	sigHash, err := txscript.CalcWitnessSigHash(input.WitnessScript, txscript.NewTxSigHashes(tx, prevOuts),
+		txscript.SigHashAll, tx, 0, amount)
+	if err != nil {
+		return fmt.Errorf("failed to calculate signature hash: %w", err)
+	}
+
+	sig, err := tb.sign(signer, sigHash)
+	if err != nil {
+		return err
+	}
+
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return fmt.Errorf("failed to update PSBT: %w", err)
+	}
+	outcome, err := updater.Sign(0, withHashType(sig, txscript.SigHashAll), pubKey, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add signature to PSBT: %w", err)
+	}
+	if outcome != psbt.SignSuccesful {
+		return fmt.Errorf("PSBT input is already finalized")
+	}
+
+	log.Printf("PSBT signed with the %s's key", path)
+	return nil
+}
+
+// FinalizePSBT assembles the witness of a signed contract PSBT, with the
+// branch selector of the key that signed, and extracts the transaction
+func (tb *TransactionBuilder) FinalizePSBT(packet *psbt.Packet) (*wire.MsgTx, error) {
+	input := &packet.Inputs[0]
+	if len(input.PartialSigs) != 1 {
+		return nil, fmt.Errorf("expected one signature in the PSBT, got %d", len(input.PartialSigs))
+	}
+	partialSig := input.PartialSigs[0]
+
+	_, selectors, err := tb.spendPath(input.WitnessScript, partialSig.PubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := append([][]byte{partialSig.Signature}, selectors...)
+	stack = append(stack, input.WitnessScript)
+	var witness bytes.Buffer
+	if err := psbt.WriteTxWitness(&witness, stack); err != nil {
+		return nil, fmt.Errorf("failed to serialize witness: %w", err)
+	}
+	input.FinalScriptWitness = witness.Bytes()
+	if input.RedeemScript != nil {
+		sigScript, err := txscript.NewScriptBuilder().AddData(input.RedeemScript).Script()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create signature script: %w", err)
+		}
+		input.FinalScriptSig = sigScript
+	}
+
+	// A finalized input keeps only its UTXO and final scripts (BIP 174)
+	input.PartialSigs = nil
+	input.SighashType = 0
+	input.WitnessScript = nil
+	input.RedeemScript = nil
+
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// spendPath returns the path ("owner" or "inheritor") and branch selectors
+// of the contract key pubKey, which may be any inheritor tier's
+func (tb *TransactionBuilder) spendPath(witnessScript, pubKey []byte) (string, [][]byte, error) {
+	parsed, err := script.ParseRedeemScript(witnessScript, tb.chainParams)
+	if err != nil {
+		return "", nil, fmt.Errorf("PSBT witness script is not an inheritance contract: %w", err)
+	}
+
+	if bytes.Equal(pubKey, parsed.OwnerPubKey) {
+		return "owner", [][]byte{script.OwnerSelector}, nil
+	}
+	for i, tier := range parsed.InheritorTiers() {
+		if bytes.Equal(pubKey, tier.PubKey) {
+			selectors, err := parsed.InheritorSelectors(i)
+			return "inheritor", selectors, err
+		}
+	}
+	return "", nil, fmt.Errorf("key %x is neither the owner's nor the inheritor's", pubKey)
+}
@@ -0,0 +1,130 @@
+package transaction
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+func TestWithdrawPSBT_RoundTrip(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	otherKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		180,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	redeemScript := inheritanceScript.RedeemScript
+
+	tests := []struct {
+		name        string
+		addressType script.AddressType
+		build       func(*TransactionBuilder, *UTXO) (*psbt.Packet, error)
+		signer      *btcec.PrivateKey
+	}{
+		{"owner", script.AddressTypeP2WSH, func(tb *TransactionBuilder, utxo *UTXO) (*psbt.Packet, error) {
+			return tb.BuildOwnerWithdrawPSBT(utxo, createTestDestination(t), redeemScript, false)
+		}, ownerKey},
+		{"owner nested", script.AddressTypeP2SHP2WSH, func(tb *TransactionBuilder, utxo *UTXO) (*psbt.Packet, error) {
+			return tb.BuildOwnerWithdrawPSBT(utxo, createTestDestination(t), redeemScript, true)
+		}, ownerKey},
+		{"inheritor", script.AddressTypeP2WSH, func(tb *TransactionBuilder, utxo *UTXO) (*psbt.Packet, error) {
+			return tb.BuildInheritorWithdrawPSBT(utxo, createTestDestination(t), redeemScript, inheritanceScript.RelativeTimelock)
+		}, inheritorKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, utxo := createTestContract(t)
+			utxo.AddressType = tt.addressType
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+			packet, err := tt.build(txBuilder, utxo)
+			if err != nil {
+				t.Fatalf("Building the PSBT failed: %v", err)
+			}
+			encoded, err := SerializePSBT(packet)
+			if err != nil {
+				t.Fatalf("SerializePSBT failed: %v", err)
+			}
+
+			// The signing machine sees only the serialized PSBT
+			unsigned, err := ParsePSBT(encoded + "\n")
+			if err != nil {
+				t.Fatalf("ParsePSBT failed: %v", err)
+			}
+			input := unsigned.Inputs[0]
+			if !bytes.Equal(input.WitnessScript, redeemScript) {
+				t.Fatalf("Expected witness script %x, got %x", redeemScript, input.WitnessScript)
+			}
+			if input.WitnessUtxo == nil || input.WitnessUtxo.Value != int64(utxo.Amount) {
+				t.Fatalf("Expected witness UTXO of %d satoshis, got %+v", utxo.Amount, input.WitnessUtxo)
+			}
+			if input.SighashType != txscript.SigHashAll {
+				t.Errorf("Expected SIGHASH_ALL, got %v", input.SighashType)
+			}
+			if nested := tt.addressType == script.AddressTypeP2SHP2WSH; nested != (input.RedeemScript != nil) {
+				t.Errorf("Expected a redeem script only for nested contracts, got %x", input.RedeemScript)
+			}
+
+			if err := txBuilder.SignPSBT(unsigned, keys.NewLocalSigner(otherKey)); err == nil {
+				t.Fatal("Expected an error signing with a key outside the contract")
+			}
+			if err := txBuilder.SignPSBT(unsigned, keys.NewLocalSigner(tt.signer)); err != nil {
+				t.Fatalf("SignPSBT failed: %v", err)
+			}
+			encoded, err = SerializePSBT(unsigned)
+			if err != nil {
+				t.Fatalf("SerializePSBT failed: %v", err)
+			}
+
+			// Back on the first machine
+			signed, err := ParsePSBT(encoded)
+			if err != nil {
+				t.Fatalf("ParsePSBT failed: %v", err)
+			}
+			tx, err := txBuilder.FinalizePSBT(signed)
+			if err != nil {
+				t.Fatalf("FinalizePSBT failed: %v", err)
+			}
+			if err := txBuilder.ValidateTransactionWithScript(tx, utxo, redeemScript); err != nil {
+				t.Errorf("Finalized transaction does not satisfy the contract: %v", err)
+			}
+		})
+	}
+}
+
+func TestFinalizePSBT_Unsigned(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	packet, err := txBuilder.BuildOwnerWithdrawPSBT(utxo, createTestDestination(t), inheritanceScript.RedeemScript, false)
+	if err != nil {
+		t.Fatalf("BuildOwnerWithdrawPSBT failed: %v", err)
+	}
+	if _, err := txBuilder.FinalizePSBT(packet); err == nil || !strings.Contains(err.Error(), "expected one signature") {
+		t.Errorf("Expected a missing signature error, got %v", err)
+	}
+}
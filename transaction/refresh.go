@@ -0,0 +1,62 @@
+package transaction
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// BuildRefreshTx builds a transaction for the owner that spends every
+// funding UTXO of a contract back to the contract's own output script,
+// minus the fee. The contract keeps its keys and address, but its relative
+// timelock restarts when the new UTXO confirms. A contract with an absolute
+// timelock is refused, as spending it does not move the unlock time. rbf is
+// as for BuildOwnerWithdrawTx. Sign it with SignOwnerTransactionMulti.
+func (tb *TransactionBuilder) BuildRefreshTx(
+	contractUTXOs []*UTXO,
+	redeemScript []byte,
+	rbf bool,
+) (*wire.MsgTx, error) {
+
+	parsed, err := script.ParseRedeemScript(redeemScript, tb.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redeem script: %w", err)
+	}
+	if parsed.TimelockType == script.Absolute {
+		return nil, fmt.Errorf("contract is locked until %s; refreshing does not move an absolute timelock",
+			script.FormatLockTime(parsed.LockTime))
+	}
+
+	tx, err := newMultiInputTx(contractUTXOs)
+	if err != nil {
+		return nil, err
+	}
+	for _, txIn := range tx.TxIn {
+		txIn.Sequence = ownerSequence(rbf)
+	}
+
+	// Every UTXO of the contract pays the same script
+	contractScript, err := contractOutputScript(contractUTXOs[0], redeemScript)
+	if err != nil {
+		return nil, err
+	}
+
+	inputAmount := TotalAmount(contractUTXOs)
+	amount := inputAmount - tb.fee
+	if amount <= 0 {
+		return nil, fmt.Errorf("insufficient funds: fee (%v) exceeds UTXO amount (%v)", tb.fee, inputAmount)
+	}
+	if err := checkDust(amount, contractScript); err != nil {
+		return nil, err
+	}
+	tx.AddTxOut(wire.NewTxOut(int64(amount), contractScript))
+
+	log.Printf("Built contract refresh transaction")
+	logInputs(contractUTXOs)
+	log.Printf("  Output: back to the contract %x (%v satoshis)", contractScript, amount)
+	log.Printf("  Fee: %v satoshis", tb.fee)
+
+	return tx, nil
+}
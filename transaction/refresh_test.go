@@ -0,0 +1,130 @@
+package transaction
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to build a contract with a known owner key, plus two funded
+// UTXOs paying to its P2WSH output
+func createTestRefreshContract(t *testing.T, opts ...script.Option) (*script.InheritanceScript, *keys.KeyPair, []*UTXO) {
+	t.Helper()
+
+	inheritanceScript, keyPairs, utxo := newTestContract(t, 2, func(keyPairs []*keys.KeyPair) (*script.InheritanceScript, error) {
+		return script.NewInheritanceScript(keyPairs[0].GetCompressedPubKeyBytes(), keyPairs[1].GetCompressedPubKeyBytes(),
+			180, &chaincfg.TestNet3Params, opts...)
+	})
+
+	second := *utxo
+	second.Vout = 1
+	second.Amount = btcutil.Amount(50000)
+	return inheritanceScript, keyPairs[0], []*UTXO{utxo, &second}
+}
+
+func TestBuildRefreshTx_PaysSameScript(t *testing.T) {
+	inheritanceScript, ownerKeys, utxos := createTestRefreshContract(t)
+	redeemScript := inheritanceScript.RedeemScript
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	tx, err := txBuilder.BuildRefreshTx(utxos, redeemScript, false)
+	if err != nil {
+		t.Fatalf("BuildRefreshTx failed: %v", err)
+	}
+
+	if len(tx.TxIn) != 2 {
+		t.Errorf("Expected both UTXOs to be spent, got %d inputs", len(tx.TxIn))
+	}
+	if len(tx.TxOut) != 1 {
+		t.Fatalf("Expected a single output, got %d", len(tx.TxOut))
+	}
+	pkScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	if !bytes.Equal(tx.TxOut[0].PkScript, pkScript) {
+		t.Errorf("Refresh output %x does not pay the contract's P2WSH %x", tx.TxOut[0].PkScript, pkScript)
+	}
+	if tx.TxOut[0].Value != 150000-500 {
+		t.Errorf("Expected output of %d satoshis, got %d", 150000-500, tx.TxOut[0].Value)
+	}
+	for i, txIn := range tx.TxIn {
+		if txIn.Sequence != ownerSequence(false) {
+			t.Errorf("Input %d: expected owner sequence %d, got %d", i, ownerSequence(false), txIn.Sequence)
+		}
+	}
+
+	if err := txBuilder.SignOwnerTransactionMulti(tx, utxos, redeemScript, ownerKeys.Signer()); err != nil {
+		t.Fatalf("SignOwnerTransactionMulti failed: %v", err)
+	}
+	if err := txBuilder.ValidateTransactionWithScriptMulti(tx, utxos, redeemScript); err != nil {
+		t.Errorf("Signed refresh does not validate: %v", err)
+	}
+}
+
+func TestBuildRefreshTx_WithoutPkScript(t *testing.T) {
+	inheritanceScript, _, utxos := createTestRefreshContract(t)
+	utxos[0].PkScript = nil
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	tx, err := txBuilder.BuildRefreshTx(utxos[:1], inheritanceScript.RedeemScript, true)
+	if err != nil {
+		t.Fatalf("BuildRefreshTx failed: %v", err)
+	}
+	pkScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	if !bytes.Equal(tx.TxOut[0].PkScript, pkScript) {
+		t.Errorf("Refresh output %x does not pay the contract's P2WSH %x", tx.TxOut[0].PkScript, pkScript)
+	}
+	if tx.TxIn[0].Sequence != ownerSequence(true) {
+		t.Errorf("Expected RBF sequence %d, got %d", ownerSequence(true), tx.TxIn[0].Sequence)
+	}
+}
+
+func TestBuildRefreshTx_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []script.Option
+		fee     btcutil.Amount
+		wantErr string
+	}{
+		{
+			name:    "absolute timelock",
+			opts:    []script.Option{script.WithAbsoluteTimelock(1893456000)},
+			fee:     500,
+			wantErr: "absolute timelock",
+		},
+		{
+			name:    "fee exceeds funds",
+			fee:     200000,
+			wantErr: "insufficient funds",
+		},
+		{
+			name:    "dust output",
+			fee:     150000 - 100,
+			wantErr: "dust",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inheritanceScript, _, utxos := createTestRefreshContract(t, tt.opts...)
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, tt.fee)
+
+			_, err := txBuilder.BuildRefreshTx(utxos, inheritanceScript.RedeemScript, false)
+			if err == nil {
+				t.Fatal("Expected an error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
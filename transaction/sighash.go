@@ -0,0 +1,73 @@
+package transaction
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// sigHashTypeNames maps the names accepted by ParseSigHashType to the
+// signature hash types SetSigHashType allows
+var sigHashTypeNames = map[string]txscript.SigHashType{
+	"all":                 txscript.SigHashAll,
+	"single":              txscript.SigHashSingle,
+	"all|anyonecanpay":    txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+	"single|anyonecanpay": txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+}
+
+// ParseSigHashType parses a signature hash type by name: all, single,
+// all|anyonecanpay or single|anyonecanpay, in any case
+func ParseSigHashType(name string) (txscript.SigHashType, error) {
+	hashType, ok := sigHashTypeNames[strings.ToLower(strings.ReplaceAll(name, " ", ""))]
+	if !ok {
+		return 0, fmt.Errorf("unsupported signature hash type %q (expected all, single, all|anyonecanpay or single|anyonecanpay)", name)
+	}
+	return hashType, nil
+}
+
+// SetSigHashType sets the signature hash type of the ECDSA signatures on
+// contract inputs, which is appended to each signature: SigHashAll (the
+// default) or SigHashSingle, either optionally with SigHashAnyOneCanPay, so
+// inputs or outputs can be added after signing. SigHashNone, which would let
+// anyone redirect the funds, and any other value are rejected. PSBTs and
+// taproot spends always sign with the default.
+func (tb *TransactionBuilder) SetSigHashType(hashType txscript.SigHashType) error {
+	switch hashType &^ txscript.SigHashAnyOneCanPay {
+	case txscript.SigHashAll, txscript.SigHashSingle:
+	default:
+		return fmt.Errorf("unsupported signature hash type 0x%02x: only ALL and SINGLE, optionally with ANYONECANPAY, are allowed", uint32(hashType))
+	}
+	tb.hashType = hashType
+	return nil
+}
+
+// sigHashType returns the signature hash type set with SetSigHashType, or
+// SigHashAll when none was set
+func (tb *TransactionBuilder) sigHashType() txscript.SigHashType {
+	if tb.hashType == 0 {
+		return txscript.SigHashAll
+	}
+	return tb.hashType
+}
+
+// checkSigHashType refuses to sign input i of tx with SigHashSingle when
+// there is no output at the same index: the signature would then commit to
+// no output at all
+func checkSigHashType(tx *wire.MsgTx, i int, hashType txscript.SigHashType) error {
+	if hashType&^txscript.SigHashAnyOneCanPay == txscript.SigHashSingle && i >= len(tx.TxOut) {
+		return fmt.Errorf("SIGHASH_SINGLE needs an output for input %d, but the transaction has %d outputs", i, len(tx.TxOut))
+	}
+	return nil
+}
+
+// withHashType returns sig followed by the sighash byte in a new slice of
+// exact length. Appending to sig directly would write into any spare capacity
+// of its backing array, which the signer may share.
+func withHashType(sig []byte, hashType txscript.SigHashType) []byte {
+	withType := make([]byte, len(sig)+1)
+	copy(withType, sig)
+	withType[len(sig)] = byte(hashType)
+	return withType
+}
@@ -0,0 +1,218 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+func TestSetSigHashType(t *testing.T) {
+	tests := []struct {
+		name     string
+		hashType txscript.SigHashType
+		wantErr  bool
+	}{
+		{"all", txscript.SigHashAll, false},
+		{"single", txscript.SigHashSingle, false},
+		{"all|anyonecanpay", txscript.SigHashAll | txscript.SigHashAnyOneCanPay, false},
+		{"single|anyonecanpay", txscript.SigHashSingle | txscript.SigHashAnyOneCanPay, false},
+		{"none", txscript.SigHashNone, true},
+		{"none|anyonecanpay", txscript.SigHashNone | txscript.SigHashAnyOneCanPay, true},
+		{"old (zero)", txscript.SigHashOld, true},
+		{"anyonecanpay alone", txscript.SigHashAnyOneCanPay, true},
+		{"unknown", 0x04, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, 1000)
+			err := txBuilder.SetSigHashType(tt.hashType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetSigHashType(0x%02x): expected error %v, got %v", uint32(tt.hashType), tt.wantErr, err)
+			}
+			if err != nil && txBuilder.sigHashType() != txscript.SigHashAll {
+				t.Errorf("A rejected type must leave the default, got 0x%02x", uint32(txBuilder.sigHashType()))
+			}
+
+			parsed, err := ParseSigHashType(strings.ToUpper(tt.name))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected ParseSigHashType(%q) to fail, got 0x%02x", tt.name, uint32(parsed))
+				}
+			} else if err != nil || parsed != tt.hashType {
+				t.Errorf("ParseSigHashType(%q): expected 0x%02x, got 0x%02x (%v)", tt.name, uint32(tt.hashType), uint32(parsed), err)
+			}
+		})
+	}
+}
+
+func TestSignTransaction_SigHashSingleAnyoneCanPay(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(), 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	redeemScript := inheritanceScript.RedeemScript
+	pkScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	fundingHash, err := chainhash.NewHashFromStr("6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807")
+	if err != nil {
+		t.Fatalf("Failed to parse funding hash: %v", err)
+	}
+	utxo := &UTXO{TxHash: fundingHash, Vout: 0, Amount: 100000, PkScript: pkScript}
+	hashType := txscript.SigHashSingle | txscript.SigHashAnyOneCanPay
+
+	tests := []struct {
+		name  string
+		build func(*TransactionBuilder) (*wire.MsgTx, error)
+		sign  func(*TransactionBuilder, *wire.MsgTx) error
+	}{
+		{"owner", func(tb *TransactionBuilder) (*wire.MsgTx, error) {
+			return tb.BuildOwnerWithdrawTx(utxo, createTestDestination(t), redeemScript, 0, false)
+		}, func(tb *TransactionBuilder, tx *wire.MsgTx) error {
+			return tb.SignOwnerTransaction(tx, utxo, redeemScript, keys.NewLocalSigner(ownerKey))
+		}},
+		{"inheritor", func(tb *TransactionBuilder) (*wire.MsgTx, error) {
+			return tb.BuildInheritorWithdrawTx(utxo, createTestDestination(t), redeemScript, inheritanceScript.RelativeTimelock, 0)
+		}, func(tb *TransactionBuilder, tx *wire.MsgTx) error {
+			return tb.SignInheritorTransaction(tx, utxo, redeemScript, keys.NewLocalSigner(inheritorKey))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, 1000)
+			if err := txBuilder.SetSigHashType(hashType); err != nil {
+				t.Fatalf("SetSigHashType failed: %v", err)
+			}
+			tx, err := tt.build(txBuilder)
+			if err != nil {
+				t.Fatalf("Building the withdrawal failed: %v", err)
+			}
+			if err := tt.sign(txBuilder, tx); err != nil {
+				t.Fatalf("Signing failed: %v", err)
+			}
+
+			sig := tx.TxIn[0].Witness[0]
+			if got := txscript.SigHashType(sig[len(sig)-1]); got != hashType {
+				t.Errorf("Expected sighash byte 0x%02x, got 0x%02x", uint32(hashType), uint32(got))
+			}
+			if err := txBuilder.ValidateTransactionWithScript(tx, utxo, redeemScript); err != nil {
+				t.Fatalf("Signed input does not execute: %v", err)
+			}
+
+			// The signature covers only its own input and output, so another
+			// party can add an input and an output without invalidating it
+			extraHash, _ := chainhash.NewHashFromStr("1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001")
+			tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(extraHash, 1), nil, nil))
+			tx.AddTxOut(wire.NewTxOut(5000, tx.TxOut[0].PkScript))
+
+			prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+			prevOuts.AddPrevOut(*wire.NewOutPoint(utxo.TxHash, utxo.Vout), wire.NewTxOut(int64(utxo.Amount), pkScript))
+			prevOuts.AddPrevOut(*wire.NewOutPoint(extraHash, 1), wire.NewTxOut(6000, tx.TxOut[0].PkScript))
+			engine, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil,
+				txscript.NewTxSigHashes(tx, prevOuts), int64(utxo.Amount), prevOuts)
+			if err != nil {
+				t.Fatalf("Failed to create script engine: %v", err)
+			}
+			if err := engine.Execute(); err != nil {
+				t.Errorf("Contract input no longer executes after adding an input and output: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignTransaction_SigHashSingleNeedsOutput(t *testing.T) {
+	inputs := []*SweepInput{
+		newSweepInput(t, "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807", 100000),
+		newSweepInput(t, "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001", 40000),
+	}
+
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(900))
+	if err := txBuilder.SetSigHashType(txscript.SigHashSingle); err != nil {
+		t.Fatalf("SetSigHashType failed: %v", err)
+	}
+	tx, err := txBuilder.BuildSweepTx(inputs, createTestDestination(t))
+	if err != nil {
+		t.Fatalf("BuildSweepTx failed: %v", err)
+	}
+
+	// Input 1 has no output at its index to commit to
+	err = txBuilder.SignSweepTx(tx, inputs)
+	if err == nil || !strings.Contains(err.Error(), "SIGHASH_SINGLE needs an output for input 1") {
+		t.Errorf("Expected SIGHASH_SINGLE without a matching output to be refused, got %v", err)
+	}
+}
+
+func TestWithHashType_SpareCapacity(t *testing.T) {
+	// A signature in a buffer with room to spare, as a signer might return
+	buffer := make([]byte, 4, 16)
+	copy(buffer, []byte{0x30, 0x01, 0x02, 0x03})
+
+	all := withHashType(buffer, txscript.SigHashAll)
+	single := withHashType(buffer, txscript.SigHashSingle)
+	if all[4] != byte(txscript.SigHashAll) || single[4] != byte(txscript.SigHashSingle) {
+		t.Errorf("Expected sighash bytes 0x01 and 0x03, got 0x%02x and 0x%02x", all[4], single[4])
+	}
+	if len(all) != cap(all) {
+		t.Errorf("Expected a slice of exact length, got length %d and capacity %d", len(all), cap(all))
+	}
+	if buffer[:5][4] != 0 {
+		t.Error("The signer's buffer was written to")
+	}
+}
+
+func TestSignTransaction_SignaturesDoNotShareMemory(t *testing.T) {
+	input := newSweepInput(t, "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807", 100000)
+	utxo, redeemScript := input.UTXO, input.RedeemScript
+
+	const count = 50
+	var txs []*wire.MsgTx
+	for i := 0; i < count; i++ {
+		// A different fee per transaction gives each a different signature
+		txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(1000+i))
+		tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t), redeemScript, input.Timelock, 0)
+		if err != nil {
+			t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+		}
+		if err := txBuilder.SignInheritorTransaction(tx, utxo, redeemScript, input.Signer); err != nil {
+			t.Fatalf("SignInheritorTransaction failed: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+
+	seen := make(map[*byte]int, count)
+	for i, tx := range txs {
+		sig := tx.TxIn[0].Witness[0]
+		if len(sig) != cap(sig) {
+			t.Errorf("Signature %d has spare capacity (length %d, capacity %d)", i, len(sig), cap(sig))
+		}
+		if j, ok := seen[&sig[0]]; ok {
+			t.Errorf("Signatures %d and %d share a backing array", j, i)
+		}
+		seen[&sig[0]] = i
+
+		txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, 0)
+		if err := txBuilder.ValidateTransactionWithScript(tx, utxo, redeemScript); err != nil {
+			t.Errorf("Signature %d does not verify: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package transaction
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// SweepInput is a matured contract UTXO spent by a sweep, with the redeem
+// script and timelock of its contract and the key of its (first) inheritor.
+// Timelock is as for BuildInheritorWithdrawTx.
+type SweepInput struct {
+	UTXO         *UTXO
+	RedeemScript []byte
+	Timelock     int64
+	Signer       keys.Signer
+}
+
+// BuildSweepTx builds a transaction for an inheritor of several contracts to
+// sweep their UTXOs to destinationAddr at once, paying the fee once. Each
+// input of a relative timelock carries the sequence of its own contract.
+// Absolute timelocks share the transaction's lock time, which is set to the
+// latest of them, so they must all be block heights or all timestamps. Sign
+// it with SignSweepTx.
+func (tb *TransactionBuilder) BuildSweepTx(inputs []*SweepInput, destinationAddr btcutil.Address) (*wire.MsgTx, error) {
+	utxos := sweepUTXOs(inputs)
+	tx, err := newMultiInputTx(utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	var lockTime int64
+	hasLockTime := false
+	for i, input := range inputs {
+		parsed, err := tb.checkScriptTimelock(input.RedeemScript, input.Timelock)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		if parsed.TimelockType != script.Absolute {
+			tx.TxIn[i].Sequence = uint32(input.Timelock)
+			continue
+		}
+
+		if hasLockTime && script.IsLockTimeTimestamp(lockTime) != script.IsLockTimeTimestamp(input.Timelock) {
+			return nil, fmt.Errorf("input %d: cannot sweep a lock time by block height together with one by time", i)
+		}
+		if input.Timelock > lockTime {
+			lockTime = input.Timelock
+		}
+		hasLockTime = true
+		// Not final, so the lock time is enforced (see setInheritorTimelock)
+		tx.TxIn[i].Sequence = RBFSequence
+	}
+	tx.LockTime = uint32(lockTime)
+
+	log.Printf("Built sweep transaction")
+	for i, input := range inputs {
+		log.Printf("  Input: %s:%d (%v satoshis, sequence %d)", input.UTXO.TxHash, input.UTXO.Vout, input.UTXO.Amount, tx.TxIn[i].Sequence)
+	}
+	if hasLockTime {
+		log.Printf("  Lock time: %s", script.FormatLockTime(lockTime))
+	}
+	if err := tb.addWithdrawalOutputs(tx, TotalAmount(utxos), destinationAddr, 0, nil); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// SignSweepTx signs every input of a transaction built by BuildSweepTx with
+// the input's signer, on the ELSE path of its contract's first inheritor
+func (tb *TransactionBuilder) SignSweepTx(tx *wire.MsgTx, inputs []*SweepInput) error {
+	prevOuts, sigScripts, err := sweepPrevOuts(tx, inputs)
+	if err != nil {
+		return err
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	hashType := tb.sigHashType()
+
+	for i, input := range inputs {
+		parsed, err := script.ParseRedeemScript(input.RedeemScript, tb.chainParams)
+		if err != nil {
+			return fmt.Errorf("input %d: failed to parse redeem script: %w", i, err)
+		}
+		selectors, err := parsed.InheritorSelectors(0)
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+
+		if err := checkSigHashType(tx, i, hashType); err != nil {
+			return err
+		}
+		sigHash, err := txscript.CalcWitnessSigHash(input.RedeemScript, sigHashes, hashType, tx, i, int64(input.UTXO.Amount))
+		if err != nil {
+			return fmt.Errorf("failed to calculate signature hash: %w", err)
+		}
+		sig, err := tb.sign(input.Signer, sigHash)
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+
+		witness := wire.TxWitness{withHashType(sig, hashType)}
+		witness = append(witness, selectors...)
+		tx.TxIn[i].Witness = append(witness, input.RedeemScript)
+		tx.TxIn[i].SignatureScript = sigScripts[i]
+	}
+
+	log.Printf("Sweep transaction signed successfully with the inheritors' keys (ELSE path, %d inputs)", len(tx.TxIn))
+	return nil
+}
+
+// ValidateSweepTx is ValidateTransactionWithScriptMulti for a transaction
+// built by BuildSweepTx, whose inputs spend different contracts
+func (tb *TransactionBuilder) ValidateSweepTx(tx *wire.MsgTx, inputs []*SweepInput) error {
+	if err := tb.ValidateTransaction(tx); err != nil {
+		return err
+	}
+	prevOuts, _, err := sweepPrevOuts(tx, inputs)
+	if err != nil {
+		return err
+	}
+	return executeInputs(tx, prevOuts)
+}
+
+// SweepFeeForRate returns the fee that makes a sweep shaped like tx pay the
+// given fee rate in sat/vB once signed
+func SweepFeeForRate(tx *wire.MsgTx, inputs []*SweepInput, feeRate float64) btcutil.Amount {
+	sized := tx.Copy()
+	for i, txIn := range sized.TxIn {
+		if i < len(inputs) {
+			txIn.Witness = estimatedWitness(inputs[i].RedeemScript)
+		}
+	}
+	return EstimateFee(sized, feeRate)
+}
+
+// sweepUTXOs returns the UTXOs of a sweep's inputs
+func sweepUTXOs(inputs []*SweepInput) []*UTXO {
+	utxos := make([]*UTXO, len(inputs))
+	for i, input := range inputs {
+		utxos[i] = input.UTXO
+	}
+	return utxos
+}
+
+// sweepPrevOuts returns the previous outputs of a sweep's inputs, each
+// checked against its own contract's redeem script, and the signature script
+// each input needs
+func sweepPrevOuts(tx *wire.MsgTx, inputs []*SweepInput) (*txscript.MultiPrevOutFetcher, [][]byte, error) {
+	if len(inputs) != len(tx.TxIn) {
+		return nil, nil, fmt.Errorf("transaction has %d inputs but %d sweep inputs were given", len(tx.TxIn), len(inputs))
+	}
+
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	sigScripts := make([][]byte, len(inputs))
+	for i, input := range inputs {
+		_, sigScript, err := contractPkScript(input.UTXO, input.RedeemScript)
+		if err != nil {
+			return nil, nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		pkScript, err := contractOutputScript(input.UTXO, input.RedeemScript)
+		if err != nil {
+			return nil, nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		prevOuts.AddPrevOut(*wire.NewOutPoint(input.UTXO.TxHash, input.UTXO.Vout),
+			wire.NewTxOut(int64(input.UTXO.Amount), pkScript))
+		sigScripts[i] = sigScript
+	}
+	return prevOuts, sigScripts, nil
+}
@@ -0,0 +1,160 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// newSweepInput creates a contract with its own keys and the given script
+// options, and returns its UTXO as a sweep input signed by its inheritor
+func newSweepInput(t *testing.T, txid string, amount btcutil.Amount, opts ...script.Option) *SweepInput {
+	t.Helper()
+
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(), 180, &chaincfg.TestNet3Params, opts...)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	pkScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	txHash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		t.Fatalf("Failed to parse funding hash: %v", err)
+	}
+
+	timelock := inheritanceScript.RelativeTimelock
+	if inheritanceScript.TimelockType == script.Absolute {
+		timelock = inheritanceScript.LockTime
+	}
+	return &SweepInput{
+		UTXO:         &UTXO{TxHash: txHash, Vout: 0, Amount: amount, PkScript: pkScript},
+		RedeemScript: inheritanceScript.RedeemScript,
+		Timelock:     timelock,
+		Signer:       keys.NewLocalSigner(inheritorKey),
+	}
+}
+
+func TestSweep_TwoContracts(t *testing.T) {
+	inputs := []*SweepInput{
+		newSweepInput(t, "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807", 100000),
+		newSweepInput(t, "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001", 40000,
+			script.WithTimelockBlocks(26000)),
+	}
+	const fee = 900
+
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(fee))
+	tx, err := txBuilder.BuildSweepTx(inputs, createTestDestination(t))
+	if err != nil {
+		t.Fatalf("BuildSweepTx failed: %v", err)
+	}
+
+	if len(tx.TxIn) != 2 || len(tx.TxOut) != 1 {
+		t.Fatalf("Expected 2 inputs and 1 output, got %d and %d", len(tx.TxIn), len(tx.TxOut))
+	}
+	if want := int64(100000 + 40000 - fee); tx.TxOut[0].Value != want {
+		t.Errorf("Expected output of sum - fee = %d, got %d", want, tx.TxOut[0].Value)
+	}
+	for i, input := range inputs {
+		if tx.TxIn[i].Sequence != uint32(input.Timelock) {
+			t.Errorf("Input %d: expected sequence %d of its own contract, got %d", i, input.Timelock, tx.TxIn[i].Sequence)
+		}
+	}
+	if tx.TxIn[0].Sequence == tx.TxIn[1].Sequence {
+		t.Errorf("Expected the contracts' differing timelocks in the sequences, both are %d", tx.TxIn[0].Sequence)
+	}
+
+	if err := txBuilder.SignSweepTx(tx, inputs); err != nil {
+		t.Fatalf("SignSweepTx failed: %v", err)
+	}
+	if err := txBuilder.ValidateSweepTx(tx, inputs); err != nil {
+		t.Fatalf("Signed sweep does not satisfy the contract scripts: %v", err)
+	}
+
+	// Signing with the other contract's key fails script validation
+	swapped := []*SweepInput{
+		{UTXO: inputs[0].UTXO, RedeemScript: inputs[0].RedeemScript, Timelock: inputs[0].Timelock, Signer: inputs[1].Signer},
+		inputs[1],
+	}
+	if err := txBuilder.SignSweepTx(tx, swapped); err != nil {
+		t.Fatalf("SignSweepTx failed: %v", err)
+	}
+	if err := txBuilder.ValidateSweepTx(tx, swapped); err == nil {
+		t.Error("Expected a sweep signed with the wrong key to fail validation")
+	}
+}
+
+func TestSweep_FeeForRate(t *testing.T) {
+	inputs := []*SweepInput{
+		newSweepInput(t, "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807", 100000),
+		newSweepInput(t, "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001", 40000),
+	}
+
+	template, err := NewTransactionBuilder(&chaincfg.TestNet3Params, 0).BuildSweepTx(inputs, createTestDestination(t))
+	if err != nil {
+		t.Fatalf("BuildSweepTx failed: %v", err)
+	}
+	fee := SweepFeeForRate(template, inputs, 2)
+
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, fee)
+	tx, err := txBuilder.BuildSweepTx(inputs, createTestDestination(t))
+	if err != nil {
+		t.Fatalf("BuildSweepTx failed: %v", err)
+	}
+	if err := txBuilder.SignSweepTx(tx, inputs); err != nil {
+		t.Fatalf("SignSweepTx failed: %v", err)
+	}
+
+	// The estimate assumes worst-case signatures, so it never undershoots
+	if vsize := VirtualSize(tx); fee < btcutil.Amount(2*vsize) {
+		t.Errorf("Fee %d is below 2 sat/vB over the signed %d vbytes", fee, vsize)
+	}
+}
+
+func TestSweep_AbsoluteLockTimes(t *testing.T) {
+	later := time.Now().Add(400 * 24 * time.Hour).Unix()
+	timeLocked := []*SweepInput{
+		newSweepInput(t, "6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807", 100000,
+			script.WithAbsoluteTimelock(time.Now().Add(200*24*time.Hour).Unix())),
+		newSweepInput(t, "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001", 40000,
+			script.WithAbsoluteTimelock(later)),
+	}
+
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, 900)
+	tx, err := txBuilder.BuildSweepTx(timeLocked, createTestDestination(t))
+	if err != nil {
+		t.Fatalf("BuildSweepTx failed: %v", err)
+	}
+	if int64(tx.LockTime) != later {
+		t.Errorf("Expected the latest lock time %d, got %d", later, tx.LockTime)
+	}
+	for i, txIn := range tx.TxIn {
+		if txIn.Sequence != RBFSequence {
+			t.Errorf("Input %d: expected non-final sequence 0x%08x, got 0x%08x", i, RBFSequence, txIn.Sequence)
+		}
+	}
+
+	heightLocked := newSweepInput(t, "1b2c3d4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff001", 40000,
+		script.WithAbsoluteTimelock(3000000))
+	_, err = txBuilder.BuildSweepTx([]*SweepInput{timeLocked[0], heightLocked}, createTestDestination(t))
+	if err == nil || !strings.Contains(err.Error(), "block height together with one by time") {
+		t.Errorf("Expected mixing lock time kinds to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// SignOwnerTaproot signs every input of tx, each spending the taproot
+// contract UTXO at the same position of contractUTXOs, by key path with the
+// owner's key tweaked by the script tree. The witness is a single 64-byte
+// Schnorr signature (SIGHASH_DEFAULT).
+func (tb *TransactionBuilder) SignOwnerTaproot(tx *wire.MsgTx, contractUTXOs []*UTXO, tapScript *script.TaprootInheritanceScript, ownerKeys *keys.KeyPair) error {
+	if !bytes.Equal(ownerKeys.GetXOnlyPubKeyBytes(), tapScript.OwnerPubKey[1:]) {
+		return fmt.Errorf("key is not the contract's owner key")
+	}
+
+	pkScript, _, sigHashes, err := taprootSigHashes(tx, contractUTXOs, tapScript)
+	if err != nil {
+		return err
+	}
+
+	for i, contractUTXO := range contractUTXOs {
+		sig, err := txscript.RawTxInTaprootSignature(tx, sigHashes, i, int64(contractUTXO.Amount), pkScript,
+			tapScript.MerkleRoot(), txscript.SigHashDefault, ownerKeys.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign input %d: %w", i, err)
+		}
+		tx.TxIn[i].Witness = wire.TxWitness{sig}
+	}
+
+	log.Printf("Owner signed %d taproot inputs (key path)", len(contractUTXOs))
+	return nil
+}
+
+// SignInheritorTaproot signs every input of tx by script path with the
+// inheritor's key, revealing the timelocked leaf. Each input's sequence is
+// set to the contract's timelock first, so tx must be version 2 or higher.
+// The witness is the signature, the leaf script and the control block.
+func (tb *TransactionBuilder) SignInheritorTaproot(tx *wire.MsgTx, contractUTXOs []*UTXO, tapScript *script.TaprootInheritanceScript, inheritorKeys *keys.KeyPair) error {
+	if !bytes.Equal(inheritorKeys.GetXOnlyPubKeyBytes(), tapScript.InheritorPubKey[1:]) {
+		return fmt.Errorf("key is not the contract's inheritor key")
+	}
+	if tx.Version < 2 {
+		return fmt.Errorf("transaction version %d does not enforce OP_CHECKSEQUENCEVERIFY; use version 2", tx.Version)
+	}
+
+	// The sequences are committed to by the signatures
+	for _, txIn := range tx.TxIn {
+		txIn.Sequence = uint32(tapScript.RelativeTimelock)
+	}
+
+	pkScript, _, sigHashes, err := taprootSigHashes(tx, contractUTXOs, tapScript)
+	if err != nil {
+		return err
+	}
+	controlBlock, err := tapScript.ControlBlock()
+	if err != nil {
+		return err
+	}
+
+	for i, contractUTXO := range contractUTXOs {
+		sig, err := txscript.RawTxInTapscriptSignature(tx, sigHashes, i, int64(contractUTXO.Amount), pkScript,
+			tapScript.TapLeaf(), txscript.SigHashDefault, inheritorKeys.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign input %d: %w", i, err)
+		}
+		tx.TxIn[i].Witness = wire.TxWitness{sig, tapScript.LeafScript, controlBlock}
+	}
+
+	log.Printf("Inheritor signed %d taproot inputs (script path)", len(contractUTXOs))
+	return nil
+}
+
+// ValidateTaprootTransaction executes every signed input of tx against the
+// taproot contract output it spends
+func (tb *TransactionBuilder) ValidateTaprootTransaction(tx *wire.MsgTx, contractUTXOs []*UTXO, tapScript *script.TaprootInheritanceScript) error {
+	pkScript, prevOuts, sigHashes, err := taprootSigHashes(tx, contractUTXOs, tapScript)
+	if err != nil {
+		return err
+	}
+
+	for i, contractUTXO := range contractUTXOs {
+		engine, err := txscript.NewEngine(pkScript, tx, i, txscript.StandardVerifyFlags, nil,
+			sigHashes, int64(contractUTXO.Amount), prevOuts)
+		if err != nil {
+			return fmt.Errorf("failed to create script engine for input %d: %w", i, err)
+		}
+		if err := engine.Execute(); err != nil {
+			return fmt.Errorf("signed input %d does not satisfy the taproot contract: %w", i, err)
+		}
+	}
+
+	log.Printf("Script execution passed")
+	return nil
+}
+
+// taprootSigHashes returns the contract's output script, the outputs spent
+// by tx and its sighash midstate. Taproot signatures commit to the amounts
+// and scripts of all inputs, so input i must spend contractUTXOs[i].
+func taprootSigHashes(tx *wire.MsgTx, contractUTXOs []*UTXO, tapScript *script.TaprootInheritanceScript) ([]byte, txscript.PrevOutputFetcher, *txscript.TxSigHashes, error) {
+	if len(contractUTXOs) != len(tx.TxIn) {
+		return nil, nil, nil, fmt.Errorf("transaction has %d inputs but %d contract UTXOs were given", len(tx.TxIn), len(contractUTXOs))
+	}
+
+	pkScript, err := tapScript.GetScriptPubKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(contractUTXOs))
+	for i, contractUTXO := range contractUTXOs {
+		outpoint := wire.OutPoint{Hash: *contractUTXO.TxHash, Index: contractUTXO.Vout}
+		if tx.TxIn[i].PreviousOutPoint != outpoint {
+			return nil, nil, nil, fmt.Errorf("input %d spends %s, not the contract UTXO %s", i, tx.TxIn[i].PreviousOutPoint, outpoint)
+		}
+		if contractUTXO.PkScript != nil && !bytes.Equal(contractUTXO.PkScript, pkScript) {
+			return nil, nil, nil, fmt.Errorf("UTXO %s does not pay to the taproot contract", outpoint)
+		}
+		prevOuts[outpoint] = wire.NewTxOut(int64(contractUTXO.Amount), pkScript)
+	}
+
+	fetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	return pkScript, fetcher, txscript.NewTxSigHashes(tx, fetcher), nil
+}
@@ -0,0 +1,96 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to build a taproot contract, its keys and an unsigned version 2
+// transaction spending a UTXO paying to it
+func createTestTaprootSpend(t *testing.T) (*script.TaprootInheritanceScript, *keys.KeyPair, *keys.KeyPair, *wire.MsgTx, []*UTXO) {
+	t.Helper()
+
+	tapScript, keyPairs, utxo := newTestContract(t, 2, func(keyPairs []*keys.KeyPair) (*script.TaprootInheritanceScript, error) {
+		return script.NewTaprootInheritanceScript(keyPairs[0].GetCompressedPubKeyBytes(), keyPairs[1].GetCompressedPubKeyBytes(),
+			180, &chaincfg.TestNet3Params)
+	})
+
+	destScript, err := txscript.PayToAddrScript(createTestDestination(t))
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(utxo.TxHash, utxo.Vout), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(99000, destScript))
+
+	return tapScript, keyPairs[0], keyPairs[1], tx, []*UTXO{utxo}
+}
+
+func TestSignOwnerTaproot_KeyPath(t *testing.T) {
+	tapScript, ownerKeys, _, tx, utxos := createTestTaprootSpend(t)
+	tb := NewTransactionBuilder(&chaincfg.TestNet3Params, 1000)
+
+	if err := tb.SignOwnerTaproot(tx, utxos, tapScript, ownerKeys); err != nil {
+		t.Fatalf("SignOwnerTaproot failed: %v", err)
+	}
+	witness := tx.TxIn[0].Witness
+	if len(witness) != 1 || len(witness[0]) != 64 {
+		t.Fatalf("Expected a single 64-byte signature, got %d items", len(witness))
+	}
+	if err := tb.ValidateTaprootTransaction(tx, utxos, tapScript); err != nil {
+		t.Errorf("Owner key path spend does not validate: %v", err)
+	}
+}
+
+func TestSignInheritorTaproot_ScriptPath(t *testing.T) {
+	tapScript, _, inheritorKeys, tx, utxos := createTestTaprootSpend(t)
+	tb := NewTransactionBuilder(&chaincfg.TestNet3Params, 1000)
+
+	if err := tb.SignInheritorTaproot(tx, utxos, tapScript, inheritorKeys); err != nil {
+		t.Fatalf("SignInheritorTaproot failed: %v", err)
+	}
+	if tx.TxIn[0].Sequence != uint32(tapScript.RelativeTimelock) {
+		t.Errorf("Expected sequence %d, got %d", tapScript.RelativeTimelock, tx.TxIn[0].Sequence)
+	}
+	if len(tx.TxIn[0].Witness) != 3 {
+		t.Fatalf("Expected signature, leaf and control block, got %d items", len(tx.TxIn[0].Witness))
+	}
+	if err := tb.ValidateTaprootTransaction(tx, utxos, tapScript); err != nil {
+		t.Fatalf("Inheritor script path spend does not validate: %v", err)
+	}
+
+	// A sequence below the timelock fails OP_CHECKSEQUENCEVERIFY
+	tx.TxIn[0].Sequence = uint32(tapScript.RelativeTimelock) - 1
+	if err := tb.ValidateTaprootTransaction(tx, utxos, tapScript); err == nil {
+		t.Errorf("Expected validation to fail before the timelock")
+	}
+}
+
+func TestSignTaproot_Errors(t *testing.T) {
+	tapScript, ownerKeys, inheritorKeys, tx, utxos := createTestTaprootSpend(t)
+	tb := NewTransactionBuilder(&chaincfg.TestNet3Params, 1000)
+
+	if err := tb.SignOwnerTaproot(tx, utxos, tapScript, inheritorKeys); err == nil || !strings.Contains(err.Error(), "owner key") {
+		t.Errorf("Expected owner key mismatch, got %v", err)
+	}
+	if err := tb.SignInheritorTaproot(tx, utxos, tapScript, ownerKeys); err == nil || !strings.Contains(err.Error(), "inheritor key") {
+		t.Errorf("Expected inheritor key mismatch, got %v", err)
+	}
+
+	otherUTXO := *utxos[0]
+	otherUTXO.Vout++
+	if err := tb.SignOwnerTaproot(tx, []*UTXO{&otherUTXO}, tapScript, ownerKeys); err == nil || !strings.Contains(err.Error(), "not the contract UTXO") {
+		t.Errorf("Expected outpoint mismatch, got %v", err)
+	}
+
+	tx.Version = 1
+	if err := tb.SignInheritorTaproot(tx, utxos, tapScript, inheritorKeys); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("Expected version error, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// SignInheritorTierTransactionMulti signs every input of an inheritor
+// withdrawal for inheritor tier (0 for the first) of a script built by
+// NewTieredInheritanceScript, selecting the tier's branch in the witness.
+// Build the transaction with the tier's timelock. For a script with a single
+// inheritor, tier 0 signs as SignInheritorTransactionMulti does.
+func (tb *TransactionBuilder) SignInheritorTierTransactionMulti(
+	tx *wire.MsgTx,
+	contractUTXOs []*UTXO,
+	redeemScript []byte,
+	tier int,
+	inheritorSigner keys.Signer,
+) error {
+	parsed, err := script.ParseRedeemScript(redeemScript, tb.chainParams)
+	if err != nil {
+		return fmt.Errorf("failed to parse redeem script: %w", err)
+	}
+	selectors, err := parsed.InheritorSelectors(tier)
+	if err != nil {
+		return err
+	}
+	tierPubKey := parsed.InheritorTiers()[tier].PubKey
+	if !bytes.Equal(inheritorSigner.PublicKey().SerializeCompressed(), tierPubKey) {
+		return fmt.Errorf("signer key is not the tier %d inheritor key %x", tier+1, tierPubKey)
+	}
+
+	if err := tb.signContractInputs(tx, contractUTXOs, redeemScript, inheritorSigner, selectors); err != nil {
+		return err
+	}
+
+	log.Printf("Transaction signed successfully with tier %d inheritor's key (ELSE path, %d inputs)", tier+1, len(tx.TxIn))
+	return nil
+}
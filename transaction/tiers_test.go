@@ -0,0 +1,132 @@
+package transaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// Test helper to build a tiered contract with a tier for each of tierDays,
+// the tiers' keys and a funded UTXO paying to it
+func createTestTieredContract(t *testing.T, tierDays ...int64) (*script.InheritanceScript, []*keys.KeyPair, *UTXO) {
+	t.Helper()
+
+	inheritanceScript, keyPairs, utxo := newTestContract(t, len(tierDays)+1, func(keyPairs []*keys.KeyPair) (*script.InheritanceScript, error) {
+		tiers := make([]script.InheritorTier, len(tierDays))
+		for i, days := range tierDays {
+			tiers[i] = script.InheritorTier{PubKey: keyPairs[i+1].GetCompressedPubKeyBytes(), TimelockDays: days}
+		}
+		return script.NewTieredInheritanceScript(keyPairs[0].GetCompressedPubKeyBytes(), tiers, &chaincfg.TestNet3Params)
+	})
+
+	// The owner key is not a tier's; drop it
+	return inheritanceScript, keyPairs[1:], utxo
+}
+
+func TestSignInheritorTierTransaction(t *testing.T) {
+	inheritanceScript, tierKeys, utxo := createTestTieredContract(t, 180, 365)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	tiers := inheritanceScript.Tiers
+
+	for tier := range tiers {
+		timelock := tiers[tier].RelativeTimelock
+		tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, timelock, 0)
+		if err != nil {
+			t.Fatalf("Tier %d: BuildInheritorWithdrawTx failed: %v", tier+1, err)
+		}
+		if tx.TxIn[0].Sequence != uint32(timelock) {
+			t.Errorf("Tier %d: expected sequence %d, got %d", tier+1, timelock, tx.TxIn[0].Sequence)
+		}
+
+		utxos := []*UTXO{utxo}
+		if err := txBuilder.SignInheritorTierTransactionMulti(tx, utxos, inheritanceScript.RedeemScript, tier, tierKeys[tier].Signer()); err != nil {
+			t.Fatalf("Tier %d: SignInheritorTierTransactionMulti failed: %v", tier+1, err)
+		}
+		// signature, two branch selectors, redeem script
+		if len(tx.TxIn[0].Witness) != 4 {
+			t.Errorf("Tier %d: expected 4 witness items, got %d", tier+1, len(tx.TxIn[0].Witness))
+		}
+		if err := txBuilder.ValidateTransactionWithScript(tx, utxo, inheritanceScript.RedeemScript); err != nil {
+			t.Errorf("Tier %d: withdrawal does not validate: %v", tier+1, err)
+		}
+	}
+}
+
+func TestSignInheritorTierTransaction_EnforcesTier(t *testing.T) {
+	inheritanceScript, tierKeys, utxo := createTestTieredContract(t, 180, 365)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	tiers := inheritanceScript.Tiers
+	utxos := []*UTXO{utxo}
+
+	// Tier 2 cannot spend with tier 1's shorter timelock
+	tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, tiers[0].RelativeTimelock, 0)
+	if err != nil {
+		t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+	}
+	if err := txBuilder.SignInheritorTierTransactionMulti(tx, utxos, inheritanceScript.RedeemScript, 1, tierKeys[1].Signer()); err != nil {
+		t.Fatalf("SignInheritorTierTransactionMulti failed: %v", err)
+	}
+	if err := txBuilder.ValidateTransactionWithScript(tx, utxo, inheritanceScript.RedeemScript); err == nil {
+		t.Errorf("Expected tier 2 to fail with tier 1's timelock")
+	}
+
+	// Tier 1's key does not satisfy tier 2's branch
+	if err := txBuilder.SignInheritorTierTransactionMulti(tx, utxos, inheritanceScript.RedeemScript, 1, tierKeys[0].Signer()); err == nil || !strings.Contains(err.Error(), "not the tier 2") {
+		t.Errorf("Expected a key mismatch, got %v", err)
+	}
+
+	// The single-inheritor witness does not select a tier
+	tx, err = txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, tiers[0].RelativeTimelock, 0)
+	if err != nil {
+		t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+	}
+	if err := txBuilder.SignInheritorTransaction(tx, utxo, inheritanceScript.RedeemScript, tierKeys[0].Signer()); err != nil {
+		t.Fatalf("SignInheritorTransaction failed: %v", err)
+	}
+	if err := txBuilder.ValidateTransactionWithScript(tx, utxo, inheritanceScript.RedeemScript); err == nil {
+		t.Errorf("Expected the single-inheritor witness to fail on a tiered script")
+	}
+
+	// A timelock of no tier is rejected when building
+	if _, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, tiers[0].RelativeTimelock+1, 0); err == nil {
+		t.Errorf("Expected a timelock mismatch")
+	}
+}
+
+func TestSignInheritorTierTransaction_LongestTier(t *testing.T) {
+	// 388 days is the longest tier BIP 68 can encode
+	inheritanceScript, tierKeys, utxo := createTestTieredContract(t, 180, 388)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	timelock := inheritanceScript.Tiers[1].RelativeTimelock
+	if timelock != 0x400000|65475 {
+		t.Fatalf("Expected the 388-day tier to encode as 0x%06x, got 0x%06x", 0x400000|65475, timelock)
+	}
+
+	tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t), inheritanceScript.RedeemScript, timelock, 0)
+	if err != nil {
+		t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+	}
+	if err := txBuilder.SignInheritorTierTransactionMulti(tx, []*UTXO{utxo}, inheritanceScript.RedeemScript, 1, tierKeys[1].Signer()); err != nil {
+		t.Fatalf("SignInheritorTierTransactionMulti failed: %v", err)
+	}
+	if err := txBuilder.ValidateTransactionWithScript(tx, utxo, inheritanceScript.RedeemScript); err != nil {
+		t.Errorf("Withdrawal of the 388-day tier does not validate: %v", err)
+	}
+
+	// A 400-day tier would wrap to about 12 days, before the 180-day tier
+	ownerKeys, err := keys.NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	_, err = script.NewTieredInheritanceScript(ownerKeys.GetCompressedPubKeyBytes(), []script.InheritorTier{
+		{PubKey: tierKeys[0].GetCompressedPubKeyBytes(), TimelockDays: 180},
+		{PubKey: tierKeys[1].GetCompressedPubKeyBytes(), TimelockDays: 400},
+	}, &chaincfg.TestNet3Params)
+	if err == nil || !strings.Contains(err.Error(), "BIP 68 maximum") {
+		t.Errorf("Expected the 400-day tier to be rejected, got %v", err)
+	}
+}
@@ -2,16 +2,21 @@ package transaction
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"log"
+	"math"
+	"sort"
 
-	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/fees"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
 )
 
 // UTXO represents an unspent transaction output
@@ -20,12 +25,18 @@ type UTXO struct {
 	Vout     uint32
 	Amount   btcutil.Amount
 	PkScript []byte
+
+	// AddressType is the contract output type; empty means P2WSH
+	AddressType script.AddressType
 }
 
 // TransactionBuilder helps build Bitcoin transactions
 type TransactionBuilder struct {
-	chainParams *chaincfg.Params
-	fee         btcutil.Amount
+	chainParams  *chaincfg.Params
+	fee          btcutil.Amount
+	feeEstimator fees.FeeEstimator
+	grindLowR    bool
+	hashType     txscript.SigHashType
 }
 
 // NewTransactionBuilder creates a new transaction builder
@@ -33,139 +44,389 @@ func NewTransactionBuilder(chainParams *chaincfg.Params, fee btcutil.Amount) *Tr
 	return &TransactionBuilder{
 		chainParams: chainParams,
 		fee:         fee,
+		grindLowR:   true,
+	}
+}
+
+// SetFeeEstimator sets the fee oracle used to derive fee rates
+func (tb *TransactionBuilder) SetFeeEstimator(estimator fees.FeeEstimator) {
+	tb.feeEstimator = estimator
+}
+
+// SetGrindLowR sets whether signatures are ground for a low R value, which
+// keeps every signature at 71 bytes (plus the sighash byte). Grinding is on
+// by default.
+func (tb *TransactionBuilder) SetGrindLowR(enabled bool) {
+	tb.grindLowR = enabled
+}
+
+// EstimateFeeRate returns the fee rate (sat/vB) for confirmation within
+// confTarget blocks according to the configured fee estimator
+func (tb *TransactionBuilder) EstimateFeeRate(confTarget int) (float64, error) {
+	if tb.feeEstimator == nil {
+		return 0, fmt.Errorf("no fee estimator configured")
+	}
+	return tb.feeEstimator.EstimateFeeRate(confTarget)
+}
+
+// txVersion is the version of built transactions. BIP 68 sequence locks, and
+// with them OP_CHECKSEQUENCEVERIFY, only apply from version 2 on.
+const txVersion = 2
+
+// RBFSequence is the highest input sequence signaling BIP 125 replaceability
+// while leaving nLockTime enforced
+const RBFSequence = wire.MaxTxInSequenceNum - 2
+
+// ownerSequence returns the sequence of an owner input. The IF path has no
+// timelock, so the input is final unless rbf asks to signal replaceability.
+func ownerSequence(rbf bool) uint32 {
+	if rbf {
+		return RBFSequence
 	}
+	return wire.MaxTxInSequenceNum
 }
 
-// BuildOwnerWithdrawTx builds a transaction for the owner to withdraw funds
+// BuildOwnerWithdrawTx builds a transaction for the owner to withdraw funds.
+// An amount of zero sweeps the whole UTXO; otherwise amount goes to
+// destinationAddr and the remainder back to the contract address (see
+// BuildOwnerPartialWithdrawTx). With rbf the input signals BIP 125
+// replaceability so a stuck withdrawal can be fee-bumped.
 func (tb *TransactionBuilder) BuildOwnerWithdrawTx(
 	contractUTXO *UTXO,
 	destinationAddr btcutil.Address,
 	redeemScript []byte,
+	amount btcutil.Amount,
+	rbf bool,
 ) (*wire.MsgTx, error) {
 
+	if amount != 0 {
+		changeScript, err := contractOutputScript(contractUTXO, redeemScript)
+		if err != nil {
+			return nil, err
+		}
+		return tb.BuildOwnerPartialWithdrawTx(contractUTXO, destinationAddr, redeemScript, amount, changeScript, rbf)
+	}
+
 	// Create new transaction
-	tx := wire.NewMsgTx(wire.TxVersion)
+	tx := wire.NewMsgTx(txVersion)
 
 	// Add input pointing to the contract UTXO
 	outPoint := wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout)
 	txIn := wire.NewTxIn(outPoint, nil, nil)
+	txIn.Sequence = ownerSequence(rbf)
 	tx.AddTxIn(txIn)
 
-	// Calculate output amount (input amount minus fee)
-	outputAmount := contractUTXO.Amount - tb.fee
-	if outputAmount <= 0 {
-		return nil, fmt.Errorf("insufficient funds: fee (%v) exceeds UTXO amount (%v)", tb.fee, contractUTXO.Amount)
-	}
-
-	// Create output script for destination address
-	destinationScript, err := txscript.PayToAddrScript(destinationAddr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create destination script: %w", err)
-	}
-
-	// Add output
-	txOut := wire.NewTxOut(int64(outputAmount), destinationScript)
-	tx.AddTxOut(txOut)
-
 	log.Printf("Built owner withdrawal transaction")
 	log.Printf("  Input: %s:%d (%v satoshis)", contractUTXO.TxHash, contractUTXO.Vout, contractUTXO.Amount)
-	log.Printf("  Output: %s (%v satoshis)", destinationAddr.EncodeAddress(), outputAmount)
-	log.Printf("  Fee: %v satoshis", tb.fee)
+	if err := tb.addWithdrawalOutputs(tx, contractUTXO.Amount, destinationAddr, 0, nil); err != nil {
+		return nil, err
+	}
 
 	return tx, nil
 }
 
-// BuildInheritorWithdrawTx builds a transaction for the inheritor to withdraw funds
-func (tb *TransactionBuilder) BuildInheritorWithdrawTx(
+// BuildOwnerPartialWithdrawTx builds a transaction for the owner to withdraw
+// part of the funds, paying the remainder (minus fee) to changeScript. Passing
+// the contract's own P2WSH scriptPubKey keeps the change under inheritance
+// protection. Change below the dust threshold is added to the fee. rbf is as
+// for BuildOwnerWithdrawTx.
+func (tb *TransactionBuilder) BuildOwnerPartialWithdrawTx(
 	contractUTXO *UTXO,
 	destinationAddr btcutil.Address,
 	redeemScript []byte,
-	relativeTimelock int64,
+	amount btcutil.Amount,
+	changeScript []byte,
+	rbf bool,
 ) (*wire.MsgTx, error) {
 
+	if amount <= 0 {
+		return nil, fmt.Errorf("withdrawal amount must be positive")
+	}
+
 	// Create new transaction
-	tx := wire.NewMsgTx(wire.TxVersion)
+	tx := wire.NewMsgTx(txVersion)
 
 	// Add input pointing to the contract UTXO
 	outPoint := wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout)
 	txIn := wire.NewTxIn(outPoint, nil, nil)
-
-	// CRITICAL: Set the sequence field to satisfy OP_CHECKSEQUENCEVERIFY
-	txIn.Sequence = uint32(relativeTimelock)
-
+	txIn.Sequence = ownerSequence(rbf)
 	tx.AddTxIn(txIn)
 
-	// Calculate output amount (input amount minus fee)
-	outputAmount := contractUTXO.Amount - tb.fee
-	if outputAmount <= 0 {
-		return nil, fmt.Errorf("insufficient funds: fee (%v) exceeds UTXO amount (%v)", tb.fee, contractUTXO.Amount)
+	log.Printf("Built owner partial withdrawal transaction")
+	log.Printf("  Input: %s:%d (%v satoshis)", contractUTXO.TxHash, contractUTXO.Vout, contractUTXO.Amount)
+	if err := tb.addWithdrawalOutputs(tx, contractUTXO.Amount, destinationAddr, amount, changeScript); err != nil {
+		return nil, err
 	}
 
+	return tx, nil
+}
+
+// addWithdrawalOutputs adds the outputs of a withdrawal spending inputAmount
+// and paying tb.fee, and logs them. An amount of zero sends inputAmount minus
+// fee to destinationAddr. Otherwise amount goes to destinationAddr and the
+// remainder to changeScript, unless that change is below the dust threshold:
+// it is then added to the fee rather than creating an output no node relays.
+func (tb *TransactionBuilder) addWithdrawalOutputs(
+	tx *wire.MsgTx,
+	inputAmount btcutil.Amount,
+	destinationAddr btcutil.Address,
+	amount btcutil.Amount,
+	changeScript []byte,
+) error {
+
 	// Create output script for destination address
 	destinationScript, err := txscript.PayToAddrScript(destinationAddr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create destination script: %w", err)
+		return fmt.Errorf("failed to create destination script: %w", err)
 	}
 
-	// Add output
-	txOut := wire.NewTxOut(int64(outputAmount), destinationScript)
-	tx.AddTxOut(txOut)
+	if amount < 0 {
+		return fmt.Errorf("withdrawal amount must not be negative")
+	}
+	if amount == 0 {
+		// Calculate output amount (input amount minus fee)
+		amount = inputAmount - tb.fee
+		if amount <= 0 {
+			return fmt.Errorf("insufficient funds: fee (%v) exceeds UTXO amount (%v)", tb.fee, inputAmount)
+		}
+		changeScript = nil
+	}
+	if err := checkDust(amount, destinationScript); err != nil {
+		return err
+	}
+	tx.AddTxOut(wire.NewTxOut(int64(amount), destinationScript))
+	log.Printf("  Output: %s (%v satoshis)", destinationAddr.EncodeAddress(), amount)
+
+	fee := tb.fee
+	if changeScript != nil {
+		// Calculate change amount (input amount minus withdrawal and fee)
+		changeAmount := inputAmount - amount - tb.fee
+		if changeAmount < 0 {
+			return fmt.Errorf("insufficient funds: amount (%v) plus fee (%v) exceeds UTXO amount (%v)",
+				amount, tb.fee, inputAmount)
+		}
+		if changeAmount < DustThreshold(changeScript) {
+			fee += changeAmount
+			log.Printf("  Change: %v satoshis is below the dust threshold; added to the fee", changeAmount)
+		} else {
+			tx.AddTxOut(wire.NewTxOut(int64(changeAmount), changeScript))
+			log.Printf("  Change: %x (%v satoshis)", changeScript, changeAmount)
+		}
+	}
+	log.Printf("  Fee: %v satoshis", fee)
 
-	log.Printf("Built inheritor withdrawal transaction")
-	log.Printf("  Input: %s:%d (%v satoshis)", contractUTXO.TxHash, contractUTXO.Vout, contractUTXO.Amount)
-	log.Printf("  Output: %s (%v satoshis)", destinationAddr.EncodeAddress(), outputAmount)
-	log.Printf("  Fee: %v satoshis", tb.fee)
-	log.Printf("  Sequence: %d (timelock)", relativeTimelock)
+	return nil
+}
 
-	return tx, nil
+// contractOutputScript returns the output script of the contract UTXO, which
+// receives the change of a partial withdrawal. A PkScript supplied with the
+// UTXO is used once checked against redeemScript, as contractPkScript does.
+func contractOutputScript(contractUTXO *UTXO, redeemScript []byte) ([]byte, error) {
+	if contractUTXO.PkScript != nil {
+		if _, _, err := contractPkScript(contractUTXO, redeemScript); err != nil {
+			return nil, err
+		}
+		return contractUTXO.PkScript, nil
+	}
+	return derivePkScript(contractUTXO.AddressType, redeemScript)
 }
 
-// SignOwnerTransaction signs a transaction for the owner using the IF path
-func (tb *TransactionBuilder) SignOwnerTransaction(
-	tx *wire.MsgTx,
+// BuildInheritorWithdrawTx builds a transaction for the inheritor to withdraw
+// funds. timelock is the value the redeem script enforces: the BIP 68
+// sequence of a relative timelock, or the lock time of an absolute one. An
+// amount of zero sweeps the whole UTXO; otherwise the remainder goes back to
+// the contract address, where it is locked again.
+func (tb *TransactionBuilder) BuildInheritorWithdrawTx(
 	contractUTXO *UTXO,
+	destinationAddr btcutil.Address,
 	redeemScript []byte,
-	ownerPrivateKey *btcec.PrivateKey,
-) error {
-	// Create a MultiPrevOutFetcher for the UTXO
-	prevOutFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	timelock int64,
+	amount btcutil.Amount,
+) (*wire.MsgTx, error) {
 
-	// Create the P2WSH output script from the redeem script
-	scriptHash := btcutil.Hash160(redeemScript)
-	p2wshScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash).Script()
+	parsed, err := tb.checkScriptTimelock(redeemScript, timelock)
 	if err != nil {
-		return fmt.Errorf("failed to create P2WSH script: %w", err)
+		return nil, err
+	}
+
+	// Create new transaction
+	tx := wire.NewMsgTx(txVersion)
+
+	// Add input pointing to the contract UTXO
+	outPoint := wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout)
+	tx.AddTxIn(wire.NewTxIn(outPoint, nil, nil))
+	setInheritorTimelock(tx, parsed, timelock)
+
+	var changeScript []byte
+	if amount != 0 {
+		var err error
+		if changeScript, err = contractOutputScript(contractUTXO, redeemScript); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Built inheritor withdrawal transaction")
+	log.Printf("  Input: %s:%d (%v satoshis)", contractUTXO.TxHash, contractUTXO.Vout, contractUTXO.Amount)
+	if err := tb.addWithdrawalOutputs(tx, contractUTXO.Amount, destinationAddr, amount, changeScript); err != nil {
+		return nil, err
+	}
+	if parsed.TimelockType == script.Absolute {
+		log.Printf("  Lock time: %s", script.FormatLockTime(timelock))
+	} else {
+		log.Printf("  Sequence: %d (timelock)", timelock)
+	}
+
+	return tx, nil
+}
+
+// checkScriptTimelock parses redeemScript and checks that it enforces
+// timelock, for any inheritor tier of a tiered script. A mismatch would only
+// be rejected at broadcast (e.g. a stale contract file).
+func (tb *TransactionBuilder) checkScriptTimelock(redeemScript []byte, timelock int64) (*script.InheritanceScript, error) {
+	parsed, err := script.ParseRedeemScript(redeemScript, tb.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timelock from redeem script: %w", err)
+	}
+	scriptTimelock := parsed.RelativeTimelock
+	if parsed.TimelockType == script.Absolute {
+		scriptTimelock = parsed.LockTime
+	}
+	for _, tier := range parsed.Tiers {
+		if tier.RelativeTimelock == timelock {
+			scriptTimelock = timelock
+		}
+	}
+	if scriptTimelock != timelock {
+		return nil, fmt.Errorf("timelock mismatch: redeem script enforces %d but sequence would be %d",
+			scriptTimelock, timelock)
+	}
+	return parsed, nil
+}
+
+// setInheritorTimelock sets the lock time and the sequence of every input of
+// an inheritor withdrawal so the ELSE branch's timelock check passes
+func setInheritorTimelock(tx *wire.MsgTx, parsed *script.InheritanceScript, timelock int64) {
+	if parsed.TimelockType == script.Absolute {
+		// CRITICAL: OP_CHECKLOCKTIMEVERIFY compares against nLockTime, which
+		// is only enforced when the input is not final. The sequence also
+		// signals replaceability (BIP 125) so fee alternatives can replace
+		// each other, as they do for relative timelocks.
+		tx.LockTime = uint32(timelock)
+		for _, txIn := range tx.TxIn {
+			txIn.Sequence = RBFSequence
+		}
+		return
+	}
+
+	// CRITICAL: Set the sequence field to satisfy OP_CHECKSEQUENCEVERIFY.
+	// A BIP 68 sequence is always below RBFSequence, so the input is
+	// replaceable (BIP 125) without asking for it.
+	for _, txIn := range tx.TxIn {
+		txIn.Sequence = uint32(timelock)
 	}
+}
 
-	// Add the UTXO to the fetcher
-	prevOut := &wire.TxOut{
-		Value:    int64(contractUTXO.Amount),
-		PkScript: p2wshScript,
+// BuildInheritorWithdrawTxAtFees builds a ladder of inheritor withdrawal
+// transactions paying increasing fee rates (sat/vB), sorted cheapest first.
+//
+// The CSV sequence of the inheritor input signals BIP 125, so each
+// alternative can replace the previous one, but a bump at a new fee rate
+// means signing a new transaction with the inheritor key. Pre-building every
+// alternative up front lets the inheritor broadcast the cheapest one and, if
+// it stalls, broadcast the next one without access to the key. All
+// alternatives spend the same UTXO, so at most one can confirm. The fee
+// levels are fixed when the ladder is built and each step still has to
+// satisfy the node's replacement rules (a higher absolute fee and fee rate).
+func (tb *TransactionBuilder) BuildInheritorWithdrawTxAtFees(
+	contractUTXO *UTXO,
+	destinationAddr btcutil.Address,
+	redeemScript []byte,
+	timelock int64,
+	feeRates []float64,
+) ([]*wire.MsgTx, error) {
+	if len(feeRates) == 0 {
+		return nil, fmt.Errorf("at least one fee rate is required")
 	}
-	prevOutFetcher.AddPrevOut(*wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout), prevOut)
 
-	// Generate signature hash for the transaction
-	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
-	hashType := txscript.SigHashAll
+	rates := append([]float64(nil), feeRates...)
+	sort.Float64s(rates)
+	if rates[0] <= 0 {
+		return nil, fmt.Errorf("fee rates must be positive")
+	}
 
-	sigHash, err := txscript.CalcWitnessSigHash(redeemScript, sigHashes, hashType, tx, 0, int64(contractUTXO.Amount))
+	// Build a template to measure the size; the output value does not affect it
+	template, err := tb.BuildInheritorWithdrawTx(contractUTXO, destinationAddr, redeemScript, timelock, 0)
 	if err != nil {
-		return fmt.Errorf("failed to calculate signature hash: %w", err)
+		return nil, err
+	}
+	vsize := estimateVirtualSize(template, redeemScript)
+
+	txs := make([]*wire.MsgTx, 0, len(rates))
+	for _, rate := range rates {
+		fee := btcutil.Amount(math.Ceil(float64(vsize) * rate))
+		rateBuilder := NewTransactionBuilder(tb.chainParams, fee)
+
+		tx, err := rateBuilder.BuildInheritorWithdrawTx(contractUTXO, destinationAddr, redeemScript, timelock, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build transaction at %.2f sat/vB: %w", rate, err)
+		}
+		txs = append(txs, tx)
 	}
 
-	// Sign the hash with the owner's private key
-	sig := ecdsa.Sign(ownerPrivateKey, sigHash)
-	sigBytes := append(sig.Serialize(), byte(hashType))
+	log.Printf("Built %d inheritor withdrawal alternatives (%d vbytes each)", len(txs), vsize)
+	return txs, nil
+}
+
+// estimateVirtualSize estimates the virtual size of a contract spend once
+// signed, assuming a worst-case 73-byte signature, the branch selector and
+// the redeem script in the witness of every input
+func estimateVirtualSize(tx *wire.MsgTx, redeemScript []byte) int64 {
+	return VirtualSize(withEstimatedWitness(tx, redeemScript))
+}
+
+// withEstimatedWitness returns a copy of tx whose inputs carry a witness the
+// size of a signed contract spend. An M-of-N owner path is sized with its
+// M signatures and the CHECKMULTISIG dummy element, and a tiered script with
+// the branch selectors of its deepest tier.
+func withEstimatedWitness(tx *wire.MsgTx, redeemScript []byte) *wire.MsgTx {
+	witness := estimatedWitness(redeemScript)
+	sized := tx.Copy()
+	for _, txIn := range sized.TxIn {
+		txIn.Witness = witness
+	}
+	return sized
+}
 
-	// Assemble witness: [signature, OP_1 (true), redeemScript]
-	witness := wire.TxWitness{
-		sigBytes,
-		{txscript.OP_1}, // OP_1 to take the IF path
-		redeemScript,
+// estimatedWitness returns a witness the size of a signed spend of
+// redeemScript, as described for withEstimatedWitness
+func estimatedWitness(redeemScript []byte) wire.TxWitness {
+	var stack wire.TxWitness
+	if parsed, err := script.ParseRedeemScript(redeemScript, nil); err == nil {
+		if parsed.IsMultisigOwner() {
+			stack = append(stack, nil)
+			for i := 1; i < parsed.OwnerRequired; i++ {
+				stack = append(stack, make([]byte, 73))
+			}
+		}
+		// A deeper inheritor tier is selected by one more item per level
+		for i := 1; i < len(parsed.Tiers); i++ {
+			stack = append(stack, script.OwnerSelector)
+		}
 	}
+	return append(stack, make([]byte, 73), script.OwnerSelector, redeemScript)
+}
 
-	// Set the witness for the first (and only) input
-	tx.TxIn[0].Witness = witness
+// SignOwnerTransaction signs a transaction for the owner using the IF path
+func (tb *TransactionBuilder) SignOwnerTransaction(
+	tx *wire.MsgTx,
+	contractUTXO *UTXO,
+	redeemScript []byte,
+	ownerSigner keys.Signer,
+) error {
+	// true (0x01) takes the IF path
+	if err := tb.signContractInputs(tx, []*UTXO{contractUTXO}, redeemScript, ownerSigner, [][]byte{script.OwnerSelector}); err != nil {
+		return err
+	}
 
 	log.Printf("Transaction signed successfully with owner's key (IF path)")
 	return nil
@@ -176,50 +437,175 @@ func (tb *TransactionBuilder) SignInheritorTransaction(
 	tx *wire.MsgTx,
 	contractUTXO *UTXO,
 	redeemScript []byte,
-	inheritorPrivateKey *btcec.PrivateKey,
+	inheritorSigner keys.Signer,
 ) error {
-	// Create a MultiPrevOutFetcher for the UTXO
-	prevOutFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	// empty (false) takes the ELSE path
+	if err := tb.signContractInputs(tx, []*UTXO{contractUTXO}, redeemScript, inheritorSigner, [][]byte{script.InheritorSelector}); err != nil {
+		return err
+	}
+
+	log.Printf("Transaction signed successfully with inheritor's key (ELSE path)")
+	return nil
+}
 
-	// Create the P2WSH output script from the redeem script
-	scriptHash := btcutil.Hash160(redeemScript)
-	p2wshScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash).Script()
+// signContractInputs signs input i of tx, which spends contractUTXOs[i], and
+// sets its witness to [signature, selectors..., redeemScript]
+func (tb *TransactionBuilder) signContractInputs(
+	tx *wire.MsgTx,
+	contractUTXOs []*UTXO,
+	redeemScript []byte,
+	signer keys.Signer,
+	selectors [][]byte,
+) error {
+	sigHashes, sigScripts, err := contractSigHashes(tx, contractUTXOs, redeemScript)
 	if err != nil {
-		return fmt.Errorf("failed to create P2WSH script: %w", err)
+		return err
+	}
+	hashType := tb.sigHashType()
+
+	for i, contractUTXO := range contractUTXOs {
+		if err := checkSigHashType(tx, i, hashType); err != nil {
+			return err
+		}
+		sigHash, err := txscript.CalcWitnessSigHash(redeemScript, sigHashes, hashType, tx, i, int64(contractUTXO.Amount))
+		if err != nil {
+			return fmt.Errorf("failed to calculate signature hash: %w", err)
+		}
+
+		sig, err := tb.sign(signer, sigHash)
+		if err != nil {
+			return err
+		}
+
+		witness := wire.TxWitness{withHashType(sig, hashType)}
+		witness = append(witness, selectors...)
+		tx.TxIn[i].Witness = append(witness, redeemScript)
+		tx.TxIn[i].SignatureScript = sigScripts[i]
+	}
+
+	return nil
+}
+
+// contractSigHashes returns the signature hash midstate of tx, whose input i
+// spends contractUTXOs[i], and the signature script of each input
+func contractSigHashes(tx *wire.MsgTx, contractUTXOs []*UTXO, redeemScript []byte) (*txscript.TxSigHashes, [][]byte, error) {
+	if len(contractUTXOs) != len(tx.TxIn) {
+		return nil, nil, fmt.Errorf("transaction has %d inputs but %d UTXOs were given", len(tx.TxIn), len(contractUTXOs))
 	}
 
-	// Add the UTXO to the fetcher
-	prevOut := &wire.TxOut{
-		Value:    int64(contractUTXO.Amount),
-		PkScript: p2wshScript,
+	// Every input's previous output goes into the signature hash
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	sigScripts := make([][]byte, len(contractUTXOs))
+	for i, contractUTXO := range contractUTXOs {
+		// Use the UTXO's own output script when known, otherwise derive it
+		pkScript, sigScript, err := contractPkScript(contractUTXO, redeemScript)
+		if err != nil {
+			return nil, nil, err
+		}
+		prevOutFetcher.AddPrevOut(*wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout),
+			wire.NewTxOut(int64(contractUTXO.Amount), pkScript))
+		sigScripts[i] = sigScript
 	}
-	prevOutFetcher.AddPrevOut(*wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout), prevOut)
 
-	// Generate signature hash for the transaction
-	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
-	hashType := txscript.SigHashAll
+	return txscript.NewTxSigHashes(tx, prevOutFetcher), sigScripts, nil
+}
+
+// sign creates an ECDSA signature over a signature hash, grinding for a
+// low R value when enabled and the signer supports it. The signature is
+// checked against the signer's public key and re-encoded with a low S value,
+// since an external signer may return a high-S signature that is not standard.
+func (tb *TransactionBuilder) sign(signer keys.Signer, sigHash []byte) ([]byte, error) {
+	var der []byte
+	var err error
+	if lowRSigner, ok := signer.(keys.LowRSigner); ok && tb.grindLowR {
+		if der, err = lowRSigner.SignLowR(sigHash); err != nil {
+			return nil, fmt.Errorf("failed to grind low-R signature: %w", err)
+		}
+	} else if der, err = signer.Sign(sigHash); err != nil {
+		return nil, fmt.Errorf("signer failed: %w", err)
+	}
 
-	sigHash, err := txscript.CalcWitnessSigHash(redeemScript, sigHashes, hashType, tx, 0, int64(contractUTXO.Amount))
+	sig, err := ecdsa.ParseDERSignature(der)
 	if err != nil {
-		return fmt.Errorf("failed to calculate signature hash: %w", err)
+		return nil, fmt.Errorf("signer returned an invalid signature: %w", err)
+	}
+	if tb.grindLowR && !keys.HasLowR(sig) {
+		log.Printf("Warning: signer cannot grind low-R signatures; this signature is one byte larger")
+	}
+	if !sig.Verify(sigHash, signer.PublicKey()) {
+		return nil, fmt.Errorf("signature does not verify against the signer's public key")
 	}
 
-	// Sign the hash with the inheritor's private key
-	sig := ecdsa.Sign(inheritorPrivateKey, sigHash)
-	sigBytes := append(sig.Serialize(), byte(hashType))
+	return sig.Serialize(), nil
+}
+
+// contractPkScript returns the output script of the contract UTXO and the
+// signature script its spend needs, which is only set for nested P2SH-P2WSH.
+// A PkScript supplied with the UTXO (e.g. from gettxout) is used as-is after
+// checking that it commits to redeemScript.
+func contractPkScript(contractUTXO *UTXO, redeemScript []byte) ([]byte, []byte, error) {
+	switch contractUTXO.AddressType {
+	case "", script.AddressTypeP2WSH:
+		pkScript, err := p2wshPkScript(contractUTXO, redeemScript)
+		return pkScript, nil, err
+	case script.AddressTypeP2SHP2WSH:
+		return p2shP2WSHPkScript(contractUTXO, redeemScript)
+	case script.AddressTypeP2TR:
+		return nil, nil, fmt.Errorf("spending taproot contracts is not supported")
+	default:
+		return nil, nil, fmt.Errorf("unknown contract address type %q", contractUTXO.AddressType)
+	}
+}
 
-	// Assemble witness: [signature, OP_0 (false), redeemScript]
-	witness := wire.TxWitness{
-		sigBytes,
-		{txscript.OP_0}, // OP_0 to take the ELSE path
-		redeemScript,
+// p2wshPkScript returns the output script of a native P2WSH contract UTXO
+func p2wshPkScript(contractUTXO *UTXO, redeemScript []byte) ([]byte, error) {
+	// The witness program of P2WSH is the 32-byte SHA256 of the script, not
+	// the 20-byte HASH160 P2SH uses
+	scriptHash := sha256.Sum256(redeemScript)
+	if contractUTXO.PkScript == nil {
+		p2wshScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create P2WSH script: %w", err)
+		}
+		return p2wshScript, nil
 	}
 
-	// Set the witness for the first (and only) input
-	tx.TxIn[0].Witness = witness
+	if txscript.GetScriptClass(contractUTXO.PkScript) != txscript.WitnessV0ScriptHashTy {
+		return nil, fmt.Errorf("UTXO script %x is not a P2WSH output", contractUTXO.PkScript)
+	}
 
-	log.Printf("Transaction signed successfully with inheritor's key (ELSE path)")
-	return nil
+	if !bytes.Equal(contractUTXO.PkScript[2:], scriptHash[:]) {
+		return nil, fmt.Errorf("UTXO script %x does not commit to the contract redeem script", contractUTXO.PkScript)
+	}
+
+	return contractUTXO.PkScript, nil
+}
+
+// p2shP2WSHPkScript returns the output script of a P2SH-P2WSH contract UTXO
+// and the signature script pushing the nested witness program
+func p2shP2WSHPkScript(contractUTXO *UTXO, redeemScript []byte) ([]byte, []byte, error) {
+	scriptHash := sha256.Sum256(redeemScript)
+	witnessProgram, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create witness program: %w", err)
+	}
+
+	pkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_HASH160).AddData(btcutil.Hash160(witnessProgram)).AddOp(txscript.OP_EQUAL).
+		Script()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create P2SH script: %w", err)
+	}
+	if contractUTXO.PkScript != nil && !bytes.Equal(contractUTXO.PkScript, pkScript) {
+		return nil, nil, fmt.Errorf("UTXO script %x does not commit to the contract redeem script", contractUTXO.PkScript)
+	}
+
+	sigScript, err := txscript.NewScriptBuilder().AddData(witnessProgram).Script()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create signature script: %w", err)
+	}
+
+	return pkScript, sigScript, nil
 }
 
 // ValidateTransaction performs basic validation on a transaction
@@ -250,6 +636,80 @@ func (tb *TransactionBuilder) ValidateTransaction(tx *wire.MsgTx) error {
 	return nil
 }
 
+// ValidateTransactionWithScript runs ValidateTransaction and then executes the
+// signed contract input against the contract output with
+// txscript.StandardVerifyFlags, so a signing error is caught locally instead
+// of by the network after the fee was paid. The output script is the UTXO's
+// own PkScript when known, otherwise it is derived from redeemScript.
+func (tb *TransactionBuilder) ValidateTransactionWithScript(tx *wire.MsgTx, contractUTXO *UTXO, redeemScript []byte) error {
+	return tb.ValidateTransactionWithScriptMulti(tx, []*UTXO{contractUTXO}, redeemScript)
+}
+
+// ValidateTransactionWithScriptMulti is ValidateTransactionWithScript for a
+// transaction whose input i spends contractUTXOs[i]
+func (tb *TransactionBuilder) ValidateTransactionWithScriptMulti(tx *wire.MsgTx, contractUTXOs []*UTXO, redeemScript []byte) error {
+	if err := tb.ValidateTransaction(tx); err != nil {
+		return err
+	}
+	if len(contractUTXOs) != len(tx.TxIn) {
+		return fmt.Errorf("transaction has %d inputs but %d UTXOs were given", len(tx.TxIn), len(contractUTXOs))
+	}
+
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for _, contractUTXO := range contractUTXOs {
+		pkScript, err := contractOutputScript(contractUTXO, redeemScript)
+		if err != nil {
+			return err
+		}
+		prevOuts.AddPrevOut(*wire.NewOutPoint(contractUTXO.TxHash, contractUTXO.Vout),
+			wire.NewTxOut(int64(contractUTXO.Amount), pkScript))
+	}
+	return executeInputs(tx, prevOuts)
+}
+
+// executeInputs runs the script of every input of tx against the previous
+// output prevOuts holds for it, with txscript.StandardVerifyFlags
+func executeInputs(tx *wire.MsgTx, prevOuts *txscript.MultiPrevOutFetcher) error {
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+
+	for i, txIn := range tx.TxIn {
+		prevOut := prevOuts.FetchPrevOutput(txIn.PreviousOutPoint)
+		if prevOut == nil {
+			return fmt.Errorf("input %d spends %s, which is not a given UTXO", i, txIn.PreviousOutPoint)
+		}
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags,
+			nil, sigHashes, prevOut.Value, prevOuts)
+		if err != nil {
+			return fmt.Errorf("failed to create script engine: %w", err)
+		}
+		if err := engine.Execute(); err != nil {
+			return fmt.Errorf("signed input %d does not satisfy the contract script: %w", i, err)
+		}
+	}
+
+	log.Printf("Script execution passed")
+	return nil
+}
+
+// derivePkScript returns the output script of a contract with the given
+// address type from its redeem script
+func derivePkScript(addressType script.AddressType, redeemScript []byte) ([]byte, error) {
+	switch addressType {
+	case "", script.AddressTypeP2WSH:
+		scriptHash := sha256.Sum256(redeemScript)
+		pkScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create P2WSH script: %w", err)
+		}
+		return pkScript, nil
+	case script.AddressTypeP2SHP2WSH:
+		pkScript, _, err := p2shP2WSHPkScript(&UTXO{}, redeemScript)
+		return pkScript, err
+	default:
+		return nil, fmt.Errorf("cannot verify spends of %q contracts", addressType)
+	}
+}
+
 // SerializeTransaction serializes a transaction to hex string
 func (tb *TransactionBuilder) SerializeTransaction(tx *wire.MsgTx) (string, error) {
 	// Serialize the transaction to bytes
@@ -0,0 +1,791 @@
+package transaction
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nikolay.stoev/bitcoin-inheritance/keys"
+	"github.com/nikolay.stoev/bitcoin-inheritance/script"
+)
+
+// contractScript is a contract whose output a test UTXO pays to
+type contractScript interface {
+	GetScriptPubKey() ([]byte, error)
+}
+
+// newTestContract generates numKeys testnet key pairs, builds a contract
+// from them with build and returns it with the keys and a funded UTXO
+// paying to its output script
+func newTestContract[S contractScript](t *testing.T, numKeys int, build func(keyPairs []*keys.KeyPair) (S, error)) (S, []*keys.KeyPair, *UTXO) {
+	t.Helper()
+
+	keyPairs := make([]*keys.KeyPair, numKeys)
+	for i := range keyPairs {
+		kp, err := keys.NewKeyPair(&chaincfg.TestNet3Params)
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		keyPairs[i] = kp
+	}
+
+	contract, err := build(keyPairs)
+	if err != nil {
+		t.Fatalf("Failed to build contract script: %v", err)
+	}
+	pkScript, err := contract.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+
+	fundingHash, err := chainhash.NewHashFromStr("6a7bd5b2e6c4d3f3c1f84fb1c7f3f0b2d9e5a4c3b2a1908f7e6d5c4b3a291807")
+	if err != nil {
+		t.Fatalf("Failed to parse funding hash: %v", err)
+	}
+	utxo := &UTXO{
+		TxHash:   fundingHash,
+		Vout:     0,
+		Amount:   btcutil.Amount(100000),
+		PkScript: pkScript,
+	}
+
+	return contract, keyPairs, utxo
+}
+
+// Test helper to build a contract script and a funded UTXO paying to it
+func createTestContract(t *testing.T) (*script.InheritanceScript, *UTXO) {
+	t.Helper()
+
+	inheritanceScript, _, utxo := newTestContract(t, 2, func(keyPairs []*keys.KeyPair) (*script.InheritanceScript, error) {
+		return script.NewInheritanceScript(keyPairs[0].GetCompressedPubKeyBytes(), keyPairs[1].GetCompressedPubKeyBytes(),
+			180, &chaincfg.TestNet3Params)
+	})
+	// Builders derive the output script from the UTXO's address type
+	utxo.PkScript = nil
+	return inheritanceScript, utxo
+}
+
+// Test helper to create a testnet P2WPKH destination address
+func createTestDestination(t *testing.T) btcutil.Address {
+	t.Helper()
+
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to create destination address: %v", err)
+	}
+	return addr
+}
+
+func TestBuildInheritorWithdrawTx_SequenceMatchesScript(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t),
+		inheritanceScript.RedeemScript, inheritanceScript.RelativeTimelock, 0)
+	if err != nil {
+		t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+	}
+
+	if tx.TxIn[0].Sequence != uint32(inheritanceScript.RelativeTimelock) {
+		t.Errorf("Expected sequence %d, got %d", inheritanceScript.RelativeTimelock, tx.TxIn[0].Sequence)
+	}
+	if tx.Version < 2 {
+		t.Errorf("OP_CHECKSEQUENCEVERIFY needs transaction version 2, got %d", tx.Version)
+	}
+}
+
+func TestBuildInheritorWithdrawTx_TimelockMismatch(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	// A stale block-count estimate instead of the script's BIP 68 value
+	staleTimelock := int64(180 * 24 * 6)
+
+	_, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t),
+		inheritanceScript.RedeemScript, staleTimelock, 0)
+	if err == nil {
+		t.Fatal("Expected timelock mismatch error but got none")
+	}
+	if !strings.Contains(err.Error(), "timelock mismatch") {
+		t.Errorf("Expected timelock mismatch error, got: %v", err)
+	}
+}
+
+func TestBuildInheritorWithdrawTx_AbsoluteTimelock(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	_, utxo := createTestContract(t)
+
+	for _, lockTime := range []int64{1893456000, 900000} {
+		inheritanceScript, err := script.NewInheritanceScript(
+			ownerKey.PubKey().SerializeCompressed(),
+			inheritorKey.PubKey().SerializeCompressed(),
+			180,
+			&chaincfg.TestNet3Params,
+			script.WithAbsoluteTimelock(lockTime),
+		)
+		if err != nil {
+			t.Fatalf("NewInheritanceScript failed: %v", err)
+		}
+		pkScript, err := inheritanceScript.GetScriptPubKey()
+		if err != nil {
+			t.Fatalf("GetScriptPubKey failed: %v", err)
+		}
+		utxo.PkScript = pkScript
+
+		txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+		if _, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t),
+			inheritanceScript.RedeemScript, lockTime-1, 0); err == nil || !strings.Contains(err.Error(), "timelock mismatch") {
+			t.Errorf("Expected timelock mismatch error, got: %v", err)
+		}
+
+		tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, createTestDestination(t),
+			inheritanceScript.RedeemScript, lockTime, 0)
+		if err != nil {
+			t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+		}
+		if tx.LockTime != uint32(lockTime) {
+			t.Errorf("Expected lock time %d, got %d", lockTime, tx.LockTime)
+		}
+		if tx.TxIn[0].Sequence == wire.MaxTxInSequenceNum {
+			t.Error("Input sequence is final, so the lock time would not be enforced")
+		}
+
+		// The signed spend must satisfy OP_CHECKLOCKTIMEVERIFY
+		if err := txBuilder.SignInheritorTransaction(tx, utxo, inheritanceScript.RedeemScript, keys.NewLocalSigner(inheritorKey)); err != nil {
+			t.Fatalf("SignInheritorTransaction failed: %v", err)
+		}
+		prevOuts := txscript.NewCannedPrevOutputFetcher(pkScript, int64(utxo.Amount))
+		engine, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags,
+			nil, txscript.NewTxSigHashes(tx, prevOuts), int64(utxo.Amount), prevOuts)
+		if err != nil {
+			t.Fatalf("NewEngine failed: %v", err)
+		}
+		if err := engine.Execute(); err != nil {
+			t.Errorf("Signed spend with lock time %d does not validate: %v", lockTime, err)
+		}
+	}
+}
+
+func TestWithdrawAmount_ChangeToContract(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	redeemScript := inheritanceScript.RedeemScript
+	destAddr := createTestDestination(t)
+	contractScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	build := map[string]func(amount btcutil.Amount) (*wire.MsgTx, error){
+		"owner": func(amount btcutil.Amount) (*wire.MsgTx, error) {
+			return txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, redeemScript, amount, false)
+		},
+		"inheritor": func(amount btcutil.Amount) (*wire.MsgTx, error) {
+			return txBuilder.BuildInheritorWithdrawTx(utxo, destAddr, redeemScript, inheritanceScript.RelativeTimelock, amount)
+		},
+	}
+
+	// The UTXO holds 100000 sat and the fee is 500 sat; P2WSH dust is 330 sat
+	tests := []struct {
+		name     string
+		amount   btcutil.Amount
+		expected []int64 // output values, the second one being the change
+		wantErr  bool
+	}{
+		{"sweep all", 0, []int64{99500}, false},
+		{"partial with change", 40000, []int64{40000, 59500}, false},
+		{"change at dust threshold", 99170, []int64{99170, 330}, false},
+		{"dust change goes to the fee", 99200, []int64{99200}, false},
+		{"no change left", 99500, []int64{99500}, false},
+		{"amount plus fee exceeds UTXO", 99600, nil, true},
+		{"negative amount", -1, nil, true},
+	}
+
+	for path, buildTx := range build {
+		for _, tt := range tests {
+			t.Run(path+"/"+tt.name, func(t *testing.T) {
+				tx, err := buildTx(tt.amount)
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("Expected error, got outputs %v", tx.TxOut)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Building the withdrawal failed: %v", err)
+				}
+
+				if len(tx.TxOut) != len(tt.expected) {
+					t.Fatalf("Expected %d outputs, got %d", len(tt.expected), len(tx.TxOut))
+				}
+				for i, value := range tt.expected {
+					if tx.TxOut[i].Value != value {
+						t.Errorf("Output %d: expected %d sat, got %d", i, value, tx.TxOut[i].Value)
+					}
+				}
+				if len(tx.TxOut) == 2 && !bytes.Equal(tx.TxOut[1].PkScript, contractScript) {
+					t.Errorf("Change pays %x, not the contract script %x", tx.TxOut[1].PkScript, contractScript)
+				}
+			})
+		}
+	}
+}
+
+func TestWithdrawSequenceSignalsRBF(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	redeemScript := inheritanceScript.RedeemScript
+	destAddr := createTestDestination(t)
+	changeScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	build := map[string]func(rbf bool) (*wire.MsgTx, error){
+		"full": func(rbf bool) (*wire.MsgTx, error) {
+			return txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, redeemScript, 0, rbf)
+		},
+		"partial": func(rbf bool) (*wire.MsgTx, error) {
+			return txBuilder.BuildOwnerPartialWithdrawTx(utxo, destAddr, redeemScript, 40000, changeScript, rbf)
+		},
+	}
+	for name, buildTx := range build {
+		for _, rbf := range []bool{false, true} {
+			tx, err := buildTx(rbf)
+			if err != nil {
+				t.Fatalf("%s owner withdrawal (rbf %v) failed: %v", name, rbf, err)
+			}
+			expected := uint32(wire.MaxTxInSequenceNum)
+			if rbf {
+				expected = 0xfffffffd
+			}
+			if sequence := tx.TxIn[0].Sequence; sequence != expected {
+				t.Errorf("%s owner withdrawal (rbf %v): expected sequence 0x%08x, got 0x%08x", name, rbf, expected, sequence)
+			}
+		}
+	}
+
+	// The inheritor's CSV sequence is below RBFSequence, so the inheritor
+	// spend is replaceable without asking for it
+	tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, destAddr, redeemScript, inheritanceScript.RelativeTimelock, 0)
+	if err != nil {
+		t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+	}
+	if sequence := tx.TxIn[0].Sequence; sequence > RBFSequence {
+		t.Errorf("Inheritor sequence 0x%08x does not signal replaceability", sequence)
+	}
+}
+
+func TestValidateTransactionWithScript(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		180,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	redeemScript := inheritanceScript.RedeemScript
+	pkScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		pkScript []byte
+		tamper   func(tx *wire.MsgTx)
+		wantErr  string
+	}{
+		{"signed IF path", pkScript, nil, ""},
+		{"output script derived from redeem script", nil, nil, ""},
+		{"tampered signature", pkScript, func(tx *wire.MsgTx) { tx.TxIn[0].Witness[0][10] ^= 0x01 }, "does not satisfy"},
+		{"ELSE selector", pkScript, func(tx *wire.MsgTx) { tx.TxIn[0].Witness[1] = script.InheritorSelector }, "does not satisfy"},
+		{"tampered output", pkScript, func(tx *wire.MsgTx) { tx.TxOut[0].Value-- }, "does not satisfy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, utxo := createTestContract(t)
+			utxo.PkScript = tt.pkScript
+
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+			tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, createTestDestination(t), redeemScript, 0, false)
+			if err != nil {
+				t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+			}
+			if err := txBuilder.SignOwnerTransaction(tx, utxo, redeemScript, keys.NewLocalSigner(ownerKey)); err != nil {
+				t.Fatalf("SignOwnerTransaction failed: %v", err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(tx)
+			}
+
+			err = txBuilder.ValidateTransactionWithScript(tx, utxo, redeemScript)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateTransactionWithScript failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSignOwnerTransaction_ProvidedPkScript(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	destAddr := createTestDestination(t)
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	contractScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	otherScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+	wrongHash := append([]byte{txscript.OP_0, txscript.OP_DATA_32}, make([]byte, 32)...)
+
+	tests := []struct {
+		name     string
+		pkScript []byte
+		wantErr  string
+	}{
+		{"derived when missing", nil, ""},
+		{"matching P2WSH", contractScript, ""},
+		{"not P2WSH", otherScript, "not a P2WSH output"},
+		{"different script hash", wrongHash, "does not commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, 0, false)
+			if err != nil {
+				t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+			}
+
+			provided := *utxo
+			provided.PkScript = tt.pkScript
+			err = txBuilder.SignOwnerTransaction(tx, &provided, inheritanceScript.RedeemScript, keys.NewLocalSigner(ownerKey))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(tx.TxIn[0].Witness) != 3 {
+					t.Errorf("Expected 3 witness items, got %d", len(tx.TxIn[0].Witness))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestP2WSHPkScript_MatchesContractAddress(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+
+	contractScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	derived := *utxo
+	derived.PkScript = nil
+	pkScript, err := p2wshPkScript(&derived, inheritanceScript.RedeemScript)
+	if err != nil {
+		t.Fatalf("p2wshPkScript failed: %v", err)
+	}
+	if !bytes.Equal(pkScript, contractScript) {
+		t.Fatalf("Reconstructed script %x differs from the contract's %x", pkScript, contractScript)
+	}
+	if len(pkScript) != 34 {
+		t.Errorf("Expected a 34-byte P2WSH script, got %d bytes", len(pkScript))
+	}
+
+	// A spend signed without a PkScript executes against the real output
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	spendable, err := script.NewInheritanceScript(ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(), 180, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	tx, err := txBuilder.BuildOwnerWithdrawTx(&derived, createTestDestination(t), spendable.RedeemScript, 0, false)
+	if err != nil {
+		t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+	}
+	if err := txBuilder.SignOwnerTransaction(tx, &derived, spendable.RedeemScript, keys.NewLocalSigner(ownerKey)); err != nil {
+		t.Fatalf("SignOwnerTransaction failed: %v", err)
+	}
+	withScript := derived
+	withScript.PkScript, err = spendable.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	if err := txBuilder.ValidateTransactionWithScript(tx, &withScript, spendable.RedeemScript); err != nil {
+		t.Errorf("Signature made without a PkScript does not verify against the funding output: %v", err)
+	}
+}
+
+func TestSignInheritorTransaction_GrindLowR(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	destAddr := createTestDestination(t)
+
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	// Vary the fee so every transaction has a different signature hash
+	for fee := btcutil.Amount(500); fee < 532; fee++ {
+		txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, fee)
+		txBuilder.SetGrindLowR(true)
+
+		tx, err := txBuilder.BuildInheritorWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, inheritanceScript.RelativeTimelock, 0)
+		if err != nil {
+			t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+		}
+		if err := txBuilder.SignInheritorTransaction(tx, utxo, inheritanceScript.RedeemScript, keys.NewLocalSigner(inheritorKey)); err != nil {
+			t.Fatalf("SignInheritorTransaction failed: %v", err)
+		}
+
+		// 71-byte DER signature plus the sighash byte
+		if sigLen := len(tx.TxIn[0].Witness[0]); sigLen > 72 {
+			t.Errorf("Fee %d: expected a signature of at most 72 bytes, got %d", fee, sigLen)
+		}
+	}
+}
+
+func TestSign_LowRByDefault(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	destAddr := createTestDestination(t)
+
+	signerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer := keys.NewLocalSigner(signerKey)
+
+	// Without grinding about half of these signatures would have a high R
+	for fee := btcutil.Amount(500); fee < 564; fee++ {
+		txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, fee)
+		owner, err := txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, 0, false)
+		if err != nil {
+			t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+		}
+		if err := txBuilder.SignOwnerTransaction(owner, utxo, inheritanceScript.RedeemScript, signer); err != nil {
+			t.Fatalf("SignOwnerTransaction failed: %v", err)
+		}
+		inheritor, err := txBuilder.BuildInheritorWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, inheritanceScript.RelativeTimelock, 0)
+		if err != nil {
+			t.Fatalf("BuildInheritorWithdrawTx failed: %v", err)
+		}
+		if err := txBuilder.SignInheritorTransaction(inheritor, utxo, inheritanceScript.RedeemScript, signer); err != nil {
+			t.Fatalf("SignInheritorTransaction failed: %v", err)
+		}
+
+		for _, witnessSig := range [][]byte{owner.TxIn[0].Witness[0], inheritor.TxIn[0].Witness[0]} {
+			der := witnessSig[:len(witnessSig)-1]
+			// DER layout: 0x30 <len> 0x02 <len(R)> <R>; a high R needs a zero pad byte
+			if der[3] > 32 || der[4]&0x80 != 0 {
+				t.Errorf("Fee %d: signature %x has a high R value", fee, der)
+			}
+			if len(der) > 71 {
+				t.Errorf("Fee %d: expected a DER signature of at most 71 bytes, got %d", fee, len(der))
+			}
+		}
+	}
+}
+
+func TestSignOwnerTransaction_AddressTypes(t *testing.T) {
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKey.PubKey().SerializeCompressed(),
+		inheritorKey.PubKey().SerializeCompressed(),
+		180,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	redeemScript := inheritanceScript.RedeemScript
+
+	p2wshScript, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+	nestedAddr, err := btcutil.NewAddressScriptHash(p2wshScript, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash failed: %v", err)
+	}
+	nestedScript, err := txscript.PayToAddrScript(nestedAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		addressType script.AddressType
+		pkScript    []byte
+		wantErr     string
+	}{
+		{"p2wsh", script.AddressTypeP2WSH, p2wshScript, ""},
+		{"p2sh-p2wsh", script.AddressTypeP2SHP2WSH, nestedScript, ""},
+		{"p2sh-p2wsh signed as p2wsh", script.AddressTypeP2WSH, nestedScript, "not a P2WSH output"},
+		{"p2wsh signed as p2sh-p2wsh", script.AddressTypeP2SHP2WSH, p2wshScript, "does not commit"},
+		{"p2tr", script.AddressTypeP2TR, nil, "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, utxo := createTestContract(t)
+			utxo.PkScript = tt.pkScript
+			utxo.AddressType = tt.addressType
+
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+			tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, createTestDestination(t), redeemScript, 0, false)
+			if err != nil {
+				t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+			}
+
+			err = txBuilder.SignOwnerTransaction(tx, utxo, redeemScript, keys.NewLocalSigner(ownerKey))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SignOwnerTransaction failed: %v", err)
+			}
+
+			// Run the spend through the script interpreter
+			prevOuts := txscript.NewCannedPrevOutputFetcher(tt.pkScript, int64(utxo.Amount))
+			engine, err := txscript.NewEngine(tt.pkScript, tx, 0, txscript.StandardVerifyFlags,
+				nil, txscript.NewTxSigHashes(tx, prevOuts), int64(utxo.Amount), prevOuts)
+			if err != nil {
+				t.Fatalf("NewEngine failed: %v", err)
+			}
+			if err := engine.Execute(); err != nil {
+				t.Errorf("Signed spend does not validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignOwnerTransaction_P2SHP2WSHScriptSig(t *testing.T) {
+	ownerKeys, err := keys.NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	inheritorKeys, err := keys.NewKeyPair(&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("Failed to generate inheritor key: %v", err)
+	}
+	inheritanceScript, err := script.NewInheritanceScript(
+		ownerKeys.GetCompressedPubKeyBytes(),
+		inheritorKeys.GetCompressedPubKeyBytes(),
+		180,
+		&chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatalf("NewInheritanceScript failed: %v", err)
+	}
+	redeemScript := inheritanceScript.RedeemScript
+
+	nestedAddr, err := inheritanceScript.GetP2SHP2WSHAddress()
+	if err != nil {
+		t.Fatalf("GetP2SHP2WSHAddress failed: %v", err)
+	}
+	if !strings.HasPrefix(nestedAddr.EncodeAddress(), "2") {
+		t.Errorf("Testnet P2SH-P2WSH address should start with '2', got %s", nestedAddr.EncodeAddress())
+	}
+	pkScript, err := txscript.PayToAddrScript(nestedAddr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript failed: %v", err)
+	}
+	witnessProgram, err := inheritanceScript.GetScriptPubKey()
+	if err != nil {
+		t.Fatalf("GetScriptPubKey failed: %v", err)
+	}
+
+	_, utxo := createTestContract(t)
+	utxo.PkScript = pkScript
+	utxo.AddressType = script.AddressTypeP2SHP2WSH
+
+	txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+	tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, createTestDestination(t), redeemScript, 0, false)
+	if err != nil {
+		t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+	}
+	if err := txBuilder.SignOwnerTransaction(tx, utxo, redeemScript, ownerKeys.Signer()); err != nil {
+		t.Fatalf("SignOwnerTransaction failed: %v", err)
+	}
+
+	// The signature script pushes only the OP_0 <32-byte hash> witness program
+	pushes, err := txscript.PushedData(tx.TxIn[0].SignatureScript)
+	if err != nil {
+		t.Fatalf("Failed to parse signature script: %v", err)
+	}
+	if len(pushes) != 1 || !bytes.Equal(pushes[0], witnessProgram) {
+		t.Errorf("Signature script %x should push the witness program %x", tx.TxIn[0].SignatureScript, witnessProgram)
+	}
+	if len(witnessProgram) != 34 || witnessProgram[0] != txscript.OP_0 || witnessProgram[1] != txscript.OP_DATA_32 {
+		t.Errorf("Witness program %x is not OP_0 <32-byte hash>", witnessProgram)
+	}
+
+	// The witness is the same as for native P2WSH: signature, selector, script
+	witness := tx.TxIn[0].Witness
+	if len(witness) != 3 {
+		t.Fatalf("Expected 3 witness items, got %d", len(witness))
+	}
+	if !bytes.Equal(witness[1], script.OwnerSelector) {
+		t.Errorf("Expected owner selector %x, got %x", script.OwnerSelector, witness[1])
+	}
+	if !bytes.Equal(witness[2], redeemScript) {
+		t.Errorf("Last witness item should be the redeem script")
+	}
+
+	if err := txBuilder.ValidateTransactionWithScript(tx, utxo, redeemScript); err != nil {
+		t.Errorf("Nested spend does not validate: %v", err)
+	}
+}
+
+// externalSigner mimics an HSM-backed keys.Signer: it cannot grind low-R
+// signatures and may return high-S signatures or report the wrong key
+type externalSigner struct {
+	privKey *btcec.PrivateKey
+	pubKey  *btcec.PublicKey
+	highS   bool
+}
+
+func (s *externalSigner) PublicKey() *btcec.PublicKey {
+	return s.pubKey
+}
+
+func (s *externalSigner) Sign(hash []byte) ([]byte, error) {
+	der := ecdsa.Sign(s.privKey, hash).Serialize()
+	if !s.highS {
+		return der, nil
+	}
+
+	// DER layout: 0x30 <len> 0x02 <len(R)> <R> 0x02 <len(S)> <S>
+	rLen := int(der[3])
+	r := der[4 : 4+rLen]
+	var sValue btcec.ModNScalar
+	sValue.SetByteSlice(der[6+rLen:])
+	sValue.Negate()
+	sBytes := sValue.Bytes()
+	highS := append([]byte{0x00}, sBytes[:]...)
+
+	out := []byte{0x30, byte(4 + len(r) + len(highS)), 0x02, byte(len(r))}
+	out = append(out, r...)
+	out = append(out, 0x02, byte(len(highS)))
+	return append(out, highS...), nil
+}
+
+func TestSignOwnerTransaction_ExternalSigner(t *testing.T) {
+	inheritanceScript, utxo := createTestContract(t)
+	destAddr := createTestDestination(t)
+
+	ownerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	otherKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		signer  keys.Signer
+		wantErr string
+	}{
+		{"low-S signature", &externalSigner{privKey: ownerKey, pubKey: ownerKey.PubKey()}, ""},
+		{"high-S signature normalized", &externalSigner{privKey: ownerKey, pubKey: ownerKey.PubKey(), highS: true}, ""},
+		{"wrong public key", &externalSigner{privKey: ownerKey, pubKey: otherKey.PubKey()}, "does not verify"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Grinding is requested but the signer cannot do it
+			txBuilder := NewTransactionBuilder(&chaincfg.TestNet3Params, btcutil.Amount(500))
+			txBuilder.SetGrindLowR(true)
+
+			tx, err := txBuilder.BuildOwnerWithdrawTx(utxo, destAddr, inheritanceScript.RedeemScript, 0, false)
+			if err != nil {
+				t.Fatalf("BuildOwnerWithdrawTx failed: %v", err)
+			}
+
+			err = txBuilder.SignOwnerTransaction(tx, utxo, inheritanceScript.RedeemScript, tt.signer)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SignOwnerTransaction failed: %v", err)
+			}
+
+			// Serialize always encodes a low S, so a round trip must not change it
+			witnessSig := tx.TxIn[0].Witness[0]
+			der := witnessSig[:len(witnessSig)-1]
+			sig, err := ecdsa.ParseDERSignature(der)
+			if err != nil {
+				t.Fatalf("Witness signature does not parse: %v", err)
+			}
+			if !bytes.Equal(sig.Serialize(), der) {
+				t.Errorf("Witness signature is not low-S encoded: %x", der)
+			}
+		})
+	}
+}